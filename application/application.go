@@ -1,29 +1,49 @@
 package application
 
 import (
-	"errors"
+	"crypto/rand"
+	"crypto/tls"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path"
+	"syscall"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/util"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/web"
 	"github.com/pivotal-cf-experimental/warrant"
 	"github.com/pivotal-golang/lager"
 )
 
-const WorkerCount = 10
+const (
+	WorkerCount         = 10
+	JobSealingBatchSize = 100
+	LeaderLease         = "singleton-duties"
+	TraceBatchSize      = 512
+	TraceFlushInterval  = 5 * time.Second
+)
 
 type Application struct {
 	env        Environment
 	logger     lager.Logger
 	dbProvider *DBProvider
 	migrator   Migrator
+	elector    *LeaderElector
+
+	smtpCertificates []tls.Certificate
+	smtpTokens       mail.TokenSource
+
+	tracer       *tracing.Tracer
+	otlpExporter *tracing.OTLPExporter
 }
 
 func New(env Environment, dbp *DBProvider) Application {
@@ -32,12 +52,44 @@ func New(env Environment, dbp *DBProvider) Application {
 	l := lager.NewLogger("notifications")
 	l.RegisterSink(lager.NewWriterSink(os.Stdout, lager.DEBUG))
 
-	return Application{
+	app := Application{
 		env:        env,
 		logger:     l,
 		dbProvider: dbp,
-		migrator:   NewMigrator(dbp, databaseMigrator, env.VCAPApplication.InstanceIndex == 0, env.ModelMigrationsPath, env.GobbleMigrationsPath, path.Join(env.RootPath, "templates", "default.json")),
+		migrator:   NewMigrator(dbp, databaseMigrator, true, env.ModelMigrationsPath, env.GobbleMigrationsPath, path.Join(env.RootPath, "templates", "default.json")),
+		elector: NewLeaderElector(LeaderElectorConfig{
+			Name:          LeaderLease,
+			Holder:        leaseHolder(),
+			LeaseDuration: time.Duration(env.LeaderLeaseDuration) * time.Millisecond,
+			RenewInterval: time.Duration(env.LeaderRenewInterval) * time.Millisecond,
+
+			Leases: NewSQLLeaseStore(dbp.sqlDB),
+			Clock:  util.NewClock(),
+			Logger: l,
+		}),
+
+		smtpCertificates: env.SMTPClientCertificates,
+		smtpTokens:       smtpTokenSource(env),
 	}
+
+	var exporter tracing.Exporter = tracing.NopExporter{}
+	switch env.TracingExporter {
+	case tracing.ExporterStdout:
+		exporter = tracing.NewWriterExporter(os.Stdout)
+	case tracing.ExporterOTLP:
+		app.otlpExporter = tracing.NewOTLPExporter(tracing.OTLPExporterConfig{
+			Endpoint:      env.TracingOTLPEndpoint,
+			ServiceName:   "notifications",
+			BatchSize:     TraceBatchSize,
+			FlushInterval: TraceFlushInterval,
+			Client:        &http.Client{Timeout: 10 * time.Second},
+			Logger:        l.Session("tracing"),
+		})
+		exporter = app.otlpExporter
+	}
+	app.tracer = tracing.NewTracer(exporter, util.NewClock(), rand.Reader)
+
+	return app
 }
 
 func (a Application) mailClient() *mail.Client {
@@ -52,13 +104,129 @@ func (a Application) mailClient() *mail.Client {
 		DisableTLS:        !a.env.SMTPTLS,
 		LoggingEnabled:    a.env.SMTPLoggingEnabled,
 		SMTPAuthMechanism: a.env.SMTPAuthMechanism,
+
+		TLSMode:            a.env.SMTPTLSMode,
+		ClientCertificates: a.smtpCertificates,
+		TokenSource:        a.smtpTokens,
 	})
 }
 
+// leaseHolder identifies this process in the leader election. The host name
+// is only there to tell instances apart in the logs and the leases table.
+func leaseHolder() string {
+	hostname, _ := os.Hostname()
+
+	id, err := util.NewIDGenerator(rand.Reader).Generate()
+	if err != nil {
+		panic(err)
+	}
+
+	return hostname + "-" + id
+}
+
+// smtpTokenSource shares a single token source between all mail clients so
+// that the access token for XOAUTH2 is only refreshed once for all workers.
+func smtpTokenSource(env Environment) mail.TokenSource {
+	if env.SMTPOAuthTokenURL == "" {
+		return nil
+	}
+
+	return mail.NewRefreshingTokenSource(mail.OAuthConfig{
+		TokenURL:      env.SMTPOAuthTokenURL,
+		ClientID:      env.SMTPOAuthClientID,
+		ClientSecret:  env.SMTPOAuthClientSecret,
+		RefreshToken:  env.SMTPOAuthRefreshToken,
+		SkipVerifySSL: !env.VerifySSL,
+	})
+}
+
+// Run serves the API and delivers the queued notifications from a single
+// process.
 func (a Application) Run() {
+	a.VerifySMTPConfiguration()
+	validator := a.tokenValidator()
 
+	a.ResignOnShutdown()
+	a.elector.Run(a.Migrate, a.StartQueueGauge, a.StartMessageGC)
+
+	a.StartTraceExporter()
+	a.StartWorkers(validator, WorkerCount)
+	a.StartBouncePoller()
+	a.StartKeyRefresher(validator)
+	a.StartServer(a.logger, validator)
+}
+
+// Serve runs the API without any workers. The instances serving the API
+// still elect a leader among themselves to run the singleton duties.
+func (a Application) Serve() {
 	a.VerifySMTPConfiguration()
+	validator := a.tokenValidator()
+
+	a.ResignOnShutdown()
+	a.elector.Run(a.Migrate, a.StartQueueGauge, a.StartMessageGC)
 
+	a.StartTraceExporter()
+	a.StartKeyRefresher(validator)
+	a.StartServer(a.logger, validator)
+}
+
+// Work delivers the queued notifications with the given number of workers,
+// and processes bounces, until the process is asked to stop. It expects the
+// database to have been migrated by the API or the migrate command. The
+// metrics and health of the workers are served on PORT.
+func (a Application) Work(workerCount int) {
+	a.VerifySMTPConfiguration()
+	validator := a.tokenValidator()
+
+	a.StartTraceExporter()
+	a.StartWorkers(validator, workerCount)
+	a.StartBouncePoller()
+	a.StartKeyRefresher(validator)
+	go a.StartWorkerServer(a.logger)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	<-signals
+}
+
+// RunMigrations migrates the database, loads the default template and seals
+// the payloads of the jobs that were enqueued before payloads were sealed,
+// then returns.
+func (a Application) RunMigrations() {
+	a.migrator.Migrate()
+	a.SealJobPayloads()
+}
+
+// CollectMessages removes the expired messages, attachments and fan-outs
+// once, for deployments that schedule the collection themselves.
+func (a Application) CollectMessages() {
+	a.messageGC().Collect()
+}
+
+// SendTestEmail sends a short message to the given address through the
+// configured SMTP server, to check the configuration end to end.
+func (a Application) SendTestEmail(to string) error {
+	message := mail.Message{
+		From:    a.env.Sender,
+		To:      to,
+		Subject: "Notifications test email",
+		Body: []mail.Part{
+			{
+				ContentType: "text/plain",
+				Content:     "This email was sent by the notifications send-test-email command to check the SMTP configuration.",
+			},
+		},
+	}
+
+	err := message.CompileBody()
+	if err != nil {
+		return err
+	}
+
+	return a.mailClient().Send(message, a.logger.Session("send-test-email"))
+}
+
+func (a Application) tokenValidator() *uaa.TokenValidator {
 	uaaClient := warrant.New(warrant.Config{
 		Host:          a.env.UAAHost,
 		SkipVerifySSL: !a.env.VerifySSL,
@@ -70,51 +238,70 @@ func (a Application) Run() {
 		a.logger.Fatal("uaa-get-token-key-errored", err)
 	}
 
-	a.migrator.Migrate()
-
-	a.StartQueueGauge()
-	a.StartWorkers(validator)
-	a.StartMessageGC()
-	a.StartKeyRefresher(validator)
-	a.StartServer(a.logger, validator)
+	return validator
 }
 
 func (a Application) VerifySMTPConfiguration() {
-	if a.env.TestMode {
-		return
-	}
-
-	mc := a.mailClient()
-	err := mc.Connect(a.logger)
-	if err != nil {
+	err := a.mailClient().Verify(a.logger)
+	switch err.(type) {
+	case nil:
+	case mail.TLSMismatchError:
+		a.logger.Fatal("smtp-config-mismatch", err)
+	case mail.SMTPError:
+		a.logger.Fatal("smtp-hello-errored", err)
+	default:
 		a.logger.Fatal("smtp-connect-errored", err)
 	}
+}
 
-	err = mc.Hello()
-	if err != nil {
-		a.logger.Fatal("smtp-hello-errored", err)
+func (a Application) StartTraceExporter() {
+	if a.otlpExporter == nil {
+		return
 	}
 
-	startTLSSupported, _ := mc.Extension("STARTTLS")
+	a.otlpExporter.Run()
+}
 
-	mc.Quit()
+// ResignOnShutdown hands the duties of the leader over to another instance
+// when the process is asked to stop, rather than leaving them undone until
+// the lease expires.
+func (a Application) ResignOnShutdown() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
 
-	if !startTLSSupported && a.env.SMTPTLS {
-		a.logger.Fatal("smtp-config-mismatch", errors.New(`SMTP TLS configuration mismatch: Configured to use TLS over SMTP, but the mail server does not support the "STARTTLS" extension.`))
-	}
+	go func() {
+		<-signals
+		a.elector.Resign()
+		os.Exit(0)
+	}()
+}
 
-	if startTLSSupported && !a.env.SMTPTLS {
-		a.logger.Fatal("smtp-config-mismatch", errors.New(`SMTP TLS configuration mismatch: Not configured to use TLS over SMTP, but the mail server does support the "STARTTLS" extension.`))
-	}
+func (a Application) Migrate(stop <-chan struct{}) {
+	a.RunMigrations()
 }
 
-func (a Application) StartQueueGauge() {
-	if a.env.VCAPApplication.InstanceIndex != 0 {
+func (a Application) SealJobPayloads() {
+	queue := gobble.NewQueue(a.dbProvider.GobbleDatabase(), util.NewClock(), gobble.Config{
+		Sealer: a.dbProvider.KeyRing(),
+	})
+
+	count, err := queue.SealPayloads(JobSealingBatchSize)
+	if err != nil {
+		a.logger.Error("job-payload-sealing-failed", err)
 		return
 	}
 
-	queueGauge := gobble.NewQueueGauge(a.dbProvider.Queue(), time.Tick(time.Minute))
-	go queueGauge.Run()
+	a.logger.Info("job-payloads-sealed", lager.Data{"count": count})
+}
+
+func (a Application) StartQueueGauge(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+
+	queueGauge := gobble.NewQueueGauge(a.dbProvider.Queue(), ticker.C)
+	go func() {
+		queueGauge.Run(stop)
+		ticker.Stop()
+	}()
 }
 
 func (a Application) StartKeyRefresher(validator *uaa.TokenValidator) {
@@ -134,7 +321,7 @@ func (a Application) StartKeyRefresher(validator *uaa.TokenValidator) {
 	}()
 }
 
-func (a Application) StartWorkers(validator *uaa.TokenValidator) {
+func (a Application) StartWorkers(validator *uaa.TokenValidator, workerCount int) {
 	postal.Boot(a.mailClient, a.dbProvider.sqlDB, postal.Config{
 		UAAClientID:          a.env.UAAClientID,
 		UAAClientSecret:      a.env.UAAClientSecret,
@@ -142,26 +329,68 @@ func (a Application) StartWorkers(validator *uaa.TokenValidator) {
 		UAAHost:              a.env.UAAHost,
 		VerifySSL:            a.env.VerifySSL,
 		InstanceIndex:        a.env.VCAPApplication.InstanceIndex,
-		WorkerCount:          WorkerCount,
+		WorkerCount:          workerCount,
 		RootPath:             a.env.RootPath,
-		EncryptionKey:        a.env.EncryptionKey,
+		KeyRing:              a.dbProvider.KeyRing(),
 		DBLoggingEnabled:     a.env.DBLoggingEnabled,
 		Sender:               a.env.Sender,
 		Domain:               a.env.Domain,
 		QueueWaitMaxDuration: a.env.GobbleWaitMaxDuration,
 		CCHost:               a.env.CCHost,
+
+		DomainThrottleRate:      a.env.DomainThrottleRate,
+		DomainThrottleOverrides: a.env.DomainThrottleOverrides,
+
+		DeliveryMaxRetries:   a.env.DeliveryMaxRetries,
+		DeliveryRetryBackoff: a.env.DeliveryRetryBackoff,
+
+		UAAUserCacheDuration: a.env.UAAUserCacheDuration,
+		FanOutChunkSize:      a.env.FanOutChunkSize,
+
+		Tracer: a.tracer,
 	})
 }
 
-func (a Application) StartMessageGC() {
-	messageLifetime := 24 * time.Hour
-	db := a.dbProvider.Database()
-	messagesRepo := a.dbProvider.MessagesRepo()
-	pollingInterval := 1 * time.Hour
+func (a Application) StartMessageGC(stop <-chan struct{}) {
+	a.messageGC().Run(stop)
+}
+
+func (a Application) messageGC() postal.MessageGC {
+	config := postal.MessageGCConfig{
+		Retention: models.RetentionPolicy{
+			Default:  a.env.MessageRetention,
+			ByStatus: a.env.MessageRetentionByStatus,
+		},
+		BatchSize:       a.env.MessageGCBatchSize,
+		PollingInterval: time.Duration(a.env.MessageGCPollingInterval) * time.Millisecond,
+
+		Database:    a.dbProvider.Database(),
+		Messages:    a.dbProvider.MessagesRepo(),
+		Attachments: a.dbProvider.AttachmentsRepo(),
+		FanOuts:     a.dbProvider.FanOutsRepo(),
+		Logger:      log.New(os.Stdout, "", 0),
+	}
+
+	if a.env.MessageArchivePath != "" {
+		config.Recipients = models.NewMessageRecipientsRepo()
+		config.Archiver = postal.NewFileArchiver(a.env.MessageArchivePath)
+	}
+
+	return postal.NewMessageGC(config)
+}
+
+// StartBouncePoller reads delivery status notifications and complaints from
+// the maildir the bounce address is delivered to, when one is configured.
+func (a Application) StartBouncePoller() {
+	if a.env.BounceMaildir == "" {
+		return
+	}
+
+	processor := bounces.NewProcessor(a.dbProvider.MessagesRepo(), models.NewMessageRecipientsRepo(), a.dbProvider.SuppressionsRepo())
+	pollingInterval := time.Duration(a.env.BouncePollingInterval) * time.Millisecond
 
-	logger := log.New(os.Stdout, "", 0)
-	messageGC := postal.NewMessageGC(messageLifetime, db, messagesRepo, pollingInterval, logger)
-	messageGC.Run()
+	poller := bounces.NewMaildirPoller(a.env.BounceMaildir, a.dbProvider.Database(), processor, pollingInterval, a.logger)
+	poller.Run()
 }
 
 func (a Application) StartServer(logger lager.Logger, validator *uaa.TokenValidator) {
@@ -173,6 +402,7 @@ func (a Application) StartServer(logger lager.Logger, validator *uaa.TokenValida
 		CORSOrigin:           a.env.CORSOrigin,
 		SQLDB:                a.dbProvider.sqlDB,
 		Queue:                a.dbProvider.Queue(),
+		KeyRing:              a.dbProvider.KeyRing(),
 		QueueWaitMaxDuration: a.env.GobbleWaitMaxDuration,
 
 		UAATokenValidator: validator,
@@ -181,6 +411,26 @@ func (a Application) StartServer(logger lager.Logger, validator *uaa.TokenValida
 		UAAClientSecret:   a.env.UAAClientSecret,
 		DefaultUAAScopes:  a.env.DefaultUAAScopes,
 		CCHost:            a.env.CCHost,
+
+		MailClient:                a.mailClient,
+		UAAKeyRefreshInterval:     a.env.UAAKeyRefreshInterval,
+		HealthCacheDuration:       a.env.HealthCacheDuration,
+		HealthQueueDepthThreshold: a.env.HealthQueueDepthThreshold,
+		MaxRequestBodySize:        a.env.MaxRequestBodySize,
+
+		Tracer: a.tracer,
+	})
+}
+
+func (a Application) StartWorkerServer(logger lager.Logger) {
+	web.NewServer().RunWorker(web.WorkerConfig{
+		Port:     a.env.Port,
+		Database: a.dbProvider.sqlDB,
+		Queue:    a.dbProvider.Queue(),
+		Logger:   logger,
+
+		HealthCacheDuration:       a.env.HealthCacheDuration,
+		HealthQueueDepthThreshold: a.env.HealthQueueDepthThreshold,
 	})
 }
 
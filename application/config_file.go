@@ -0,0 +1,294 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// renamedVariables maps the former names of variables to their current ones,
+// so that deployments setting the former name keep working.
+var renamedVariables = map[string]string{
+	"UAA_KEY_REFRESH_INTREVAL": "UAA_KEY_REFRESH_INTERVAL",
+}
+
+func applyRenamedVariables() {
+	for former, current := range renamedVariables {
+		if os.Getenv(current) == "" && os.Getenv(former) != "" {
+			os.Setenv(current, os.Getenv(former))
+		}
+	}
+}
+
+// loadConfigFile reads the settings in the file at path into the environment.
+// The keys of the file are the names of the environment variables in lower
+// case, and variables that are already set take precedence over the file.
+func loadConfigFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Could not read CONFIG_FILE %q: %s", path, err)
+	}
+
+	var settings map[string]string
+	switch filepath.Ext(path) {
+	case ".json":
+		settings, err = parseJSONConfig(data)
+	case ".yml", ".yaml":
+		settings, err = parseYAMLConfig(data)
+	default:
+		err = fmt.Errorf("it must end in .json, .yml or .yaml")
+	}
+	if err != nil {
+		return fmt.Errorf("Could not parse CONFIG_FILE %q, %s", path, err)
+	}
+
+	variables := configVariables()
+
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		if !contains(variables, name) {
+			return fmt.Errorf("Could not parse CONFIG_FILE %q, %s", path, unknownSettingMessage(key, variables))
+		}
+
+		if os.Getenv(name) == "" {
+			os.Setenv(name, settings[key])
+		}
+	}
+
+	return nil
+}
+
+func parseJSONConfig(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	err := decoder.Decode(&values)
+	if err != nil {
+		return nil, fmt.Errorf("it is not a JSON object: %s", err)
+	}
+
+	settings := map[string]string{}
+	for key, value := range values {
+		switch v := value.(type) {
+		case string:
+			settings[key] = v
+		case json.Number:
+			settings[key] = v.String()
+		case bool:
+			settings[key] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("%q must be a string, number or boolean", key)
+		}
+	}
+
+	return settings, nil
+}
+
+// parseYAMLConfig reads the subset of YAML that a flat list of settings
+// needs: one "key: value" pair per line, with optional quotes and comments.
+func parseYAMLConfig(data []byte) (map[string]string, error) {
+	settings := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if trimmed != line || strings.HasPrefix(line, "- ") {
+			return nil, fmt.Errorf("line %d is not a top level %q pair", number, "key: value")
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("line %d is not a %q pair", number, "key: value")
+		}
+
+		key := strings.TrimSpace(parts[0])
+		if _, ok := settings[key]; ok {
+			return nil, fmt.Errorf("%q is set more than once", key)
+		}
+
+		value, err := parseYAMLScalar(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d has %s", number, err)
+		}
+
+		settings[key] = value
+	}
+
+	return settings, scanner.Err()
+}
+
+func parseYAMLScalar(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("an invalid double quoted value %s", value)
+		}
+
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("an invalid single quoted value %s", value)
+		}
+
+		return strings.Replace(value[1:len(value)-1], "''", "'", -1), nil
+	case strings.HasPrefix(value, "{"), strings.HasPrefix(value, "["):
+		return "", fmt.Errorf("a value that is not a string, number or boolean")
+	}
+
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+
+	return value, nil
+}
+
+// configVariables lists the environment variables that can be set in a
+// configuration file.
+func configVariables() []string {
+	var variables []string
+
+	t := reflect.TypeOf(Environment{})
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("env")
+		if name == "" || name == "VCAP_APPLICATION" {
+			continue
+		}
+
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+
+	return variables
+}
+
+func unknownSettingMessage(key string, variables []string) string {
+	message := fmt.Sprintf("%q is not a known setting", key)
+
+	closest, distance := "", 3
+	for _, variable := range variables {
+		d := editDistance(strings.ToLower(key), strings.ToLower(variable))
+		if d < distance {
+			closest, distance = strings.ToLower(variable), d
+		}
+	}
+
+	if closest != "" {
+		message += fmt.Sprintf(", did you mean %q?", closest)
+	}
+
+	return message
+}
+
+// editDistance counts the insertions, deletions and substitutions that turn
+// a into b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = smallest(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func smallest(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteConfig writes the effective configuration in the format of a YAML
+// configuration file. The values of the settings tagged as secret are
+// replaced, unless they are empty.
+func (env Environment) WriteConfig(w io.Writer) error {
+	lines := map[string]string{}
+
+	t := reflect.TypeOf(env)
+	v := reflect.ValueOf(env)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("env")
+		if name == "" || name == "VCAP_APPLICATION" {
+			continue
+		}
+
+		var value string
+		switch field.Type.Kind() {
+		case reflect.String:
+			value = v.Field(i).String()
+		case reflect.Slice:
+			value = string(v.Field(i).Bytes())
+		default:
+			lines[name] = fmt.Sprintf("%v", v.Field(i).Interface())
+			continue
+		}
+
+		if field.Tag.Get("secret") == "true" && value != "" {
+			value = redacted
+		}
+		lines[name] = strconv.Quote(value)
+	}
+
+	for _, name := range configVariables() {
+		_, err := fmt.Fprintf(w, "%s: %s\n", strings.ToLower(name), lines[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,54 +1,94 @@
 package application
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/keyring"
 	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/ryanmoran/viron"
 )
 
 type Environment struct {
-	CCHost                             string `env:"CC_HOST" env-required:"true"`
-	CORSOrigin                         string `env:"CORS_ORIGIN" env-default:"*"`
-	DBLoggingEnabled                   bool   `env:"DB_LOGGING_ENABLED"`
-	DBMaxOpenConns                     int    `env:"DB_MAX_OPEN_CONNS"`
-	DatabaseURL                        string `env:"DATABASE_URL" env-required:"true"`
-	DefaultUAAScopesList               string `env:"DEFAULT_UAA_SCOPES"`
-	Domain                             string `env:"DOMAIN" env-required:"true"`
-	EncryptionKey                      []byte `env:"ENCRYPTION_KEY" env-required:"true"`
-	GobbleWaitMaxDuration              int    `env:"GOBBLE_WAIT_MAX_DURATION" env-default:"5000"`
-	Port                               int    `env:"PORT" env-default:"3000"`
-	RootPath                           string `env:"ROOT_PATH"`
-	SMTPAuthMechanism                  string `env:"SMTP_AUTH_MECHANISM" env-required:"true"`
-	SMTPCRAMMD5Secret                  string `env:"SMTP_CRAMMD5_SECRET"`
-	SMTPHost                           string `env:"SMTP_HOST" env-required:"true"`
-	SMTPLoggingEnabled                 bool   `env:"SMTP_LOGGING_ENABLED" env-default:"false"`
-	SMTPPass                           string `env:"SMTP_PASS"`
-	SMTPPort                           string `env:"SMTP_PORT" env-required:"true"`
-	SMTPTLS                            bool   `env:"SMTP_TLS" env-default:"true"`
-	SMTPUser                           string `env:"SMTP_USER"`
-	Sender                             string `env:"SENDER" env-required:"true"`
-	TestMode                           bool   `env:"TEST_MODE" env-default:"false"`
-	UAAClientID                        string `env:"UAA_CLIENT_ID" env-required:"true"`
-	UAAClientSecret                    string `env:"UAA_CLIENT_SECRET" env-required:"true"`
-	UAAHost                            string `env:"UAA_HOST" env-required:"true"`
-	UAAKeyRefreshInterval              int    `env:"UAA_KEY_REFRESH_INTREVAL" env-default:"60000"`
-	VerifySSL                          bool   `env:"VERIFY_SSL" env-default:"true"`
-	DatabaseCACertFile                 string `env:"DATABASE_CA_CERT_FILE"`
-	DatabaseCommonName                 string `env:"DATABASE_COMMON_NAME"`
-	DatabaseEnableIdentityVerification bool   `env:"DATABASE_ENABLE_IDENTITY_VERIFICATION" env-default:"true"`
+	BounceMaildir                      string  `env:"BOUNCE_MAILDIR"`
+	BouncePollingInterval              int     `env:"BOUNCE_POLLING_INTERVAL" env-default:"60000"`
+	CCHost                             string  `env:"CC_HOST" env-required:"true"`
+	CORSOrigin                         string  `env:"CORS_ORIGIN" env-default:"*"`
+	DBLoggingEnabled                   bool    `env:"DB_LOGGING_ENABLED"`
+	DBMaxOpenConns                     int     `env:"DB_MAX_OPEN_CONNS"`
+	DatabaseURL                        string  `env:"DATABASE_URL" env-required:"true" secret:"true"`
+	DefaultUAAScopesList               string  `env:"DEFAULT_UAA_SCOPES"`
+	DeliveryMaxRetries                 int     `env:"DELIVERY_MAX_RETRIES" env-default:"10"`
+	DeliveryRetryBackoff               int     `env:"DELIVERY_RETRY_BACKOFF" env-default:"60000"`
+	Domain                             string  `env:"DOMAIN" env-required:"true"`
+	DomainThrottleRate                 float64 `env:"DOMAIN_THROTTLE_RATE"`
+	DomainThrottleOverridesList        string  `env:"DOMAIN_THROTTLE_OVERRIDES"`
+	EncryptionKey                      []byte  `env:"ENCRYPTION_KEY" env-required:"true" secret:"true"`
+	EncryptionKeyID                    string  `env:"ENCRYPTION_KEY_ID" env-default:"1"`
+	FanOutChunkSize                    int     `env:"FANOUT_CHUNK_SIZE" env-default:"100"`
+	GobbleWaitMaxDuration              int     `env:"GOBBLE_WAIT_MAX_DURATION" env-default:"5000"`
+	HealthCacheDuration                int     `env:"HEALTH_CACHE_DURATION" env-default:"10000"`
+	HealthQueueDepthThreshold          int     `env:"HEALTH_QUEUE_DEPTH_THRESHOLD" env-default:"10000"`
+	LeaderLeaseDuration                int     `env:"LEADER_LEASE_DURATION" env-default:"30000"`
+	LeaderRenewInterval                int     `env:"LEADER_RENEW_INTERVAL" env-default:"10000"`
+	MaxRequestBodySize                 int     `env:"MAX_REQUEST_BODY_SIZE" env-default:"16777216"`
+	MessageArchivePath                 string  `env:"MESSAGE_ARCHIVE_PATH"`
+	MessageGCBatchSize                 int     `env:"MESSAGE_GC_BATCH_SIZE" env-default:"1000"`
+	MessageGCPollingInterval           int     `env:"MESSAGE_GC_POLLING_INTERVAL" env-default:"3600000"`
+	MessageRetentionValue              string  `env:"MESSAGE_RETENTION" env-default:"24h"`
+	MessageRetentionByStatusList       string  `env:"MESSAGE_RETENTION_BY_STATUS"`
+	RetiredEncryptionKeysList          string  `env:"RETIRED_ENCRYPTION_KEYS" secret:"true"`
+	Port                               int     `env:"PORT" env-default:"3000"`
+	RootPath                           string  `env:"ROOT_PATH"`
+	SMTPAuthMechanism                  string  `env:"SMTP_AUTH_MECHANISM" env-required:"true"`
+	SMTPCRAMMD5Secret                  string  `env:"SMTP_CRAMMD5_SECRET" secret:"true"`
+	SMTPHost                           string  `env:"SMTP_HOST" env-required:"true"`
+	SMTPLoggingEnabled                 bool    `env:"SMTP_LOGGING_ENABLED" env-default:"false"`
+	SMTPPass                           string  `env:"SMTP_PASS" secret:"true"`
+	SMTPPort                           string  `env:"SMTP_PORT" env-required:"true"`
+	SMTPTLS                            bool    `env:"SMTP_TLS" env-default:"true"`
+	SMTPTLSMode                        string  `env:"SMTP_TLS_MODE"`
+	SMTPClientCertFile                 string  `env:"SMTP_CLIENT_CERT_FILE"`
+	SMTPClientKeyFile                  string  `env:"SMTP_CLIENT_KEY_FILE"`
+	SMTPOAuthTokenURL                  string  `env:"SMTP_OAUTH_TOKEN_URL"`
+	SMTPOAuthClientID                  string  `env:"SMTP_OAUTH_CLIENT_ID"`
+	SMTPOAuthClientSecret              string  `env:"SMTP_OAUTH_CLIENT_SECRET" secret:"true"`
+	SMTPOAuthRefreshToken              string  `env:"SMTP_OAUTH_REFRESH_TOKEN" secret:"true"`
+	SMTPUser                           string  `env:"SMTP_USER"`
+	Sender                             string  `env:"SENDER" env-required:"true"`
+	TestMode                           bool    `env:"TEST_MODE" env-default:"false"`
+	TracingExporter                    string  `env:"TRACING_EXPORTER" env-default:"none"`
+	TracingOTLPEndpoint                string  `env:"TRACING_OTLP_ENDPOINT"`
+	UAAClientID                        string  `env:"UAA_CLIENT_ID" env-required:"true"`
+	UAAClientSecret                    string  `env:"UAA_CLIENT_SECRET" env-required:"true" secret:"true"`
+	UAAHost                            string  `env:"UAA_HOST" env-required:"true"`
+	UAAKeyRefreshInterval              int     `env:"UAA_KEY_REFRESH_INTERVAL" env-default:"60000"`
+	UAAUserCacheDuration               int     `env:"UAA_USER_CACHE_DURATION" env-default:"60000"`
+	VerifySSL                          bool    `env:"VERIFY_SSL" env-default:"true"`
+	DatabaseCACertFile                 string  `env:"DATABASE_CA_CERT_FILE"`
+	DatabaseCommonName                 string  `env:"DATABASE_COMMON_NAME"`
+	DatabaseEnableIdentityVerification bool    `env:"DATABASE_ENABLE_IDENTITY_VERIFICATION" env-default:"true"`
 
 	VCAPApplication struct {
 		InstanceIndex int `json:"instance_index"`
 	} `env:"VCAP_APPLICATION" env-required:"true"`
 
-	ModelMigrationsPath  string
-	GobbleMigrationsPath string
-	DefaultUAAScopes     []string
+	ModelMigrationsPath      string
+	GobbleMigrationsPath     string
+	DefaultUAAScopes         []string
+	DomainThrottleOverrides  map[string]float64
+	MessageRetention         time.Duration
+	MessageRetentionByStatus map[string]time.Duration
+	RetiredEncryptionKeys    []keyring.Key
+	SMTPClientCertificates   []tls.Certificate
 }
 
 type EnvironmentError struct {
@@ -59,8 +99,20 @@ func (e EnvironmentError) Error() string {
 	return e.Err.Error() + " (Please see https://github.com/cloudfoundry-incubator/notifications-release to find a packaged version of notifications and see the required configuration)"
 }
 
+// NewEnvironment reads the configuration from the environment variables,
+// falling back to the file named by CONFIG_FILE for those that are not set.
 func NewEnvironment() (Environment, error) {
 	env := Environment{}
+
+	applyRenamedVariables()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		err := loadConfigFile(path)
+		if err != nil {
+			return env, EnvironmentError{err}
+		}
+	}
+
 	err := viron.Parse(&env)
 	if err != nil {
 		return env, EnvironmentError{err}
@@ -78,6 +130,66 @@ func NewEnvironment() (Environment, error) {
 		return env, EnvironmentError{err}
 	}
 
+	err = env.validateSMTPTLSMode()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateSMTPClientCertificate()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateSMTPOAuth()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateSMTPCredentials()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.parseDomainThrottleOverrides()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateDeliveryRetries()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateFanOutChunkSize()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateMaxRequestBodySize()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.parseMessageRetention()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.parseRetiredEncryptionKeys()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateLeaderElection()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
+	err = env.validateTracingExporter()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
 	env.inferMigrationsDirs()
 	env.parseDefaultUAAScopes()
 
@@ -88,6 +200,153 @@ func (env *Environment) parseDefaultUAAScopes() {
 	env.DefaultUAAScopes = strings.Split(env.DefaultUAAScopesList, ",")
 }
 
+// parseDomainThrottleOverrides reads a comma separated list of domain=rate
+// pairs, e.g. "gmail.com=10,example.com=0.5", that replace the
+// DOMAIN_THROTTLE_RATE for those domains.
+func (env *Environment) parseDomainThrottleOverrides() error {
+	if env.DomainThrottleRate < 0 {
+		return fmt.Errorf("Could not parse DOMAIN_THROTTLE_RATE %v, it must not be negative", env.DomainThrottleRate)
+	}
+
+	env.DomainThrottleOverrides = map[string]float64{}
+	if env.DomainThrottleOverridesList == "" {
+		return nil
+	}
+
+	for _, pair := range strings.Split(env.DomainThrottleOverridesList, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("Could not parse DOMAIN_THROTTLE_OVERRIDES %q, it does not fit format %q", env.DomainThrottleOverridesList, "domain=rate,domain=rate")
+		}
+
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("Could not parse DOMAIN_THROTTLE_OVERRIDES %q, %q is not a valid rate", env.DomainThrottleOverridesList, parts[1])
+		}
+
+		env.DomainThrottleOverrides[parts[0]] = rate
+	}
+
+	return nil
+}
+
+func (env *Environment) validateDeliveryRetries() error {
+	if env.DeliveryMaxRetries < 0 {
+		return fmt.Errorf("Could not parse DELIVERY_MAX_RETRIES %d, it must not be negative", env.DeliveryMaxRetries)
+	}
+
+	if env.DeliveryRetryBackoff <= 0 {
+		return fmt.Errorf("Could not parse DELIVERY_RETRY_BACKOFF %d, it must be positive", env.DeliveryRetryBackoff)
+	}
+
+	return nil
+}
+
+func (env *Environment) validateFanOutChunkSize() error {
+	if env.FanOutChunkSize <= 0 {
+		return fmt.Errorf("Could not parse FANOUT_CHUNK_SIZE %d, it must be positive", env.FanOutChunkSize)
+	}
+
+	return nil
+}
+
+func (env *Environment) validateMaxRequestBodySize() error {
+	if env.MaxRequestBodySize <= 0 {
+		return fmt.Errorf("Could not parse MAX_REQUEST_BODY_SIZE %d, it must be positive", env.MaxRequestBodySize)
+	}
+
+	return nil
+}
+
+// parseRetiredEncryptionKeys reads a comma separated list of id=key pairs,
+// e.g. "1=old-key,2=older-key", of the keys that ENCRYPTION_KEY replaced. The
+// keys are left out of the errors, since they are secret.
+func (env *Environment) parseRetiredEncryptionKeys() error {
+	env.RetiredEncryptionKeys = []keyring.Key{}
+
+	if env.RetiredEncryptionKeysList != "" {
+		for _, pair := range strings.Split(env.RetiredEncryptionKeysList, ",") {
+			parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("Could not parse RETIRED_ENCRYPTION_KEYS, it does not fit format %q", "id=key,id=key")
+			}
+
+			env.RetiredEncryptionKeys = append(env.RetiredEncryptionKeys, keyring.Key{ID: parts[0], Secret: []byte(parts[1])})
+		}
+	}
+
+	_, err := keyring.New(keyring.Key{ID: env.EncryptionKeyID, Secret: env.EncryptionKey}, env.RetiredEncryptionKeys...)
+	if err != nil {
+		return fmt.Errorf("Could not parse the encryption keys, %s", err)
+	}
+
+	return nil
+}
+
+// validateLeaderElection makes sure that the leader renews its lease well
+// before it expires.
+func (env *Environment) validateLeaderElection() error {
+	if env.LeaderRenewInterval <= 0 {
+		return fmt.Errorf("Could not parse LEADER_RENEW_INTERVAL %d, it must be positive", env.LeaderRenewInterval)
+	}
+
+	if env.LeaderLeaseDuration <= env.LeaderRenewInterval {
+		return fmt.Errorf("Could not parse LEADER_LEASE_DURATION %d, it must be longer than LEADER_RENEW_INTERVAL %d", env.LeaderLeaseDuration, env.LeaderRenewInterval)
+	}
+
+	return nil
+}
+
+// parseMessageRetention reads how long messages are kept from MESSAGE_RETENTION
+// and from a comma separated list of status=duration pairs, e.g.
+// "failed=720h,delivered=168h", that replace it for those statuses.
+func (env *Environment) parseMessageRetention() error {
+	if env.MessageGCBatchSize <= 0 {
+		return fmt.Errorf("Could not parse MESSAGE_GC_BATCH_SIZE %d, it must be positive", env.MessageGCBatchSize)
+	}
+
+	retention, err := time.ParseDuration(env.MessageRetentionValue)
+	if err != nil || retention <= 0 {
+		return fmt.Errorf("Could not parse MESSAGE_RETENTION %q, it must be a positive duration like %q", env.MessageRetentionValue, "24h")
+	}
+	env.MessageRetention = retention
+
+	env.MessageRetentionByStatus = map[string]time.Duration{}
+	if env.MessageRetentionByStatusList == "" {
+		return nil
+	}
+
+	for _, pair := range strings.Split(env.MessageRetentionByStatusList, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("Could not parse MESSAGE_RETENTION_BY_STATUS %q, it does not fit format %q", env.MessageRetentionByStatusList, "status=duration,status=duration")
+		}
+
+		if !isMessageStatus(parts[0]) {
+			return fmt.Errorf("Could not parse MESSAGE_RETENTION_BY_STATUS %q, %q is not one of the message statuses: %+v", env.MessageRetentionByStatusList, parts[0], common.Statuses)
+		}
+
+		retention, err := time.ParseDuration(parts[1])
+		if err != nil || retention <= 0 {
+			return fmt.Errorf("Could not parse MESSAGE_RETENTION_BY_STATUS %q, %q is not a valid duration", env.MessageRetentionByStatusList, parts[1])
+		}
+
+		env.MessageRetentionByStatus[parts[0]] = retention
+	}
+
+	return nil
+}
+
+func isMessageStatus(status string) bool {
+	for _, s := range common.Statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (env *Environment) expandRoot() {
 	env.RootPath = os.ExpandEnv(env.RootPath)
 }
@@ -123,3 +382,111 @@ func (env *Environment) validateSMTPAuthMechanism() error {
 
 	return fmt.Errorf("Could not parse SMTP_AUTH_MECHANISM %q, it is not one of the allowed values: %+v", env.SMTPAuthMechanism, mail.SMTPAuthMechanisms)
 }
+
+// validateSMTPTLSMode falls back to the SMTP_TLS flag when SMTP_TLS_MODE is
+// not set, so that existing deployments keep their behaviour. When both are
+// set, they must agree on whether TLS is used.
+func (env *Environment) validateSMTPTLSMode() error {
+	if env.SMTPTLSMode == "" {
+		env.SMTPTLSMode = mail.TLSModeNone
+		if env.SMTPTLS {
+			env.SMTPTLSMode = mail.TLSModeStartTLS
+		}
+
+		return nil
+	}
+
+	for _, mode := range mail.TLSModes {
+		if mode != env.SMTPTLSMode {
+			continue
+		}
+
+		if os.Getenv("SMTP_TLS") != "" && env.SMTPTLS != (mode != mail.TLSModeNone) {
+			return fmt.Errorf("SMTP_TLS %t contradicts SMTP_TLS_MODE %q", env.SMTPTLS, mode)
+		}
+
+		env.SMTPTLS = mode != mail.TLSModeNone
+		return nil
+	}
+
+	return fmt.Errorf("Could not parse SMTP_TLS_MODE %q, it is not one of the allowed values: %+v", env.SMTPTLSMode, mail.TLSModes)
+}
+
+func (env *Environment) validateSMTPClientCertificate() error {
+	if (env.SMTPClientCertFile == "") != (env.SMTPClientKeyFile == "") {
+		return fmt.Errorf("SMTP_CLIENT_CERT_FILE and SMTP_CLIENT_KEY_FILE must be set together")
+	}
+
+	if env.SMTPClientCertFile == "" {
+		return nil
+	}
+
+	if env.SMTPTLSMode == mail.TLSModeNone {
+		return fmt.Errorf("SMTP_CLIENT_CERT_FILE requires SMTP_TLS_MODE %q or %q", mail.TLSModeStartTLS, mail.TLSModeImplicit)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(env.SMTPClientCertFile, env.SMTPClientKeyFile)
+	if err != nil {
+		return fmt.Errorf("Could not load SMTP_CLIENT_CERT_FILE and SMTP_CLIENT_KEY_FILE, %s", err)
+	}
+
+	env.SMTPClientCertificates = []tls.Certificate{certificate}
+
+	return nil
+}
+
+func (env *Environment) validateTracingExporter() error {
+	for _, exporter := range tracing.Exporters {
+		if exporter != env.TracingExporter {
+			continue
+		}
+
+		if exporter == tracing.ExporterOTLP && env.TracingOTLPEndpoint == "" {
+			return fmt.Errorf("TRACING_OTLP_ENDPOINT is required when TRACING_EXPORTER is %q", tracing.ExporterOTLP)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Could not parse TRACING_EXPORTER %q, it is not one of the allowed values: %+v", env.TracingExporter, tracing.Exporters)
+}
+
+func (env *Environment) validateSMTPOAuth() error {
+	if env.SMTPOAuthTokenURL != "" && env.SMTPOAuthRefreshToken == "" {
+		return fmt.Errorf("SMTP_OAUTH_REFRESH_TOKEN is required when SMTP_OAUTH_TOKEN_URL is set")
+	}
+
+	return nil
+}
+
+// validateSMTPCredentials makes sure that the credentials SMTP_AUTH_MECHANISM
+// authenticates with are set. XOAUTH2 authenticates with a token that is
+// either fetched from SMTP_OAUTH_TOKEN_URL or given as is in SMTP_PASS.
+func (env *Environment) validateSMTPCredentials() error {
+	var required []string
+	switch env.SMTPAuthMechanism {
+	case mail.SMTPAuthPlain, mail.SMTPAuthLogin:
+		required = []string{"SMTP_USER", "SMTP_PASS"}
+	case mail.SMTPAuthCRAMMD5:
+		required = []string{"SMTP_USER", "SMTP_CRAMMD5_SECRET"}
+	case mail.SMTPAuthXOAUTH2:
+		required = []string{"SMTP_USER"}
+		if env.SMTPOAuthTokenURL == "" && env.SMTPPass == "" {
+			return fmt.Errorf("SMTP_OAUTH_TOKEN_URL or SMTP_PASS is required when SMTP_AUTH_MECHANISM is %q", env.SMTPAuthMechanism)
+		}
+	}
+
+	values := map[string]string{
+		"SMTP_USER":           env.SMTPUser,
+		"SMTP_PASS":           env.SMTPPass,
+		"SMTP_CRAMMD5_SECRET": env.SMTPCRAMMD5Secret,
+	}
+
+	for _, name := range required {
+		if values[name] == "" {
+			return fmt.Errorf("%s is required when SMTP_AUTH_MECHANISM is %q", name, env.SMTPAuthMechanism)
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,24 @@
 package application_test
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/application"
+	"github.com/cloudfoundry-incubator/notifications/keyring"
 	"github.com/ryanmoran/viron"
 
 	. "github.com/onsi/ginkgo"
@@ -14,16 +28,36 @@ import (
 var _ = Describe("Environment", func() {
 	var variables = map[string]string{}
 	var envVars = []string{
+		"BOUNCE_MAILDIR",
+		"BOUNCE_POLLING_INTERVAL",
 		"CC_HOST",
+		"CONFIG_FILE",
 		"CORS_ORIGIN",
 		"DATABASE_URL",
 		"DB_LOGGING_ENABLED",
 		"DB_MAX_OPEN_CONNS",
 		"DEFAULT_UAA_SCOPES",
+		"DELIVERY_MAX_RETRIES",
+		"DELIVERY_RETRY_BACKOFF",
 		"DOMAIN",
+		"DOMAIN_THROTTLE_RATE",
+		"DOMAIN_THROTTLE_OVERRIDES",
 		"ENCRYPTION_KEY",
+		"ENCRYPTION_KEY_ID",
+		"FANOUT_CHUNK_SIZE",
 		"GOBBLE_WAIT_MAX_DURATION",
+		"HEALTH_CACHE_DURATION",
+		"HEALTH_QUEUE_DEPTH_THRESHOLD",
+		"LEADER_LEASE_DURATION",
+		"LEADER_RENEW_INTERVAL",
+		"MAX_REQUEST_BODY_SIZE",
+		"MESSAGE_ARCHIVE_PATH",
+		"MESSAGE_GC_BATCH_SIZE",
+		"MESSAGE_GC_POLLING_INTERVAL",
+		"MESSAGE_RETENTION",
+		"MESSAGE_RETENTION_BY_STATUS",
 		"PORT",
+		"RETIRED_ENCRYPTION_KEYS",
 		"ROOT_PATH",
 		"SENDER",
 		"SMTP_AUTH_MECHANISM",
@@ -33,10 +67,21 @@ var _ = Describe("Environment", func() {
 		"SMTP_PASS",
 		"SMTP_PORT",
 		"SMTP_USER",
+		"SMTP_TLS",
+		"SMTP_TLS_MODE",
+		"SMTP_CLIENT_CERT_FILE",
+		"SMTP_CLIENT_KEY_FILE",
+		"SMTP_OAUTH_TOKEN_URL",
+		"SMTP_OAUTH_REFRESH_TOKEN",
 		"TEST_MODE",
+		"TRACING_EXPORTER",
+		"TRACING_OTLP_ENDPOINT",
 		"UAA_CLIENT_ID",
 		"UAA_CLIENT_SECRET",
 		"UAA_HOST",
+		"UAA_KEY_REFRESH_INTERVAL",
+		"UAA_USER_CACHE_DURATION",
+		"UAA_KEY_REFRESH_INTREVAL",
 		"VCAP_APPLICATION",
 		"VERIFY_SSL",
 		"DATABASE_ENABLE_IDENTITY_VERIFICATION",
@@ -181,6 +226,37 @@ var _ = Describe("Environment", func() {
 			_, err = application.NewEnvironment()
 			Expect(err).To(MatchError(application.EnvironmentError{Err: viron.RequiredFieldError{Name: "UAA_CLIENT_SECRET"}}))
 		})
+
+		It("loads the key refresh interval", func() {
+			os.Setenv("UAA_KEY_REFRESH_INTERVAL", "30000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.UAAKeyRefreshInterval).To(Equal(30000))
+		})
+
+		It("still loads the key refresh interval from its misspelled former name", func() {
+			os.Setenv("UAA_KEY_REFRESH_INTERVAL", "")
+			os.Setenv("UAA_KEY_REFRESH_INTREVAL", "20000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.UAAKeyRefreshInterval).To(Equal(20000))
+		})
+
+		It("loads the user cache duration", func() {
+			os.Setenv("UAA_USER_CACHE_DURATION", "5000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.UAAUserCacheDuration).To(Equal(5000))
+		})
+
+		It("defaults to caching users for a minute", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.UAAUserCacheDuration).To(Equal(60000))
+		})
 	})
 
 	Describe("SMTP configuration", func() {
@@ -213,7 +289,8 @@ var _ = Describe("Environment", func() {
 			Expect(env.SMTPTLS).To(BeTrue())
 		})
 
-		It("does not error when SMTP_USER and/or SMTP_PASS are empty", func() {
+		It("does not error when SMTP_USER and/or SMTP_PASS are empty without authentication", func() {
+			os.Setenv("SMTP_AUTH_MECHANISM", "none")
 			os.Setenv("SMTP_USER", "")
 			os.Setenv("SMTP_PASS", "")
 
@@ -222,6 +299,7 @@ var _ = Describe("Environment", func() {
 		})
 
 		It("it errors if SMTP_AUTH_MECHANISM is not one of the three supported types", func() {
+			os.Setenv("SMTP_CRAMMD5_SECRET", "supersecret")
 			os.Setenv("SMTP_AUTH_MECHANISM", "cram-md5")
 			_, err := application.NewEnvironment()
 			Expect(err).NotTo(HaveOccurred())
@@ -236,7 +314,168 @@ var _ = Describe("Environment", func() {
 
 			os.Setenv("SMTP_AUTH_MECHANISM", "banana")
 			_, err = application.NewEnvironment()
-			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse SMTP_AUTH_MECHANISM \"banana\", it is not one of the allowed values: [none plain cram-md5]")}))
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse SMTP_AUTH_MECHANISM \"banana\", it is not one of the allowed values: [none plain cram-md5 login xoauth2]")}))
+		})
+
+		It("accepts the login and xoauth2 mechanisms", func() {
+			os.Setenv("SMTP_AUTH_MECHANISM", "login")
+			_, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+
+			os.Setenv("SMTP_AUTH_MECHANISM", "xoauth2")
+			os.Setenv("SMTP_OAUTH_TOKEN_URL", "https://oauth.example.com/token")
+			os.Setenv("SMTP_OAUTH_REFRESH_TOKEN", "refresh-token")
+			_, err = application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("accepts a static xoauth2 token in SMTP_PASS when no token URL is set", func() {
+			os.Setenv("SMTP_AUTH_MECHANISM", "xoauth2")
+			os.Setenv("SMTP_OAUTH_TOKEN_URL", "")
+			os.Setenv("SMTP_PASS", "static-access-token")
+
+			_, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("errors if the credentials of the auth mechanism are missing", func() {
+			os.Setenv("SMTP_AUTH_MECHANISM", "plain")
+			os.Setenv("SMTP_PASS", "")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_PASS is required when SMTP_AUTH_MECHANISM is "plain"`)}))
+
+			os.Setenv("SMTP_AUTH_MECHANISM", "cram-md5")
+			os.Setenv("SMTP_CRAMMD5_SECRET", "")
+
+			_, err = application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_CRAMMD5_SECRET is required when SMTP_AUTH_MECHANISM is "cram-md5"`)}))
+
+			os.Setenv("SMTP_AUTH_MECHANISM", "xoauth2")
+			os.Setenv("SMTP_OAUTH_TOKEN_URL", "")
+
+			_, err = application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_OAUTH_TOKEN_URL or SMTP_PASS is required when SMTP_AUTH_MECHANISM is "xoauth2"`)}))
+
+			os.Setenv("SMTP_AUTH_MECHANISM", "login")
+			os.Setenv("SMTP_USER", "")
+
+			_, err = application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_USER is required when SMTP_AUTH_MECHANISM is "login"`)}))
+		})
+
+		It("derives SMTP_TLS_MODE from SMTP_TLS when it is not set", func() {
+			os.Setenv("SMTP_TLS_MODE", "")
+			os.Setenv("SMTP_TLS", "true")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.SMTPTLSMode).To(Equal("starttls"))
+
+			os.Setenv("SMTP_TLS", "false")
+
+			env, err = application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.SMTPTLSMode).To(Equal("none"))
+		})
+
+		It("derives SMTP_TLS from SMTP_TLS_MODE when it is not set", func() {
+			os.Setenv("SMTP_TLS", "")
+			os.Setenv("SMTP_TLS_MODE", "implicit")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.SMTPTLSMode).To(Equal("implicit"))
+			Expect(env.SMTPTLS).To(BeTrue())
+		})
+
+		It("errors if SMTP_TLS contradicts SMTP_TLS_MODE", func() {
+			os.Setenv("SMTP_TLS", "false")
+			os.Setenv("SMTP_TLS_MODE", "implicit")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_TLS false contradicts SMTP_TLS_MODE "implicit"`)}))
+
+			os.Setenv("SMTP_TLS", "true")
+			os.Setenv("SMTP_TLS_MODE", "none")
+
+			_, err = application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_TLS true contradicts SMTP_TLS_MODE "none"`)}))
+		})
+
+		It("errors if SMTP_TLS_MODE is not one of the supported modes", func() {
+			os.Setenv("SMTP_TLS_MODE", "sometimes")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse SMTP_TLS_MODE \"sometimes\", it is not one of the allowed values: [none starttls implicit]")}))
+		})
+
+		It("errors if only one of the client certificate files is set", func() {
+			os.Setenv("SMTP_CLIENT_CERT_FILE", "/path/to/cert.pem")
+			os.Setenv("SMTP_CLIENT_KEY_FILE", "")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("SMTP_CLIENT_CERT_FILE and SMTP_CLIENT_KEY_FILE must be set together")}))
+		})
+
+		Context("when a client certificate is set", func() {
+			var dir, certFile, keyFile string
+
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "certificates")
+				Expect(err).NotTo(HaveOccurred())
+
+				certFile, keyFile = writeKeyPair(dir)
+				os.Setenv("SMTP_CLIENT_CERT_FILE", certFile)
+				os.Setenv("SMTP_CLIENT_KEY_FILE", keyFile)
+				os.Setenv("SMTP_TLS", "true")
+				os.Setenv("SMTP_TLS_MODE", "starttls")
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("loads the key pair", func() {
+				env, err := application.NewEnvironment()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(env.SMTPClientCertificates).To(HaveLen(1))
+			})
+
+			It("errors if the key pair cannot be loaded", func() {
+				os.Setenv("SMTP_CLIENT_KEY_FILE", certFile)
+
+				_, err := application.NewEnvironment()
+				Expect(err).To(BeAssignableToTypeOf(application.EnvironmentError{}))
+				Expect(err.Error()).To(HavePrefix("Could not load SMTP_CLIENT_CERT_FILE and SMTP_CLIENT_KEY_FILE, "))
+			})
+
+			It("errors if the files do not exist", func() {
+				os.Setenv("SMTP_CLIENT_CERT_FILE", filepath.Join(dir, "missing.pem"))
+
+				_, err := application.NewEnvironment()
+				Expect(err).To(BeAssignableToTypeOf(application.EnvironmentError{}))
+				Expect(err.Error()).To(ContainSubstring("missing.pem"))
+			})
+		})
+
+		It("errors if a client certificate is set without TLS", func() {
+			os.Setenv("SMTP_CLIENT_CERT_FILE", "/path/to/cert.pem")
+			os.Setenv("SMTP_CLIENT_KEY_FILE", "/path/to/key.pem")
+			os.Setenv("SMTP_TLS", "")
+			os.Setenv("SMTP_TLS_MODE", "none")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`SMTP_CLIENT_CERT_FILE requires SMTP_TLS_MODE "starttls" or "implicit"`)}))
+		})
+
+		It("errors if the OAuth token URL is set without a refresh token", func() {
+			os.Setenv("SMTP_OAUTH_TOKEN_URL", "https://oauth.example.com/token")
+			os.Setenv("SMTP_OAUTH_REFRESH_TOKEN", "")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("SMTP_OAUTH_REFRESH_TOKEN is required when SMTP_OAUTH_TOKEN_URL is set")}))
 		})
 
 		It("errors when the values are missing", func() {
@@ -456,6 +695,44 @@ var _ = Describe("Environment", func() {
 			_, err := application.NewEnvironment()
 			Expect(err).To(MatchError(application.EnvironmentError{Err: viron.RequiredFieldError{Name: "ENCRYPTION_KEY"}}))
 		})
+
+		It("loads the ID of the key and the retired keys", func() {
+			os.Setenv("ENCRYPTION_KEY_ID", "3")
+			os.Setenv("RETIRED_ENCRYPTION_KEYS", "2=the old key, 1=the=oldest key")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.EncryptionKeyID).To(Equal("3"))
+			Expect(env.RetiredEncryptionKeys).To(Equal([]keyring.Key{
+				{ID: "2", Secret: []byte("the old key")},
+				{ID: "1", Secret: []byte("the=oldest key")},
+			}))
+		})
+
+		It("defaults to a key ID of 1 without retired keys", func() {
+			os.Setenv("ENCRYPTION_KEY_ID", "")
+			os.Setenv("RETIRED_ENCRYPTION_KEYS", "")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.EncryptionKeyID).To(Equal("1"))
+			Expect(env.RetiredEncryptionKeys).To(BeEmpty())
+		})
+
+		It("errors if the retired keys are malformed, without revealing them", func() {
+			os.Setenv("RETIRED_ENCRYPTION_KEYS", "the old key")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse RETIRED_ENCRYPTION_KEYS, it does not fit format "id=key,id=key"`)}))
+		})
+
+		It("errors if a retired key has the ID of the active key", func() {
+			os.Setenv("ENCRYPTION_KEY_ID", "2")
+			os.Setenv("RETIRED_ENCRYPTION_KEYS", "2=the old key")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse the encryption keys, key ID "2" is used more than once`)}))
+		})
 	})
 
 	Describe("Gobble WaitMaxDuration", func() {
@@ -476,6 +753,255 @@ var _ = Describe("Environment", func() {
 		})
 	})
 
+	Describe("Health checks", func() {
+		It("sets the cache duration and queue depth threshold if present", func() {
+			os.Setenv("HEALTH_CACHE_DURATION", "5000")
+			os.Setenv("HEALTH_QUEUE_DEPTH_THRESHOLD", "250")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.HealthCacheDuration).To(Equal(5000))
+			Expect(env.HealthQueueDepthThreshold).To(Equal(250))
+		})
+
+		It("defaults to caching for 10000 and a threshold of 10000 jobs", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.HealthCacheDuration).To(Equal(10000))
+			Expect(env.HealthQueueDepthThreshold).To(Equal(10000))
+		})
+	})
+
+	Describe("Leader election", func() {
+		It("sets the lease duration and renew interval if present", func() {
+			os.Setenv("LEADER_LEASE_DURATION", "15000")
+			os.Setenv("LEADER_RENEW_INTERVAL", "5000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.LeaderLeaseDuration).To(Equal(15000))
+			Expect(env.LeaderRenewInterval).To(Equal(5000))
+		})
+
+		It("defaults to a lease of 30000 renewed every 10000", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.LeaderLeaseDuration).To(Equal(30000))
+			Expect(env.LeaderRenewInterval).To(Equal(10000))
+		})
+
+		It("errors if the renew interval is not positive", func() {
+			os.Setenv("LEADER_RENEW_INTERVAL", "0")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse LEADER_RENEW_INTERVAL 0, it must be positive")}))
+		})
+
+		It("errors if the lease does not outlast the renew interval", func() {
+			os.Setenv("LEADER_LEASE_DURATION", "10000")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse LEADER_LEASE_DURATION 10000, it must be longer than LEADER_RENEW_INTERVAL 10000")}))
+		})
+	})
+
+	Describe("Delivery retries", func() {
+		It("sets the maximum retries and backoff if present", func() {
+			os.Setenv("DELIVERY_MAX_RETRIES", "3")
+			os.Setenv("DELIVERY_RETRY_BACKOFF", "30000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.DeliveryMaxRetries).To(Equal(3))
+			Expect(env.DeliveryRetryBackoff).To(Equal(30000))
+		})
+
+		It("defaults to 10 retries starting from 60000", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.DeliveryMaxRetries).To(Equal(10))
+			Expect(env.DeliveryRetryBackoff).To(Equal(60000))
+		})
+
+		It("errors if the maximum retries is negative", func() {
+			os.Setenv("DELIVERY_MAX_RETRIES", "-1")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse DELIVERY_MAX_RETRIES -1, it must not be negative")}))
+		})
+
+		It("errors if the backoff is not positive", func() {
+			os.Setenv("DELIVERY_RETRY_BACKOFF", "0")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse DELIVERY_RETRY_BACKOFF 0, it must be positive")}))
+		})
+	})
+
+	Describe("Fan-out chunk size", func() {
+		It("sets the chunk size if present", func() {
+			os.Setenv("FANOUT_CHUNK_SIZE", "20")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.FanOutChunkSize).To(Equal(20))
+		})
+
+		It("defaults to 100", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.FanOutChunkSize).To(Equal(100))
+		})
+
+		It("errors if the chunk size is not positive", func() {
+			os.Setenv("FANOUT_CHUNK_SIZE", "0")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse FANOUT_CHUNK_SIZE 0, it must be positive")}))
+		})
+	})
+
+	Describe("Request body size", func() {
+		It("sets the largest request body if present", func() {
+			os.Setenv("MAX_REQUEST_BODY_SIZE", "1024")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MaxRequestBodySize).To(Equal(1024))
+		})
+
+		It("defaults to 16 MiB", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MaxRequestBodySize).To(Equal(16777216))
+		})
+
+		It("errors if the size is not positive", func() {
+			os.Setenv("MAX_REQUEST_BODY_SIZE", "0")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse MAX_REQUEST_BODY_SIZE 0, it must be positive")}))
+		})
+	})
+
+	Describe("Tracing", func() {
+		It("sets the exporter and collector endpoint if present", func() {
+			os.Setenv("TRACING_EXPORTER", "otlp")
+			os.Setenv("TRACING_OTLP_ENDPOINT", "http://collector.example.com:4318")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.TracingExporter).To(Equal("otlp"))
+			Expect(env.TracingOTLPEndpoint).To(Equal("http://collector.example.com:4318"))
+		})
+
+		It("defaults to not exporting spans", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.TracingExporter).To(Equal("none"))
+		})
+
+		It("errors if the exporter is not one of the supported exporters", func() {
+			os.Setenv("TRACING_EXPORTER", "zipkin")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse TRACING_EXPORTER "zipkin", it is not one of the allowed values: [none stdout otlp]`)}))
+		})
+
+		It("errors if the OTLP exporter has no collector endpoint", func() {
+			os.Setenv("TRACING_EXPORTER", "otlp")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`TRACING_OTLP_ENDPOINT is required when TRACING_EXPORTER is "otlp"`)}))
+		})
+	})
+
+	Describe("Message retention", func() {
+		It("sets the retention and garbage collection settings if present", func() {
+			os.Setenv("MESSAGE_RETENTION", "72h")
+			os.Setenv("MESSAGE_RETENTION_BY_STATUS", "failed=720h, delivered=168h")
+			os.Setenv("MESSAGE_GC_BATCH_SIZE", "500")
+			os.Setenv("MESSAGE_GC_POLLING_INTERVAL", "600000")
+			os.Setenv("MESSAGE_ARCHIVE_PATH", "/var/vcap/store/messages.ndjson")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MessageRetention).To(Equal(72 * time.Hour))
+			Expect(env.MessageRetentionByStatus).To(Equal(map[string]time.Duration{
+				"failed":    720 * time.Hour,
+				"delivered": 168 * time.Hour,
+			}))
+			Expect(env.MessageGCBatchSize).To(Equal(500))
+			Expect(env.MessageGCPollingInterval).To(Equal(600000))
+			Expect(env.MessageArchivePath).To(Equal("/var/vcap/store/messages.ndjson"))
+		})
+
+		It("keeps every message for 24 hours and collects hourly by default", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MessageRetention).To(Equal(24 * time.Hour))
+			Expect(env.MessageRetentionByStatus).To(BeEmpty())
+			Expect(env.MessageGCBatchSize).To(Equal(1000))
+			Expect(env.MessageGCPollingInterval).To(Equal(3600000))
+			Expect(env.MessageArchivePath).To(BeEmpty())
+		})
+
+		It("errors if the retention is not a duration", func() {
+			os.Setenv("MESSAGE_RETENTION", "7 days")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse MESSAGE_RETENTION "7 days", it must be a positive duration like "24h"`)}))
+		})
+
+		It("errors if the retention of a status is malformed", func() {
+			os.Setenv("MESSAGE_RETENTION_BY_STATUS", "failed")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse MESSAGE_RETENTION_BY_STATUS "failed", it does not fit format "status=duration,status=duration"`)}))
+		})
+
+		It("errors if the status is unknown", func() {
+			os.Setenv("MESSAGE_RETENTION_BY_STATUS", "lost=720h")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse MESSAGE_RETENTION_BY_STATUS "lost=720h", "lost" is not one of the message statuses: [failed retry delivered queued undeliverable bounced]`)}))
+		})
+
+		It("errors if the retention of a status is not a duration", func() {
+			os.Setenv("MESSAGE_RETENTION_BY_STATUS", "failed=-1h")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse MESSAGE_RETENTION_BY_STATUS "failed=-1h", "-1h" is not a valid duration`)}))
+		})
+
+		It("errors if the batch size is not positive", func() {
+			os.Setenv("MESSAGE_GC_BATCH_SIZE", "0")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse MESSAGE_GC_BATCH_SIZE 0, it must be positive")}))
+		})
+	})
+
+	Describe("Bounce maildir", func() {
+		It("sets the maildir and polling interval if present", func() {
+			os.Setenv("BOUNCE_MAILDIR", "/var/vcap/data/bounces")
+			os.Setenv("BOUNCE_POLLING_INTERVAL", "30000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.BounceMaildir).To(Equal("/var/vcap/data/bounces"))
+			Expect(env.BouncePollingInterval).To(Equal(30000))
+		})
+
+		It("defaults the polling interval to 60000", func() {
+			os.Setenv("BOUNCE_POLLING_INTERVAL", "")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.BouncePollingInterval).To(Equal(60000))
+		})
+	})
+
 	Describe("Default UAA scopes", func() {
 		It("sets the value if present", func() {
 			os.Setenv("DEFAULT_UAA_SCOPES", "my-scope,banana,foo,bar")
@@ -507,4 +1033,209 @@ var _ = Describe("Environment", func() {
 			Expect(err).To(MatchError(application.EnvironmentError{Err: viron.RequiredFieldError{Name: "DOMAIN"}}))
 		})
 	})
+
+	Describe("Domain throttling", func() {
+		It("sets the rate and the rates of individual domains if present", func() {
+			os.Setenv("DOMAIN_THROTTLE_RATE", "2.5")
+			os.Setenv("DOMAIN_THROTTLE_OVERRIDES", "gmail.com=10, example.com=0.5")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.DomainThrottleRate).To(Equal(2.5))
+			Expect(env.DomainThrottleOverrides).To(Equal(map[string]float64{
+				"gmail.com":   10,
+				"example.com": 0.5,
+			}))
+		})
+
+		It("does not throttle by default", func() {
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.DomainThrottleRate).To(BeZero())
+			Expect(env.DomainThrottleOverrides).To(BeEmpty())
+		})
+
+		It("errors if the rate is negative", func() {
+			os.Setenv("DOMAIN_THROTTLE_RATE", "-1")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New("Could not parse DOMAIN_THROTTLE_RATE -1, it must not be negative")}))
+		})
+
+		It("errors if an override is malformed", func() {
+			os.Setenv("DOMAIN_THROTTLE_OVERRIDES", "gmail.com")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse DOMAIN_THROTTLE_OVERRIDES "gmail.com", it does not fit format "domain=rate,domain=rate"`)}))
+		})
+
+		It("errors if an override rate is not a number", func() {
+			os.Setenv("DOMAIN_THROTTLE_OVERRIDES", "gmail.com=fast")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: errors.New(`Could not parse DOMAIN_THROTTLE_OVERRIDES "gmail.com=fast", "fast" is not a valid rate`)}))
+		})
+	})
+
+	Describe("Config file", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "config")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		writeConfig := func(name, content string) string {
+			path := filepath.Join(dir, name)
+			Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+
+		It("loads the settings that are not set in the environment from a YAML file", func() {
+			os.Setenv("SMTP_HOST", "")
+			os.Setenv("SMTP_PASS", "")
+			os.Setenv("PORT", "4000")
+			os.Setenv("CONFIG_FILE", writeConfig("config.yml", strings.Join([]string{
+				"---",
+				"# SMTP",
+				"smtp_host: smtp.example.com # the relay",
+				`smtp_pass: "it's a secret"`,
+				"smtp_user: 'file-user'",
+				"port: 5000",
+				"",
+			}, "\n")))
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.SMTPHost).To(Equal("smtp.example.com"))
+			Expect(env.SMTPPass).To(Equal("it's a secret"))
+			Expect(env.SMTPUser).To(Equal("user"))
+			Expect(env.Port).To(Equal(4000))
+		})
+
+		It("loads the settings from a JSON file", func() {
+			os.Setenv("SMTP_HOST", "")
+			os.Setenv("PORT", "")
+			os.Setenv("TEST_MODE", "")
+			os.Setenv("CONFIG_FILE", writeConfig("config.json", `{"smtp_host": "smtp.example.com", "port": 5000, "test_mode": true}`))
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.SMTPHost).To(Equal("smtp.example.com"))
+			Expect(env.Port).To(Equal(5000))
+			Expect(env.TestMode).To(BeTrue())
+		})
+
+		It("errors on unknown settings, suggesting the closest known one", func() {
+			path := writeConfig("config.yml", "uaa_key_refresh_intreval: 1000\n")
+			os.Setenv("CONFIG_FILE", path)
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: fmt.Errorf(`Could not parse CONFIG_FILE %q, "uaa_key_refresh_intreval" is not a known setting, did you mean "uaa_key_refresh_interval"?`, path)}))
+
+			path = writeConfig("config.json", `{"banana": "yellow"}`)
+			os.Setenv("CONFIG_FILE", path)
+
+			_, err = application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: fmt.Errorf(`Could not parse CONFIG_FILE %q, "banana" is not a known setting`, path)}))
+		})
+
+		It("errors on nested settings", func() {
+			path := writeConfig("config.yml", "smtp:\n  host: smtp.example.com\n")
+			os.Setenv("CONFIG_FILE", path)
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: fmt.Errorf(`Could not parse CONFIG_FILE %q, line 2 is not a top level "key: value" pair`, path)}))
+
+			path = writeConfig("config.json", `{"smtp": {"host": "smtp.example.com"}}`)
+			os.Setenv("CONFIG_FILE", path)
+
+			_, err = application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: fmt.Errorf(`Could not parse CONFIG_FILE %q, "smtp" must be a string, number or boolean`, path)}))
+		})
+
+		It("errors on settings that are set twice", func() {
+			path := writeConfig("config.yaml", "port: 1\nport: 2\n")
+			os.Setenv("CONFIG_FILE", path)
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: fmt.Errorf(`Could not parse CONFIG_FILE %q, "port" is set more than once`, path)}))
+		})
+
+		It("errors on files in other formats", func() {
+			path := writeConfig("config.toml", "port = 1\n")
+			os.Setenv("CONFIG_FILE", path)
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{Err: fmt.Errorf(`Could not parse CONFIG_FILE %q, it must end in .json, .yml or .yaml`, path)}))
+		})
+
+		It("errors when the file cannot be read", func() {
+			os.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yml"))
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Could not read CONFIG_FILE"))
+		})
+	})
+
+	Describe("WriteConfig", func() {
+		It("writes the effective settings with the secrets redacted", func() {
+			os.Setenv("SMTP_PASS", "my-smtp-password")
+			os.Setenv("SMTP_CRAMMD5_SECRET", "")
+			os.Setenv("SMTP_HOST", "smtp.example.com")
+			os.Setenv("SMTP_TLS", "true")
+			os.Setenv("SMTP_TLS_MODE", "")
+			os.Setenv("PORT", "4000")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+
+			buffer := bytes.NewBuffer([]byte{})
+			Expect(env.WriteConfig(buffer)).To(Succeed())
+
+			lines := strings.Split(buffer.String(), "\n")
+			Expect(lines).To(ContainElement(`smtp_pass: "[REDACTED]"`))
+			Expect(lines).To(ContainElement(`smtp_crammd5_secret: ""`))
+			Expect(lines).To(ContainElement(`database_url: "[REDACTED]"`))
+			Expect(lines).To(ContainElement(`encryption_key: "[REDACTED]"`))
+			Expect(lines).To(ContainElement(`uaa_client_secret: "[REDACTED]"`))
+			Expect(lines).To(ContainElement(`smtp_host: "smtp.example.com"`))
+			Expect(lines).To(ContainElement(`smtp_tls_mode: "starttls"`))
+			Expect(lines).To(ContainElement(`port: 4000`))
+			Expect(lines).To(ContainElement(`verify_ssl: true`))
+			Expect(buffer.String()).NotTo(ContainSubstring("my-smtp-password"))
+		})
+	})
 })
+
+func writeKeyPair(dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "notifications"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	certFile := filepath.Join(dir, "cert.pem")
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)).To(Succeed())
+
+	keyFile := filepath.Join(dir, "key.pem")
+	Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)).To(Succeed())
+
+	return certFile, keyFile
+}
@@ -0,0 +1,201 @@
+package application
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+type leaseStore interface {
+	Acquire(name, holder string, now time.Time, duration time.Duration) (bool, error)
+	Release(name, holder string) error
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+// Duty is work that only the elected leader does. Duties are started in
+// order when the instance is elected, and a duty that keeps working, such as
+// a periodic job, must do so in a goroutine of its own until stop is closed,
+// which happens when the instance loses the lease or resigns.
+type Duty func(stop <-chan struct{})
+
+type LeaderElectorConfig struct {
+	Name          string
+	Holder        string
+	LeaseDuration time.Duration
+	RenewInterval time.Duration
+
+	Leases leaseStore
+	Clock  clock
+	Logger lager.Logger
+}
+
+// LeaderElector elects a single instance among those sharing the database,
+// by having each of them try to take or renew a lease every RenewInterval.
+// The instance holding the lease performs the duties. When the leader stops
+// renewing, another instance takes over once the lease has expired.
+type LeaderElector struct {
+	name          string
+	holder        string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+
+	leases leaseStore
+	clock  clock
+	logger lager.Logger
+
+	mutex     *sync.Mutex
+	duties    []Duty
+	leading   bool
+	renewedAt time.Time
+	resigned  bool
+	stop      chan struct{}
+	started   chan struct{}
+	halt      chan struct{}
+}
+
+func NewLeaderElector(config LeaderElectorConfig) *LeaderElector {
+	started := make(chan struct{})
+	close(started)
+
+	return &LeaderElector{
+		name:          config.Name,
+		holder:        config.Holder,
+		leaseDuration: config.LeaseDuration,
+		renewInterval: config.RenewInterval,
+
+		leases: config.Leases,
+		clock:  config.Clock,
+		logger: config.Logger.Session("leader-election", lager.Data{
+			"lease":  config.Name,
+			"holder": config.Holder,
+		}),
+
+		mutex:   &sync.Mutex{},
+		started: started,
+		halt:    make(chan struct{}),
+	}
+}
+
+// Run campaigns for the lease in the background. The first campaign happens
+// before Run returns, and when it elects this instance Run also waits for the
+// duties to have started, so that work such as migrations is done before the
+// instance goes on to serve requests.
+func (e *LeaderElector) Run(duties ...Duty) {
+	e.mutex.Lock()
+	e.duties = duties
+	e.mutex.Unlock()
+
+	e.Campaign()
+
+	e.mutex.Lock()
+	started := e.started
+	e.mutex.Unlock()
+
+	go func() {
+		timer := time.NewTimer(e.renewInterval)
+		for {
+			select {
+			case <-timer.C:
+				e.Campaign()
+				timer.Reset(e.renewInterval)
+			case <-e.halt:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+
+	<-started
+}
+
+// Campaign takes or renews the lease. A leader that cannot reach the
+// database keeps its duties until its lease would have expired, since no
+// other instance can take over before then.
+func (e *LeaderElector) Campaign() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := e.clock.Now()
+	elected, err := e.leases.Acquire(e.name, e.holder, now, e.leaseDuration)
+	if err != nil {
+		e.logger.Error("lease-acquire-failed", err)
+
+		if e.leading && now.Sub(e.renewedAt) >= e.leaseDuration {
+			e.stepDown()
+		}
+		return
+	}
+
+	switch {
+	case elected && !e.leading:
+		e.renewedAt = now
+		e.stepUp()
+	case elected:
+		e.renewedAt = now
+	case e.leading:
+		e.stepDown()
+	}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.leading
+}
+
+// Resign stops campaigning and hands the lease over, so that another
+// instance can take on the duties without waiting for it to expire.
+func (e *LeaderElector) Resign() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.resigned {
+		return
+	}
+	e.resigned = true
+	close(e.halt)
+
+	if !e.leading {
+		return
+	}
+
+	e.stepDown()
+
+	err := e.leases.Release(e.name, e.holder)
+	if err != nil {
+		e.logger.Error("lease-release-failed", err)
+	}
+}
+
+func (e *LeaderElector) stepUp() {
+	e.logger.Info("elected")
+
+	e.leading = true
+	e.stop = make(chan struct{})
+	e.started = make(chan struct{})
+
+	go func(duties []Duty, stop, started chan struct{}) {
+		defer close(started)
+
+		for _, duty := range duties {
+			select {
+			case <-stop:
+				return
+			default:
+				duty(stop)
+			}
+		}
+	}(e.duties, e.stop, e.started)
+}
+
+func (e *LeaderElector) stepDown() {
+	e.logger.Info("stepped-down")
+
+	e.leading = false
+	close(e.stop)
+}
@@ -0,0 +1,184 @@
+package application_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/application"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LeaderElector", func() {
+	var (
+		elector   *application.LeaderElector
+		leases    *mocks.LeaseStore
+		clock     *mocks.Clock
+		now       time.Time
+		performed []string
+		stops     []<-chan struct{}
+		duties    []application.Duty
+	)
+
+	duty := func(name string) application.Duty {
+		return func(stop <-chan struct{}) {
+			performed = append(performed, name)
+			stops = append(stops, stop)
+		}
+	}
+
+	isClosed := func(stop <-chan struct{}) bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	BeforeEach(func() {
+		leases = mocks.NewLeaseStore()
+		clock = mocks.NewClock()
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		clock.NowCall.Returns.Time = now
+
+		performed = nil
+		stops = nil
+		duties = []application.Duty{duty("migrate"), duty("collect")}
+
+		elector = application.NewLeaderElector(application.LeaderElectorConfig{
+			Name:          "some-lease",
+			Holder:        "some-instance",
+			LeaseDuration: 30 * time.Second,
+			RenewInterval: time.Hour,
+
+			Leases: leases,
+			Clock:  clock,
+			Logger: lager.NewLogger("test"),
+		})
+	})
+
+	AfterEach(func() {
+		elector.Resign()
+	})
+
+	Context("when the instance is elected", func() {
+		BeforeEach(func() {
+			leases.AcquireCall.Returns.Acquired = true
+		})
+
+		It("performs the duties in order before Run returns", func() {
+			elector.Run(duties...)
+
+			Expect(leases.AcquireCall.Receives.Name).To(Equal("some-lease"))
+			Expect(leases.AcquireCall.Receives.Holder).To(Equal("some-instance"))
+			Expect(leases.AcquireCall.Receives.Now).To(Equal(now))
+			Expect(leases.AcquireCall.Receives.Duration).To(Equal(30 * time.Second))
+
+			Expect(elector.IsLeader()).To(BeTrue())
+			Expect(performed).To(Equal([]string{"migrate", "collect"}))
+			Expect(isClosed(stops[0])).To(BeFalse())
+		})
+
+		It("keeps the duties when it renews the lease", func() {
+			elector.Run(duties...)
+			elector.Campaign()
+
+			Expect(leases.AcquireCall.CallCount).To(Equal(2))
+			Expect(elector.IsLeader()).To(BeTrue())
+			Expect(performed).To(HaveLen(2))
+			Expect(isClosed(stops[0])).To(BeFalse())
+		})
+
+		It("stops the duties when another instance has taken the lease", func() {
+			elector.Run(duties...)
+
+			leases.AcquireCall.Returns.Acquired = false
+			elector.Campaign()
+
+			Expect(elector.IsLeader()).To(BeFalse())
+			Expect(isClosed(stops[0])).To(BeTrue())
+		})
+
+		Context("when the lease cannot be renewed", func() {
+			BeforeEach(func() {
+				elector.Run(duties...)
+				leases.AcquireCall.Returns.Error = errors.New("database is down")
+			})
+
+			It("keeps the duties while the lease is still valid", func() {
+				clock.NowCall.Returns.Time = now.Add(29 * time.Second)
+				elector.Campaign()
+
+				Expect(elector.IsLeader()).To(BeTrue())
+				Expect(isClosed(stops[0])).To(BeFalse())
+			})
+
+			It("stops the duties once the lease has expired", func() {
+				clock.NowCall.Returns.Time = now.Add(30 * time.Second)
+				elector.Campaign()
+
+				Expect(elector.IsLeader()).To(BeFalse())
+				Expect(isClosed(stops[0])).To(BeTrue())
+			})
+		})
+
+		It("stops the duties and releases the lease when it resigns", func() {
+			elector.Run(duties...)
+			elector.Resign()
+
+			Expect(elector.IsLeader()).To(BeFalse())
+			Expect(isClosed(stops[0])).To(BeTrue())
+			Expect(leases.ReleaseCall.Receives.Name).To(Equal("some-lease"))
+			Expect(leases.ReleaseCall.Receives.Holder).To(Equal("some-instance"))
+		})
+	})
+
+	Context("when another instance holds the lease", func() {
+		It("does not perform the duties", func() {
+			elector.Run(duties...)
+
+			Expect(elector.IsLeader()).To(BeFalse())
+			Expect(performed).To(BeEmpty())
+		})
+
+		It("performs them once it is elected", func() {
+			elector.Run(duties...)
+
+			leases.AcquireCall.Returns.Acquired = true
+			elector.Campaign()
+
+			Expect(elector.IsLeader()).To(BeTrue())
+			Eventually(func() []string { return performed }).Should(Equal([]string{"migrate", "collect"}))
+		})
+
+		It("does not release the lease when it resigns", func() {
+			elector.Run(duties...)
+			elector.Resign()
+
+			Expect(leases.ReleaseCall.CallCount).To(Equal(0))
+		})
+	})
+
+	It("campaigns every renew interval", func() {
+		elector = application.NewLeaderElector(application.LeaderElectorConfig{
+			Name:          "some-lease",
+			Holder:        "some-instance",
+			LeaseDuration: 30 * time.Millisecond,
+			RenewInterval: 10 * time.Millisecond,
+
+			Leases: leases,
+			Clock:  clock,
+			Logger: lager.NewLogger("test"),
+		})
+
+		elector.Run()
+
+		Eventually(func() int {
+			return leases.AcquireCall.CallCount
+		}).Should(BeNumerically(">=", 3))
+	})
+})
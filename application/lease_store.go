@@ -0,0 +1,72 @@
+package application
+
+import (
+	"database/sql"
+	"time"
+)
+
+// The leases table is created by the store itself rather than by a
+// migration, since electing the instance that runs the migrations is what
+// the leases are for.
+const createLeasesTable = "CREATE TABLE IF NOT EXISTS `leases` (" +
+	"`name` varchar(255) NOT NULL, " +
+	"`holder` varchar(255) NOT NULL, " +
+	"`expires_at` datetime NOT NULL, " +
+	"PRIMARY KEY (`name`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8"
+
+// The holder is only replaced once the lease has expired, and the expiry is
+// only extended for the holder, which is the new holder at that point since
+// MySQL applies the assignments in order.
+const acquireLease = "INSERT INTO `leases` (`name`, `holder`, `expires_at`) VALUES (?, ?, ?) " +
+	"ON DUPLICATE KEY UPDATE " +
+	"`holder` = IF(`expires_at` < ? OR `holder` = VALUES(`holder`), VALUES(`holder`), `holder`), " +
+	"`expires_at` = IF(`holder` = VALUES(`holder`), VALUES(`expires_at`), `expires_at`)"
+
+// SQLLeaseStore keeps named, expiring leases in the database, so that
+// instances sharing the database can agree on which of them holds each one.
+type SQLLeaseStore struct {
+	db      *sql.DB
+	created *bool
+}
+
+func NewSQLLeaseStore(db *sql.DB) SQLLeaseStore {
+	return SQLLeaseStore{
+		db:      db,
+		created: new(bool),
+	}
+}
+
+// Acquire takes the lease for holder when it is free or has expired, or
+// extends it when holder already has it, and reports whether holder now
+// holds the lease.
+func (s SQLLeaseStore) Acquire(name, holder string, now time.Time, duration time.Duration) (bool, error) {
+	if !*s.created {
+		_, err := s.db.Exec(createLeasesTable)
+		if err != nil {
+			return false, err
+		}
+		*s.created = true
+	}
+
+	now = now.UTC()
+	_, err := s.db.Exec(acquireLease, name, holder, now.Add(duration), now)
+	if err != nil {
+		return false, err
+	}
+
+	var current string
+	err = s.db.QueryRow("SELECT `holder` FROM `leases` WHERE `name` = ?", name).Scan(&current)
+	if err != nil {
+		return false, err
+	}
+
+	return current == holder, nil
+}
+
+// Release expires the lease if holder still holds it, so that another
+// instance can take it over straight away.
+func (s SQLLeaseStore) Release(name, holder string) error {
+	_, err := s.db.Exec("UPDATE `leases` SET `expires_at` = ? WHERE `name` = ? AND `holder` = ?", time.Unix(0, 0).UTC(), name, holder)
+	return err
+}
@@ -0,0 +1,99 @@
+package application_test
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cloudfoundry-incubator/notifications/application"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQLLeaseStore", func() {
+	var (
+		store    application.SQLLeaseStore
+		sqlDB    *sql.DB
+		mock     sqlmock.Sqlmock
+		now      time.Time
+		duration time.Duration
+	)
+
+	BeforeEach(func() {
+		var err error
+		sqlDB, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		store = application.NewSQLLeaseStore(sqlDB)
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		duration = 30 * time.Second
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		sqlDB.Close()
+	})
+
+	Describe("Acquire", func() {
+		expectAcquire := func(holder string) {
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `leases` (`name`, `holder`, `expires_at`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE")).
+				WithArgs("some-lease", "some-instance", now.Add(duration), now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT `holder` FROM `leases` WHERE `name` = ?")).
+				WithArgs("some-lease").
+				WillReturnRows(sqlmock.NewRows([]string{"holder"}).AddRow(holder))
+		}
+
+		It("creates the leases table the first time", func() {
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `leases`")).WillReturnResult(sqlmock.NewResult(0, 0))
+			expectAcquire("some-instance")
+			expectAcquire("some-instance")
+
+			_, err := store.Acquire("some-lease", "some-instance", now, duration)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = store.Acquire("some-lease", "some-instance", now, duration)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports that the lease was acquired when the instance holds it", func() {
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `leases`")).WillReturnResult(sqlmock.NewResult(0, 0))
+			expectAcquire("some-instance")
+
+			acquired, err := store.Acquire("some-lease", "some-instance", now, duration)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeTrue())
+		})
+
+		It("reports that the lease was not acquired when another instance holds it", func() {
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `leases`")).WillReturnResult(sqlmock.NewResult(0, 0))
+			expectAcquire("other-instance")
+
+			acquired, err := store.Acquire("some-lease", "some-instance", now, duration)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeFalse())
+		})
+
+		It("returns errors from the database", func() {
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `leases`")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `leases`")).WillReturnError(errors.New("database is down"))
+
+			acquired, err := store.Acquire("some-lease", "some-instance", now, duration)
+			Expect(err).To(MatchError("database is down"))
+			Expect(acquired).To(BeFalse())
+		})
+	})
+
+	Describe("Release", func() {
+		It("expires the lease if the instance holds it", func() {
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `leases` SET `expires_at` = ? WHERE `name` = ? AND `holder` = ?")).
+				WithArgs(time.Unix(0, 0).UTC(), "some-lease", "some-instance").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			Expect(store.Release("some-lease", "some-instance")).To(Succeed())
+		})
+	})
+})
@@ -13,6 +13,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/keyring"
 	"github.com/cloudfoundry-incubator/notifications/util"
 	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/go-sql-driver/mysql"
@@ -55,9 +56,21 @@ func (d *DBProvider) GobbleDatabase() gobble.DatabaseInterface {
 func (d *DBProvider) Queue() gobble.QueueInterface {
 	return gobble.NewQueue(d.GobbleDatabase(), util.NewClock(), gobble.Config{
 		WaitMaxDuration: time.Duration(d.env.GobbleWaitMaxDuration) * time.Millisecond,
+		Sealer:          d.KeyRing(),
 	})
 }
 
+// KeyRing encrypts with ENCRYPTION_KEY and decrypts with it or any of the
+// RETIRED_ENCRYPTION_KEYS.
+func (d *DBProvider) KeyRing() keyring.KeyRing {
+	ring, err := keyring.New(keyring.Key{ID: d.env.EncryptionKeyID, Secret: d.env.EncryptionKey}, d.env.RetiredEncryptionKeys...)
+	if err != nil {
+		panic(err)
+	}
+
+	return ring
+}
+
 func (d *DBProvider) Database() db.DatabaseInterface {
 	database := v1models.NewDatabase(d.sqlDB, v1models.Config{
 		DefaultTemplatePath: path.Join(d.env.RootPath, "templates", "default.json"),
@@ -74,6 +87,18 @@ func (d *DBProvider) MessagesRepo() v1models.MessagesRepo {
 	return v1models.NewMessagesRepo(util.NewIDGenerator(rand.Reader).Generate)
 }
 
+func (d *DBProvider) AttachmentsRepo() v1models.AttachmentsRepo {
+	return v1models.NewAttachmentsRepo(util.NewIDGenerator(rand.Reader).Generate)
+}
+
+func (d *DBProvider) FanOutsRepo() v1models.FanOutsRepo {
+	return v1models.NewFanOutsRepo(util.NewIDGenerator(rand.Reader).Generate)
+}
+
+func (d *DBProvider) SuppressionsRepo() v1models.SuppressionsRepo {
+	return v1models.NewSuppressionsRepo(util.NewIDGenerator(rand.Reader).Generate)
+}
+
 func registerTLSConfig(env Environment) {
 	ca, err := ioutil.ReadFile(env.DatabaseCACertFile)
 	if err != nil {
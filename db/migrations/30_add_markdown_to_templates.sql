@@ -0,0 +1,8 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `templates` ADD `markdown` longtext;
+UPDATE `templates` SET `markdown` = "" WHERE `markdown` IS NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `templates` DROP COLUMN `markdown`;
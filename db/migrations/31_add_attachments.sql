@@ -0,0 +1,13 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `attachments` (
+      `id` varchar(255) NOT NULL,
+      `content` longblob,
+      `created_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`id`),
+      KEY `created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `attachments`;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `message_recipients` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `message_id` varchar(255) NOT NULL,
+      `address` varchar(255) NOT NULL,
+      `type` varchar(255) NOT NULL,
+      `status` varchar(255) DEFAULT NULL,
+      `updated_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `message_id_address` (`message_id`, `address`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `message_recipients`;
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `suppressions`
+      DROP KEY `address`,
+      CHANGE `address` `value` varchar(255) NOT NULL,
+      ADD COLUMN `id` varchar(255) NOT NULL DEFAULT '' AFTER `primary`,
+      ADD COLUMN `type` varchar(255) NOT NULL DEFAULT 'address' AFTER `id`,
+      ADD COLUMN `hard` tinyint(1) NOT NULL DEFAULT 0,
+      ADD COLUMN `expires_at` datetime DEFAULT NULL;
+UPDATE `suppressions` SET `id` = UUID() WHERE `id` = '';
+ALTER TABLE `suppressions`
+      ADD UNIQUE KEY `id` (`id`),
+      ADD UNIQUE KEY `type_value` (`type`, `value`);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM `suppressions` WHERE `type` != 'address';
+ALTER TABLE `suppressions`
+      DROP KEY `id`,
+      DROP KEY `type_value`,
+      DROP COLUMN `id`,
+      DROP COLUMN `type`,
+      DROP COLUMN `hard`,
+      DROP COLUMN `expires_at`,
+      CHANGE `value` `address` varchar(255) NOT NULL,
+      ADD UNIQUE KEY `address` (`address`);
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `clients` ADD COLUMN `rate_limit` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `clients` ADD COLUMN `rate_limit_burst` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `clients` ADD COLUMN `daily_recipient_quota` int(11) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS `client_recipient_counts` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `client_id` varchar(255) NOT NULL,
+      `day` date NOT NULL,
+      `count` int(11) NOT NULL DEFAULT 0,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `client_id_day` (`client_id`, `day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `client_recipient_counts`;
+ALTER TABLE `clients` DROP COLUMN `daily_recipient_quota`;
+ALTER TABLE `clients` DROP COLUMN `rate_limit_burst`;
+ALTER TABLE `clients` DROP COLUMN `rate_limit`;
@@ -0,0 +1,24 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `audit_events` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `id` varchar(255) NOT NULL,
+      `actor_type` varchar(255) NOT NULL,
+      `actor_id` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `action` varchar(255) NOT NULL,
+      `target` varchar(255) NOT NULL,
+      `state_before` longtext NOT NULL,
+      `state_after` longtext NOT NULL,
+      `created_at` datetime NOT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `id` (`id`),
+      KEY `actor_id` (`actor_id`),
+      KEY `action` (`action`),
+      KEY `target` (`target`),
+      KEY `created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `audit_events`;
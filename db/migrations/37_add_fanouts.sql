@@ -0,0 +1,17 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `fanouts` (
+      `id` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `status` varchar(255) NOT NULL,
+      `total` int(11) NOT NULL DEFAULT 0,
+      `enqueued` int(11) NOT NULL DEFAULT 0,
+      `created_at` datetime NOT NULL,
+      `updated_at` datetime NOT NULL,
+      PRIMARY KEY (`id`),
+      KEY `created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `fanouts`;
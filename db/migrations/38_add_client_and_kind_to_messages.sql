@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `messages` ADD COLUMN `client_id` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `messages` ADD COLUMN `kind_id` varchar(255) NOT NULL DEFAULT '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP COLUMN `kind_id`;
+ALTER TABLE `messages` DROP COLUMN `client_id`;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `attachment_references` (
+      `attachment_id` varchar(255) NOT NULL,
+      `owner_id` varchar(255) NOT NULL,
+      PRIMARY KEY (`attachment_id`, `owner_id`),
+      KEY `owner_id` (`owner_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `attachment_references`;
@@ -1,7 +1,23 @@
 package gobble
 
-import "time"
+import (
+	"log"
+	"time"
+)
+
+type payloadSealer interface {
+	Seal([]byte) ([]byte, error)
+	Open([]byte) ([]byte, error)
+}
 
 type Config struct {
 	WaitMaxDuration time.Duration
+
+	// Sealer encrypts the payloads of the jobs while they are stored. When it
+	// is nil, the payloads are stored as they are.
+	Sealer payloadSealer
+
+	// Logger reports the payloads that could not be opened. When it is nil,
+	// they are reported on stdout.
+	Logger *log.Logger
 }
@@ -19,7 +19,7 @@ func (beater Heartbeater) Beat(job *Job) {
 	for {
 		select {
 		case job.ActiveAt = <-beater.ticker.Tick():
-			beater.queue.Requeue(job)
+			beater.queue.Touch(job)
 		case <-beater.haltChan:
 			beater.ticker.Stop()
 			return
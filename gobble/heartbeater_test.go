@@ -58,7 +58,7 @@ var _ = Describe("Heartbeater", func() {
 			timeChan := make(chan time.Time)
 			ticker.TickCall.Returns.TimeChan = timeChan
 			job := &gobble.Job{}
-			Expect(queue.RequeueCall.Receives.Job).To(BeNil())
+			Expect(queue.TouchCall.Receives.Job).To(BeNil())
 
 			go beater.Beat(job)
 
@@ -70,7 +70,7 @@ var _ = Describe("Heartbeater", func() {
 			timeChan <- now
 
 			Eventually(func() *gobble.Job {
-				return queue.RequeueCall.Receives.Job
+				return queue.TouchCall.Receives.Job
 			}).Should(Equal(&gobble.Job{
 				ActiveAt: now,
 			}))
@@ -79,7 +79,7 @@ var _ = Describe("Heartbeater", func() {
 			timeChan <- futureTime
 
 			Eventually(func() *gobble.Job {
-				return queue.RequeueCall.Receives.Job
+				return queue.TouchCall.Receives.Job
 			}).Should(Equal(&gobble.Job{
 				ActiveAt: futureTime,
 			}))
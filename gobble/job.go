@@ -37,6 +37,14 @@ func (job *Job) Retry(duration time.Duration) {
 	job.ShouldRetry = true
 }
 
+// Defer puts the job back on the queue to be performed at activeAt. Unlike
+// Retry it does not count as a failed attempt.
+func (job *Job) Defer(activeAt time.Time) {
+	job.WorkerID = ""
+	job.ActiveAt = activeAt
+	job.ShouldRetry = true
+}
+
 func (job *Job) State() (int, time.Time) {
 	return job.RetryCount, job.ActiveAt
 }
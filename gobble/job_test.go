@@ -57,6 +57,23 @@ var _ = Describe("Job", func() {
 		})
 	})
 
+	Describe("Defer", func() {
+		It("sets up the job to be performed later without counting a retry", func() {
+			job := gobble.NewJob("the data")
+			job.RetryCount = 1
+			job.WorkerID = "my-id"
+			job.ActiveAt = time.Now().Add(-5 * time.Minute)
+
+			activeAt := time.Now().Add(30 * time.Second)
+			job.Defer(activeAt)
+
+			Expect(job.WorkerID).To(Equal(""))
+			Expect(job.RetryCount).To(Equal(1))
+			Expect(job.ActiveAt).To(Equal(activeAt))
+			Expect(job.ShouldRetry).To(BeTrue())
+		})
+	})
+
 	Describe("State", func() {
 		It("returns the current retry count and active at values", func() {
 			expectedActiveAt := time.Now().Add(-5 * time.Minute)
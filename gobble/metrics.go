@@ -22,10 +22,17 @@ func NewQueueGauge(queue queue, timer <-chan time.Time) QueueGauge {
 	}
 }
 
-func (g QueueGauge) Run() {
-	for range g.timer {
-		ql, _ := g.queue.Len()
+// Run reports the length of the queue on every tick of the timer until stop
+// is closed.
+func (g QueueGauge) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-g.timer:
+			ql, _ := g.queue.Len()
 
-		metrics.GetOrRegisterGauge("notifications.queue.length", nil).Update(int64(ql))
+			metrics.GetOrRegisterGauge("notifications.queue.length", nil).Update(int64(ql))
+		case <-stop:
+			return
+		}
 	}
 }
@@ -2,10 +2,13 @@ package gobble
 
 import (
 	"database/sql"
+	"log"
 	"math/rand"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/keyring"
 	"gopkg.in/gorp.v1"
 )
 
@@ -16,6 +19,7 @@ type QueueInterface interface {
 	Reserve(string) <-chan *Job
 	Dequeue(*Job)
 	Requeue(*Job)
+	Touch(*Job)
 	Len() (int, error)
 }
 
@@ -35,6 +39,10 @@ func NewQueue(database DatabaseInterface, clock clock, config Config) *Queue {
 		config.WaitMaxDuration = WaitMaxDuration
 	}
 
+	if config.Logger == nil {
+		config.Logger = log.New(os.Stdout, "", 0)
+	}
+
 	return &Queue{
 		database: database.(*DB),
 		clock:    clock,
@@ -47,7 +55,12 @@ func (queue *Queue) Enqueue(job *Job, connection ConnectionInterface) (*Job, err
 		job.ActiveAt = queue.clock.Now()
 	}
 
-	err := connection.Insert(job)
+	err := queue.seal(job)
+	if err != nil {
+		return job, err
+	}
+
+	err = connection.Insert(job)
 	if err != nil {
 		return job, err
 	}
@@ -55,11 +68,41 @@ func (queue *Queue) Enqueue(job *Job, connection ConnectionInterface) (*Job, err
 	return job, nil
 }
 
+// Requeue stores a sealed copy of the job, since the worker may still be
+// reading the opened payload when it is requeued.
 func (queue *Queue) Requeue(job *Job) {
-	_, err := queue.database.Connection.Update(job)
+	stored := *job
+
+	err := queue.seal(&stored)
 	if err != nil {
 		panic(err)
 	}
+
+	_, err = queue.database.Connection.Update(&stored)
+	if err != nil {
+		panic(err)
+	}
+
+	job.Version = stored.Version
+}
+
+// Touch renews the lease of a reserved job by storing when it was last active
+// and the worker it is reserved by. The stored payload is left as it is, so
+// that a heartbeat does not seal it again.
+func (queue *Queue) Touch(job *Job) {
+	result, err := queue.database.Connection.Exec("UPDATE `jobs` SET `active_at` = ?, `worker_id` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ?", job.ActiveAt, job.WorkerID, job.ID, job.Version)
+	if err != nil {
+		panic(err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	if count == 1 {
+		job.Version++
+	}
 }
 
 func (queue *Queue) Len() (int, error) {
@@ -104,6 +147,7 @@ func (queue *Queue) reserve(channel chan *Job, workerID string) {
 		return
 	}
 
+	queue.open(job)
 	channel <- job
 }
 
@@ -155,3 +199,78 @@ func (queue *Queue) waitUpTo(max time.Duration) {
 	waitTime := rand.Int63n(int64(max))
 	<-time.After(time.Duration(waitTime))
 }
+
+func (queue *Queue) seal(job *Job) error {
+	if queue.config.Sealer == nil || keyring.IsSealed([]byte(job.Payload)) {
+		return nil
+	}
+
+	sealed, err := queue.config.Sealer.Seal([]byte(job.Payload))
+	if err != nil {
+		return err
+	}
+
+	job.Payload = string(sealed)
+	return nil
+}
+
+// open decrypts the payload of a reserved job, so that it can be unmarshalled.
+// Payloads that were stored before they were sealed are left as they are, as
+// are those that cannot be opened, which are logged and then fail to
+// unmarshal.
+func (queue *Queue) open(job *Job) {
+	if queue.config.Sealer == nil || !keyring.IsSealed([]byte(job.Payload)) {
+		return
+	}
+
+	payload, err := queue.config.Sealer.Open([]byte(job.Payload))
+	if err != nil {
+		queue.config.Logger.Printf("gobble: could not open the payload of job %d: %s", job.ID, err)
+		return
+	}
+
+	job.Payload = string(payload)
+}
+
+// SealPayloads encrypts the payloads of the jobs that were enqueued before
+// payloads were sealed. Jobs that change while they are sealed are skipped,
+// since a worker that requeues them seals them itself.
+func (queue *Queue) SealPayloads(batchSize int) (int, error) {
+	if queue.config.Sealer == nil {
+		return 0, nil
+	}
+
+	var sealed, lastID int
+	for {
+		var jobs []Job
+		_, err := queue.database.Connection.Select(&jobs, "SELECT * FROM `jobs` WHERE `id` > ? AND `payload` NOT LIKE ? ORDER BY `id` LIMIT ?", lastID, keyring.SealedPrefix+"%", batchSize)
+		if err != nil {
+			return sealed, err
+		}
+
+		for i := range jobs {
+			job := &jobs[i]
+			lastID = job.ID
+
+			err = queue.seal(job)
+			if err != nil {
+				return sealed, err
+			}
+
+			_, err = queue.database.Connection.Update(job)
+			if err != nil {
+				if _, ok := err.(gorp.OptimisticLockError); ok {
+					continue
+				}
+
+				return sealed, err
+			}
+
+			sealed++
+		}
+
+		if len(jobs) < batchSize {
+			return sealed, nil
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package gobble_test
 
 import (
+	"bytes"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/keyring"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 
 	. "github.com/onsi/ginkgo"
@@ -278,4 +282,153 @@ var _ = Describe("Queue", func() {
 			Expect(length).To(Equal(0))
 		})
 	})
+
+	Context("when a sealer is configured", func() {
+		var ring keyring.KeyRing
+
+		BeforeEach(func() {
+			var err error
+			ring, err = keyring.New(keyring.Key{ID: "1", Secret: []byte("some-secret")})
+			Expect(err).NotTo(HaveOccurred())
+
+			queue = gobble.NewQueue(database, clock, gobble.Config{
+				WaitMaxDuration: 50 * time.Millisecond,
+				Sealer:          ring,
+			})
+		})
+
+		It("stores the payload sealed and opens it when the job is reserved", func() {
+			_, err := queue.Enqueue(gobble.NewJob(map[string]string{
+				"email": "user@example.com",
+			}), database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			stored := gobble.Job{}
+			err = database.Connection.SelectOne(&stored, "SELECT * FROM `jobs` LIMIT 1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Payload).To(HavePrefix(keyring.SealedPrefix))
+			Expect(stored.Payload).NotTo(ContainSubstring("user@example.com"))
+
+			var reservedJob *gobble.Job
+			Eventually(queue.Reserve("worker-id")).Should(Receive(&reservedJob))
+
+			var payload map[string]string
+			Expect(reservedJob.Unmarshal(&payload)).To(Succeed())
+			Expect(payload).To(Equal(map[string]string{"email": "user@example.com"}))
+		})
+
+		It("stores the payload sealed when the job is requeued, leaving the reserved job opened", func() {
+			_, err := queue.Enqueue(gobble.NewJob(map[string]string{
+				"email": "user@example.com",
+			}), database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			var reservedJob *gobble.Job
+			Eventually(queue.Reserve("worker-id")).Should(Receive(&reservedJob))
+
+			queue.Requeue(reservedJob)
+			Expect(reservedJob.Payload).To(Equal(`{"email":"user@example.com"}`))
+
+			stored := gobble.Job{}
+			err = database.Connection.SelectOne(&stored, "SELECT * FROM `jobs` where id = ?", reservedJob.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Payload).To(HavePrefix(keyring.SealedPrefix))
+			Expect(stored.Version).To(Equal(reservedJob.Version))
+		})
+
+		It("keeps the sealed payload when the lease of a reserved job is renewed", func() {
+			job, err := queue.Enqueue(gobble.NewJob(map[string]string{
+				"email": "user@example.com",
+			}), database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			stored := gobble.Job{}
+			err = database.Connection.SelectOne(&stored, "SELECT * FROM `jobs` where id = ?", job.ID)
+			Expect(err).NotTo(HaveOccurred())
+			sealedPayload := stored.Payload
+
+			var reservedJob *gobble.Job
+			Eventually(queue.Reserve("worker-id")).Should(Receive(&reservedJob))
+
+			reservedJob.ActiveAt = time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+			queue.Touch(reservedJob)
+
+			err = database.Connection.SelectOne(&stored, "SELECT * FROM `jobs` where id = ?", reservedJob.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Payload).To(Equal(sealedPayload))
+			Expect(stored.WorkerID).To(Equal("worker-id"))
+			Expect(stored.ActiveAt).To(BeTemporally("~", reservedJob.ActiveAt, time.Second))
+			Expect(stored.Version).To(Equal(reservedJob.Version))
+		})
+
+		It("logs the ID of a job whose payload cannot be opened", func() {
+			job, err := queue.Enqueue(gobble.NewJob(map[string]string{
+				"email": "user@example.com",
+			}), database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			otherRing, err := keyring.New(keyring.Key{ID: "1", Secret: []byte("some-other-secret")})
+			Expect(err).NotTo(HaveOccurred())
+
+			buffer := bytes.NewBuffer([]byte{})
+			queue.Close()
+			queue = gobble.NewQueue(database, clock, gobble.Config{
+				WaitMaxDuration: 50 * time.Millisecond,
+				Sealer:          otherRing,
+				Logger:          log.New(buffer, "", 0),
+			})
+
+			var reservedJob *gobble.Job
+			Eventually(queue.Reserve("worker-id")).Should(Receive(&reservedJob))
+
+			Expect(reservedJob.Payload).To(HavePrefix(keyring.SealedPrefix))
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("could not open the payload of job %d", job.ID)))
+		})
+
+		It("reserves jobs that were stored before payloads were sealed", func() {
+			job := gobble.Job{
+				Payload:  `{"email":"user@example.com"}`,
+				ActiveAt: time.Now().UTC().Truncate(time.Second),
+			}
+			Expect(database.Connection.Insert(&job)).To(Succeed())
+
+			var reservedJob *gobble.Job
+			Eventually(queue.Reserve("worker-id")).Should(Receive(&reservedJob))
+			Expect(reservedJob.Payload).To(Equal(`{"email":"user@example.com"}`))
+		})
+
+		Describe("SealPayloads", func() {
+			It("seals the payloads that were stored before payloads were sealed", func() {
+				for i := 0; i < 5; i++ {
+					job := gobble.Job{
+						Payload:  fmt.Sprintf(`{"email":"user-%d@example.com"}`, i),
+						ActiveAt: time.Now().UTC().Truncate(time.Second),
+					}
+					Expect(database.Connection.Insert(&job)).To(Succeed())
+				}
+
+				_, err := queue.Enqueue(gobble.NewJob(map[string]string{
+					"email": "sealed@example.com",
+				}), database.Connection)
+				Expect(err).NotTo(HaveOccurred())
+
+				count, err := queue.SealPayloads(2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(5))
+
+				jobs := []gobble.Job{}
+				_, err = database.Connection.Select(&jobs, "SELECT * FROM `jobs`")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jobs).To(HaveLen(6))
+
+				for _, job := range jobs {
+					Expect(job.Payload).To(HavePrefix(keyring.SealedPrefix))
+
+					opened, err := ring.Open([]byte(job.Payload))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(opened)).To(ContainSubstring("@example.com"))
+				}
+			})
+		})
+	})
 })
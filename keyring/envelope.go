@@ -0,0 +1,107 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// SealedPrefix starts all data sealed by a KeyRing, which tells it apart from
+// data that was stored before it was sealed.
+const SealedPrefix = "sealed:"
+
+const dataKeySize = 32
+
+type NotSealedError struct{}
+
+func (e NotSealedError) Error() string {
+	return "data is not sealed"
+}
+
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(SealedPrefix))
+}
+
+// Seal encrypts data of any size with a new AES-GCM data key. The data key is
+// veiled with the active key and stored in front of the encrypted data, so
+// that retired keys can still open it after a rotation.
+func (ring KeyRing) Seal(data []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	_, err := io.ReadFull(rand.Reader, dataKey)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	veiledKey, err := ring.Veil(dataKey)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	sealed := aead.Seal(nonce, nonce, data, veiledKey)
+
+	buffer := bytes.NewBufferString(SealedPrefix)
+	buffer.Write(veiledKey)
+	buffer.WriteString(":")
+	buffer.WriteString(base64.URLEncoding.EncodeToString(sealed))
+
+	return buffer.Bytes(), nil
+}
+
+// Open decrypts data sealed by Seal with the key ring it was sealed with, or
+// with one that retired its key.
+func (ring KeyRing) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return []byte{}, NotSealedError{}
+	}
+
+	parts := bytes.SplitN(bytes.TrimPrefix(data, []byte(SealedPrefix)), []byte(":"), 2)
+	if len(parts) != 2 {
+		return []byte{}, errors.New("sealed data is malformed")
+	}
+	veiledKey := parts[0]
+
+	dataKey, err := ring.Unveil(veiledKey)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	sealed, err := base64.URLEncoding.DecodeString(string(parts[1]))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return []byte{}, errors.New("sealed data is malformed")
+	}
+
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], veiledKey)
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
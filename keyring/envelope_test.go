@@ -0,0 +1,95 @@
+package keyring_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/keyring"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Envelope", func() {
+	var (
+		oldKey keyring.Key
+		newKey keyring.Key
+		ring   keyring.KeyRing
+		data   []byte
+	)
+
+	BeforeEach(func() {
+		oldKey = keyring.Key{ID: "2015", Secret: []byte("the old secret")}
+		newKey = keyring.Key{ID: "2016", Secret: []byte("the new secret")}
+
+		var err error
+		ring, err = keyring.New(newKey, oldKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		data = []byte(`{"Email":"user@example.com","Options":{"HTML":"<p>Your invoice is ` + strings.Repeat("long ", 1000) + `</p>"}}`)
+	})
+
+	Describe("Seal", func() {
+		It("encrypts the data so that it can be opened again", func() {
+			sealed, err := ring.Seal(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keyring.IsSealed(sealed)).To(BeTrue())
+			Expect(string(sealed)).To(HavePrefix("sealed:2016."))
+			Expect(string(sealed)).NotTo(ContainSubstring("user@example.com"))
+
+			opened, err := ring.Open(sealed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opened).To(Equal(data))
+		})
+
+		It("uses a new data key every time", func() {
+			first, err := ring.Seal(data)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := ring.Seal(data)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first).NotTo(Equal(second))
+		})
+	})
+
+	Describe("Open", func() {
+		It("opens data sealed before the key was rotated", func() {
+			before, err := keyring.New(oldKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			sealed, err := before.Seal(data)
+			Expect(err).NotTo(HaveOccurred())
+
+			opened, err := ring.Open(sealed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opened).To(Equal(data))
+		})
+
+		It("errors when the data is not sealed", func() {
+			_, err := ring.Open(data)
+			Expect(err).To(MatchError(keyring.NotSealedError{}))
+			Expect(keyring.IsSealed(data)).To(BeFalse())
+		})
+
+		It("errors when the sealed data was tampered with", func() {
+			sealed, err := ring.Seal(data)
+			Expect(err).NotTo(HaveOccurred())
+
+			tampered := append([]byte{}, sealed...)
+			middle := len(tampered) / 2
+			if tampered[middle] == 'A' {
+				tampered[middle] = 'B'
+			} else {
+				tampered[middle] = 'A'
+			}
+
+			_, err = ring.Open(tampered)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("errors when the sealed data is malformed", func() {
+			_, err := ring.Open([]byte("sealed:no-separator"))
+			Expect(err).To(MatchError("sealed data is malformed"))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package keyring_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestKeyRingSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "keyring")
+}
@@ -0,0 +1,119 @@
+// Package keyring encrypts data with one active key while it can still
+// decrypt data that was encrypted with keys that have since been retired.
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/pivotal-golang/conceal"
+)
+
+// Separator divides the ID of the key from the veiled data. It does not occur
+// in the URL safe base64 alphabet of the veiled data.
+const Separator = "."
+
+// keyIDFormat limits key IDs to characters that are safe in URLs and that
+// include neither Separator nor the ":" that divides a sealed envelope.
+var keyIDFormat = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+type UnknownKeyError struct {
+	ID string
+}
+
+func (e UnknownKeyError) Error() string {
+	return fmt.Sprintf("no key with ID %q", e.ID)
+}
+
+// KeyRing veils with the active key and prefixes the result with the ID of
+// the key, so that it can be unveiled with the same key after a rotation.
+type KeyRing struct {
+	activeID string
+	ids      []string
+	cloaks   map[string]conceal.Cloak
+}
+
+// New returns a KeyRing that veils with the active key and unveils with any
+// of the keys.
+func New(active Key, retired ...Key) (KeyRing, error) {
+	ring := KeyRing{
+		activeID: active.ID,
+		cloaks:   map[string]conceal.Cloak{},
+	}
+
+	for _, key := range append([]Key{active}, retired...) {
+		if !keyIDFormat.MatchString(key.ID) {
+			return KeyRing{}, fmt.Errorf("key ID %q must only contain letters, digits, %q and %q", key.ID, "_", "-")
+		}
+
+		if len(key.Secret) == 0 {
+			return KeyRing{}, fmt.Errorf("key %q has no secret", key.ID)
+		}
+
+		if _, ok := ring.cloaks[key.ID]; ok {
+			return KeyRing{}, fmt.Errorf("key ID %q is used more than once", key.ID)
+		}
+
+		cloak, err := conceal.NewCloak(key.Secret)
+		if err != nil {
+			return KeyRing{}, err
+		}
+
+		ring.ids = append(ring.ids, key.ID)
+		ring.cloaks[key.ID] = cloak
+	}
+
+	return ring, nil
+}
+
+func (ring KeyRing) Veil(data []byte) ([]byte, error) {
+	cloak, ok := ring.cloaks[ring.activeID]
+	if !ok {
+		return []byte{}, errors.New("key ring has no active key")
+	}
+
+	veiled, err := cloak.Veil(data)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append([]byte(ring.activeID+Separator), veiled...), nil
+}
+
+// Unveil reads the ID of the key from the data. Data veiled before keys had
+// IDs is unveiled with the first key that succeeds, starting with the active
+// one.
+func (ring KeyRing) Unveil(data []byte) ([]byte, error) {
+	index := bytes.Index(data, []byte(Separator))
+	if index < 0 {
+		return ring.unveilWithoutID(data)
+	}
+
+	id := string(data[:index])
+	cloak, ok := ring.cloaks[id]
+	if !ok {
+		return []byte{}, UnknownKeyError{ID: id}
+	}
+
+	return cloak.Unveil(data[index+len(Separator):])
+}
+
+func (ring KeyRing) unveilWithoutID(data []byte) ([]byte, error) {
+	err := errors.New("no keys")
+	for _, id := range ring.ids {
+		var unveiled []byte
+		unveiled, err = ring.cloaks[id].Unveil(data)
+		if err == nil {
+			return unveiled, nil
+		}
+	}
+
+	return []byte{}, err
+}
@@ -0,0 +1,112 @@
+package keyring_test
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/keyring"
+	"github.com/pivotal-golang/conceal"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyRing", func() {
+	var (
+		oldKey keyring.Key
+		newKey keyring.Key
+		ring   keyring.KeyRing
+	)
+
+	BeforeEach(func() {
+		oldKey = keyring.Key{ID: "2015", Secret: []byte("the old secret")}
+		newKey = keyring.Key{ID: "2016", Secret: []byte("the new secret")}
+
+		var err error
+		ring, err = keyring.New(newKey, oldKey)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Veil", func() {
+		It("prefixes the veiled data with the ID of the active key", func() {
+			veiled, err := ring.Veil([]byte("user-123|client-id|kind-id"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(veiled)).To(HavePrefix("2016."))
+			Expect(string(veiled)).NotTo(ContainSubstring("user-123"))
+
+			unveiled, err := ring.Unveil(veiled)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(unveiled)).To(Equal("user-123|client-id|kind-id"))
+		})
+	})
+
+	Describe("Veil without keys", func() {
+		It("errors rather than veiling with an empty key", func() {
+			_, err := keyring.KeyRing{}.Veil([]byte("user-123|client-id|kind-id"))
+			Expect(err).To(MatchError("key ring has no active key"))
+		})
+	})
+
+	Describe("Unveil", func() {
+		It("unveils data veiled before the key was rotated", func() {
+			before, err := keyring.New(oldKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			veiled, err := before.Veil([]byte("user-123|client-id|kind-id"))
+			Expect(err).NotTo(HaveOccurred())
+
+			unveiled, err := ring.Unveil(veiled)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(unveiled)).To(Equal("user-123|client-id|kind-id"))
+		})
+
+		It("unveils data veiled without a key ID under a retired key", func() {
+			cloak, err := conceal.NewCloak(oldKey.Secret)
+			Expect(err).NotTo(HaveOccurred())
+
+			veiled, err := cloak.Veil([]byte("user-123|client-id|kind-id"))
+			Expect(err).NotTo(HaveOccurred())
+
+			unveiled, err := ring.Unveil(veiled)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(unveiled)).To(Equal("user-123|client-id|kind-id"))
+		})
+
+		It("errors once the key the data was veiled with is removed", func() {
+			veiled, err := ring.Veil([]byte("user-123|client-id|kind-id"))
+			Expect(err).NotTo(HaveOccurred())
+
+			after, err := keyring.New(keyring.Key{ID: "2017", Secret: []byte("the newest secret")})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = after.Unveil(veiled)
+			Expect(err).To(MatchError(keyring.UnknownKeyError{ID: "2016"}))
+		})
+	})
+
+	Describe("New", func() {
+		It("errors when a key ID is used more than once", func() {
+			_, err := keyring.New(newKey, oldKey, keyring.Key{ID: "2015", Secret: []byte("another secret")})
+			Expect(err).To(MatchError(`key ID "2015" is used more than once`))
+		})
+
+		It("errors when a key ID is empty or contains characters that are not URL safe", func() {
+			_, err := keyring.New(keyring.Key{ID: "", Secret: []byte("secret")})
+			Expect(err).To(MatchError(`key ID "" must only contain letters, digits, "_" and "-"`))
+
+			for _, id := range []string{"v1.2", "v1:2", "v1,2", "v1=2", "v1/2", "v 1"} {
+				_, err = keyring.New(keyring.Key{ID: id, Secret: []byte("secret")})
+				Expect(err).To(MatchError(fmt.Sprintf(`key ID %q must only contain letters, digits, "_" and "-"`, id)))
+			}
+		})
+
+		It("accepts key IDs made of letters, digits, underscores and dashes", func() {
+			_, err := keyring.New(keyring.Key{ID: "Key_2015-01", Secret: []byte("secret")})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("errors when a key has no secret", func() {
+			_, err := keyring.New(newKey, keyring.Key{ID: "2014"})
+			Expect(err).To(MatchError(`key "2014" has no secret`))
+		})
+	})
+})
@@ -0,0 +1,177 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAttachmentContentType = "application/octet-stream"
+	maxEncodedLineLength         = 76
+)
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Inline      bool
+	Content     []byte
+}
+
+// compileMixedBody lays out a message that carries attachments as a
+// multipart/mixed body. The text and html parts stay grouped as
+// multipart/alternative and, when inline attachments are present, are wrapped
+// together with them in a multipart/related part so that the html can
+// reference them by content ID.
+func (msg *Message) compileMixedBody() error {
+	buffer := bytes.NewBuffer([]byte{})
+	mixed := multipart.NewWriter(buffer)
+
+	var inline, attached []Attachment
+	for _, attachment := range msg.Attachments {
+		if attachment.Inline {
+			inline = append(inline, attachment)
+		} else {
+			attached = append(attached, attachment)
+		}
+	}
+
+	if len(inline) > 0 {
+		related := openMultipart(buffer, mixed, "related")
+		writeBodyParts(buffer, related, msg.Body)
+		writeAttachments(related, inline)
+		related.Close()
+	} else {
+		writeBodyParts(buffer, mixed, msg.Body)
+	}
+
+	writeAttachments(mixed, attached)
+	mixed.Close()
+
+	msg.CompiledBody = strings.Replace(buffer.String(), "\r\n", "\n", -1)
+	msg.Date = time.Now().Format(time.RFC822Z)
+	msg.MimeVersion = "1.0"
+	msg.ContentType = "multipart/mixed; boundary=" + mixed.Boundary()
+	msg.ContentTransferEncoding = ""
+
+	return nil
+}
+
+// openMultipart starts a nested multipart section within parent. Both writers
+// share the same underlying buffer, so the nested parts end up inside the
+// part that announces their boundary.
+func openMultipart(buffer *bytes.Buffer, parent *multipart.Writer, subtype string) *multipart.Writer {
+	child := multipart.NewWriter(buffer)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "multipart/"+subtype+"; boundary="+child.Boundary())
+	parent.CreatePart(header)
+
+	return child
+}
+
+func writeBodyParts(buffer *bytes.Buffer, writer *multipart.Writer, parts []Part) {
+	if len(parts) > 1 {
+		alternative := openMultipart(buffer, writer, "alternative")
+		defer alternative.Close()
+
+		writer = alternative
+	}
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.ContentType+"; charset=UTF-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		// The errors are not checked since the writers wrap a bytes.Buffer.
+		partWriter, _ := writer.CreatePart(header)
+		encoder := quotedprintable.NewWriter(partWriter)
+		encoder.Write([]byte(part.Content))
+		encoder.Close()
+	}
+}
+
+func writeAttachments(writer *multipart.Writer, attachments []Attachment) {
+	for _, attachment := range attachments {
+		disposition := "attachment"
+		if attachment.Inline {
+			disposition = "inline"
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", attachmentContentType(attachment))
+		header.Set("Content-Transfer-Encoding", "base64")
+		header.Set("Content-Disposition", formatMediaType(disposition, map[string]string{"filename": attachment.Filename}, disposition))
+		if attachment.ContentID != "" {
+			header.Set("Content-ID", "<"+strings.Trim(attachment.ContentID, "<> ")+">")
+		}
+
+		partWriter, _ := writer.CreatePart(header)
+		encoder := base64.NewEncoder(base64.StdEncoding, &lineWrapper{writer: partWriter})
+		encoder.Write(attachment.Content)
+		encoder.Close()
+	}
+}
+
+func attachmentContentType(attachment Attachment) string {
+	mediaType, params, err := mime.ParseMediaType(attachment.ContentType)
+	if err != nil {
+		mediaType, params = defaultAttachmentContentType, map[string]string{}
+	}
+
+	if attachment.Filename != "" {
+		params["name"] = attachment.Filename
+	}
+
+	return formatMediaType(mediaType, params, defaultAttachmentContentType)
+}
+
+// formatMediaType serializes a header value, quoting and encoding the
+// parameters as needed, and falls back to the given value if the parameters
+// cannot be represented.
+func formatMediaType(mediaType string, params map[string]string, fallback string) string {
+	for key, value := range params {
+		if value == "" {
+			delete(params, key)
+		}
+	}
+
+	formatted := mime.FormatMediaType(mediaType, params)
+	if formatted == "" {
+		return fallback
+	}
+
+	return formatted
+}
+
+// lineWrapper breaks base64 encoded content into lines of 76 characters, as
+// required by RFC 2045.
+type lineWrapper struct {
+	writer io.Writer
+	length int
+}
+
+func (w *lineWrapper) Write(p []byte) (int, error) {
+	written := 0
+	for len(p)+w.length > maxEncodedLineLength {
+		chunk := maxEncodedLineLength - w.length
+		w.writer.Write(p[:chunk])
+		w.writer.Write([]byte("\r\n"))
+
+		p = p[chunk:]
+		written += chunk
+		w.length = 0
+	}
+
+	w.writer.Write(p)
+	w.length += len(p)
+
+	return written + len(p), nil
+}
@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// LoginAuth returns an smtp.Auth that implements the LOGIN mechanism, which
+// sends the username and password in response to the server's prompts. Like
+// smtp.PlainAuth it refuses to send credentials over an unencrypted
+// connection to anything but localhost.
+func LoginAuth(username, password, host string) smtp.Auth {
+	return &loginAuth{
+		username: username,
+		password: password,
+		host:     host,
+	}
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %q", fromServer)
+	}
+}
+
+// TokenSource provides the OAuth access token used by XOAUTH2.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticToken is a TokenSource for an access token that never changes.
+type StaticToken string
+
+func (t StaticToken) Token() (string, error) {
+	return string(t), nil
+}
+
+type xoauth2Auth struct {
+	username string
+	tokens   TokenSource
+}
+
+// XOAUTH2Auth returns an smtp.Auth that implements the XOAUTH2 mechanism. A
+// fresh access token is requested from tokens for every authentication.
+func XOAUTH2Auth(username string, tokens TokenSource) smtp.Auth {
+	return &xoauth2Auth{
+		username: username,
+		tokens:   tokens,
+	}
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	token, err := a.tokens.Token()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + token + "\x01\x01"), nil
+}
+
+// Next answers the error details the server sends when it rejects the token
+// with an empty response, after which the server fails the authentication.
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		return []byte{}, nil
+	}
+
+	return nil, nil
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,94 @@
+package mail_test
+
+import (
+	"errors"
+	"net/smtp"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type failingTokenSource struct{}
+
+func (failingTokenSource) Token() (string, error) {
+	return "", errors.New("token endpoint unavailable")
+}
+
+var _ = Describe("Auth", func() {
+	Describe("LoginAuth", func() {
+		var auth smtp.Auth
+
+		BeforeEach(func() {
+			auth = mail.LoginAuth("some-user", "some-pass", "smtp.example.com")
+		})
+
+		It("answers the username and password prompts", func() {
+			mechanism, response, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mechanism).To(Equal("LOGIN"))
+			Expect(response).To(BeNil())
+
+			answer, err := auth.Next([]byte("Username:"), true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(answer)).To(Equal("some-user"))
+
+			answer, err = auth.Next([]byte("Password:"), true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(answer)).To(Equal("some-pass"))
+
+			answer, err = auth.Next(nil, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(answer).To(BeNil())
+		})
+
+		It("refuses to send credentials over an unencrypted connection", func() {
+			_, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com"})
+			Expect(err).To(MatchError("unencrypted connection"))
+		})
+
+		It("refuses to authenticate against another host", func() {
+			_, _, err := auth.Start(&smtp.ServerInfo{Name: "evil.example.com", TLS: true})
+			Expect(err).To(MatchError("wrong host name"))
+		})
+
+		It("fails on unexpected prompts", func() {
+			_, err := auth.Next([]byte("Favourite colour:"), true)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("XOAUTH2Auth", func() {
+		It("sends the user and the bearer token in the initial response", func() {
+			auth := mail.XOAUTH2Auth("some-user", mail.StaticToken("some-token"))
+
+			mechanism, response, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mechanism).To(Equal("XOAUTH2"))
+			Expect(string(response)).To(Equal("user=some-user\x01auth=Bearer some-token\x01\x01"))
+		})
+
+		It("acknowledges the error details sent when the token is rejected", func() {
+			auth := mail.XOAUTH2Auth("some-user", mail.StaticToken("some-token"))
+
+			answer, err := auth.Next([]byte(`{"status":"401"}`), true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(answer).To(Equal([]byte{}))
+		})
+
+		It("returns the error when no token can be obtained", func() {
+			auth := mail.XOAUTH2Auth("some-user", failingTokenSource{})
+
+			_, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+			Expect(err).To(MatchError("token endpoint unavailable"))
+		})
+
+		It("refuses to send the token over an unencrypted connection", func() {
+			auth := mail.XOAUTH2Auth("some-user", mail.StaticToken("some-token"))
+
+			_, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com"})
+			Expect(err).To(MatchError("unencrypted connection"))
+		})
+	})
+})
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net"
 	"net/smtp"
+	"net/textproto"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,9 +19,19 @@ const (
 	SMTPAuthNone    = "none"
 	SMTPAuthPlain   = "plain"
 	SMTPAuthCRAMMD5 = "cram-md5"
+	SMTPAuthLogin   = "login"
+	SMTPAuthXOAUTH2 = "xoauth2"
 )
 
-var SMTPAuthMechanisms = []string{SMTPAuthNone, SMTPAuthPlain, SMTPAuthCRAMMD5}
+var SMTPAuthMechanisms = []string{SMTPAuthNone, SMTPAuthPlain, SMTPAuthCRAMMD5, SMTPAuthLogin, SMTPAuthXOAUTH2}
+
+const (
+	TLSModeNone     = "none"
+	TLSModeStartTLS = "starttls"
+	TLSModeImplicit = "implicit"
+)
+
+var TLSModes = []string{TLSModeNone, TLSModeStartTLS, TLSModeImplicit}
 
 type AuthMechanism int
 
@@ -40,6 +52,36 @@ type Config struct {
 	DisableTLS        bool
 	ConnectTimeout    time.Duration
 	LoggingEnabled    bool
+
+	// TLSMode is one of TLSModeNone, TLSModeStartTLS or TLSModeImplicit. When
+	// it is empty, DisableTLS chooses between none and starttls.
+	TLSMode string
+
+	// ClientCertificates are presented to servers that ask for a client
+	// certificate during the TLS handshake.
+	ClientCertificates []tls.Certificate
+
+	// TokenSource provides the access tokens for XOAUTH2. Pass is used as a
+	// static token when it is nil.
+	TokenSource TokenSource
+}
+
+// RecipientsError is returned by Send when the server refuses some or all of
+// the recipients of a message. The message has still been delivered to the
+// accepted recipients, if there are any.
+type RecipientsError struct {
+	Accepted []string
+	Rejected map[string]error
+}
+
+func (e RecipientsError) Error() string {
+	var recipients []string
+	for recipient, err := range e.Rejected {
+		recipients = append(recipients, fmt.Sprintf("%s (%s)", recipient, err))
+	}
+	sort.Strings(recipients)
+
+	return "SMTP server rejected recipients: " + strings.Join(recipients, ", ")
 }
 
 type connection struct {
@@ -54,6 +96,13 @@ func NewClient(config Config) *Client {
 		client.config.ConnectTimeout = 15 * time.Second
 	}
 
+	if client.config.TLSMode == "" {
+		client.config.TLSMode = TLSModeStartTLS
+		if client.config.DisableTLS {
+			client.config.TLSMode = TLSModeNone
+		}
+	}
+
 	return client
 }
 
@@ -99,7 +148,27 @@ func (c *Client) connect() chan connection {
 	channel := make(chan connection)
 
 	go func() {
-		client, err := smtp.Dial(net.JoinHostPort(c.config.Host, c.config.Port))
+		address := net.JoinHostPort(c.config.Host, c.config.Port)
+
+		if c.config.TLSMode != TLSModeImplicit {
+			client, err := smtp.Dial(address)
+			channel <- connection{
+				client: client,
+				err:    err,
+			}
+			return
+		}
+
+		conn, err := tls.Dial("tcp", address, c.tlsConfig())
+		if err != nil {
+			channel <- connection{err: err}
+			return
+		}
+
+		client, err := smtp.NewClient(conn, c.config.Host)
+		if err != nil {
+			conn.Close()
+		}
 		channel <- connection{
 			client: client,
 			err:    err,
@@ -129,13 +198,15 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 	}
 	c.PrintLog(logger, "hello-complete")
 
-	if !c.config.DisableTLS {
-		c.PrintLog(logger, "tls-starting")
-		err = c.StartTLS()
-		if err != nil {
-			return c.Error(logger, err)
+	if c.config.TLSMode != TLSModeNone {
+		if c.config.TLSMode == TLSModeStartTLS {
+			c.PrintLog(logger, "tls-starting")
+			err = c.StartTLS()
+			if err != nil {
+				return c.Error(logger, err)
+			}
+			c.PrintLog(logger, "tls-connected")
 		}
-		c.PrintLog(logger, "tls-connected")
 
 		c.PrintLog(logger, "authentication-starting")
 		err = c.Auth(logger)
@@ -148,13 +219,28 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 	c.PrintLog(logger, "setting-msg-from", lager.Data{"from": msg.From})
 	err = c.client.Mail(msg.From)
 	if err != nil {
-		return c.Error(logger, err)
+		return c.Error(logger, smtpError("MAIL", err))
 	}
 
-	c.PrintLog(logger, "setting-msg-to", lager.Data{"to": msg.To})
-	err = c.client.Rcpt(msg.To)
-	if err != nil {
-		return c.Error(logger, err)
+	recipientsErr := RecipientsError{Rejected: map[string]error{}}
+	for _, recipient := range msg.EnvelopeRecipients() {
+		c.PrintLog(logger, "setting-msg-to", lager.Data{"to": recipient})
+		err = c.client.Rcpt(recipient)
+		if err != nil {
+			if _, ok := err.(*textproto.Error); !ok {
+				return c.Error(logger, err)
+			}
+
+			c.PrintLog(logger, "recipient-rejected", lager.Data{"to": recipient, "error": err.Error()})
+			recipientsErr.Rejected[recipient] = smtpError("RCPT", err)
+			continue
+		}
+
+		recipientsErr.Accepted = append(recipientsErr.Accepted, recipient)
+	}
+
+	if len(recipientsErr.Accepted) == 0 {
+		return c.Error(logger, recipientsErr)
 	}
 
 	c.PrintLog(logger, "setting-msg-data", lager.Data{"message-data": base64.StdEncoding.EncodeToString([]byte(msg.Data()))})
@@ -171,15 +257,58 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 	}
 	c.PrintLog(logger, "disconnected")
 
+	if len(recipientsErr.Rejected) > 0 {
+		return recipientsErr
+	}
+
 	return nil
 }
 
 func (c *Client) Hello() error {
 	err := c.client.Hello("localhost")
+	if err != nil {
+		return smtpError("EHLO", err)
+	}
+
+	return nil
+}
+
+// Verify connects to the server and checks that it agrees with the
+// configuration on whether to use STARTTLS. It returns a TLSMismatchError if
+// it does not.
+func (c *Client) Verify(logger lager.Logger) error {
+	if c.config.TestMode {
+		return nil
+	}
+
+	err := c.Connect(logger)
 	if err != nil {
 		return err
 	}
 
+	err = c.Hello()
+	if err != nil {
+		c.Quit()
+		return err
+	}
+
+	startTLSSupported, _ := c.Extension("STARTTLS")
+
+	c.Quit()
+
+	// With implicit TLS the handshake made while connecting has already shown
+	// that the server speaks TLS.
+	switch c.config.TLSMode {
+	case TLSModeStartTLS:
+		if !startTLSSupported {
+			return TLSMismatchError{errors.New(`SMTP TLS configuration mismatch: Configured to use TLS over SMTP, but the mail server does not support the "STARTTLS" extension.`)}
+		}
+	case TLSModeNone:
+		if startTLSSupported {
+			return TLSMismatchError{errors.New(`SMTP TLS configuration mismatch: Not configured to use TLS over SMTP, but the mail server does support the "STARTTLS" extension.`)}
+		}
+	}
+
 	return nil
 }
 
@@ -189,24 +318,30 @@ func (c *Client) Extension(name string) (bool, string) {
 
 func (c *Client) StartTLS() error {
 	if ok, _ := c.Extension("STARTTLS"); ok {
-		err := c.client.StartTLS(&tls.Config{
-			ServerName:         c.config.Host,
-			InsecureSkipVerify: c.config.SkipVerifySSL,
-		})
+		err := c.client.StartTLS(c.tlsConfig())
 		if err != nil {
-			return err
+			return smtpError("STARTTLS", err)
 		}
 	}
 
 	return nil
 }
 
+func (c *Client) tlsConfig() *tls.Config {
+	return &tls.Config{
+		ServerName:         c.config.Host,
+		InsecureSkipVerify: c.config.SkipVerifySSL,
+		Certificates:       c.config.ClientCertificates,
+	}
+}
+
 func (c *Client) Auth(logger lager.Logger) error {
 	if ok, _ := c.Extension("AUTH"); ok {
 		if mechanism := c.AuthMechanism(logger); mechanism != nil {
 			err := c.client.Auth(mechanism)
 			if err != nil {
-				return err
+				c.invalidateToken()
+				return smtpError("AUTH", err)
 			}
 		}
 	}
@@ -222,16 +357,44 @@ func (c *Client) AuthMechanism(logger lager.Logger) smtp.Auth {
 	case SMTPAuthPlain:
 		c.PrintLog(logger, "plain-authentication")
 		return smtp.PlainAuth("", c.config.User, c.config.Pass, c.config.Host)
+	case SMTPAuthLogin:
+		c.PrintLog(logger, "login-authentication")
+		return LoginAuth(c.config.User, c.config.Pass, c.config.Host)
+	case SMTPAuthXOAUTH2:
+		c.PrintLog(logger, "xoauth2-authentication")
+		return XOAUTH2Auth(c.config.User, c.tokenSource())
 	default:
 		c.PrintLog(logger, "no-authentication")
 		return nil
 	}
 }
 
+func (c *Client) tokenSource() TokenSource {
+	if c.config.TokenSource == nil {
+		return StaticToken(c.config.Pass)
+	}
+
+	return c.config.TokenSource
+}
+
+// invalidateToken makes sure a token the server refused is not presented
+// again, in case it was revoked before it expired.
+func (c *Client) invalidateToken() {
+	if c.config.SMTPAuthMechanism != SMTPAuthXOAUTH2 {
+		return
+	}
+
+	if tokens, ok := c.config.TokenSource.(interface {
+		Invalidate()
+	}); ok {
+		tokens.Invalidate()
+	}
+}
+
 func (c *Client) Data(msg Message) error {
 	wc, err := c.client.Data()
 	if err != nil {
-		return err
+		return smtpError("DATA", err)
 	}
 
 	data := strings.Replace(string(msg.Data()), "%", "%%", -1)
@@ -242,7 +405,7 @@ func (c *Client) Data(msg Message) error {
 
 	err = wc.Close()
 	if err != nil {
-		return err
+		return smtpError("DATA", err)
 	}
 
 	return nil
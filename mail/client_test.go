@@ -2,6 +2,7 @@ package mail_test
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"net"
@@ -203,7 +204,7 @@ var _ = Describe("Mail", func() {
 
 			Expect(delivery.Sender).To(Equal("me@example.com"))
 			Expect(delivery.Recipient).To(Equal("you@example.com"))
-			Expect(delivery.Data).To(Equal(strings.Split(msg.Data(), "\n")))
+			Expect(delivery.Data).To(Equal(strings.Split(msg.Data(), "\r\n")))
 			Expect(delivery.UsedTLS).To(BeTrue())
 		})
 
@@ -232,7 +233,7 @@ var _ = Describe("Mail", func() {
 
 			Expect(delivery.Sender).To(Equal("me@example.com"))
 			Expect(delivery.Recipient).To(Equal("you@example.com"))
-			Expect(delivery.Data).To(Equal(strings.Split(firstMsg.Data(), "\n")))
+			Expect(delivery.Data).To(Equal(strings.Split(firstMsg.Data(), "\r\n")))
 
 			secondMsg := mail.Message{
 				From:    "first@example.com",
@@ -258,7 +259,128 @@ var _ = Describe("Mail", func() {
 
 			Expect(delivery.Sender).To(Equal("first@example.com"))
 			Expect(delivery.Recipient).To(Equal("second@example.com"))
-			Expect(delivery.Data).To(Equal(strings.Split(secondMsg.Data(), "\n")))
+			Expect(delivery.Data).To(Equal(strings.Split(secondMsg.Data(), "\r\n")))
+		})
+
+		Context("when the message has several recipients", func() {
+			var msg mail.Message
+
+			BeforeEach(func() {
+				msg = mail.Message{
+					From:       "me@example.com",
+					To:         "you@example.com, them@example.com",
+					CC:         "boss@example.com",
+					Subject:    "Team update",
+					Recipients: []string{"you@example.com", "them@example.com", "boss@example.com", "hidden@example.com"},
+					Body: []mail.Part{
+						{
+							ContentType: "text/plain",
+							Content:     "Hello, team!",
+						},
+					},
+				}
+			})
+
+			It("sends the message to every recipient in a single transaction", func() {
+				err := client.Send(msg, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				delivery := mailServer.Deliveries[0]
+
+				Expect(delivery.Recipients).To(Equal([]string{"you@example.com", "them@example.com", "boss@example.com", "hidden@example.com"}))
+				Expect(delivery.Data).To(ContainElement("Cc: boss@example.com"))
+				for _, line := range delivery.Data {
+					Expect(line).NotTo(ContainSubstring("hidden@example.com"))
+				}
+			})
+
+			Context("when the server defers a recipient", func() {
+				BeforeEach(func() {
+					mailServer.DefersRcptTo = map[string]bool{"them@example.com": true}
+					msg.Recipients = []string{"them@example.com"}
+				})
+
+				AfterEach(func() {
+					mailServer.DefersRcptTo = nil
+				})
+
+				It("reports a transient failure", func() {
+					err := client.Send(msg, logger)
+					Expect(err).To(BeAssignableToTypeOf(mail.RecipientsError{}))
+					Expect(err.(mail.RecipientsError).Rejected).To(HaveKeyWithValue("them@example.com", mail.SMTPError{
+						Command: "RCPT",
+						Code:    451,
+						Message: "Greylisted, try again later",
+					}))
+					Expect(mail.IsPermanent(err)).To(BeFalse())
+				})
+			})
+
+			Context("when the server rejects the message data", func() {
+				BeforeEach(func() {
+					mailServer.RejectsData = true
+				})
+
+				AfterEach(func() {
+					mailServer.RejectsData = false
+				})
+
+				It("returns the reply as a permanent failure", func() {
+					err := client.Send(msg, logger)
+					Expect(err).To(Equal(mail.SMTPError{
+						Command: "DATA",
+						Code:    554,
+						Message: "Message rejected as spam",
+					}))
+					Expect(mail.IsPermanent(err)).To(BeTrue())
+				})
+			})
+
+			Context("when the server rejects some of the recipients", func() {
+				BeforeEach(func() {
+					mailServer.RejectsRcptTo = map[string]bool{"them@example.com": true}
+				})
+
+				AfterEach(func() {
+					mailServer.RejectsRcptTo = nil
+				})
+
+				It("delivers to the others and reports the rejected recipients", func() {
+					err := client.Send(msg, logger)
+					Expect(err).To(BeAssignableToTypeOf(mail.RecipientsError{}))
+
+					recipientsErr := err.(mail.RecipientsError)
+					Expect(recipientsErr.Accepted).To(Equal([]string{"you@example.com", "boss@example.com", "hidden@example.com"}))
+					Expect(recipientsErr.Rejected).To(HaveKeyWithValue("them@example.com", mail.SMTPError{
+						Command: "RCPT",
+						Code:    550,
+						Message: "No such user here",
+					}))
+					Expect(recipientsErr.Error()).To(ContainSubstring("them@example.com (550"))
+
+					Eventually(func() int {
+						return len(mailServer.Deliveries)
+					}).Should(Equal(1))
+					Expect(mailServer.Deliveries[0].Data).NotTo(BeEmpty())
+				})
+
+				It("does not send any data when every recipient is rejected", func() {
+					msg.Recipients = []string{"them@example.com"}
+
+					err := client.Send(msg, logger)
+					Expect(err).To(BeAssignableToTypeOf(mail.RecipientsError{}))
+					Expect(err.(mail.RecipientsError).Accepted).To(BeEmpty())
+					Expect(mail.IsPermanent(err)).To(BeTrue())
+
+					Eventually(func() int {
+						return len(mailServer.Deliveries)
+					}).Should(Equal(1))
+					Expect(mailServer.Deliveries[0].Data).To(BeEmpty())
+				})
+			})
 		})
 
 		Context("when configured to use TLS", func() {
@@ -326,6 +448,78 @@ var _ = Describe("Mail", func() {
 				Expect(delivery.UsedTLS).To(BeFalse())
 			})
 		})
+		Context("when configured to use implicit TLS", func() {
+			var msg mail.Message
+
+			BeforeEach(func() {
+				mailServer.SupportsTLS = false
+				mailServer.ImplicitTLS = true
+				config.TLSMode = mail.TLSModeImplicit
+				config.SMTPAuthMechanism = mail.SMTPAuthLogin
+				client = mail.NewClient(config)
+
+				msg = mail.Message{
+					From:    "me@example.com",
+					To:      "you@example.com",
+					Subject: "Urgent! Read now!",
+					Body: []mail.Part{
+						{
+							ContentType: "text/plain",
+							Content:     "This email is the most important thing you will read all day!",
+						},
+					},
+				}
+			})
+
+			It("communicates over TLS from the start and authenticates", func() {
+				err := client.Send(msg, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+
+				delivery := mailServer.Deliveries[0]
+				Expect(delivery.UsedTLS).To(BeTrue())
+				Expect(delivery.AuthMechanism).To(Equal("LOGIN"))
+				Expect(delivery.AuthCredentials).To(Equal([]string{"user", "pass"}))
+				Expect(delivery.Recipient).To(Equal("you@example.com"))
+			})
+
+			It("presents the configured client certificate", func() {
+				cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+				Expect(err).NotTo(HaveOccurred())
+
+				mailServer.RequestsCert = true
+				config.ClientCertificates = []tls.Certificate{cert}
+				client = mail.NewClient(config)
+
+				err = client.Send(msg, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				Expect(mailServer.Deliveries[0].ClientCertificate).To(BeTrue())
+			})
+
+			It("authenticates with an XOAUTH2 token", func() {
+				config.SMTPAuthMechanism = mail.SMTPAuthXOAUTH2
+				config.TokenSource = mail.StaticToken("some-access-token")
+				client = mail.NewClient(config)
+
+				err := client.Send(msg, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+
+				delivery := mailServer.Deliveries[0]
+				Expect(delivery.AuthMechanism).To(Equal("XOAUTH2"))
+				Expect(delivery.AuthCredentials).To(Equal([]string{"user=user\x01auth=Bearer some-access-token\x01\x01"}))
+			})
+		})
 	})
 
 	Describe("Connect", func() {
@@ -416,6 +610,48 @@ var _ = Describe("Mail", func() {
 		})
 	})
 
+	Describe("Verify", func() {
+		It("succeeds when the server agrees with the TLS configuration", func() {
+			mailServer.SupportsTLS = true
+
+			Expect(client.Verify(logger)).To(Succeed())
+		})
+
+		It("returns a mismatch when the server does not support STARTTLS", func() {
+			mailServer.SupportsTLS = false
+
+			err := client.Verify(logger)
+			Expect(err).To(BeAssignableToTypeOf(mail.TLSMismatchError{}))
+			Expect(err).To(MatchError(`SMTP TLS configuration mismatch: Configured to use TLS over SMTP, but the mail server does not support the "STARTTLS" extension.`))
+		})
+
+		It("returns a mismatch when TLS is disabled but the server supports STARTTLS", func() {
+			mailServer.SupportsTLS = true
+			config.DisableTLS = true
+			client = mail.NewClient(config)
+
+			err := client.Verify(logger)
+			Expect(err).To(BeAssignableToTypeOf(mail.TLSMismatchError{}))
+		})
+
+		It("returns the error when it cannot connect", func() {
+			config.Host = "127.0.0.1"
+			config.Port = "1"
+			config.ConnectTimeout = 100 * time.Millisecond
+			client = mail.NewClient(config)
+
+			Expect(client.Verify(logger)).NotTo(Succeed())
+		})
+
+		It("does not connect in test mode", func() {
+			config.TestMode = true
+			config.Host = "fakewebsiteoninternet.com"
+			client = mail.NewClient(config)
+
+			Expect(client.Verify(logger)).To(Succeed())
+		})
+	})
+
 	Describe("AuthMechanism", func() {
 		Context("when configured to use PLAIN auth", func() {
 			BeforeEach(func() {
@@ -457,6 +693,24 @@ var _ = Describe("Mail", func() {
 				Expect(mechanism).To(BeNil())
 			})
 		})
+
+		Context("when configured to use LOGIN auth", func() {
+			It("creates a LoginAuth strategy", func() {
+				config.SMTPAuthMechanism = mail.SMTPAuthLogin
+				client = mail.NewClient(config)
+
+				Expect(client.AuthMechanism(logger)).To(BeAssignableToTypeOf(mail.LoginAuth("", "", "")))
+			})
+		})
+
+		Context("when configured to use XOAUTH2 auth", func() {
+			It("creates an XOAUTH2Auth strategy", func() {
+				config.SMTPAuthMechanism = mail.SMTPAuthXOAUTH2
+				client = mail.NewClient(config)
+
+				Expect(client.AuthMechanism(logger)).To(BeAssignableToTypeOf(mail.XOAUTH2Auth("", nil)))
+			})
+		})
 	})
 
 	Describe("Error", func() {
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"fmt"
+	"net/textproto"
+)
+
+// SMTPError is returned when the server answers a command with an error
+// reply. Command names the command the server refused, e.g. "RCPT".
+type SMTPError struct {
+	Command string
+	Code    int
+	Message string
+}
+
+func (e SMTPError) Error() string {
+	return fmt.Sprintf("%03d %s", e.Code, e.Message)
+}
+
+// Permanent reports whether the server refused the message itself for good,
+// so that sending it again cannot succeed. Errors from setting up the
+// session, like a failed login, are never permanent: they point at a problem
+// with the service's own configuration that may well be fixed.
+func (e SMTPError) Permanent() bool {
+	if e.Code < 500 || e.Code > 599 {
+		return false
+	}
+
+	switch e.Command {
+	case "MAIL", "RCPT", "DATA":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPermanent reports whether an error returned by Send means the message
+// will never be delivered. Network errors and 4xx replies are transient. A
+// RecipientsError is permanent when every recipient was refused for good.
+func IsPermanent(err error) bool {
+	switch e := err.(type) {
+	case SMTPError:
+		return e.Permanent()
+	case RecipientsError:
+		if len(e.Accepted) > 0 || len(e.Rejected) == 0 {
+			return false
+		}
+
+		for _, rejection := range e.Rejected {
+			if !IsPermanent(rejection) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// TLSMismatchError is returned by Verify when the server does not agree with
+// the configuration on whether to use STARTTLS.
+type TLSMismatchError struct {
+	Err error
+}
+
+func (e TLSMismatchError) Error() string {
+	return e.Err.Error()
+}
+
+func smtpError(command string, err error) error {
+	if reply, ok := err.(*textproto.Error); ok {
+		return SMTPError{
+			Command: command,
+			Code:    reply.Code,
+			Message: reply.Msg,
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,68 @@
+package mail_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SMTPError", func() {
+	It("renders the reply code and message", func() {
+		err := mail.SMTPError{Command: "RCPT", Code: 550, Message: "5.1.1 Mailbox unavailable"}
+		Expect(err.Error()).To(Equal("550 5.1.1 Mailbox unavailable"))
+	})
+
+	Describe("Permanent", func() {
+		It("is true for 5xx replies about the message", func() {
+			Expect(mail.SMTPError{Command: "MAIL", Code: 552}.Permanent()).To(BeTrue())
+			Expect(mail.SMTPError{Command: "RCPT", Code: 550}.Permanent()).To(BeTrue())
+			Expect(mail.SMTPError{Command: "DATA", Code: 554}.Permanent()).To(BeTrue())
+		})
+
+		It("is false for 4xx replies", func() {
+			Expect(mail.SMTPError{Command: "RCPT", Code: 451}.Permanent()).To(BeFalse())
+		})
+
+		It("is false for replies while setting up the session", func() {
+			Expect(mail.SMTPError{Command: "EHLO", Code: 554}.Permanent()).To(BeFalse())
+			Expect(mail.SMTPError{Command: "STARTTLS", Code: 554}.Permanent()).To(BeFalse())
+			Expect(mail.SMTPError{Command: "AUTH", Code: 535}.Permanent()).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("IsPermanent", func() {
+	It("is false for errors that are not SMTP replies", func() {
+		Expect(mail.IsPermanent(errors.New("connection reset by peer"))).To(BeFalse())
+	})
+
+	It("is true when every recipient was refused for good", func() {
+		Expect(mail.IsPermanent(mail.RecipientsError{
+			Rejected: map[string]error{
+				"first@example.com":  mail.SMTPError{Command: "RCPT", Code: 550},
+				"second@example.com": mail.SMTPError{Command: "RCPT", Code: 553},
+			},
+		})).To(BeTrue())
+	})
+
+	It("is false when any recipient may be accepted later", func() {
+		Expect(mail.IsPermanent(mail.RecipientsError{
+			Rejected: map[string]error{
+				"first@example.com":  mail.SMTPError{Command: "RCPT", Code: 550},
+				"second@example.com": mail.SMTPError{Command: "RCPT", Code: 452},
+			},
+		})).To(BeFalse())
+	})
+
+	It("is false when some recipients were accepted", func() {
+		Expect(mail.IsPermanent(mail.RecipientsError{
+			Accepted: []string{"first@example.com"},
+			Rejected: map[string]error{
+				"second@example.com": mail.SMTPError{Command: "RCPT", Code: 550},
+			},
+		})).To(BeFalse())
+	})
+})
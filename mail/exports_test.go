@@ -5,3 +5,7 @@ import "time"
 func (c *Client) ConnectTimeout() time.Duration {
 	return c.config.ConnectTimeout
 }
+
+func (s *RefreshingTokenSource) SetNow(now func() time.Time) {
+	s.now = now
+}
@@ -0,0 +1,156 @@
+package mail
+
+import (
+	"bytes"
+	"mime"
+	netmail "net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxHeaderLineLength is the line length recommended by RFC 5322, section
+// 2.1.1. Lines are folded at whitespace to stay within it where possible.
+const maxHeaderLineLength = 78
+
+// headerWriter writes RFC 5322 header fields with CRLF line endings. Values
+// are stripped of line breaks so that they cannot inject additional header
+// fields, non-ASCII text is written as RFC 2047 encoded-words, and long lines
+// are folded.
+type headerWriter struct {
+	buffer *bytes.Buffer
+}
+
+func newHeaderWriter(buffer *bytes.Buffer) headerWriter {
+	return headerWriter{buffer: buffer}
+}
+
+// WriteRaw writes a header field whose value is already in its wire format,
+// such as Content-Type or Date.
+func (w headerWriter) WriteRaw(name, value string) {
+	w.write(name, sanitizeHeaderValue(value))
+}
+
+// WriteText writes an unstructured header field, such as Subject.
+func (w headerWriter) WriteText(name, value string) {
+	w.write(name, encodeHeaderText(sanitizeHeaderValue(value)))
+}
+
+// WriteAddresses writes an address list header field, such as To or
+// Reply-To. Addresses that cannot be parsed are written as they were given,
+// without their line breaks.
+func (w headerWriter) WriteAddresses(name, value string) {
+	w.write(name, formatAddressList(sanitizeHeaderValue(value)))
+}
+
+// WriteField writes a preformatted "Name: value" header field as unstructured
+// text.
+func (w headerWriter) WriteField(field string) {
+	parts := strings.SplitN(field, ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+
+	w.WriteText(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+}
+
+func (w headerWriter) write(name, value string) {
+	w.buffer.WriteString(foldHeader(sanitizeHeaderName(name), value))
+	w.buffer.WriteString("\r\n")
+}
+
+// foldHeader joins the name and value into a header field, breaking it onto
+// continuation lines before whitespace whenever a line would otherwise exceed
+// maxHeaderLineLength. A single word longer than the limit is left intact.
+func foldHeader(name, value string) string {
+	if value == "" {
+		return name + ":"
+	}
+
+	var lines []string
+	line := name + ":"
+	for _, word := range strings.Split(value, " ") {
+		if len(line)+1+len(word) > maxHeaderLineLength && strings.TrimSpace(line) != name+":" {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		line += " " + word
+	}
+	lines = append(lines, line)
+
+	return strings.Join(lines, "\r\n")
+}
+
+func sanitizeHeaderName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == ':' || r >= utf8.RuneSelf {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// sanitizeHeaderValue replaces line breaks and other control characters with
+// spaces, which keeps a value from starting a new header field or ending the
+// header section early.
+func sanitizeHeaderValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, value)
+
+	return strings.TrimSpace(value)
+}
+
+// encodeHeaderText returns value as RFC 2047 encoded-words if it contains
+// anything other than printable ASCII. Mostly non-ASCII text, such as
+// Japanese, is base64 encoded, which is more compact than quoted-printable.
+func encodeHeaderText(value string) string {
+	nonASCII := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			nonASCII++
+		}
+	}
+
+	if nonASCII == 0 && !strings.Contains(value, "=?") {
+		return value
+	}
+
+	if nonASCII > len(value)/2 {
+		return mime.BEncoding.Encode("utf-8", value)
+	}
+
+	return mime.QEncoding.Encode("utf-8", value)
+}
+
+// formatAddressList parses and re-serializes a list of addresses so that
+// display names are quoted or encoded as needed. Bare addresses are written
+// without angle brackets.
+func formatAddressList(value string) string {
+	if value == "" {
+		return value
+	}
+
+	addresses, err := netmail.ParseAddressList(value)
+	if err != nil {
+		return value
+	}
+
+	var formatted []string
+	for _, address := range addresses {
+		formatted = append(formatted, formatAddress(address))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func formatAddress(address *netmail.Address) string {
+	if address.Name == "" {
+		return address.Address
+	}
+
+	return address.String()
+}
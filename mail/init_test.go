@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"crypto/rand"
 	"crypto/tls"
+	"encoding/base64"
 	"log"
 	"net"
 	"net/url"
@@ -86,13 +87,22 @@ type SMTPServer struct {
 	halt            chan bool
 	ConnectionState string
 	FailsHello      bool
+	RejectsRcptTo   map[string]bool
+	DefersRcptTo    map[string]bool
+	RejectsData     bool
+	ImplicitTLS     bool
+	RequestsCert    bool
 }
 
 type Delivery struct {
-	Recipient string
-	Sender    string
-	Data      []string
-	UsedTLS   bool
+	Recipient         string
+	Recipients        []string
+	Sender            string
+	Data              []string
+	UsedTLS           bool
+	ClientCertificate bool
+	AuthMechanism     string
+	AuthCredentials   []string
 }
 
 func NewSMTPServer(user, pass string) *SMTPServer {
@@ -155,6 +165,10 @@ func (server *SMTPServer) Respond(conn net.Conn) {
 	<-time.After(server.ConnectWait)
 	server.ConnectionState = StateConnected
 
+	if server.ImplicitTLS {
+		conn = server.ServeTLS(conn)
+	}
+
 	input := bufio.NewReader(conn)
 	output := bufio.NewWriter(conn)
 	server.Broadcast(output)
@@ -169,6 +183,10 @@ Loop:
 			conn, input, output = server.RespondToStartTLS(conn, input, output)
 		case strings.Contains(msg, "AUTH PLAIN"):
 			server.RespondToAuthPlain(output)
+		case strings.Contains(msg, "AUTH LOGIN"):
+			server.RespondToAuthLogin(output, input)
+		case strings.Contains(msg, "AUTH XOAUTH2"):
+			server.RespondToAuthXOAUTH2(output, msg)
 		case strings.Contains(msg, "MAIL FROM"):
 			server.RespondToMailFrom(output, msg)
 		case strings.Contains(msg, "RCPT TO"):
@@ -212,6 +230,12 @@ func (server *SMTPServer) RespondToStartTLS(conn net.Conn, input *bufio.Reader,
 	output.WriteString("220 Go ahead\r\n")
 	output.Flush()
 
+	tlsConn := server.ServeTLS(conn)
+
+	return tlsConn, bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn)
+}
+
+func (server *SMTPServer) ServeTLS(conn net.Conn) *tls.Conn {
 	server.CurrentDelivery.UsedTLS = true
 
 	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
@@ -220,9 +244,16 @@ func (server *SMTPServer) RespondToStartTLS(conn net.Conn, input *bufio.Reader,
 	}
 	config := tls.Config{Certificates: []tls.Certificate{cert}}
 	config.Rand = rand.Reader
+	if server.RequestsCert {
+		config.ClientAuth = tls.RequestClientCert
+	}
+
 	tlsConn := tls.Server(conn, &config)
+	if err := tlsConn.Handshake(); err == nil {
+		server.CurrentDelivery.ClientCertificate = len(tlsConn.ConnectionState().PeerCertificates) > 0
+	}
 
-	return tlsConn, bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn)
+	return tlsConn
 }
 
 func (server *SMTPServer) RespondToAuthPlain(output *bufio.Writer) {
@@ -230,6 +261,33 @@ func (server *SMTPServer) RespondToAuthPlain(output *bufio.Writer) {
 	output.Flush()
 }
 
+func (server *SMTPServer) RespondToAuthLogin(output *bufio.Writer, input *bufio.Reader) {
+	server.CurrentDelivery.AuthMechanism = "LOGIN"
+
+	for _, prompt := range []string{"Username:", "Password:"} {
+		output.WriteString("334 " + base64.StdEncoding.EncodeToString([]byte(prompt)) + "\r\n")
+		output.Flush()
+
+		answer, _ := input.ReadString('\n')
+		decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(answer))
+		server.CurrentDelivery.AuthCredentials = append(server.CurrentDelivery.AuthCredentials, string(decoded))
+	}
+
+	output.WriteString("235 Authentication successful\r\n")
+	output.Flush()
+}
+
+func (server *SMTPServer) RespondToAuthXOAUTH2(output *bufio.Writer, msg string) {
+	server.CurrentDelivery.AuthMechanism = "XOAUTH2"
+
+	fields := strings.Fields(msg)
+	decoded, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+	server.CurrentDelivery.AuthCredentials = append(server.CurrentDelivery.AuthCredentials, string(decoded))
+
+	output.WriteString("235 Accepted\r\n")
+	output.Flush()
+}
+
 func (server *SMTPServer) RespondToMailFrom(output *bufio.Writer, msg string) {
 	sender := strings.TrimSpace(msg)
 	sender = strings.TrimPrefix(sender, "MAIL FROM:")
@@ -244,7 +302,21 @@ func (server *SMTPServer) RespondToRcptTo(output *bufio.Writer, msg string) {
 	recipient := strings.TrimSpace(msg)
 	recipient = strings.TrimPrefix(recipient, "RCPT TO:")
 	recipient = strings.Trim(recipient, "<>")
+
+	if server.RejectsRcptTo[recipient] {
+		output.WriteString("550 No such user here\r\n")
+		output.Flush()
+		return
+	}
+
+	if server.DefersRcptTo[recipient] {
+		output.WriteString("451 Greylisted, try again later\r\n")
+		output.Flush()
+		return
+	}
+
 	server.CurrentDelivery.Recipient = recipient
+	server.CurrentDelivery.Recipients = append(server.CurrentDelivery.Recipients, recipient)
 
 	output.WriteString("250 OK\r\n")
 	output.Flush()
@@ -267,6 +339,13 @@ func (server *SMTPServer) RecordData(output *bufio.Writer, input *bufio.Reader)
 		}
 		server.CurrentDelivery.Data = append(server.CurrentDelivery.Data, strings.TrimSpace(msg))
 	}
+
+	if server.RejectsData {
+		output.WriteString("554 Message rejected as spam\r\n")
+		output.Flush()
+		return
+	}
+
 	output.WriteString("250 Written safely to disk.\r\n")
 	output.Flush()
 }
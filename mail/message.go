@@ -5,23 +5,10 @@ import (
 	"io/ioutil"
 	"mime"
 	"strings"
-	"text/template"
 
 	"gopkg.in/gomail.v1"
 )
 
-const emailTemplate = `{{range .Headers}}{{.}}
-{{end}}Date: {{.Date}}
-Mime-Version: {{.MimeVersion}}
-Content-Type: {{.ContentType}}
-{{if .ContentTransferEncoding}}Content-Transfer-Encoding: {{.ContentTransferEncoding}}
-{{end}}From: {{.From}}{{if .ReplyTo}}
-Reply-To: {{.ReplyTo}}{{end}}
-To: {{.To}}
-Subject: {{.Subject}}
-
-{{.CompiledBody}}`
-
 type Message struct {
 	Date                    string
 	MimeVersion             string
@@ -30,10 +17,16 @@ type Message struct {
 	From                    string
 	ReplyTo                 string
 	To                      string
+	CC                      string
 	Subject                 string
 	Body                    []Part
 	Headers                 []string
+	Attachments             []Attachment
 	CompiledBody            string
+
+	// Recipients lists the envelope recipients, including blind copies.
+	// When empty, the message is sent to the To address only.
+	Recipients []string
 }
 
 type Part struct {
@@ -41,28 +34,58 @@ type Part struct {
 	Content     string
 }
 
+// Data returns the message in its wire format: the header fields followed by
+// the compiled body, with CRLF line endings throughout.
 func (msg *Message) Data() string {
-	buf := bytes.NewBuffer([]byte{})
+	buffer := bytes.NewBuffer([]byte{})
 
 	err := msg.CompileBody()
 	if err != nil {
 		panic(err)
 	}
 
-	tmpl, err := template.New("test").Parse(emailTemplate)
-	if err != nil {
-		panic(err)
+	header := newHeaderWriter(buffer)
+	for _, field := range msg.Headers {
+		header.WriteField(field)
 	}
 
-	err = tmpl.Execute(buf, msg)
-	if err != nil {
-		panic(err)
+	header.WriteRaw("Date", msg.Date)
+	header.WriteRaw("Mime-Version", msg.MimeVersion)
+	header.WriteRaw("Content-Type", msg.ContentType)
+	if msg.ContentTransferEncoding != "" {
+		header.WriteRaw("Content-Transfer-Encoding", msg.ContentTransferEncoding)
 	}
 
-	return buf.String()
+	header.WriteAddresses("From", msg.From)
+	if msg.ReplyTo != "" {
+		header.WriteAddresses("Reply-To", msg.ReplyTo)
+	}
+	header.WriteAddresses("To", msg.To)
+	if msg.CC != "" {
+		header.WriteAddresses("Cc", msg.CC)
+	}
+	header.WriteText("Subject", msg.Subject)
+
+	buffer.WriteString("\r\n")
+	buffer.WriteString(strings.Replace(msg.CompiledBody, "\n", "\r\n", -1))
+
+	return buffer.String()
+}
+
+// EnvelopeRecipients returns the addresses the message is delivered to.
+func (msg Message) EnvelopeRecipients() []string {
+	if len(msg.Recipients) > 0 {
+		return msg.Recipients
+	}
+
+	return []string{msg.To}
 }
 
 func (msg *Message) CompileBody() error {
+	if len(msg.Attachments) > 0 {
+		return msg.compileMixedBody()
+	}
+
 	message := gomail.NewMessage()
 	for _, part := range msg.Body {
 		message.AddAlternative(part.ContentType, part.Content)
@@ -1,6 +1,12 @@
 package mail_test
 
 import (
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -33,14 +39,15 @@ var _ = Describe("Message", func() {
 		})
 
 		It("returns a populated data mail field as a string", func() {
-			parts := strings.Split(msg.Data(), "\n")
+			parts := strings.Split(msg.Data(), "\r\n")
 			boundary := msg.Boundary()
 
 			Expect(parts).To(ConsistOf([]string{
 				"From: me@example.com",
 				"To: you@example.com",
 				"Subject: Super Urgent! Read Now!",
-				"Content-Type: multipart/alternative; boundary=" + boundary,
+				"Content-Type: multipart/alternative;",
+				" boundary=" + boundary,
 				"Date: " + time.Now().Format(time.RFC822Z),
 				"Mime-Version: 1.0",
 				"",
@@ -62,7 +69,7 @@ var _ = Describe("Message", func() {
 		Context("when optional fields are present", func() {
 			It("includes Reply-To in message body", func() {
 				msg.ReplyTo = "banana@chiquita.com"
-				parts := strings.Split(msg.Data(), "\n")
+				parts := strings.Split(msg.Data(), "\r\n")
 				boundary := msg.Boundary()
 
 				Expect(parts).To(ConsistOf([]string{
@@ -70,7 +77,8 @@ var _ = Describe("Message", func() {
 					"Reply-To: banana@chiquita.com",
 					"To: you@example.com",
 					"Subject: Super Urgent! Read Now!",
-					"Content-Type: multipart/alternative; boundary=" + boundary,
+					"Content-Type: multipart/alternative;",
+					" boundary=" + boundary,
 					"Date: " + time.Now().Format(time.RFC822Z),
 					"Mime-Version: 1.0",
 					"",
@@ -91,7 +99,7 @@ var _ = Describe("Message", func() {
 
 			It("includes headers in the response if there are any", func() {
 				msg.Headers = append(msg.Headers, "X-ClientID: banana")
-				parts := strings.Split(msg.Data(), "\n")
+				parts := strings.Split(msg.Data(), "\r\n")
 				boundary := msg.Boundary()
 
 				Expect(parts).To(ConsistOf([]string{
@@ -99,7 +107,8 @@ var _ = Describe("Message", func() {
 					"To: you@example.com",
 					"Subject: Super Urgent! Read Now!",
 					"X-ClientID: banana",
-					"Content-Type: multipart/alternative; boundary=" + boundary,
+					"Content-Type: multipart/alternative;",
+					" boundary=" + boundary,
 					"Date: " + time.Now().Format(time.RFC822Z),
 					"Mime-Version: 1.0",
 					"",
@@ -126,7 +135,7 @@ var _ = Describe("Message", func() {
 					},
 				}
 
-				parts := strings.Split(msg.Data(), "\n")
+				parts := strings.Split(msg.Data(), "\r\n")
 
 				Expect(parts).To(Equal([]string{
 					"Date: " + time.Now().Format(time.RFC822Z),
@@ -142,4 +151,245 @@ var _ = Describe("Message", func() {
 			})
 		})
 	})
+
+	Describe("Data header encoding", func() {
+		var msg mail.Message
+
+		header := func(msg mail.Message) netmail.Header {
+			envelope, err := netmail.ReadMessage(strings.NewReader(msg.Data()))
+			Expect(err).NotTo(HaveOccurred())
+
+			return envelope.Header
+		}
+
+		BeforeEach(func() {
+			msg = mail.Message{
+				From:    "me@example.com",
+				To:      "you@example.com",
+				Subject: "Hello",
+				Body: []mail.Part{
+					{ContentType: "text/plain", Content: "Banana"},
+				},
+			}
+		})
+
+		It("uses CRLF line endings throughout", func() {
+			msg.Body[0].Content = "first line\nsecond line"
+
+			data := msg.Data()
+			Expect(strings.Count(data, "\n")).To(Equal(strings.Count(data, "\r\n")))
+			Expect(data).To(HaveSuffix("first line\r\nsecond line"))
+		})
+
+		It("encodes non-ASCII subjects as encoded-words", func() {
+			msg.Subject = "Änderungen in der Organisation Müller & Söhne"
+
+			data := msg.Data()
+			Expect(data).NotTo(ContainSubstring("Müller"))
+
+			decoded, err := new(mime.WordDecoder).DecodeHeader(header(msg).Get("Subject"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal("Änderungen in der Organisation Müller & Söhne"))
+		})
+
+		It("uses base64 encoded-words for mostly non-ASCII text", func() {
+			msg.Subject = "組織の請求書"
+
+			Expect(msg.Data()).To(ContainSubstring("Subject: =?utf-8?b?"))
+
+			decoded, err := new(mime.WordDecoder).DecodeHeader(header(msg).Get("Subject"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal("組織の請求書"))
+		})
+
+		It("quotes and encodes display names in addresses", func() {
+			msg.From = `"Doe, Jane" <jane@example.com>`
+			msg.ReplyTo = `"Jürgen \"JJ\" Müller" <jj@example.com>`
+
+			fields := header(msg)
+
+			from, err := fields.AddressList("From")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(from).To(Equal([]*netmail.Address{{Name: "Doe, Jane", Address: "jane@example.com"}}))
+
+			replyTo, err := fields.AddressList("Reply-To")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replyTo).To(Equal([]*netmail.Address{{Name: `Jürgen "JJ" Müller`, Address: "jj@example.com"}}))
+		})
+
+		It("lists carbon copies but never blind copies in the header", func() {
+			msg.To = "you@example.com, them@example.com"
+			msg.CC = "boss@example.com"
+			msg.Recipients = []string{"you@example.com", "them@example.com", "boss@example.com", "hidden@example.com"}
+
+			fields := header(msg)
+			Expect(fields.Get("To")).To(Equal("you@example.com, them@example.com"))
+			Expect(fields.Get("Cc")).To(Equal("boss@example.com"))
+			Expect(msg.Data()).NotTo(ContainSubstring("hidden@example.com"))
+
+			Expect(msg.EnvelopeRecipients()).To(Equal(msg.Recipients))
+		})
+
+		It("is delivered to the To address when no recipients are given", func() {
+			Expect(msg.EnvelopeRecipients()).To(Equal([]string{"you@example.com"}))
+		})
+
+		It("folds long header lines at 78 characters", func() {
+			msg.Subject = strings.Repeat("banana ", 30)
+
+			for _, line := range strings.Split(msg.Data(), "\r\n") {
+				Expect(len(line)).To(BeNumerically("<=", 78))
+			}
+
+			Expect(header(msg).Get("Subject")).To(Equal(strings.TrimSpace(strings.Repeat("banana ", 30))))
+		})
+
+		It("does not allow header injection through line breaks", func() {
+			msg.Subject = "Hello\r\nBcc: victim@example.com"
+			msg.ReplyTo = "me@example.com\nBcc: victim@example.com"
+			msg.Headers = []string{"X-CF-Client-ID: banana\r\nBcc: victim@example.com"}
+
+			fields := header(msg)
+			Expect(fields).NotTo(HaveKey("Bcc"))
+			Expect(fields.Get("Subject")).To(Equal("Hello  Bcc: victim@example.com"))
+
+			for _, line := range strings.Split(msg.Data(), "\r\n") {
+				Expect(line).NotTo(HavePrefix("Bcc:"))
+			}
+		})
+	})
+
+	Describe("Data with attachments", func() {
+		var msg mail.Message
+
+		type mimePart struct {
+			Header   textproto.MIMEHeader
+			FileName string
+			Body     string
+		}
+
+		readParts := func(body string, contentType string) []mimePart {
+			mediaType, params, err := mime.ParseMediaType(contentType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mediaType).To(HavePrefix("multipart/"))
+
+			var parts []mimePart
+			reader := multipart.NewReader(strings.NewReader(body), params["boundary"])
+			for {
+				part, err := reader.NextPart()
+				if err != nil {
+					break
+				}
+
+				content, err := ioutil.ReadAll(part)
+				Expect(err).NotTo(HaveOccurred())
+
+				parts = append(parts, mimePart{
+					Header:   part.Header,
+					FileName: part.FileName(),
+					Body:     string(content),
+				})
+			}
+
+			return parts
+		}
+
+		BeforeEach(func() {
+			msg = mail.Message{
+				From:    "me@example.com",
+				To:      "you@example.com",
+				Subject: "Your invoice",
+				Body: []mail.Part{
+					{ContentType: "text/plain", Content: "Banana"},
+					{ContentType: "text/html", Content: `<img src="cid:logo">`},
+				},
+				Attachments: []mail.Attachment{
+					{
+						Filename:    "invoice.csv",
+						ContentType: "text/csv",
+						Content:     []byte("item,price\nbanana,1\n"),
+					},
+				},
+			}
+		})
+
+		It("emits a multipart/mixed message with the body followed by the attachments", func() {
+			envelope, err := netmail.ReadMessage(strings.NewReader(msg.Data()))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(envelope.Header.Get("Subject")).To(Equal("Your invoice"))
+			Expect(envelope.Header.Get("Mime-Version")).To(Equal("1.0"))
+			Expect(envelope.Header.Get("Content-Transfer-Encoding")).To(BeEmpty())
+			Expect(envelope.Header.Get("Content-Type")).To(HavePrefix("multipart/mixed; boundary="))
+
+			body, err := ioutil.ReadAll(envelope.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			parts := readParts(string(body), envelope.Header.Get("Content-Type"))
+			Expect(parts).To(HaveLen(2))
+
+			alternatives := readParts(parts[0].Body, parts[0].Header.Get("Content-Type"))
+			Expect(alternatives).To(HaveLen(2))
+			Expect(alternatives[0].Header.Get("Content-Type")).To(Equal("text/plain; charset=UTF-8"))
+			Expect(alternatives[0].Body).To(Equal("Banana"))
+			Expect(alternatives[1].Header.Get("Content-Type")).To(Equal("text/html; charset=UTF-8"))
+			Expect(alternatives[1].Body).To(Equal(`<img src="cid:logo">`))
+
+			Expect(parts[1].Header.Get("Content-Type")).To(Equal(`text/csv; name=invoice.csv`))
+			Expect(parts[1].Header.Get("Content-Disposition")).To(Equal(`attachment; filename=invoice.csv`))
+			Expect(parts[1].Header.Get("Content-Transfer-Encoding")).To(Equal("base64"))
+			Expect(parts[1].FileName).To(Equal("invoice.csv"))
+			Expect(parts[1].Body).To(Equal(base64.StdEncoding.EncodeToString([]byte("item,price\nbanana,1\n"))))
+		})
+
+		It("groups inline attachments with the body in a multipart/related part", func() {
+			msg.Attachments = append(msg.Attachments, mail.Attachment{
+				Filename:    "logo.png",
+				ContentType: "image/png",
+				ContentID:   "logo",
+				Inline:      true,
+				Content:     []byte("not really a png"),
+			})
+
+			envelope, err := netmail.ReadMessage(strings.NewReader(msg.Data()))
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := ioutil.ReadAll(envelope.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			parts := readParts(string(body), envelope.Header.Get("Content-Type"))
+			Expect(parts).To(HaveLen(2))
+			Expect(parts[1].FileName).To(Equal("invoice.csv"))
+
+			related := readParts(parts[0].Body, parts[0].Header.Get("Content-Type"))
+			Expect(related).To(HaveLen(2))
+			Expect(related[0].Header.Get("Content-Type")).To(HavePrefix("multipart/alternative; boundary="))
+			Expect(related[1].Header.Get("Content-Disposition")).To(Equal("inline; filename=logo.png"))
+			Expect(related[1].Header.Get("Content-ID")).To(Equal("<logo>"))
+			Expect(related[1].Body).To(Equal(base64.StdEncoding.EncodeToString([]byte("not really a png"))))
+		})
+
+		It("quotes and encodes filenames that need it", func() {
+			msg.Attachments[0].Filename = `Rechnung "März".csv`
+
+			envelope, err := netmail.ReadMessage(strings.NewReader(msg.Data()))
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := ioutil.ReadAll(envelope.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			parts := readParts(string(body), envelope.Header.Get("Content-Type"))
+			Expect(parts[1].FileName).To(Equal(`Rechnung "März".csv`))
+		})
+
+		It("wraps the encoded attachment content at 76 characters", func() {
+			msg.Attachments[0].Content = []byte(strings.Repeat("banana", 100))
+
+			for _, line := range strings.Split(msg.Data(), "\r\n") {
+				if !strings.HasPrefix(line, "Content-") {
+					Expect(len(line)).To(BeNumerically("<=", 76))
+				}
+			}
+		})
+	})
 })
@@ -0,0 +1,117 @@
+package mail
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// tokenExpiryMargin is how long before its expiry an access token is
+// considered stale, so that it is never presented just as it runs out.
+const tokenExpiryMargin = time.Minute
+
+type OAuthConfig struct {
+	TokenURL      string
+	ClientID      string
+	ClientSecret  string
+	RefreshToken  string
+	SkipVerifySSL bool
+}
+
+// RefreshingTokenSource exchanges a refresh token for access tokens at an
+// OAuth token endpoint and caches each access token until shortly before it
+// expires.
+type RefreshingTokenSource struct {
+	config OAuthConfig
+	client *http.Client
+	now    func() time.Time
+
+	mutex        sync.Mutex
+	refreshToken string
+	token        string
+	expiry       time.Time
+}
+
+func NewRefreshingTokenSource(config OAuthConfig) *RefreshingTokenSource {
+	return &RefreshingTokenSource{
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipVerifySSL},
+			},
+		},
+		now:          time.Now,
+		refreshToken: config.RefreshToken,
+	}
+}
+
+func (s *RefreshingTokenSource) Token() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiry) {
+		return s.token, nil
+	}
+
+	return s.refresh()
+}
+
+// Invalidate discards the cached access token, for example after the server
+// rejected it, so that the next call to Token fetches a new one.
+func (s *RefreshingTokenSource) Invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.token = ""
+}
+
+func (s *RefreshingTokenSource) refresh() (string, error) {
+	response, err := s.client.PostForm(s.config.TokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.refreshToken},
+		"client_id":     {s.config.ClientID},
+		"client_secret": {s.config.ClientSecret},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OAuth token refresh failed with status %d: %s", response.StatusCode, body)
+	}
+
+	var document struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	err = json.Unmarshal(body, &document)
+	if err != nil {
+		return "", err
+	}
+
+	if document.AccessToken == "" {
+		return "", fmt.Errorf("OAuth token response did not include an access token")
+	}
+
+	if document.RefreshToken != "" {
+		s.refreshToken = document.RefreshToken
+	}
+
+	s.token = document.AccessToken
+	s.expiry = s.now().Add(time.Duration(document.ExpiresIn)*time.Second - tokenExpiryMargin)
+
+	return s.token, nil
+}
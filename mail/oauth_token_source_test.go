@@ -0,0 +1,102 @@
+package mail_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RefreshingTokenSource", func() {
+	var (
+		server   *httptest.Server
+		requests []*http.Request
+		status   int
+		body     string
+		now      time.Time
+		source   *mail.RefreshingTokenSource
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		status = http.StatusOK
+		body = `{"access_token": "first-token", "expires_in": 3600}`
+		now = time.Now()
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			requests = append(requests, req)
+
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+
+		source = mail.NewRefreshingTokenSource(mail.OAuthConfig{
+			TokenURL:     server.URL,
+			ClientID:     "some-client",
+			ClientSecret: "some-secret",
+			RefreshToken: "some-refresh-token",
+		})
+		source.SetNow(func() time.Time { return now })
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("exchanges the refresh token for an access token", func() {
+		token, err := source.Token()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("first-token"))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal("POST"))
+		Expect(requests[0].PostForm.Get("grant_type")).To(Equal("refresh_token"))
+		Expect(requests[0].PostForm.Get("refresh_token")).To(Equal("some-refresh-token"))
+		Expect(requests[0].PostForm.Get("client_id")).To(Equal("some-client"))
+		Expect(requests[0].PostForm.Get("client_secret")).To(Equal("some-secret"))
+	})
+
+	It("reuses the access token until shortly before it expires", func() {
+		_, err := source.Token()
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(58 * time.Minute)
+		token, err := source.Token()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("first-token"))
+		Expect(requests).To(HaveLen(1))
+
+		body = `{"access_token": "second-token", "expires_in": 3600, "refresh_token": "rotated-refresh-token"}`
+		now = now.Add(90 * time.Second)
+		token, err = source.Token()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("second-token"))
+		Expect(requests).To(HaveLen(2))
+
+		source.Invalidate()
+		_, err = source.Token()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[2].PostForm.Get("refresh_token")).To(Equal("rotated-refresh-token"))
+	})
+
+	It("returns an error when the token endpoint refuses the refresh token", func() {
+		status = http.StatusBadRequest
+		body = `{"error": "invalid_grant"}`
+
+		_, err := source.Token()
+		Expect(err).To(MatchError(`OAuth token refresh failed with status 400: {"error": "invalid_grant"}`))
+	})
+
+	It("returns an error when the response has no access token", func() {
+		body = `{}`
+
+		_, err := source.Token()
+		Expect(err).To(MatchError("OAuth token response did not include an access token"))
+	})
+})
@@ -1,20 +1,158 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/application"
 )
 
+const usage = `Usage: notifications [command] [flags]
+
+Without a command, the API and the workers run in a single process.
+
+Commands:
+  serve             Serve the API and run the singleton duties of the elected instance
+  work              Deliver queued notifications
+  migrate           Migrate the database and exit
+  gc                Remove expired messages and attachments once and exit
+  send-test-email   Send a test email through the configured SMTP server and exit
+  config check      Validate the configuration and print it with the secrets redacted
+
+Flags:
+`
+
+// errUsage is returned by parseArgs for command lines that cannot be run,
+// once the problem has been reported.
+var errUsage = errors.New("invalid usage")
+
+// invocation is what the command line asks the process to do. Commands that
+// check the configuration exit before the application is created.
+type invocation struct {
+	run        func(application.Application)
+	check      bool
+	configFile string
+}
+
 func main() {
+	inv, err := parseArgs(os.Args[1:], os.Stderr)
+	switch err {
+	case nil:
+	case flag.ErrHelp:
+		return
+	default:
+		os.Exit(2)
+	}
+
+	os.Setenv("CONFIG_FILE", inv.configFile)
+
 	env, err := application.NewEnvironment()
 	if err != nil {
 		log.Fatalf("CRASHING: %s\n", err)
 	}
 
+	if inv.check {
+		if err := env.WriteConfig(os.Stdout); err != nil {
+			log.Fatalf("CRASHING: %s\n", err)
+		}
+		return
+	}
+
 	dbp := application.NewDBProvider(env)
 	app := application.New(env, dbp)
 	defer app.Crash()
 
-	app.Run()
+	inv.run(app)
+}
+
+// parseArgs reads the command and its flags, writing the usage and any
+// problems with them to output.
+func parseArgs(args []string, output io.Writer) (invocation, error) {
+	command := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.Usage = func() {
+		fmt.Fprint(output, usage)
+		flags.PrintDefaults()
+	}
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or JSON file with the settings that are not set in the environment")
+
+	var (
+		inv      invocation
+		validate func() error
+	)
+
+	switch command {
+	case "":
+		inv.run = application.Application.Run
+	case "serve":
+		inv.run = application.Application.Serve
+	case "work":
+		workers := flags.Int("workers", application.WorkerCount, "number of workers to start")
+		validate = func() error {
+			if *workers < 1 {
+				return fmt.Errorf("--workers must be positive, got %d", *workers)
+			}
+			return nil
+		}
+		inv.run = func(app application.Application) {
+			app.Work(*workers)
+		}
+	case "migrate":
+		inv.run = application.Application.RunMigrations
+	case "gc":
+		inv.run = application.Application.CollectMessages
+	case "send-test-email":
+		to := flags.String("to", "", "address the test email is sent to")
+		validate = func() error {
+			if *to == "" {
+				return errors.New("send-test-email requires --to")
+			}
+			return nil
+		}
+		inv.run = func(app application.Application) {
+			if err := app.SendTestEmail(*to); err != nil {
+				log.Fatalf("CRASHING: %s\n", err)
+			}
+		}
+	case "config":
+		if len(args) == 0 || args[0] != "check" {
+			fmt.Fprintln(output, "Unknown config command, did you mean \"config check\"?")
+			return invocation{}, errUsage
+		}
+		args = args[1:]
+		inv.check = true
+	case "help":
+		flags.Usage()
+		return invocation{}, flag.ErrHelp
+	default:
+		fmt.Fprintf(output, "Unknown command %q\n\n", command)
+		flags.Usage()
+		return invocation{}, errUsage
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return invocation{}, err
+	}
+
+	if validate != nil {
+		if err := validate(); err != nil {
+			fmt.Fprintln(output, err)
+			return invocation{}, errUsage
+		}
+	}
+
+	inv.configFile = *configFile
+
+	return inv, nil
 }
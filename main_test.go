@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseArgs", func() {
+	var output *bytes.Buffer
+
+	BeforeEach(func() {
+		output = bytes.NewBuffer([]byte{})
+	})
+
+	It("runs the API and the workers without a command", func() {
+		inv, err := parseArgs([]string{}, output)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.run).NotTo(BeNil())
+		Expect(inv.check).To(BeFalse())
+	})
+
+	It("takes the config file from the flag over the environment", func() {
+		os.Setenv("CONFIG_FILE", "/from/env.yml")
+		defer os.Unsetenv("CONFIG_FILE")
+
+		inv, err := parseArgs([]string{"serve"}, output)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.configFile).To(Equal("/from/env.yml"))
+
+		inv, err = parseArgs([]string{"serve", "--config", "/from/flag.yml"}, output)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.configFile).To(Equal("/from/flag.yml"))
+	})
+
+	Describe("work", func() {
+		It("accepts a number of workers", func() {
+			inv, err := parseArgs([]string{"work", "--workers", "3"}, output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inv.run).NotTo(BeNil())
+		})
+
+		It("rejects a number of workers that is not positive", func() {
+			_, err := parseArgs([]string{"work", "--workers", "0"}, output)
+			Expect(err).To(Equal(errUsage))
+			Expect(output.String()).To(Equal("--workers must be positive, got 0\n"))
+		})
+
+		It("rejects a number of workers that is not a number", func() {
+			_, err := parseArgs([]string{"work", "--workers", "many"}, output)
+			Expect(err).To(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring(`invalid value "many" for flag -workers`))
+		})
+	})
+
+	Describe("send-test-email", func() {
+		It("requires the address to send to", func() {
+			_, err := parseArgs([]string{"send-test-email"}, output)
+			Expect(err).To(Equal(errUsage))
+			Expect(output.String()).To(Equal("send-test-email requires --to\n"))
+		})
+
+		It("accepts an address", func() {
+			inv, err := parseArgs([]string{"send-test-email", "--to", "someone@example.com"}, output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inv.run).NotTo(BeNil())
+		})
+	})
+
+	Describe("config", func() {
+		It("checks the configuration", func() {
+			inv, err := parseArgs([]string{"config", "check", "--config", "/some/config.yml"}, output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inv.check).To(BeTrue())
+			Expect(inv.configFile).To(Equal("/some/config.yml"))
+		})
+
+		It("rejects other config commands", func() {
+			_, err := parseArgs([]string{"config", "show"}, output)
+			Expect(err).To(Equal(errUsage))
+			Expect(output.String()).To(Equal("Unknown config command, did you mean \"config check\"?\n"))
+		})
+	})
+
+	It("rejects unknown commands with the usage", func() {
+		_, err := parseArgs([]string{"deliver"}, output)
+		Expect(err).To(Equal(errUsage))
+		Expect(output.String()).To(HavePrefix("Unknown command \"deliver\"\n\nUsage: notifications [command] [flags]"))
+	})
+
+	It("rejects unknown flags", func() {
+		_, err := parseArgs([]string{"serve", "--verbose"}, output)
+		Expect(err).To(HaveOccurred())
+		Expect(output.String()).To(ContainSubstring("flag provided but not defined: -verbose"))
+	})
+
+	It("prints the usage when asked for help", func() {
+		_, err := parseArgs([]string{"help"}, output)
+		Expect(err).To(Equal(flag.ErrHelp))
+		Expect(output.String()).To(ContainSubstring("config check"))
+		Expect(output.String()).To(ContainSubstring("-config"))
+	})
+})
@@ -0,0 +1,495 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var (
+	autolinkPattern = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]*)>`)
+	emailPattern    = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?)>`)
+	safeSchemes     = map[string]bool{"http": true, "https": true, "mailto": true}
+	actionPattern   = regexp.MustCompile(`{{.*?}}`)
+)
+
+// TemplateFuncs holds the functions that the HTML produced by RenderTemplate
+// calls. They must be added to any template that it is parsed into.
+var TemplateFuncs = template.FuncMap{
+	"markdownURL":     templateURL,
+	"markdownURLPart": templateURLPart,
+}
+
+const (
+	textInline = iota
+	actionInline
+	codeInline
+	strongInline
+	emphasisInline
+	linkInline
+	imageInline
+	breakInline
+	softBreakInline
+)
+
+type inline struct {
+	kind     int
+	text     string
+	url      string
+	trusted  bool
+	children []inline
+}
+
+func (r renderer) inlineHTML(source string) string {
+	return inlinesHTML(r.parseInlines(source))
+}
+
+func (r renderer) inlineText(source string) string {
+	return inlinesText(r.parseInlines(source))
+}
+
+func (r renderer) parseInlines(source string) []inline {
+	var (
+		inlines []inline
+		text    []byte
+	)
+
+	flush := func() {
+		if len(text) > 0 {
+			inlines = append(inlines, inline{kind: textInline, text: string(text)})
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(source); {
+		c := source[i]
+
+		switch {
+		case r.preserveActions && strings.HasPrefix(source[i:], "{{"):
+			end := strings.Index(source[i:], "}}")
+			if end < 0 {
+				text = append(text, source[i:]...)
+				i = len(source)
+				continue
+			}
+
+			flush()
+			inlines = append(inlines, inline{kind: actionInline, text: source[i : i+end+2]})
+			i += end + 2
+
+		case c == '\\' && i+1 < len(source) && strings.IndexByte("\\`*_{}[]()#+-.!<>|~\"'", source[i+1]) >= 0:
+			text = append(text, source[i+1])
+			i += 2
+
+		case c == '\\' && i+1 < len(source) && source[i+1] == '\n':
+			flush()
+			inlines = append(inlines, inline{kind: breakInline})
+			i += 2
+
+		case c == '\n':
+			trimmed := strings.TrimRight(string(text), " ")
+			hard := len(text)-len(trimmed) >= 2
+			text = []byte(trimmed)
+
+			flush()
+			if hard {
+				inlines = append(inlines, inline{kind: breakInline})
+			} else {
+				inlines = append(inlines, inline{kind: softBreakInline})
+			}
+			i++
+
+		case c == '`':
+			run := countRun(source[i:], '`')
+			closing := findRun(source[i+run:], '`', run)
+			if closing < 0 {
+				text = append(text, source[i:i+run]...)
+				i += run
+				continue
+			}
+
+			code := strings.Replace(source[i+run:i+run+closing], "\n", " ", -1)
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+				code = code[1 : len(code)-1]
+			}
+
+			flush()
+			inlines = append(inlines, inline{kind: codeInline, text: code})
+			i += run + closing + run
+
+		case c == '!' && strings.HasPrefix(source[i+1:], "["):
+			label, destination, length, ok := r.parseLink(source[i+1:])
+			if !ok {
+				text = append(text, c)
+				i++
+				continue
+			}
+
+			flush()
+			inlines = append(inlines, inline{kind: imageInline, text: inlinesText(r.parseInlines(label)), url: destination, trusted: r.trustedURL(destination)})
+			i += 1 + length
+
+		case c == '[':
+			label, destination, length, ok := r.parseLink(source[i:])
+			if !ok {
+				text = append(text, c)
+				i++
+				continue
+			}
+
+			flush()
+			inlines = append(inlines, inline{kind: linkInline, url: destination, trusted: r.trustedURL(destination), children: r.parseInlines(label)})
+			i += length
+
+		case c == '<' && autolinkPattern.MatchString(source[i:]):
+			matches := autolinkPattern.FindStringSubmatch(source[i:])
+			flush()
+			inlines = append(inlines, inline{kind: linkInline, url: matches[1], children: []inline{{kind: textInline, text: matches[1]}}})
+			i += len(matches[0])
+
+		case c == '<' && emailPattern.MatchString(source[i:]):
+			matches := emailPattern.FindStringSubmatch(source[i:])
+			flush()
+			inlines = append(inlines, inline{kind: linkInline, url: "mailto:" + matches[1], children: []inline{{kind: textInline, text: matches[1]}}})
+			i += len(matches[0])
+
+		case c == '*' || c == '_':
+			kind, inner, length, ok := parseEmphasis(source, i)
+			if !ok {
+				run := countRun(source[i:], c)
+				text = append(text, source[i:i+run]...)
+				i += run
+				continue
+			}
+
+			flush()
+			inlines = append(inlines, inline{kind: kind, children: r.parseInlines(inner)})
+			i += length
+
+		default:
+			text = append(text, c)
+			i++
+		}
+	}
+
+	flush()
+
+	return inlines
+}
+
+func countRun(source string, c byte) int {
+	count := 0
+	for count < len(source) && source[count] == c {
+		count++
+	}
+
+	return count
+}
+
+// findRun returns the offset of the first run of exactly length c bytes in
+// source, or -1 if there is none.
+func findRun(source string, c byte, length int) int {
+	for i := 0; i < len(source); {
+		if source[i] != c {
+			i++
+			continue
+		}
+
+		run := countRun(source[i:], c)
+		if run == length {
+			return i
+		}
+		i += run
+	}
+
+	return -1
+}
+
+// parseLink parses "[label](destination)" at the start of source and returns
+// the label, the destination and the number of bytes consumed. Anything after
+// the destination, such as a title, is ignored.
+func (r renderer) parseLink(source string) (string, string, int, bool) {
+	depth := 0
+	closing := -1
+
+	for i := 0; i < len(source) && closing < 0; i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closing = i
+			}
+		}
+	}
+
+	if closing < 0 || !strings.HasPrefix(source[closing+1:], "(") {
+		return "", "", 0, false
+	}
+
+	end := -1
+	depth = 0
+	for i := closing + 2; i < len(source) && end < 0; i++ {
+		switch source[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				end = i - closing - 2
+			}
+			depth--
+		}
+	}
+
+	if end < 0 {
+		return "", "", 0, false
+	}
+
+	destination := strings.TrimSpace(source[closing+2 : closing+2+end])
+	if strings.HasPrefix(destination, "<") {
+		if index := strings.Index(destination, ">"); index >= 0 {
+			return source[1:closing], destination[1:index], closing + 2 + end + 1, true
+		}
+	}
+
+	return source[1:closing], destination[:r.destinationEnd(destination)], closing + 2 + end + 1, true
+}
+
+// destinationEnd returns the length of a destination that is followed by a
+// title. White space inside of template actions does not end it.
+func (r renderer) destinationEnd(destination string) int {
+	for i := 0; i < len(destination); i++ {
+		if r.preserveActions && strings.HasPrefix(destination[i:], "{{") {
+			if end := strings.Index(destination[i:], "}}"); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+
+		if isSpace(destination[i]) {
+			return i
+		}
+	}
+
+	return len(destination)
+}
+
+// parseEmphasis parses an emphasis or strong span that opens at source[i].
+// Underscores only open and close emphasis at word boundaries so that
+// identifiers like snake_case_names are left alone. A run of three opens
+// emphasis around strong text, and closing runs only match opening runs of
+// the same length, so that nested spans close in the right order.
+func parseEmphasis(source string, i int) (int, string, int, bool) {
+	c := source[i]
+	run := countRun(source[i:], c)
+
+	length := 1
+	kind := emphasisInline
+	if run == 2 {
+		length = 2
+		kind = strongInline
+	}
+
+	if c == '_' && i > 0 && isWordByte(source[i-1]) {
+		return 0, "", 0, false
+	}
+
+	start := i + length
+	if start >= len(source) || isSpace(source[start]) {
+		return 0, "", 0, false
+	}
+
+	offset := start
+	if i+run > offset {
+		offset = i + run
+	}
+
+	for offset < len(source) {
+		switch source[offset] {
+		case '\\':
+			offset += 2
+			continue
+		case '`':
+			ticks := countRun(source[offset:], '`')
+			if closing := findRun(source[offset+ticks:], '`', ticks); closing >= 0 {
+				offset += ticks + closing + ticks
+				continue
+			}
+			offset += ticks
+			continue
+		case c:
+		default:
+			offset++
+			continue
+		}
+
+		closing := countRun(source[offset:], c)
+		after := offset + closing
+		canClose := !isSpace(source[offset-1]) && !(c == '_' && after < len(source) && isWordByte(source[after]))
+		if canClose && (closing == length || closing >= 3) {
+			return kind, source[start : after-length], after - i, true
+		}
+
+		offset = after
+	}
+
+	return 0, "", 0, false
+}
+
+// trustedURL reports whether a link destination contains template actions,
+// such as {{.Data.link}}, whose values are only known once the template is
+// executed.
+func (r renderer) trustedURL(destination string) bool {
+	return r.preserveActions && actionPattern.MatchString(destination)
+}
+
+func isAction(destination string) bool {
+	return strings.HasPrefix(destination, "{{") && strings.HasSuffix(destination, "}}") && strings.Count(destination, "{{") == 1
+}
+
+// templateURL checks a link destination that was given by a template action
+// once its value is known, in the same way as any other destination, and
+// escapes it for use in an attribute. Unsafe destinations are left empty.
+// The html portion of a message is executed with values that are escaped
+// already, so they are unescaped first to keep them from being escaped twice.
+func templateURL(value interface{}) string {
+	destination := html.UnescapeString(fmt.Sprint(value))
+	if !safeURL(destination) {
+		return ""
+	}
+
+	return html.EscapeString(destination)
+}
+
+// templateURLPart escapes the value of a template action inside of a link
+// destination exactly once, whether or not it was escaped already.
+func templateURLPart(value interface{}) string {
+	return html.EscapeString(html.UnescapeString(fmt.Sprint(value)))
+}
+
+// wrapAction passes the value of a template action through function.
+func wrapAction(action, function string) string {
+	pipeline := strings.TrimSpace(action[2 : len(action)-2])
+	pipeline = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(pipeline, "- "), " -"))
+
+	return "{{" + function + " (" + pipeline + ")}}"
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// allowedURL reports whether a link or image may be rendered. A destination
+// that is entirely a template action is checked by templateURL once the
+// template is executed, while one with actions inside of it has to start
+// with a safe scheme itself.
+func allowedURL(node inline) bool {
+	if node.trusted && isAction(node.url) {
+		return true
+	}
+
+	return safeURL(node.url)
+}
+
+func safeURL(destination string) bool {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return false
+	}
+
+	return safeSchemes[strings.ToLower(parsed.Scheme)]
+}
+
+func inlinesHTML(inlines []inline) string {
+	var output []string
+
+	for _, node := range inlines {
+		switch node.kind {
+		case textInline:
+			output = append(output, html.EscapeString(node.text))
+		case actionInline:
+			output = append(output, node.text)
+		case codeInline:
+			output = append(output, "<code>"+html.EscapeString(node.text)+"</code>")
+		case strongInline:
+			output = append(output, "<strong>"+inlinesHTML(node.children)+"</strong>")
+		case emphasisInline:
+			output = append(output, "<em>"+inlinesHTML(node.children)+"</em>")
+		case linkInline:
+			if !allowedURL(node) {
+				output = append(output, inlinesHTML(node.children))
+				continue
+			}
+			output = append(output, `<a href="`+escapeURL(node)+`">`+inlinesHTML(node.children)+"</a>")
+		case imageInline:
+			if !allowedURL(node) {
+				output = append(output, html.EscapeString(node.text))
+				continue
+			}
+			output = append(output, `<img src="`+escapeURL(node)+`" alt="`+html.EscapeString(node.text)+`">`)
+		case breakInline:
+			output = append(output, "<br>\n")
+		case softBreakInline:
+			output = append(output, "\n")
+		}
+	}
+
+	return strings.Join(output, "")
+}
+
+// escapeURL escapes a destination for use in an attribute. The values of any
+// template actions in it are escaped when the template is executed.
+func escapeURL(node inline) string {
+	if !node.trusted {
+		return html.EscapeString(node.url)
+	}
+
+	if isAction(node.url) {
+		return wrapAction(node.url, "markdownURL")
+	}
+
+	var escaped string
+	last := 0
+	for _, match := range actionPattern.FindAllStringIndex(node.url, -1) {
+		escaped += html.EscapeString(node.url[last:match[0]]) + wrapAction(node.url[match[0]:match[1]], "markdownURLPart")
+		last = match[1]
+	}
+
+	return escaped + html.EscapeString(node.url[last:])
+}
+
+func inlinesText(inlines []inline) string {
+	var output []string
+
+	for _, node := range inlines {
+		switch node.kind {
+		case textInline, actionInline, codeInline:
+			output = append(output, node.text)
+		case strongInline, emphasisInline:
+			output = append(output, inlinesText(node.children))
+		case linkInline:
+			label := inlinesText(node.children)
+			if label == node.url || "mailto:"+label == node.url || !allowedURL(node) {
+				output = append(output, label)
+				continue
+			}
+			output = append(output, label+" ("+node.url+")")
+		case imageInline:
+			output = append(output, node.text)
+		case breakInline, softBreakInline:
+			output = append(output, "\n")
+		}
+	}
+
+	return strings.Join(output, "")
+}
@@ -0,0 +1,379 @@
+// Package markdown renders a conservative subset of Markdown into both an HTML
+// and a plain text body.
+//
+// The renderer never passes raw HTML through: every piece of source text is
+// escaped, only a fixed set of tags is ever emitted, and links and images are
+// only rendered when they point at http, https or mailto URLs.
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	rulePattern       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern      = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	quotePattern      = regexp.MustCompile(`^ {0,3}> ?`)
+	listItemPattern   = regexp.MustCompile(`^( {0,3})([-*+]|[0-9]{1,9}[.)])([ \t]+|$)`)
+	setextH1Pattern   = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2Pattern   = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	indentCodePattern = regexp.MustCompile(`^(?: {4}|\t)`)
+)
+
+const (
+	paragraphBlock = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	ruleBlock
+)
+
+type block struct {
+	kind     int
+	level    int
+	ordered  bool
+	start    int
+	text     string
+	children []block
+	items    [][]block
+}
+
+type renderer struct {
+	preserveActions bool
+}
+
+// Render converts Markdown source into an HTML fragment and a plain text
+// equivalent of the same content.
+func Render(source string) (string, string) {
+	return renderer{}.render(source)
+}
+
+// RenderTemplate behaves like Render, but copies text/template actions such
+// as {{.Subject}} into both outputs untouched so that the result can still be
+// compiled as a template. Actions in link and image destinations are passed
+// through TemplateFuncs in the HTML, so that their values are checked once
+// they are known. Only use it for trusted template sources.
+func RenderTemplate(source string) (string, string) {
+	return renderer{preserveActions: true}.render(source)
+}
+
+func (r renderer) render(source string) (string, string) {
+	source = strings.Replace(source, "\r\n", "\n", -1)
+	blocks := parseBlocks(strings.Split(source, "\n"))
+
+	return r.blocksHTML(blocks), r.blocksText(blocks)
+}
+
+func parseBlocks(lines []string) []block {
+	var blocks []block
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			fence := fencePattern.FindStringSubmatch(line)[1]
+			var code []string
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++
+			blocks = append(blocks, block{kind: codeBlock, text: strings.Join(code, "\n")})
+
+		case headingPattern.MatchString(line):
+			matches := headingPattern.FindStringSubmatch(line)
+			blocks = append(blocks, block{kind: headingBlock, level: len(matches[1]), text: matches[2]})
+			i++
+
+		case rulePattern.MatchString(line):
+			blocks = append(blocks, block{kind: ruleBlock})
+			i++
+
+		case quotePattern.MatchString(line):
+			var quoted []string
+			for i < len(lines) && quotePattern.MatchString(lines[i]) {
+				quoted = append(quoted, quotePattern.ReplaceAllString(lines[i], ""))
+				i++
+			}
+			blocks = append(blocks, block{kind: quoteBlock, children: parseBlocks(quoted)})
+
+		case listItemPattern.MatchString(line):
+			var list block
+			list, i = parseList(lines, i)
+			blocks = append(blocks, list)
+
+		case indentCodePattern.MatchString(line):
+			var code []string
+			for i < len(lines) && (indentCodePattern.MatchString(lines[i]) || strings.TrimSpace(lines[i]) == "") {
+				code = append(code, indentCodePattern.ReplaceAllString(lines[i], ""))
+				i++
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, block{kind: codeBlock, text: strings.Join(code, "\n")})
+
+		default:
+			var paragraph []string
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+				if len(paragraph) > 0 && setextH1Pattern.MatchString(lines[i]) {
+					blocks = append(blocks, block{kind: headingBlock, level: 1, text: strings.Join(paragraph, "\n")})
+					paragraph = nil
+					i++
+					break
+				}
+
+				if len(paragraph) > 0 && setextH2Pattern.MatchString(lines[i]) {
+					blocks = append(blocks, block{kind: headingBlock, level: 2, text: strings.Join(paragraph, "\n")})
+					paragraph = nil
+					i++
+					break
+				}
+
+				if len(paragraph) > 0 && interruptsParagraph(lines[i]) {
+					break
+				}
+
+				paragraph = append(paragraph, strings.TrimLeft(lines[i], " \t"))
+				i++
+			}
+
+			if len(paragraph) > 0 {
+				blocks = append(blocks, block{kind: paragraphBlock, text: strings.Join(paragraph, "\n")})
+			}
+		}
+	}
+
+	return blocks
+}
+
+func interruptsParagraph(line string) bool {
+	return fencePattern.MatchString(line) ||
+		headingPattern.MatchString(line) ||
+		rulePattern.MatchString(line) ||
+		quotePattern.MatchString(line) ||
+		listItemPattern.MatchString(line)
+}
+
+func parseList(lines []string, i int) (block, int) {
+	first := listItemPattern.FindStringSubmatch(lines[i])
+	marker := first[2]
+
+	list := block{kind: listBlock, ordered: !strings.ContainsAny(marker, "-*+")}
+	if list.ordered {
+		fmt.Sscanf(marker, "%d", &list.start)
+	}
+
+	sameList := func(line string) bool {
+		matches := listItemPattern.FindStringSubmatch(line)
+		if matches == nil || rulePattern.MatchString(line) {
+			return false
+		}
+
+		if list.ordered {
+			return !strings.ContainsAny(matches[2], "-*+") && matches[2][len(matches[2])-1] == marker[len(marker)-1]
+		}
+
+		return matches[2] == marker
+	}
+
+	for i < len(lines) && sameList(lines[i]) {
+		matches := listItemPattern.FindStringSubmatch(lines[i])
+		indent := len(matches[0])
+		content := []string{lines[i][indent:]}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+
+			if strings.TrimSpace(line) == "" {
+				next := i + 1
+				for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+					next++
+				}
+
+				if next < len(lines) && leadingSpaces(lines[next]) >= indent {
+					content = append(content, "")
+					i++
+					continue
+				}
+
+				break
+			}
+
+			if leadingSpaces(line) >= indent {
+				content = append(content, stripColumns(line, indent))
+				i++
+				continue
+			}
+
+			if sameList(line) || interruptsParagraph(line) || strings.TrimSpace(content[len(content)-1]) == "" {
+				break
+			}
+
+			content = append(content, line)
+			i++
+		}
+
+		list.items = append(list.items, parseBlocks(content))
+
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+
+			if next < len(lines) && sameList(lines[next]) {
+				i = next
+			}
+		}
+	}
+
+	return list, i
+}
+
+func stripColumns(line string, columns int) string {
+	for i, r := range line {
+		if columns <= 0 {
+			return line[i:]
+		}
+
+		switch r {
+		case ' ':
+			columns--
+		case '\t':
+			columns -= 4
+		default:
+			return line[i:]
+		}
+	}
+
+	return ""
+}
+
+func leadingSpaces(line string) int {
+	count := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			count++
+		case '\t':
+			count += 4
+		default:
+			return count
+		}
+	}
+
+	return count
+}
+
+func (r renderer) blocksHTML(blocks []block) string {
+	var parts []string
+
+	for _, b := range blocks {
+		switch b.kind {
+		case paragraphBlock:
+			parts = append(parts, "<p>"+r.inlineHTML(b.text)+"</p>")
+		case headingBlock:
+			parts = append(parts, fmt.Sprintf("<h%d>%s</h%d>", b.level, r.inlineHTML(b.text), b.level))
+		case codeBlock:
+			parts = append(parts, "<pre><code>"+html.EscapeString(b.text)+"</code></pre>")
+		case quoteBlock:
+			parts = append(parts, "<blockquote>\n"+r.blocksHTML(b.children)+"\n</blockquote>")
+		case ruleBlock:
+			parts = append(parts, "<hr>")
+		case listBlock:
+			parts = append(parts, r.listHTML(b))
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func (r renderer) listHTML(list block) string {
+	open, close := "<ul>", "</ul>"
+	if list.ordered {
+		open, close = "<ol>", "</ol>"
+		if list.start != 1 {
+			open = fmt.Sprintf(`<ol start="%d">`, list.start)
+		}
+	}
+
+	items := []string{open}
+	for _, item := range list.items {
+		if len(item) == 1 && item[0].kind == paragraphBlock {
+			items = append(items, "<li>"+r.inlineHTML(item[0].text)+"</li>")
+			continue
+		}
+
+		items = append(items, "<li>\n"+r.blocksHTML(item)+"\n</li>")
+	}
+	items = append(items, close)
+
+	return strings.Join(items, "\n")
+}
+
+func (r renderer) blocksText(blocks []block) string {
+	var parts []string
+
+	for _, b := range blocks {
+		switch b.kind {
+		case paragraphBlock, headingBlock:
+			parts = append(parts, r.inlineText(b.text))
+		case codeBlock:
+			parts = append(parts, b.text)
+		case quoteBlock:
+			parts = append(parts, prefixLines(r.blocksText(b.children), "> ", "> "))
+		case ruleBlock:
+			parts = append(parts, "----------")
+		case listBlock:
+			parts = append(parts, r.listText(b))
+		}
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+func (r renderer) listText(list block) string {
+	var items []string
+
+	for i, item := range list.items {
+		marker := "- "
+		if list.ordered {
+			marker = fmt.Sprintf("%d. ", list.start+i)
+		}
+
+		items = append(items, prefixLines(r.blocksText(item), marker, strings.Repeat(" ", len(marker))))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+
+		lines[i] = prefix + line
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,251 @@
+package markdown_test
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/cloudfoundry-incubator/notifications/markdown"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type example struct {
+	description string
+	source      string
+	html        string
+	text        string
+}
+
+func renders(examples []example) {
+	for _, e := range examples {
+		e := e
+
+		It(e.description, func() {
+			html, text := markdown.Render(e.source)
+
+			Expect(html).To(Equal(e.html))
+			Expect(text).To(Equal(e.text))
+		})
+	}
+}
+
+func execute(source string, data map[string]string) string {
+	parsed, err := template.New("test").Funcs(markdown.TemplateFuncs).Parse(source)
+	Expect(err).NotTo(HaveOccurred())
+
+	buffer := bytes.NewBuffer([]byte{})
+	Expect(parsed.Execute(buffer, map[string]interface{}{"Data": data})).To(Succeed())
+
+	return buffer.String()
+}
+
+var _ = Describe("Markdown", func() {
+	Describe("Render", func() {
+		It("renders headings, paragraphs and inline formatting", func() {
+			html, text := markdown.Render("# Hello *world*\n\nSome **bold** text with `code` and a snake_case_name.\nSecond line  \nafter a break")
+
+			Expect(html).To(Equal("<h1>Hello <em>world</em></h1>\n" +
+				"<p>Some <strong>bold</strong> text with <code>code</code> and a snake_case_name.\n" +
+				"Second line<br>\nafter a break</p>"))
+			Expect(text).To(Equal("Hello world\n\n" +
+				"Some bold text with code and a snake_case_name.\n" +
+				"Second line\nafter a break"))
+		})
+
+		It("renders setext headings", func() {
+			html, _ := markdown.Render("Title\n=====\nSubtitle\n--------")
+
+			Expect(html).To(Equal("<h1>Title</h1>\n<h2>Subtitle</h2>"))
+		})
+
+		It("renders ordered and unordered lists", func() {
+			html, text := markdown.Render("- one\n- two\n  continued\n\n3. three\n4. four")
+
+			Expect(html).To(Equal("<ul>\n<li>one</li>\n<li>two\ncontinued</li>\n</ul>\n" +
+				"<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>"))
+			Expect(text).To(Equal("- one\n- two\n  continued\n\n3. three\n4. four"))
+		})
+
+		It("renders nested lists", func() {
+			html, _ := markdown.Render("- a\n  - nested\n- b")
+
+			Expect(html).To(Equal("<ul>\n<li>\n<p>a</p>\n<ul>\n<li>nested</li>\n</ul>\n</li>\n<li>b</li>\n</ul>"))
+		})
+
+		It("renders block quotes, rules and code blocks", func() {
+			html, text := markdown.Render("> quoted\n\n---\n\n```\nif a < b {\n}\n```\n\n    indented")
+
+			Expect(html).To(Equal("<blockquote>\n<p>quoted</p>\n</blockquote>\n<hr>\n" +
+				"<pre><code>if a &lt; b {\n}</code></pre>\n<pre><code>indented</code></pre>"))
+			Expect(text).To(Equal("> quoted\n\n----------\n\nif a < b {\n}\n\nindented"))
+		})
+
+		It("renders links and images, keeping the URL in the plain text", func() {
+			html, text := markdown.Render("See [the docs](https://example.com/docs), <https://example.com> or <help@example.com>. ![logo](https://example.com/logo.png)")
+
+			Expect(html).To(Equal(`<p>See <a href="https://example.com/docs">the docs</a>, ` +
+				`<a href="https://example.com">https://example.com</a> or ` +
+				`<a href="mailto:help@example.com">help@example.com</a>. ` +
+				`<img src="https://example.com/logo.png" alt="logo"></p>`))
+			Expect(text).To(Equal("See the docs (https://example.com/docs), https://example.com or help@example.com. logo"))
+		})
+
+		Context("nesting", func() {
+			renders([]example{
+				{"emphasis inside strong", "**bold *and em* inside**", "<p><strong>bold <em>and em</em> inside</strong></p>", "bold and em inside"},
+				{"strong inside emphasis", "*em **strong** em*", "<p><em>em <strong>strong</strong> em</em></p>", "em strong em"},
+				{"runs of three", "***both***", "<p><em><strong>both</strong></em></p>", "both"},
+				{"strong closing a run of three", "***bold** em*", "<p><em><strong>bold</strong> em</em></p>", "bold em"},
+				{"code inside emphasis", "*`a*b` c*", "<p><em><code>a*b</code> c</em></p>", "a*b c"},
+				{"formatting inside link labels", "[**bold** link](https://example.com)", `<p><a href="https://example.com"><strong>bold</strong> link</a></p>`, "bold link (https://example.com)"},
+				{"unclosed delimiters", "**unclosed and *open", "<p>**unclosed and *open</p>", "**unclosed and *open"},
+				{"delimiters surrounded by spaces", "2 * 3 * 4", "<p>2 * 3 * 4</p>", "2 * 3 * 4"},
+				{"underscores inside words", "__init__ is _special_ but snake_case is not", "<p><strong>init</strong> is <em>special</em> but snake_case is not</p>", "init is special but snake_case is not"},
+			})
+		})
+
+		Context("escaping", func() {
+			renders([]example{
+				{"backslash escapes", `\*not em\* and \\ backslash`, `<p>*not em* and \ backslash</p>`, `*not em* and \ backslash`},
+				{"escaped link syntax", `\[x\](https://example.com)`, `<p>[x](https://example.com)</p>`, `[x](https://example.com)`},
+				{"escaped delimiters inside emphasis", `*a\*b*`, `<p><em>a*b</em></p>`, `a*b`},
+				{"escaped angle brackets", `a \<b\>`, `<p>a &lt;b&gt;</p>`, `a <b>`},
+				{"entities", `AT&amp;T`, `<p>AT&amp;amp;T</p>`, `AT&amp;T`},
+				{"quotes in alt text", `![a "q"](https://example.com/x.png)`, `<p><img src="https://example.com/x.png" alt="a &#34;q&#34;"></p>`, `a "q"`},
+			})
+		})
+
+		Context("code spans", func() {
+			renders([]example{
+				{"backticks inside longer runs", "``code with ` inside``", "<p><code>code with ` inside</code></p>", "code with ` inside"},
+				{"a single surrounding space", "` spaced `", "<p><code>spaced</code></p>", "spaced"},
+				{"html inside code", "`<b>&amp;</b>`", "<p><code>&lt;b&gt;&amp;amp;&lt;/b&gt;</code></p>", "<b>&amp;</b>"},
+				{"delimiters inside code", "`*not em*`", "<p><code>*not em*</code></p>", "*not em*"},
+				{"unclosed backticks", "`unclosed", "<p>`unclosed</p>", "`unclosed"},
+				{"fenced code with an info string", "```go\n<x>\n```", "<pre><code>&lt;x&gt;</code></pre>", "<x>"},
+				{"unclosed fences", "```\nunclosed fence", "<pre><code>unclosed fence</code></pre>", "unclosed fence"},
+			})
+		})
+
+		Context("lists", func() {
+			renders([]example{
+				{"items with several paragraphs", "1. one\n2. two\n\n   para\n3. three", "<ol>\n<li>one</li>\n<li>\n<p>two</p>\n<p>para</p>\n</li>\n<li>three</li>\n</ol>", "1. one\n2. two\n\n   para\n3. three"},
+				{"parenthesised numbers", "1) one\n2) two", "<ol>\n<li>one</li>\n<li>two</li>\n</ol>", "1. one\n2. two"},
+				{"start numbers", "10. ten\n11. eleven", "<ol start=\"10\">\n<li>ten</li>\n<li>eleven</li>\n</ol>", "10. ten\n11. eleven"},
+				{"a change of marker", "- a\n* b", "<ul>\n<li>a</li>\n</ul>\n<ul>\n<li>b</li>\n</ul>", "- a\n\n- b"},
+				{"blank lines between items", "- a\n\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>", "- a\n- b"},
+				{"several levels", "- a\n    - b\n        - c", "<ul>\n<li>\n<p>a</p>\n<ul>\n<li>\n<p>b</p>\n<ul>\n<li>c</li>\n</ul>\n</li>\n</ul>\n</li>\n</ul>", "- a\n\n  - b\n\n    - c"},
+				{"lists inside quotes", "> - a\n> - b", "<blockquote>\n<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n</blockquote>", "> - a\n> - b"},
+				{"quotes inside items", "- item\n\n  > quoted", "<ul>\n<li>\n<p>item</p>\n<blockquote>\n<p>quoted</p>\n</blockquote>\n</li>\n</ul>", "- item\n\n  > quoted"},
+				{"lists interrupting paragraphs", "text\n- item", "<p>text</p>\n<ul>\n<li>item</li>\n</ul>", "text\n\n- item"},
+				{"nested quotes", "> outer\n> > inner", "<blockquote>\n<p>outer</p>\n<blockquote>\n<p>inner</p>\n</blockquote>\n</blockquote>", "> outer\n>\n> > inner"},
+			})
+		})
+
+		Context("links", func() {
+			renders([]example{
+				{"brackets inside labels", "[nested [brackets]](https://example.com)", `<p><a href="https://example.com">nested [brackets]</a></p>`, "nested [brackets] (https://example.com)"},
+				{"parentheses inside destinations", "[a](https://example.com/a_(b))", `<p><a href="https://example.com/a_(b)">a</a></p>`, "a (https://example.com/a_(b))"},
+				{"angle bracketed destinations", "[a](<https://example.com/x y>)", `<p><a href="https://example.com/x y">a</a></p>`, "a (https://example.com/x y)"},
+				{"titles", `[a](https://example.com "title")`, `<p><a href="https://example.com">a</a></p>`, "a (https://example.com)"},
+				{"spaces around destinations", "[a]( https://example.com )", `<p><a href="https://example.com">a</a></p>`, "a (https://example.com)"},
+				{"upper case schemes", "[help](MAILTO:help@example.com)", `<p><a href="MAILTO:help@example.com">help</a></p>`, "help (MAILTO:help@example.com)"},
+				{"unclosed destinations", "[a](https://example.com", "<p>[a](https://example.com</p>", "[a](https://example.com"},
+				{"labels without destinations", "[no destination]", "<p>[no destination]</p>", "[no destination]"},
+				{"mixed case unsafe schemes", "[js](JavaScript:alert(1))", "<p>js</p>", "js"},
+				{"file urls", "[file](file:///etc/passwd)", "<p>file</p>", "file"},
+				{"unsafe autolinks", "<javascript:alert(1)>", "<p>&lt;javascript:alert(1)&gt;</p>", "<javascript:alert(1)>"},
+				{"images without alt text", "![](https://example.com/x.png)", `<p><img src="https://example.com/x.png" alt=""></p>`, ""},
+			})
+		})
+
+		Context("sanitising", func() {
+			It("escapes raw html", func() {
+				html, text := markdown.Render(`<script>alert("hi")</script> & <b onclick="x">bold</b>`)
+
+				Expect(html).To(Equal(`<p>&lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt; &amp; &lt;b onclick=&#34;x&#34;&gt;bold&lt;/b&gt;</p>`))
+				Expect(text).To(Equal(`<script>alert("hi")</script> & <b onclick="x">bold</b>`))
+			})
+
+			It("drops links and images with unsafe schemes", func() {
+				html, _ := markdown.Render(`[click](javascript:alert(1)) ![x](data:image/png;base64,AAAA) [rel](/relative)`)
+
+				Expect(html).To(Equal("<p>click x rel</p>"))
+			})
+
+			It("escapes attribute values", func() {
+				html, _ := markdown.Render(`[a](https://example.com/?q="><script>)`)
+
+				Expect(html).To(Equal(`<p><a href="https://example.com/?q=&#34;&gt;&lt;script&gt;">a</a></p>`))
+			})
+
+			It("escapes template actions", func() {
+				html, _ := markdown.Render(`{{template "x" .}}`)
+
+				Expect(html).To(Equal(`<p>{{template &#34;x&#34; .}}</p>`))
+			})
+		})
+	})
+
+	Describe("RenderTemplate", func() {
+		It("preserves template actions in both outputs", func() {
+			html, text := markdown.RenderTemplate(`Hello **{{.Data.name}}**, see [the docs]({{.Data.link}}). {{template "footer" .}}`)
+
+			Expect(html).To(Equal(`<p>Hello <strong>{{.Data.name}}</strong>, see <a href="{{markdownURL (.Data.link)}}">the docs</a>. {{template "footer" .}}</p>`))
+			Expect(text).To(Equal(`Hello {{.Data.name}}, see the docs ({{.Data.link}}). {{template "footer" .}}`))
+		})
+
+		Context("when a link destination is a template action", func() {
+			It("checks and escapes its value once the template is executed", func() {
+				html, _ := markdown.RenderTemplate(`[a]({{.Data.link}}) ![b]({{- .Data.image -}})`)
+
+				Expect(execute(html, map[string]string{
+					"link":  `https://example.com/?q="><script>`,
+					"image": "https://example.com/logo.png",
+				})).To(Equal(`<p><a href="https://example.com/?q=&#34;&gt;&lt;script&gt;">a</a> <img src="https://example.com/logo.png" alt="b"></p>`))
+			})
+
+			It("does not escape values that were escaped already a second time", func() {
+				html, _ := markdown.RenderTemplate(`[a]({{.Data.link}}) [b](https://example.com/?id={{.Data.id}})`)
+
+				Expect(execute(html, map[string]string{
+					"link": "https://example.com/?a=1&amp;b=2",
+					"id":   "1&amp;b=2",
+				})).To(Equal(`<p><a href="https://example.com/?a=1&amp;b=2">a</a> <a href="https://example.com/?id=1&amp;b=2">b</a></p>`))
+			})
+
+			It("leaves out values with unsafe schemes", func() {
+				html, _ := markdown.RenderTemplate(`[a]({{.Data.link}})`)
+
+				Expect(execute(html, map[string]string{
+					"link": "javascript:alert(1)",
+				})).To(Equal(`<p><a href="">a</a></p>`))
+			})
+
+			It("keeps actions with spaces in them", func() {
+				html, _ := markdown.RenderTemplate(`[a]({{index .Data "link"}})`)
+
+				Expect(html).To(Equal(`<p><a href="{{markdownURL (index .Data "link")}}">a</a></p>`))
+			})
+		})
+
+		Context("when a link destination contains template actions", func() {
+			It("escapes their values once the template is executed", func() {
+				html, _ := markdown.RenderTemplate(`[a](https://example.com/{{.Data.id}}?q={{.Data.q}})`)
+
+				Expect(execute(html, map[string]string{
+					"id": "123",
+					"q":  `"><script>`,
+				})).To(Equal(`<p><a href="https://example.com/123?q=&#34;&gt;&lt;script&gt;">a</a></p>`))
+			})
+
+			It("drops it unless it starts with a safe scheme", func() {
+				html, text := markdown.RenderTemplate(`[a]({{.Data.scheme}}://example.com)`)
+
+				Expect(html).To(Equal("<p>a</p>"))
+				Expect(text).To(Equal("a"))
+			})
+		})
+	})
+})
@@ -10,13 +10,15 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/keyring"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/postal/v1"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/util"
 	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
-	"github.com/pivotal-golang/conceal"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/pivotal-golang/lager"
 )
 
@@ -28,13 +30,24 @@ type Config struct {
 	VerifySSL            bool
 	InstanceIndex        int
 	WorkerCount          int
-	EncryptionKey        []byte
+	KeyRing              keyring.KeyRing
 	DBLoggingEnabled     bool
 	RootPath             string
 	Sender               string
 	Domain               string
 	QueueWaitMaxDuration int
 	CCHost               string
+
+	DomainThrottleRate      float64
+	DomainThrottleOverrides map[string]float64
+
+	DeliveryMaxRetries   int
+	DeliveryRetryBackoff int
+
+	UAAUserCacheDuration int
+	FanOutChunkSize      int
+
+	Tracer *tracing.Tracer
 }
 
 func database(db *sql.DB, dbLoggingEnabled bool, rootPath string) db.DatabaseInterface {
@@ -62,13 +75,9 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 	gobbleDatabase := gobble.NewDatabase(db)
 	gobbleQueue := gobble.NewQueue(gobbleDatabase, clock, gobble.Config{
 		WaitMaxDuration: time.Duration(config.QueueWaitMaxDuration) * time.Millisecond,
+		Sealer:          config.KeyRing,
 	})
 
-	cloak, err := conceal.NewCloak(config.EncryptionKey)
-	if err != nil {
-		panic(err)
-	}
-
 	guidGenerator := util.NewIDGenerator(rand.Reader)
 
 	// V1
@@ -76,15 +85,23 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 	unsubscribesRepo := v1models.NewUnsubscribesRepo()
 	globalUnsubscribesRepo := v1models.NewGlobalUnsubscribesRepo()
 	messagesRepo := v1models.NewMessagesRepo(guidGenerator.Generate)
+	attachmentsRepo := v1models.NewAttachmentsRepo(guidGenerator.Generate)
+	messageRecipientsRepo := v1models.NewMessageRecipientsRepo()
+	suppressionsRepo := v1models.NewSuppressionsRepo(guidGenerator.Generate)
+	fanOutsRepo := v1models.NewFanOutsRepo(guidGenerator.Generate)
+	clientRecipientCountsRepo := v1models.NewClientRecipientCountsRepo()
 	clientsRepo := v1models.NewClientsRepo()
 	kindsRepo := v1models.NewKindsRepo()
 	templatesRepo := v1models.NewTemplatesRepo()
-	v1TemplateLoader := v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo)
-	deliveryFailureHandler := common.NewDeliveryFailureHandler()
+	partialsRepo := v1models.NewPartialsRepo()
+	v1TemplateLoader := v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo, partialsRepo)
+	deliveryFailureHandler := common.NewDeliveryFailureHandler(config.DeliveryMaxRetries, time.Duration(config.DeliveryRetryBackoff)*time.Millisecond)
 	messageStatusUpdater := v1.NewMessageStatusUpdater(messagesRepo)
-	userLoader := common.NewUserLoader(uaaClient)
-	tokenLoader := uaa.NewTokenLoader(uaaClient)
-	packager := common.NewPackager(v1TemplateLoader, cloak)
+	userLoader := common.NewUserLoader(uaaClient, clock, time.Duration(config.UAAUserCacheDuration)*time.Millisecond)
+	tokenLoader := uaa.NewTokenLoader(uaaClient, clock)
+	packager := common.NewPackager(v1TemplateLoader, config.KeyRing)
+	domainThrottle := common.NewDomainThrottle(config.DomainThrottleRate, config.DomainThrottleOverrides, clock)
+	enqueuer := services.NewEnqueuer(gobbleQueue, messagesRepo, attachmentsRepo, messageRecipientsRepo, clientRecipientCountsRepo, gobble.Initializer{})
 
 	WorkerGenerator{
 		InstanceIndex: config.InstanceIndex,
@@ -107,8 +124,28 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 			ReceiptsRepo:           receiptsRepo,
 			UnsubscribesRepo:       unsubscribesRepo,
 			GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+			AttachmentsRepo:        attachmentsRepo,
+			RecipientsRepo:         messageRecipientsRepo,
+			SuppressionsRepo:       suppressionsRepo,
 			MessageStatusUpdater:   messageStatusUpdater,
 			DeliveryFailureHandler: deliveryFailureHandler,
+			DomainThrottle:         domainThrottle,
+			Tracer:                 config.Tracer,
+			Clock:                  clock,
+		})
+
+		fanOutJobProcessor := v1.NewFanOutJobProcessor(v1.FanOutJobProcessorConfig{
+			UAAHost:   config.UAAHost,
+			ChunkSize: config.FanOutChunkSize,
+
+			Database:    database,
+			TokenLoader: tokenLoader,
+			UserLoader:  userLoader,
+			Enqueuer:    enqueuer,
+
+			FanOutsRepo:            fanOutsRepo,
+			DeliveryFailureHandler: deliveryFailureHandler,
+			Clock:                  clock,
 		})
 
 		worker := NewDeliveryWorker(v1DeliveryJobProcessor, DeliveryWorkerConfig{
@@ -116,6 +153,7 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 			UAAHost: config.UAAHost,
 			DBTrace: config.DBLoggingEnabled,
 
+			FanOutJobProcessor:     fanOutJobProcessor,
 			DeliveryFailureHandler: deliveryFailureHandler,
 
 			Logger: logger.Session("worker", lager.Data{"worker_id": index}),
@@ -0,0 +1,91 @@
+package bounces_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBouncesSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "postal/bounces")
+}
+
+// crlf converts the fixtures below to the line endings used in email.
+func crlf(s string) string {
+	return strings.Replace(s, "\n", "\r\n", -1)
+}
+
+var deliveryStatusNotification = crlf(`From: MAILER-DAEMON@mail.example.com
+To: bounces@notifications.example.com
+Subject: Undelivered Mail Returned to Sender
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="dsn-boundary"
+
+--dsn-boundary
+Content-Type: text/plain
+
+The mail system could not deliver your message.
+
+--dsn-boundary
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mail.example.com
+Arrival-Date: Mon, 19 Oct 2026 10:00:00 +0000
+
+Final-Recipient: rfc822; missing@example.com
+Original-Recipient: rfc822;missing@example.com
+Action: failed
+Status: 5.1.1
+Diagnostic-Code: smtp; 550 5.1.1 <missing@example.com>:
+ Recipient address rejected: User unknown
+
+Final-Recipient: rfc822; slow@example.com
+Action: delayed
+Status: 4.4.1
+Diagnostic-Code: smtp; 421 4.4.1 Connection timed out
+
+--dsn-boundary
+Content-Type: text/rfc822-headers
+
+From: no-reply@notifications.example.com
+To: missing@example.com, slow@example.com
+Subject: CF Notification: Hello
+X-CF-Notification-ID: message-123
+
+--dsn-boundary--
+`)
+
+var feedbackReport = crlf(`From: abuse@mailbox.example.com
+To: bounces@notifications.example.com
+Subject: FW: CF Notification: Hello
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=feedback-report; boundary="arf-boundary"
+
+--arf-boundary
+Content-Type: text/plain
+
+This is an email abuse report.
+
+--arf-boundary
+Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: SomeGenerator/1.0
+Version: 1
+Original-Rcpt-To: <complainer@example.com>
+
+--arf-boundary
+Content-Type: message/rfc822
+Content-Disposition: inline
+
+From: no-reply@notifications.example.com
+To: complainer@example.com
+Subject: CF Notification: Hello
+X-CF-Notification-ID: message-456
+
+The body of the notification.
+--arf-boundary--
+`)
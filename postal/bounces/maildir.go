@@ -0,0 +1,98 @@
+package bounces
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+)
+
+type reportProcessor interface {
+	Process(conn models.ConnectionInterface, report Report, logger lager.Logger) error
+}
+
+// MaildirPoller reads the reports delivered to a local maildir, for example
+// by the MTA that receives mail for the bounce address.
+type MaildirPoller struct {
+	dir             string
+	db              db.DatabaseInterface
+	processor       reportProcessor
+	logger          lager.Logger
+	timer           <-chan time.Time
+	pollingInterval time.Duration
+}
+
+func NewMaildirPoller(dir string, db db.DatabaseInterface, processor reportProcessor, pollingInterval time.Duration, logger lager.Logger) MaildirPoller {
+	return MaildirPoller{
+		dir:             dir,
+		db:              db,
+		processor:       processor,
+		logger:          logger.Session("maildir-poller", lager.Data{"maildir": dir}),
+		pollingInterval: pollingInterval,
+		timer:           time.After(0),
+	}
+}
+
+// Poll processes every message in the "new" directory of the maildir and
+// moves it to "cur" once it has been handled. Messages that are not reports
+// are moved as well so that they are not read again, while messages that
+// failed to be processed stay in "new" to be retried on the next poll.
+func (p MaildirPoller) Poll() {
+	entries, err := ioutil.ReadDir(filepath.Join(p.dir, "new"))
+	if err != nil {
+		p.logger.Error("maildir-read-failed", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		p.handle(entry.Name())
+	}
+}
+
+func (p MaildirPoller) handle(name string) {
+	logger := p.logger.WithData(lager.Data{"file": name})
+	path := filepath.Join(p.dir, "new", name)
+
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Error("maildir-open-failed", err)
+		return
+	}
+
+	report, err := Parse(file)
+	file.Close()
+
+	if err != nil {
+		logger.Error("report-parse-failed", err)
+	} else {
+		err = p.processor.Process(p.db.Connection(), report, logger)
+		if err != nil {
+			logger.Error("report-processing-failed", err)
+			return
+		}
+	}
+
+	err = os.Rename(path, filepath.Join(p.dir, "cur", name+":2,S"))
+	if err != nil {
+		logger.Error("maildir-move-failed", err)
+	}
+}
+
+func (p MaildirPoller) Run() {
+	go func() {
+		for {
+			<-p.timer
+			p.Poll()
+			p.timer = time.After(p.pollingInterval)
+		}
+	}()
+}
@@ -0,0 +1,116 @@
+package bounces_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaildirPoller", func() {
+	var (
+		poller    bounces.MaildirPoller
+		processor *mocks.BounceProcessor
+		database  *mocks.Database
+		conn      *mocks.Connection
+		buffer    *bytes.Buffer
+		dir       string
+	)
+
+	deliver := func(name, content string) {
+		err := ioutil.WriteFile(filepath.Join(dir, "new", name), []byte(content), 0600)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "maildir")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, subdir := range []string{"tmp", "new", "cur"} {
+			Expect(os.Mkdir(filepath.Join(dir, subdir), 0700)).To(Succeed())
+		}
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+		processor = mocks.NewBounceProcessor()
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		poller = bounces.NewMaildirPoller(dir, database, processor, 100*time.Millisecond, logger)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("Poll", func() {
+		It("processes new reports and moves them to cur", func() {
+			deliver("1000.dsn.host", deliveryStatusNotification)
+			deliver("1001.arf.host", feedbackReport)
+
+			poller.Poll()
+
+			Expect(processor.ProcessCall.CallCount).To(Equal(2))
+			Expect(processor.ProcessCall.Receives.Connection).To(Equal(conn))
+			Expect(processor.ProcessCall.Receives.Reports[0].NotificationID).To(Equal("message-123"))
+			Expect(processor.ProcessCall.Receives.Reports[1].NotificationID).To(Equal("message-456"))
+
+			Expect(filepath.Join(dir, "new", "1000.dsn.host")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(dir, "cur", "1000.dsn.host:2,S")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "cur", "1001.arf.host:2,S")).To(BeAnExistingFile())
+		})
+
+		It("moves messages that are not reports without processing them", func() {
+			deliver("1002.reply.host", "From: someone@example.com\r\nContent-Type: text/plain\r\n\r\nOut of office\r\n")
+
+			poller.Poll()
+
+			Expect(processor.ProcessCall.CallCount).To(Equal(0))
+			Expect(filepath.Join(dir, "cur", "1002.reply.host:2,S")).To(BeAnExistingFile())
+			Expect(buffer).To(ContainSubstring("report-parse-failed"))
+		})
+
+		It("leaves reports that could not be processed in new", func() {
+			processor.ProcessCall.Returns.Error = errors.New("database is down")
+			deliver("1003.dsn.host", deliveryStatusNotification)
+
+			poller.Poll()
+
+			Expect(filepath.Join(dir, "new", "1003.dsn.host")).To(BeAnExistingFile())
+			Expect(buffer).To(ContainSubstring("report-processing-failed"))
+		})
+
+		It("logs an error when the maildir cannot be read", func() {
+			os.RemoveAll(dir)
+
+			poller.Poll()
+
+			Expect(buffer).To(ContainSubstring("maildir-read-failed"))
+		})
+	})
+
+	Describe("Run", func() {
+		It("polls the maildir every polling interval", func() {
+			poller.Run()
+
+			deliver("1004.dsn.host", deliveryStatusNotification)
+
+			Eventually(func() int {
+				return processor.ProcessCall.CallCount
+			}).Should(Equal(1))
+		})
+	})
+})
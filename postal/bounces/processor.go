@@ -0,0 +1,112 @@
+package bounces
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+)
+
+type messagesRepository interface {
+	FindByID(conn models.ConnectionInterface, messageID string) (models.Message, error)
+	Update(conn models.ConnectionInterface, message models.Message) (models.Message, error)
+}
+
+type recipientStatusUpdater interface {
+	UpdateStatus(conn models.ConnectionInterface, messageID, address, status string) error
+}
+
+type suppressionsAdder interface {
+	Add(conn models.ConnectionInterface, suppression models.Suppression) error
+}
+
+type Processor struct {
+	messagesRepo     messagesRepository
+	recipientsRepo   recipientStatusUpdater
+	suppressionsRepo suppressionsAdder
+}
+
+func NewProcessor(messagesRepo messagesRepository, recipientsRepo recipientStatusUpdater, suppressionsRepo suppressionsAdder) Processor {
+	return Processor{
+		messagesRepo:     messagesRepo,
+		recipientsRepo:   recipientsRepo,
+		suppressionsRepo: suppressionsRepo,
+	}
+}
+
+// Process marks the message a bounce reports on as bounced and suppresses
+// every address that bounced or complained, so that no further email is
+// sent to it.
+func (p Processor) Process(conn models.ConnectionInterface, report Report, logger lager.Logger) error {
+	logger = logger.Session("bounces", lager.Data{
+		"kind":       report.Kind,
+		"message_id": report.NotificationID,
+	})
+
+	failed := report.Failed()
+	if len(failed) == 0 {
+		logger.Info("report-ignored")
+		return nil
+	}
+
+	if report.Kind == KindBounce && report.NotificationID != "" {
+		err := p.markBounced(conn, report.NotificationID, failed, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, recipient := range failed {
+		err := p.suppressionsRepo.Add(conn, models.Suppression{
+			Type:      models.SuppressionTypeAddress,
+			Value:     recipient.Address,
+			Reason:    reason(report, recipient),
+			MessageID: report.NotificationID,
+		})
+		if err != nil {
+			return err
+		}
+
+		logger.Info("address-suppressed", lager.Data{
+			"address": recipient.Address,
+		})
+	}
+
+	return nil
+}
+
+func (p Processor) markBounced(conn models.ConnectionInterface, messageID string, recipients []Recipient, logger lager.Logger) error {
+	message, err := p.messagesRepo.FindByID(conn, messageID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			logger.Info("message-not-found")
+			return nil
+		}
+		return err
+	}
+
+	message.Status = common.StatusBounced
+	_, err = p.messagesRepo.Update(conn, message)
+	if err != nil {
+		return err
+	}
+
+	for _, recipient := range recipients {
+		err = p.recipientsRepo.UpdateStatus(conn, messageID, recipient.Address, common.StatusBounced)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func reason(report Report, recipient Recipient) string {
+	if report.Kind == KindComplaint {
+		return strings.TrimSpace(fmt.Sprintf("complaint: %s", report.FeedbackType))
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("bounce: %s %s", recipient.Status, recipient.Diagnostic))
+}
@@ -0,0 +1,146 @@
+package bounces_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Processor", func() {
+	var (
+		processor        bounces.Processor
+		messagesRepo     *mocks.MessagesRepo
+		recipientsRepo   *mocks.MessageRecipientsRepo
+		suppressionsRepo *mocks.SuppressionsRepo
+		conn             *mocks.Connection
+		logger           lager.Logger
+		buffer           *bytes.Buffer
+		report           bounces.Report
+	)
+
+	BeforeEach(func() {
+		messagesRepo = mocks.NewMessagesRepo()
+		messagesRepo.FindByIDCall.Returns.Message = models.Message{ID: "message-123", Status: "delivered"}
+		recipientsRepo = mocks.NewMessageRecipientsRepo()
+		suppressionsRepo = mocks.NewSuppressionsRepo()
+		conn = mocks.NewConnection()
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		report = bounces.Report{
+			Kind:           bounces.KindBounce,
+			NotificationID: "message-123",
+			Recipients: []bounces.Recipient{
+				{Address: "missing@example.com", Action: "failed", Status: "5.1.1", Diagnostic: "550 5.1.1 User unknown"},
+				{Address: "slow@example.com", Action: "delayed", Status: "4.4.1"},
+			},
+		}
+
+		processor = bounces.NewProcessor(messagesRepo, recipientsRepo, suppressionsRepo)
+	})
+
+	Context("when processing a bounce", func() {
+		It("marks the message as bounced", func() {
+			err := processor.Process(conn, report, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+			Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal("message-123"))
+			Expect(messagesRepo.UpdateCall.Receives.Messages).To(Equal([]models.Message{
+				{ID: "message-123", Status: "bounced"},
+			}))
+		})
+
+		It("marks the recipients that bounced", func() {
+			err := processor.Process(conn, report, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(recipientsRepo.UpdateStatusCall.Receives.MessageID).To(Equal("message-123"))
+			Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(Equal(map[string]string{
+				"missing@example.com": "bounced",
+			}))
+		})
+
+		It("suppresses the addresses that bounced", func() {
+			err := processor.Process(conn, report, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(suppressionsRepo.AddCall.Receives.Connection).To(Equal(conn))
+			Expect(suppressionsRepo.AddCall.Receives.Suppressions).To(Equal([]models.Suppression{
+				{Type: "address", Value: "missing@example.com", Reason: "bounce: 5.1.1 550 5.1.1 User unknown", MessageID: "message-123"},
+			}))
+			Expect(buffer).To(ContainSubstring(`"message":"notifications.bounces.address-suppressed"`))
+		})
+
+		It("ignores reports without failed recipients", func() {
+			report.Recipients = report.Recipients[1:]
+
+			err := processor.Process(conn, report, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+			Expect(suppressionsRepo.AddCall.CallCount).To(Equal(0))
+			Expect(buffer).To(ContainSubstring(`"message":"notifications.bounces.report-ignored"`))
+		})
+
+		It("still suppresses the addresses when the message no longer exists", func() {
+			messagesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			err := processor.Process(conn, report, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+			Expect(suppressionsRepo.AddCall.CallCount).To(Equal(1))
+		})
+	})
+
+	Context("when processing a complaint", func() {
+		It("suppresses the address without changing the message status", func() {
+			err := processor.Process(conn, bounces.Report{
+				Kind:           bounces.KindComplaint,
+				NotificationID: "message-456",
+				FeedbackType:   "abuse",
+				Recipients:     []bounces.Recipient{{Address: "complainer@example.com"}},
+			}, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+			Expect(suppressionsRepo.AddCall.Receives.Suppressions).To(Equal([]models.Suppression{
+				{Type: "address", Value: "complainer@example.com", Reason: "complaint: abuse", MessageID: "message-456"},
+			}))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns errors from finding the message", func() {
+			messagesRepo.FindByIDCall.Returns.Error = errors.New("database is down")
+
+			err := processor.Process(conn, report, logger)
+			Expect(err).To(MatchError("database is down"))
+			Expect(suppressionsRepo.AddCall.CallCount).To(Equal(0))
+		})
+
+		It("returns errors from updating the message", func() {
+			messagesRepo.UpdateCall.Returns.Error = errors.New("update failed")
+
+			err := processor.Process(conn, report, logger)
+			Expect(err).To(MatchError("update failed"))
+		})
+
+		It("returns errors from suppressing the address", func() {
+			suppressionsRepo.AddCall.Returns.Error = errors.New("insert failed")
+
+			err := processor.Process(conn, report, logger)
+			Expect(err).To(MatchError("insert failed"))
+		})
+	})
+})
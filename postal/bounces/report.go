@@ -0,0 +1,249 @@
+package bounces
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+const (
+	KindBounce    = "bounce"
+	KindComplaint = "complaint"
+)
+
+const notificationIDHeader = "X-CF-Notification-ID"
+
+type Recipient struct {
+	Address    string
+	Action     string
+	Status     string
+	Diagnostic string
+}
+
+// Report is a delivery status notification (RFC 3464) or an abuse complaint
+// in the Abuse Reporting Format (RFC 5965), correlated with the notification
+// it reports on through the X-CF-Notification-ID header of the original
+// message.
+type Report struct {
+	Kind           string
+	NotificationID string
+	FeedbackType   string
+	Recipients     []Recipient
+}
+
+// Failed returns the recipients that should no longer receive email. For a
+// delivery status notification these are the recipients the reporting MTA
+// gave up on, delays and successful relays are not counted. Every recipient
+// of a complaint is included.
+func (r Report) Failed() []Recipient {
+	if r.Kind == KindComplaint {
+		return r.Recipients
+	}
+
+	var failed []Recipient
+	for _, recipient := range r.Recipients {
+		if strings.EqualFold(recipient.Action, "failed") {
+			failed = append(failed, recipient)
+		}
+	}
+
+	return failed
+}
+
+type ParseError struct {
+	Err error
+}
+
+func (e ParseError) Error() string {
+	return "Report could not be parsed: " + e.Err.Error()
+}
+
+// Parse reads a multipart/report message as it is delivered to the bounce
+// address, either as a delivery status notification or as a feedback report.
+func Parse(reader io.Reader) (Report, error) {
+	message, err := mail.ReadMessage(reader)
+	if err != nil {
+		return Report{}, ParseError{err}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		return Report{}, ParseError{err}
+	}
+
+	if mediaType != "multipart/report" {
+		return Report{}, ParseError{fmt.Errorf("expected a multipart/report message, got %q", mediaType)}
+	}
+
+	var report Report
+	switch strings.ToLower(params["report-type"]) {
+	case "delivery-status":
+		report.Kind = KindBounce
+	case "feedback-report":
+		report.Kind = KindComplaint
+	default:
+		return Report{}, ParseError{fmt.Errorf("unsupported report type %q", params["report-type"])}
+	}
+
+	var originalRecipients []string
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Report{}, ParseError{err}
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status":
+			report.Recipients, err = parseDeliveryStatus(partBody(part))
+		case "message/feedback-report":
+			report.FeedbackType, report.Recipients, err = parseFeedbackReport(partBody(part))
+		case "message/rfc822", "message/rfc822-headers", "text/rfc822-headers":
+			var header textproto.MIMEHeader
+			header, err = textproto.NewReader(bufio.NewReader(partBody(part))).ReadMIMEHeader()
+			if err == io.EOF {
+				err = nil
+			}
+
+			report.NotificationID = header.Get(notificationIDHeader)
+			originalRecipients = addresses(header.Get("To"), header.Get("Cc"))
+		}
+		if err != nil {
+			return Report{}, ParseError{err}
+		}
+	}
+
+	// Feedback reports may leave out the recipient the complaint is about,
+	// in which case the recipients of the original message are used.
+	if report.Kind == KindComplaint && len(report.Recipients) == 0 {
+		for _, address := range originalRecipients {
+			report.Recipients = append(report.Recipients, Recipient{Address: address})
+		}
+	}
+
+	if len(report.Recipients) == 0 {
+		return Report{}, ParseError{errors.New("no recipients found in report")}
+	}
+
+	return report, nil
+}
+
+func parseDeliveryStatus(body io.Reader) ([]Recipient, error) {
+	groups, err := readFieldGroups(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []Recipient
+	for _, fields := range groups {
+		address := typedValue(fields.Get("Final-Recipient"))
+		if address == "" {
+			continue
+		}
+
+		recipients = append(recipients, Recipient{
+			Address:    address,
+			Action:     strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+			Status:     strings.TrimSpace(fields.Get("Status")),
+			Diagnostic: typedValue(fields.Get("Diagnostic-Code")),
+		})
+	}
+
+	return recipients, nil
+}
+
+func parseFeedbackReport(body io.Reader) (string, []Recipient, error) {
+	groups, err := readFieldGroups(body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var feedbackType string
+	var recipients []Recipient
+	for _, fields := range groups {
+		if value := fields.Get("Feedback-Type"); value != "" {
+			feedbackType = strings.ToLower(strings.TrimSpace(value))
+		}
+
+		for _, address := range fields["Original-Rcpt-To"] {
+			recipients = append(recipients, Recipient{Address: typedValue(address)})
+		}
+	}
+
+	return feedbackType, recipients, nil
+}
+
+// readFieldGroups reads the blocks of header-style fields that make up the
+// machine-readable part of a report. The blocks are separated by blank lines.
+func readFieldGroups(body io.Reader) ([]textproto.MIMEHeader, error) {
+	reader := textproto.NewReader(bufio.NewReader(body))
+
+	var groups []textproto.MIMEHeader
+	for {
+		fields, err := reader.ReadMIMEHeader()
+		if len(fields) > 0 {
+			groups = append(groups, fields)
+		}
+
+		if err == io.EOF {
+			return groups, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// typedValue strips the type prefix from fields such as
+// "Final-Recipient: rfc822; user@example.com", along with any angle brackets
+// around the address.
+func typedValue(value string) string {
+	if i := strings.Index(value, ";"); i >= 0 {
+		value = value[i+1:]
+	}
+
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
+
+func addresses(fields ...string) []string {
+	var result []string
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+
+		list, err := mail.ParseAddressList(field)
+		if err != nil {
+			continue
+		}
+
+		for _, address := range list {
+			result = append(result, address.Address)
+		}
+	}
+
+	return result
+}
+
+// partBody decodes base64 encoded parts. Quoted-printable parts are already
+// decoded by the multipart reader.
+func partBody(part *multipart.Part) io.Reader {
+	if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+		return base64.NewDecoder(base64.StdEncoding, part)
+	}
+
+	return part
+}
@@ -0,0 +1,111 @@
+package bounces_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/bounces"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	Context("when given a delivery status notification", func() {
+		It("returns a bounce report for each recipient", func() {
+			report, err := bounces.Parse(strings.NewReader(deliveryStatusNotification))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Kind).To(Equal(bounces.KindBounce))
+			Expect(report.NotificationID).To(Equal("message-123"))
+			Expect(report.Recipients).To(Equal([]bounces.Recipient{
+				{
+					Address:    "missing@example.com",
+					Action:     "failed",
+					Status:     "5.1.1",
+					Diagnostic: "550 5.1.1 <missing@example.com>: Recipient address rejected: User unknown",
+				},
+				{
+					Address:    "slow@example.com",
+					Action:     "delayed",
+					Status:     "4.4.1",
+					Diagnostic: "421 4.4.1 Connection timed out",
+				},
+			}))
+		})
+
+		It("only counts recipients whose delivery failed", func() {
+			report, err := bounces.Parse(strings.NewReader(deliveryStatusNotification))
+			Expect(err).NotTo(HaveOccurred())
+
+			failed := report.Failed()
+			Expect(failed).To(HaveLen(1))
+			Expect(failed[0].Address).To(Equal("missing@example.com"))
+		})
+
+		It("decodes base64 encoded report parts", func() {
+			encoded := strings.Replace(deliveryStatusNotification, crlf(`Content-Type: text/rfc822-headers
+
+From: no-reply@notifications.example.com
+To: missing@example.com, slow@example.com
+Subject: CF Notification: Hello
+X-CF-Notification-ID: message-123
+`), crlf(`Content-Type: text/rfc822-headers
+Content-Transfer-Encoding: base64
+
+WC1DRi1Ob3RpZmljYXRpb24tSUQ6IG1lc3NhZ2UtNzg5DQoNCg==
+`), 1)
+
+			report, err := bounces.Parse(strings.NewReader(encoded))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.NotificationID).To(Equal("message-789"))
+		})
+	})
+
+	Context("when given a feedback report", func() {
+		It("returns a complaint report", func() {
+			report, err := bounces.Parse(strings.NewReader(feedbackReport))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Kind).To(Equal(bounces.KindComplaint))
+			Expect(report.NotificationID).To(Equal("message-456"))
+			Expect(report.FeedbackType).To(Equal("abuse"))
+			Expect(report.Recipients).To(Equal([]bounces.Recipient{
+				{Address: "complainer@example.com"},
+			}))
+			Expect(report.Failed()).To(Equal(report.Recipients))
+		})
+
+		It("falls back to the recipients of the original message", func() {
+			redacted := strings.Replace(feedbackReport, "Original-Rcpt-To: <complainer@example.com>\r\n", "", 1)
+
+			report, err := bounces.Parse(strings.NewReader(redacted))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Recipients).To(Equal([]bounces.Recipient{
+				{Address: "complainer@example.com"},
+			}))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns a parse error for messages that are not reports", func() {
+			_, err := bounces.Parse(strings.NewReader(crlf("From: someone@example.com\nContent-Type: text/plain\n\nOut of office\n")))
+			Expect(err).To(BeAssignableToTypeOf(bounces.ParseError{}))
+			Expect(err).To(MatchError(`Report could not be parsed: expected a multipart/report message, got "text/plain"`))
+		})
+
+		It("returns a parse error for unsupported report types", func() {
+			dispositionNotification := strings.Replace(deliveryStatusNotification, "report-type=delivery-status", "report-type=disposition-notification", 1)
+
+			_, err := bounces.Parse(strings.NewReader(dispositionNotification))
+			Expect(err).To(MatchError(`Report could not be parsed: unsupported report type "disposition-notification"`))
+		})
+
+		It("returns a parse error when the report names no recipients", func() {
+			empty := strings.Replace(feedbackReport, "Original-Rcpt-To: <complainer@example.com>\r\n", "", 1)
+			empty = strings.Replace(empty, "To: complainer@example.com\r\n", "", 1)
+
+			_, err := bounces.Parse(strings.NewReader(empty))
+			Expect(err).To(MatchError("Report could not be parsed: no recipients found in report"))
+		})
+	})
+})
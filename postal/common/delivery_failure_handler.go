@@ -13,19 +13,28 @@ type Retryable interface {
 	State() (retryCount int, activeAt time.Time)
 }
 
-type DeliveryFailureHandler struct{}
+// DeliveryFailureHandler retries failed deliveries with an exponential
+// backoff, waiting backoffBase before the first retry and doubling the wait
+// after each attempt until maxRetries have been made.
+type DeliveryFailureHandler struct {
+	maxRetries  int
+	backoffBase time.Duration
+}
 
-func NewDeliveryFailureHandler() DeliveryFailureHandler {
-	return DeliveryFailureHandler{}
+func NewDeliveryFailureHandler(maxRetries int, backoffBase time.Duration) DeliveryFailureHandler {
+	return DeliveryFailureHandler{
+		maxRetries:  maxRetries,
+		backoffBase: backoffBase,
+	}
 }
 
 func (h DeliveryFailureHandler) Handle(job Retryable, logger lager.Logger) {
 	retryCount, _ := job.State()
-	if retryCount > 9 {
+	if retryCount >= h.maxRetries {
 		return
 	}
 
-	duration := time.Duration(int64(math.Pow(2, float64(retryCount)))) * time.Minute
+	duration := time.Duration(int64(math.Pow(2, float64(retryCount)))) * h.backoffBase
 	job.Retry(duration)
 
 	retryCount, activeAt := job.State()
@@ -26,7 +26,7 @@ var _ = Describe("DeliveryFailureHandler", func() {
 		logger = lager.NewLogger("notifications")
 		logger.RegisterSink(lager.NewWriterSink(buffer, lager.INFO))
 
-		handler = common.NewDeliveryFailureHandler()
+		handler = common.NewDeliveryFailureHandler(10, time.Minute)
 	})
 
 	It("retries the job using an exponential backoff algorithm", func() {
@@ -52,7 +52,7 @@ var _ = Describe("DeliveryFailureHandler", func() {
 		}
 	})
 
-	It("gives up after 9 retries", func() {
+	It("gives up after the maximum number of retries", func() {
 		job.StateCall.Returns.Count = 10
 
 		handler.Handle(job, logger)
@@ -60,6 +60,28 @@ var _ = Describe("DeliveryFailureHandler", func() {
 		Expect(job.RetryCall.WasCalled).To(BeFalse())
 	})
 
+	Context("when the retries are configured", func() {
+		BeforeEach(func() {
+			handler = common.NewDeliveryFailureHandler(3, 30*time.Second)
+		})
+
+		It("backs off from the configured base", func() {
+			job.StateCall.Returns.Count = 2
+
+			handler.Handle(job, logger)
+
+			Expect(job.RetryCall.Receives.Duration).To(Equal(2 * time.Minute))
+		})
+
+		It("gives up after the configured number of retries", func() {
+			job.StateCall.Returns.Count = 3
+
+			handler.Handle(job, logger)
+
+			Expect(job.RetryCall.WasCalled).To(BeFalse())
+		})
+	})
+
 	It("logs the retry attempt", func() {
 		expectedActiveAt := time.Now().Truncate(time.Second)
 		job.StateCall.Returns.Time = expectedActiveAt
@@ -0,0 +1,123 @@
+package common
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedDomains is how many domains the throttle remembers before it
+// forgets those that no longer hold back any message.
+const maxTrackedDomains = 1000
+
+type clock interface {
+	Now() time.Time
+}
+
+// DomainThrottle limits how many messages per second are sent to each
+// recipient domain. A single throttle is shared by all of the workers of an
+// instance, so the limits apply to the instance as a whole. Messages that
+// have to wait are lined up one slot apart, rather than all being sent back
+// to the queue for the same moment.
+type DomainThrottle struct {
+	rate      float64
+	overrides map[string]float64
+	clock     clock
+
+	mutex    *sync.Mutex
+	next     map[string]time.Time
+	deferred map[string]time.Time
+}
+
+// NewDomainThrottle allows rate messages per second to every domain, except
+// for the domains named in overrides. A rate of zero means unlimited.
+func NewDomainThrottle(rate float64, overrides map[string]float64, clock clock) DomainThrottle {
+	normalized := map[string]float64{}
+	for domain, domainRate := range overrides {
+		normalized[strings.ToLower(domain)] = domainRate
+	}
+
+	return DomainThrottle{
+		rate:      rate,
+		overrides: normalized,
+		clock:     clock,
+		mutex:     &sync.Mutex{},
+		next:      map[string]time.Time{},
+		deferred:  map[string]time.Time{},
+	}
+}
+
+// Reserve claims a slot to send a message to the domains of the given
+// addresses. When any of the domains has no slot free, nothing is claimed
+// and the time until the message should be tried again is returned instead.
+// That time is one slot after the last message that was told to wait for
+// the same domains, so that waiting messages come back one at a time.
+func (t DomainThrottle) Reserve(addresses []string) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	domains := t.limitedDomains(addresses)
+
+	if len(t.next) > maxTrackedDomains {
+		forgetPast(t.next, now)
+		forgetPast(t.deferred, now)
+	}
+
+	free := now
+	for domain := range domains {
+		if next := t.next[domain]; next.After(free) {
+			free = next
+		}
+	}
+
+	if free.After(now) {
+		for domain := range domains {
+			if deferred := t.deferred[domain]; deferred.After(free) {
+				free = deferred
+			}
+		}
+
+		for domain, interval := range domains {
+			t.deferred[domain] = free.Add(interval)
+		}
+
+		return free.Sub(now)
+	}
+
+	for domain, interval := range domains {
+		t.next[domain] = now.Add(interval)
+	}
+
+	return 0
+}
+
+func forgetPast(times map[string]time.Time, now time.Time) {
+	for domain, next := range times {
+		if !next.After(now) {
+			delete(times, domain)
+		}
+	}
+}
+
+func (t DomainThrottle) limitedDomains(addresses []string) map[string]time.Duration {
+	domains := map[string]time.Duration{}
+	for _, address := range addresses {
+		index := strings.LastIndex(address, "@")
+		if index < 0 {
+			continue
+		}
+
+		domain := strings.ToLower(strings.TrimRight(address[index+1:], ">"))
+		rate, ok := t.overrides[domain]
+		if !ok {
+			rate = t.rate
+		}
+
+		if rate > 0 {
+			domains[domain] = time.Duration(float64(time.Second) / rate)
+		}
+	}
+
+	return domains
+}
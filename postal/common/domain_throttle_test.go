@@ -0,0 +1,79 @@
+package common_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DomainThrottle", func() {
+	var (
+		throttle common.DomainThrottle
+		clock    *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		throttle = common.NewDomainThrottle(2, map[string]float64{
+			"Slow.example.com": 0.5,
+			"fast.example.com": 0,
+		}, clock)
+	})
+
+	It("spaces out messages to the same domain", func() {
+		Expect(throttle.Reserve([]string{"first@example.com"})).To(Equal(time.Duration(0)))
+		Expect(throttle.Reserve([]string{"second@EXAMPLE.com"})).To(Equal(500 * time.Millisecond))
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(500 * time.Millisecond)
+		Expect(throttle.Reserve([]string{"second@example.com"})).To(Equal(time.Duration(0)))
+	})
+
+	It("lines up the messages that have to wait one slot apart", func() {
+		Expect(throttle.Reserve([]string{"first@example.com"})).To(Equal(time.Duration(0)))
+		Expect(throttle.Reserve([]string{"second@example.com"})).To(Equal(500 * time.Millisecond))
+		Expect(throttle.Reserve([]string{"third@example.com"})).To(Equal(1000 * time.Millisecond))
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(200 * time.Millisecond)
+		Expect(throttle.Reserve([]string{"fourth@example.com"})).To(Equal(1300 * time.Millisecond))
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(300 * time.Millisecond)
+		Expect(throttle.Reserve([]string{"second@example.com"})).To(Equal(time.Duration(0)))
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(500 * time.Millisecond)
+		Expect(throttle.Reserve([]string{"third@example.com"})).To(Equal(time.Duration(0)))
+	})
+
+	It("throttles each domain separately", func() {
+		Expect(throttle.Reserve([]string{"someone@example.com"})).To(Equal(time.Duration(0)))
+		Expect(throttle.Reserve([]string{"someone@example.org"})).To(Equal(time.Duration(0)))
+	})
+
+	It("uses the rate configured for a domain", func() {
+		Expect(throttle.Reserve([]string{"someone@slow.example.com"})).To(Equal(time.Duration(0)))
+		Expect(throttle.Reserve([]string{"someone@slow.example.com"})).To(Equal(2 * time.Second))
+	})
+
+	It("does not throttle domains with a rate of zero", func() {
+		for i := 0; i < 5; i++ {
+			Expect(throttle.Reserve([]string{"someone@fast.example.com"})).To(Equal(time.Duration(0)))
+		}
+	})
+
+	It("waits for every domain of a message, without claiming any of them", func() {
+		Expect(throttle.Reserve([]string{"someone@slow.example.com"})).To(Equal(time.Duration(0)))
+
+		Expect(throttle.Reserve([]string{"someone@example.com", "other@slow.example.com"})).To(Equal(2 * time.Second))
+		Expect(throttle.Reserve([]string{"someone@example.com"})).To(Equal(time.Duration(0)))
+	})
+
+	It("ignores addresses without a domain", func() {
+		Expect(throttle.Reserve([]string{"user-123", ""})).To(Equal(time.Duration(0)))
+		Expect(throttle.Reserve([]string{"user-123"})).To(Equal(time.Duration(0)))
+	})
+})
@@ -2,6 +2,7 @@ package common
 
 import (
 	"html"
+	"strings"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
@@ -20,6 +21,32 @@ type Options struct {
 	Role              string
 	Endorsement       string
 	TemplateID        string
+	Data              map[string]interface{}
+	Attachments       []Attachment
+	Recipients        []Recipient
+}
+
+const (
+	RecipientTo  = "to"
+	RecipientCC  = "cc"
+	RecipientBCC = "bcc"
+)
+
+// Recipient is one of the addresses of an email that is sent to several
+// people at once.
+type Recipient struct {
+	Address string
+	Type    string
+}
+
+// Attachment refers to attachment content stored when the message was
+// enqueued.
+type Attachment struct {
+	ID          string
+	Filename    string
+	ContentType string
+	ContentID   string
+	Inline      bool
 }
 
 type Delivery struct {
@@ -35,13 +62,16 @@ type Delivery struct {
 	VCAPRequestID   string
 	RequestReceived time.Time
 	CampaignID      string
+	TraceParent     string
 }
 
 type Templates struct {
-	Name    string
-	Subject string
-	Text    string
-	HTML    string
+	Name     string
+	Subject  string
+	Text     string
+	HTML     string
+	Layout   string
+	Partials map[string]string
 }
 
 type HTML struct {
@@ -55,6 +85,8 @@ type MessageContext struct {
 	From              string
 	ReplyTo           string
 	To                string
+	CC                string
+	Recipients        []string
 	Subject           string
 	Text              string
 	HTML              string
@@ -62,6 +94,8 @@ type MessageContext struct {
 	TextTemplate      string
 	HTMLTemplate      string
 	SubjectTemplate   string
+	Layout            string
+	Partials          map[string]string
 	KindDescription   string
 	SourceDescription string
 	UserGUID          string
@@ -77,6 +111,7 @@ type MessageContext struct {
 	OrganizationRole  string
 	RequestReceived   time.Time
 	Domain            string
+	Data              map[string]interface{}
 }
 
 func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.CloakInterface, templates Templates) MessageContext {
@@ -107,6 +142,8 @@ func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.C
 		TextTemplate:      templates.Text,
 		HTMLTemplate:      templates.HTML,
 		SubjectTemplate:   templates.Subject,
+		Layout:            templates.Layout,
+		Partials:          templates.Partials,
 		KindDescription:   kindDescription,
 		SourceDescription: sourceDescription,
 		UserGUID:          delivery.UserGUID,
@@ -121,6 +158,24 @@ func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.C
 		OrganizationRole:  options.Role,
 		RequestReceived:   delivery.RequestReceived,
 		Domain:            domain,
+		Data:              options.Data,
+	}
+
+	if len(options.Recipients) > 0 {
+		var to, cc []string
+		for _, recipient := range options.Recipients {
+			switch recipient.Type {
+			case RecipientTo:
+				to = append(to, recipient.Address)
+			case RecipientCC:
+				cc = append(cc, recipient.Address)
+			}
+
+			messageContext.Recipients = append(messageContext.Recipients, recipient.Address)
+		}
+
+		messageContext.To = strings.Join(to, ", ")
+		messageContext.CC = strings.Join(cc, ", ")
 	}
 
 	if messageContext.Subject == "" {
@@ -139,6 +194,7 @@ func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.C
 func (context *MessageContext) Escape() {
 	context.From = html.EscapeString(context.From)
 	context.To = html.EscapeString(context.To)
+	context.CC = html.EscapeString(context.CC)
 	context.ReplyTo = html.EscapeString(context.ReplyTo)
 	context.Subject = html.EscapeString(context.Subject)
 	context.Text = html.EscapeString(context.Text)
@@ -149,4 +205,32 @@ func (context *MessageContext) Escape() {
 	context.Space = html.EscapeString(context.Space)
 	context.Organization = html.EscapeString(context.Organization)
 	context.Endorsement = html.EscapeString(context.Endorsement)
+
+	if context.Data != nil {
+		context.Data = escapeData(context.Data).(map[string]interface{})
+	}
+}
+
+// escapeData returns a copy of the given decoded JSON value with every string
+// HTML escaped. A copy is made so that the unescaped values remain available
+// to the plain text and subject portions of the message.
+func escapeData(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return html.EscapeString(v)
+	case map[string]interface{}:
+		escaped := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			escaped[key] = escapeData(item)
+		}
+		return escaped
+	case []interface{}:
+		escaped := make([]interface{}, len(v))
+		for i, item := range v {
+			escaped[i] = escapeData(item)
+		}
+		return escaped
+	default:
+		return v
+	}
 }
@@ -45,6 +45,7 @@ var _ = Describe("MessageContext", func() {
 			KindID:            "the-kind-id",
 			Endorsement:       "this is the endorsement",
 			Role:              "OrgRole",
+			Data:              map[string]interface{}{"quota": "10GB"},
 		}
 
 		reqReceived, _ = time.Parse(time.RFC3339Nano, "2015-06-08T14:40:12.207187819-07:00")
@@ -102,6 +103,7 @@ var _ = Describe("MessageContext", func() {
 			Expect(context.OrganizationRole).To(Equal("OrgRole"))
 			Expect(context.RequestReceived).To(Equal(reqReceived))
 			Expect(context.Domain).To(Equal(domain))
+			Expect(context.Data).To(Equal(map[string]interface{}{"quota": "10GB"}))
 		})
 
 		It("falls back to Kind if KindDescription is missing", func() {
@@ -123,6 +125,20 @@ var _ = Describe("MessageContext", func() {
 			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
 			Expect(context.Subject).To(Equal("[no subject]"))
 		})
+
+		It("addresses every recipient of a message sent to several of them", func() {
+			delivery.Options.Recipients = []common.Recipient{
+				{Address: "first@example.com", Type: common.RecipientTo},
+				{Address: "second@example.com", Type: common.RecipientTo},
+				{Address: "cc@example.com", Type: common.RecipientCC},
+				{Address: "bcc@example.com", Type: common.RecipientBCC},
+			}
+			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
+
+			Expect(context.To).To(Equal("first@example.com, second@example.com"))
+			Expect(context.CC).To(Equal("cc@example.com"))
+			Expect(context.Recipients).To(Equal([]string{"first@example.com", "second@example.com", "cc@example.com", "bcc@example.com"}))
+		})
 	})
 
 	Describe("Escape", func() {
@@ -137,6 +153,11 @@ var _ = Describe("MessageContext", func() {
 				KindID:            "the & kind",
 				Endorsement:       "this & is the endorsement",
 				Role:              "OrgRole",
+				Data: map[string]interface{}{
+					"link":   "<a href=\"https://example.com\">here</a>",
+					"quota":  float64(10),
+					"nested": map[string]interface{}{"items": []interface{}{"a & b", true}},
+				},
 			}
 
 			delivery.Options = options
@@ -170,6 +191,18 @@ var _ = Describe("MessageContext", func() {
 			Expect(context.Scope).To(Equal(""))
 			Expect(context.Endorsement).To(Equal("this &amp; is the endorsement"))
 			Expect(context.OrganizationRole).To(Equal("OrgRole"))
+			Expect(context.Data).To(Equal(map[string]interface{}{
+				"link":   "&lt;a href=&#34;https://example.com&#34;&gt;here&lt;/a&gt;",
+				"quota":  float64(10),
+				"nested": map[string]interface{}{"items": []interface{}{"a &amp; b", true}},
+			}))
+		})
+
+		It("does not modify the data held by the delivery", func() {
+			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			context.Escape()
+
+			Expect(delivery.Options.Data["link"]).To(Equal("<a href=\"https://example.com\">here</a>"))
 		})
 	})
 })
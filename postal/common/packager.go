@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/mail"
@@ -26,12 +25,14 @@ type templatesLoader interface {
 type Packager struct {
 	templates templatesLoader
 	cloak     conceal.CloakInterface
+	cache     *TemplateCache
 }
 
 func NewPackager(templates templatesLoader, cloak conceal.CloakInterface) Packager {
 	return Packager{
 		templates: templates,
 		cloak:     cloak,
+		cache:     NewTemplateCache(),
 	}
 }
 
@@ -56,11 +57,13 @@ func (packager Packager) Pack(context MessageContext) (mail.Message, error) {
 	}
 
 	return mail.Message{
-		From:    context.From,
-		ReplyTo: context.ReplyTo,
-		To:      context.To,
-		Subject: compiledSubject,
-		Body:    parts,
+		From:       context.From,
+		ReplyTo:    context.ReplyTo,
+		To:         context.To,
+		CC:         context.CC,
+		Recipients: context.Recipients,
+		Subject:    compiledSubject,
+		Body:       parts,
 		Headers: []string{
 			fmt.Sprintf("X-CF-Client-ID: %s", context.ClientID),
 			fmt.Sprintf("X-CF-Notification-ID: %s", context.MessageID),
@@ -100,7 +103,7 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 			return parts, err
 		}
 
-		htmlPart, err := packager.compileTemplate(context, HTMLWrapperTemplate, true)
+		htmlPart, err := packager.compileTemplate(context, layoutTemplate(context.Layout), true)
 		if err != nil {
 			return parts, err
 		}
@@ -117,7 +120,7 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 func (packager Packager) compileTemplate(context MessageContext, theTemplate string, escapeContext bool) (string, error) {
 	buffer := bytes.NewBuffer([]byte{})
 
-	source, err := template.New("compileTemplate").Parse(theTemplate)
+	source, err := packager.cache.Parse(theTemplate, context.Partials)
 	if err != nil {
 		return "", err
 	}
@@ -131,3 +134,11 @@ func (packager Packager) compileTemplate(context MessageContext, theTemplate str
 
 	return compiledTemplate, nil
 }
+
+func layoutTemplate(layout string) string {
+	if layout == "" {
+		return HTMLWrapperTemplate
+	}
+
+	return fmt.Sprintf("{{template %q .}}", layout)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/markdown"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 
@@ -240,5 +241,109 @@ Banana preamble <p>user supplied banana html</p>  3&amp;3 4&#39;4 user-123
 				}))
 			})
 		})
+
+		Context("when the templates reference partials", func() {
+			BeforeEach(func() {
+				context.Partials = map[string]string{
+					"footer": "-- {{.ClientID}} footer --",
+				}
+				context.TextTemplate = `{{.Text}} {{template "footer" .}}`
+				context.HTMLTemplate = `{{.HTML}} {{template "footer" .}}`
+			})
+
+			It("includes the partials in both the text and html portions", func() {
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts).To(HaveLen(2))
+				Expect(parts[0].Content).To(Equal(`User <supplied> "banana" text -- 3&3 footer --`))
+				Expect(parts[1].Content).To(ContainSubstring("<p>user supplied banana html</p> -- 3&amp;3 footer --"))
+			})
+
+			It("returns an error when a partial does not exist", func() {
+				context.TextTemplate = `{{template "missing" .}}`
+
+				_, err := packager.CompileParts(context)
+				Expect(err).To(BeAssignableToTypeOf(common.MissingPartialError{}))
+			})
+
+			It("returns an error when the partials reference each other in a cycle", func() {
+				context.Partials = map[string]string{
+					"footer":  `{{template "imprint" .}}`,
+					"imprint": `{{if .Text}}{{template "footer" .}}{{end}}`,
+				}
+
+				_, err := packager.CompileParts(context)
+				Expect(err).To(BeAssignableToTypeOf(common.PartialCycleError{}))
+			})
+		})
+
+		Context("when the message carries template data", func() {
+			It("exposes the data to the templates, escaping it for the html portion only", func() {
+				context.Data = map[string]interface{}{
+					"link":  `<a href="https://example.com">quota</a>`,
+					"quota": map[string]interface{}{"used": float64(7)},
+				}
+				context.TextTemplate = "{{.Data.link}} {{.Data.quota.used}}"
+				context.HTMLTemplate = "{{.Data.link}} {{.Data.quota.used}}"
+				context.SubjectTemplate = "{{.Data.link}}"
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts).To(HaveLen(2))
+				Expect(parts[0].Content).To(Equal(`<a href="https://example.com">quota</a> 7`))
+				Expect(parts[1].Content).To(ContainSubstring("&lt;a href=&#34;https://example.com&#34;&gt;quota&lt;/a&gt; 7"))
+
+				message, err := packager.Pack(context)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(message.Subject).To(Equal(`<a href="https://example.com">quota</a>`))
+			})
+		})
+
+		Context("when the html template was rendered from markdown", func() {
+			It("escapes link destinations exactly once", func() {
+				html, _ := markdown.RenderTemplate("[a]({{.Data.link}}) [b](https://b.example/?a=1&b=2) [c](https://c.example/{{.Data.path}})")
+				context.Data = map[string]interface{}{
+					"link": "https://a.example/?a=1&b=2",
+					"path": "x?a=1&b=2",
+				}
+				context.HTMLTemplate = html
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts).To(HaveLen(2))
+				Expect(parts[1].Content).To(ContainSubstring(`<a href="https://a.example/?a=1&amp;b=2">a</a>`))
+				Expect(parts[1].Content).To(ContainSubstring(`<a href="https://b.example/?a=1&amp;b=2">b</a>`))
+				Expect(parts[1].Content).To(ContainSubstring(`<a href="https://c.example/x?a=1&amp;b=2">c</a>`))
+			})
+		})
+
+		Context("when the templates specify a layout", func() {
+			It("wraps the html body in the layout instead of the default wrapper", func() {
+				context.Layout = "branded"
+				context.Partials = map[string]string{
+					"branded": `<html><body>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</body></html>`,
+					"footer":  `<footer>{{.Organization}}</footer>`,
+				}
+				context.HTMLTemplate = "{{.HTML}}"
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts).To(ContainElement(mail.Part{
+					ContentType: "text/html",
+					Content:     "<html><body><p>user supplied banana html</p><footer>banana</footer></body></html>",
+				}))
+			})
+
+			It("returns an error when the layout does not exist", func() {
+				context.Layout = "missing"
+
+				_, err := packager.CompileParts(context)
+				Expect(err).To(BeAssignableToTypeOf(common.MissingPartialError{}))
+			})
+		})
 	})
 })
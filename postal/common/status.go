@@ -6,4 +6,14 @@ const (
 	StatusDelivered     = "delivered"
 	StatusQueued        = "queued"
 	StatusUndeliverable = "undeliverable"
+	StatusBounced       = "bounced"
 )
+
+var Statuses = []string{
+	StatusFailed,
+	StatusRetry,
+	StatusDelivered,
+	StatusQueued,
+	StatusUndeliverable,
+	StatusBounced,
+}
@@ -0,0 +1,256 @@
+package common
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/cloudfoundry-incubator/notifications/markdown"
+)
+
+const templateCacheSize = 512
+
+type PartialCycleError struct {
+	Err error
+}
+
+func (e PartialCycleError) Error() string {
+	return e.Err.Error()
+}
+
+type MissingPartialError struct {
+	Err error
+}
+
+func (e MissingPartialError) Error() string {
+	return e.Err.Error()
+}
+
+// ParseTemplateSet parses source together with the given named partials so
+// that the source can reference them with {{template "name" .}}. Partials
+// that reference each other in a cycle, or references to partials that do not
+// exist, are reported as errors rather than failing at execution time.
+func ParseTemplateSet(source string, partials map[string]string) (*template.Template, error) {
+	root := template.New("compileTemplate").Funcs(markdown.TemplateFuncs)
+
+	for _, name := range sortedNames(partials) {
+		_, err := root.New(name).Parse(partials[name])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := root.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkReferences(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// PartialReferences returns the sorted names of the partials that the given
+// sources reference with {{template "name" .}}, leaving out the templates the
+// sources define themselves. Sources that do not parse are skipped, since
+// ParseTemplateSet reports their errors once they are rendered.
+func PartialReferences(sources ...string) []string {
+	seen := map[string]bool{}
+	var names []string
+
+	for _, source := range sources {
+		root, err := template.New("references").Funcs(markdown.TemplateFuncs).Parse(source)
+		if err != nil {
+			continue
+		}
+
+		for _, t := range root.Templates() {
+			if t.Tree == nil {
+				continue
+			}
+
+			for _, reference := range templateReferences(t.Tree.Root, nil) {
+				if seen[reference] || root.Lookup(reference) != nil {
+					continue
+				}
+
+				seen[reference] = true
+				names = append(names, reference)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func checkReferences(root *template.Template) error {
+	graph := map[string][]string{}
+	for _, t := range root.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+
+		graph[t.Name()] = templateReferences(t.Tree.Root, nil)
+	}
+
+	for name, references := range graph {
+		for _, reference := range references {
+			if _, ok := graph[reference]; !ok {
+				return MissingPartialError{fmt.Errorf("template %q references unknown partial %q", name, reference)}
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return PartialCycleError{fmt.Errorf("partials form a cycle: %v", append(path, name))}
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, reference := range graph[name] {
+			err := visit(reference, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, name := range sortedKeys(graph) {
+		err := visit(name, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func templateReferences(node parse.Node, references []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return references
+		}
+
+		for _, child := range n.Nodes {
+			references = templateReferences(child, references)
+		}
+	case *parse.IfNode:
+		references = branchReferences(n.BranchNode, references)
+	case *parse.RangeNode:
+		references = branchReferences(n.BranchNode, references)
+	case *parse.WithNode:
+		references = branchReferences(n.BranchNode, references)
+	case *parse.TemplateNode:
+		references = append(references, n.Name)
+	}
+
+	return references
+}
+
+func branchReferences(branch parse.BranchNode, references []string) []string {
+	references = templateReferences(branch.List, references)
+	return templateReferences(branch.ElseList, references)
+}
+
+func sortedNames(partials map[string]string) []string {
+	var names []string
+	for name := range partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedKeys(graph map[string][]string) []string {
+	var names []string
+	for name := range graph {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// TemplateCache holds parsed template trees keyed by their source and the
+// partials they were parsed with, so that deliveries sharing a template do
+// not re-parse it. Parsed templates are safe to execute concurrently.
+type TemplateCache struct {
+	mutex     sync.Mutex
+	templates map[[sha256.Size]byte]*template.Template
+}
+
+func NewTemplateCache() *TemplateCache {
+	return &TemplateCache{
+		templates: map[[sha256.Size]byte]*template.Template{},
+	}
+}
+
+func (cache *TemplateCache) Parse(source string, partials map[string]string) (*template.Template, error) {
+	key := cacheKey(source, partials)
+
+	cache.mutex.Lock()
+	parsed, ok := cache.templates[key]
+	cache.mutex.Unlock()
+
+	if ok {
+		return parsed, nil
+	}
+
+	parsed, err := ParseTemplateSet(source, partials)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mutex.Lock()
+	if len(cache.templates) >= templateCacheSize {
+		cache.templates = map[[sha256.Size]byte]*template.Template{}
+	}
+	cache.templates[key] = parsed
+	cache.mutex.Unlock()
+
+	return parsed, nil
+}
+
+func (cache *TemplateCache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return len(cache.templates)
+}
+
+func cacheKey(source string, partials map[string]string) [sha256.Size]byte {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d:%s", len(source), source)
+
+	for _, name := range sortedNames(partials) {
+		fmt.Fprintf(hash, "%d:%s%d:%s", len(name), name, len(partials[name]), partials[name])
+	}
+
+	var key [sha256.Size]byte
+	copy(key[:], hash.Sum(nil))
+
+	return key
+}
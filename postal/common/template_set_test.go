@@ -0,0 +1,115 @@
+package common_test
+
+import (
+	"bytes"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseTemplateSet", func() {
+	It("parses the source with the partials available to it", func() {
+		set, err := common.ParseTemplateSet(`{{template "greeting" .}}, world`, map[string]string{
+			"greeting": "{{.}}",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := bytes.NewBuffer([]byte{})
+		err = set.Execute(buffer, "hello")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal("hello, world"))
+	})
+
+	It("makes the functions called by rendered markdown available", func() {
+		set, err := common.ParseTemplateSet(`<a href="{{markdownURL (.)}}">`, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := bytes.NewBuffer([]byte{})
+		err = set.Execute(buffer, "javascript:alert(1)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`<a href="">`))
+	})
+
+	It("detects a partial that references itself", func() {
+		_, err := common.ParseTemplateSet(`{{template "loop" .}}`, map[string]string{
+			"loop": `{{range .}}{{template "loop" .}}{{end}}`,
+		})
+		Expect(err).To(BeAssignableToTypeOf(common.PartialCycleError{}))
+	})
+
+	It("detects partials that reference each other through the else branch", func() {
+		_, err := common.ParseTemplateSet(`hello`, map[string]string{
+			"a": `{{with .}}{{else}}{{template "b" .}}{{end}}`,
+			"b": `{{template "a" .}}`,
+		})
+		Expect(err).To(BeAssignableToTypeOf(common.PartialCycleError{}))
+	})
+
+	It("detects references to partials that do not exist", func() {
+		_, err := common.ParseTemplateSet(`{{template "nope" .}}`, map[string]string{})
+		Expect(err).To(BeAssignableToTypeOf(common.MissingPartialError{}))
+		Expect(err.Error()).To(ContainSubstring(`unknown partial "nope"`))
+	})
+
+	It("returns syntax errors from partials", func() {
+		_, err := common.ParseTemplateSet(`hello`, map[string]string{
+			"broken": `{{.Text`,
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("PartialReferences", func() {
+	It("returns the partials referenced by any of the sources", func() {
+		Expect(common.PartialReferences(
+			`{{template "header" .}} hello`,
+			`{{if .Subject}}{{template "footer" .}}{{else}}{{template "header" .}}{{end}}`,
+		)).To(Equal([]string{"footer", "header"}))
+	})
+
+	It("leaves out templates the source defines itself", func() {
+		Expect(common.PartialReferences(`{{define "local"}}hi{{end}}{{template "local" .}}{{template "footer" .}}`)).To(Equal([]string{"footer"}))
+	})
+
+	It("skips sources that do not parse", func() {
+		Expect(common.PartialReferences(`{{template "header" .`, `{{template "footer" .}}`)).To(Equal([]string{"footer"}))
+	})
+})
+
+var _ = Describe("TemplateCache", func() {
+	var cache *common.TemplateCache
+
+	BeforeEach(func() {
+		cache = common.NewTemplateCache()
+	})
+
+	It("reuses the parsed template for the same source and partials", func() {
+		first, err := cache.Parse("{{.}}", map[string]string{"footer": "footer"})
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := cache.Parse("{{.}}", map[string]string{"footer": "footer"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second == first).To(BeTrue())
+		Expect(cache.Len()).To(Equal(1))
+	})
+
+	It("parses again when a partial changes", func() {
+		first, err := cache.Parse("{{.}}", map[string]string{"footer": "footer"})
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := cache.Parse("{{.}}", map[string]string{"footer": "new footer"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second == first).To(BeFalse())
+		Expect(cache.Len()).To(Equal(2))
+	})
+
+	It("does not cache templates that fail to parse", func() {
+		_, err := cache.Parse(`{{template "missing" .}}`, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(cache.Len()).To(Equal(0))
+	})
+})
@@ -1,8 +1,10 @@
 package common
 
 import (
+	"sync"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	metrics "github.com/rcrowley/go-metrics"
 )
@@ -11,20 +13,59 @@ type uaaEmailGetter interface {
 	UsersEmailsByIDs(string, ...string) ([]uaa.User, error)
 }
 
+// UserLoader looks up users in UAA. Users that are found are remembered for
+// the cache duration, which is shared by every copy of the loader, so that
+// workers delivering to the same users do not look them up again. A cache
+// duration of zero disables the cache.
 type UserLoader struct {
-	uaaClient uaaEmailGetter
+	uaaClient     uaaEmailGetter
+	clock         clock
+	cacheDuration time.Duration
+	cache         *userCache
 }
 
-func NewUserLoader(uaaClient uaaEmailGetter) UserLoader {
+type userCache struct {
+	mutex   sync.Mutex
+	users   map[string]cachedUser
+	sweptAt time.Time
+}
+
+type cachedUser struct {
+	user      uaa.User
+	expiresAt time.Time
+}
+
+func NewUserLoader(uaaClient uaaEmailGetter, clock clock, cacheDuration time.Duration) UserLoader {
 	return UserLoader{
-		uaaClient: uaaClient,
+		uaaClient:     uaaClient,
+		clock:         clock,
+		cacheDuration: cacheDuration,
+		cache: &userCache{
+			users: map[string]cachedUser{},
+		},
 	}
 }
 
 func (loader UserLoader) Load(guids []string, token string) (map[string]uaa.User, error) {
 	users := make(map[string]uaa.User)
 
-	usersByIDs, err := loader.fetchUsersByIDs(token, guids)
+	var missing []string
+	for _, guid := range guids {
+		if user, ok := loader.cached(guid); ok {
+			users[guid] = user
+			countUserCacheLookup("hit")
+			continue
+		}
+
+		missing = append(missing, guid)
+		countUserCacheLookup("miss")
+	}
+
+	if len(missing) == 0 {
+		return users, nil
+	}
+
+	usersByIDs, err := loader.fetchUsersByIDs(token, missing)
 	if err != nil {
 		err = UAAErrorFor(err)
 		return users, err
@@ -32,6 +73,7 @@ func (loader UserLoader) Load(guids []string, token string) (map[string]uaa.User
 
 	for _, user := range usersByIDs {
 		users[user.ID] = user
+		loader.remember(user)
 	}
 
 	for _, guid := range guids {
@@ -52,3 +94,58 @@ func (loader UserLoader) fetchUsersByIDs(token string, guids []string) ([]uaa.Us
 
 	return usersByIDs, err
 }
+
+func (loader UserLoader) cached(guid string) (uaa.User, bool) {
+	if loader.cacheDuration <= 0 {
+		return uaa.User{}, false
+	}
+
+	loader.cache.mutex.Lock()
+	defer loader.cache.mutex.Unlock()
+
+	cached, ok := loader.cache.users[guid]
+	if !ok {
+		return uaa.User{}, false
+	}
+
+	if !loader.clock.Now().Before(cached.expiresAt) {
+		delete(loader.cache.users, guid)
+		return uaa.User{}, false
+	}
+
+	return cached.user, true
+}
+
+func (loader UserLoader) remember(user uaa.User) {
+	if loader.cacheDuration <= 0 {
+		return
+	}
+
+	loader.cache.mutex.Lock()
+	defer loader.cache.mutex.Unlock()
+
+	now := loader.clock.Now()
+
+	// Users that are not looked up again would otherwise stay in the cache
+	// after they expire.
+	if now.Sub(loader.cache.sweptAt) >= loader.cacheDuration {
+		for guid, cached := range loader.cache.users {
+			if !now.Before(cached.expiresAt) {
+				delete(loader.cache.users, guid)
+			}
+		}
+		loader.cache.sweptAt = now
+	}
+
+	loader.cache.users[user.ID] = cachedUser{
+		user:      user,
+		expiresAt: now.Add(loader.cacheDuration),
+	}
+}
+
+func countUserCacheLookup(result string) {
+	metrics.GetOrRegisterCounter(prometheus.Labelled("notifications.uaa.cache.lookups", prometheus.Labels{
+		"cache":  "user",
+		"result": result,
+	}), nil).Inc(1)
+}
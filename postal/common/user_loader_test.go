@@ -1,6 +1,8 @@
 package common_test
 
 import (
+	"time"
+
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
@@ -15,6 +17,8 @@ var _ = Describe("UserLoader", func() {
 		loader    common.UserLoader
 		token     string
 		uaaClient *mocks.ZonedUAAClient
+		clock     *mocks.Clock
+		now       time.Time
 	)
 
 	Describe("Load", func() {
@@ -37,7 +41,11 @@ var _ = Describe("UserLoader", func() {
 				},
 			}
 
-			loader = common.NewUserLoader(uaaClient)
+			now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+			clock = mocks.NewClock()
+			clock.NowCall.Returns.Time = now
+
+			loader = common.NewUserLoader(uaaClient, clock, time.Minute)
 		})
 
 		Context("UAA returns a collection of users", func() {
@@ -60,6 +68,76 @@ var _ = Describe("UserLoader", func() {
 			})
 		})
 
+		Context("when users have been loaded before", func() {
+			It("reuses the users that were found for the cache duration", func() {
+				_, err := loader.Load([]string{"user-123", "user-789"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				clock.NowCall.Returns.Time = now.Add(time.Minute - time.Second)
+				uaaClient.UsersEmailsByIDsCall.Returns.Users = nil
+
+				users, err := loader.Load([]string{"user-123", "user-789"}, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users["user-123"].Emails).To(Equal([]string{"user-123@example.com"}))
+				Expect(users["user-789"]).To(Equal(uaa.User{}))
+
+				Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(2))
+				Expect(uaaClient.UsersEmailsByIDsCall.Receives.IDs).To(Equal([]string{"user-789"}))
+			})
+
+			It("does not ask UAA when every user is cached", func() {
+				_, err := loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				users, err := loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users["user-123"].ID).To(Equal("user-123"))
+
+				Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(1))
+			})
+
+			It("loads the users again once the cache duration has passed", func() {
+				_, err := loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				clock.NowCall.Returns.Time = now.Add(time.Minute)
+				uaaClient.UsersEmailsByIDsCall.Returns.Users = []uaa.User{
+					{
+						Emails: []string{"new-address@example.com"},
+						ID:     "user-123",
+					},
+				}
+
+				users, err := loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users["user-123"].Emails).To(Equal([]string{"new-address@example.com"}))
+				Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(2))
+			})
+
+			It("shares the cache between copies of the loader", func() {
+				_, err := loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				otherLoader := loader
+				_, err = otherLoader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(1))
+			})
+
+			It("does not cache users when the cache duration is zero", func() {
+				loader = common.NewUserLoader(uaaClient, clock, 0)
+
+				_, err := loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = loader.Load([]string{"user-123"}, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(2))
+			})
+		})
+
 		Describe("UAA Error Responses", func() {
 			Context("when UAA cannot be reached", func() {
 				It("returns a UAADownError", func() {
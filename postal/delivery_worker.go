@@ -39,6 +39,7 @@ type DeliveryWorkerConfig struct {
 	DBTrace                bool
 	Database               db.DatabaseInterface
 	CampaignJobProcessor   campaignJobProcessor
+	FanOutJobProcessor     DeliveryJobProcessor
 	DeliveryFailureHandler deliveryFailureHandler
 	MessageStatusUpdater   messageStatusUpdater
 }
@@ -52,6 +53,7 @@ type DeliveryWorker struct {
 	logger                 lager.Logger
 	database               db.DatabaseInterface
 	campaignJobProcessor   campaignJobProcessor
+	fanOutJobProcessor     DeliveryJobProcessor
 	deliveryFailureHandler deliveryFailureHandler
 	messageStatusUpdater   messageStatusUpdater
 }
@@ -63,6 +65,7 @@ func NewDeliveryWorker(v1DeliveryJobProcessor DeliveryJobProcessor, config Deliv
 		logger:                 config.Logger,
 		database:               config.Database,
 		campaignJobProcessor:   config.CampaignJobProcessor,
+		fanOutJobProcessor:     config.FanOutJobProcessor,
 		deliveryFailureHandler: config.DeliveryFailureHandler,
 		messageStatusUpdater:   config.MessageStatusUpdater,
 	}
@@ -86,5 +89,10 @@ func (worker DeliveryWorker) Deliver(job *gobble.Job) {
 		return
 	}
 
+	if typedJob.JobType == services.JobTypeFanOut {
+		worker.fanOutJobProcessor.Process(job, worker.logger)
+		return
+	}
+
 	worker.DeliveryJobProcessor.Process(job, worker.logger)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/postal"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/pivotal-golang/lager"
 
 	. "github.com/onsi/ginkgo"
@@ -23,6 +24,7 @@ var _ = Describe("DeliveryWorker", func() {
 		queue                  *mocks.Queue
 		deliveryFailureHandler *mocks.DeliveryFailureHandler
 		v1DeliveryJobProcessor *mocks.V1DeliveryJobProcessor
+		fanOutJobProcessor     *mocks.V1DeliveryJobProcessor
 		connection             *mocks.Connection
 		messageStatusUpdater   *mocks.MessageStatusUpdater
 	)
@@ -37,6 +39,7 @@ var _ = Describe("DeliveryWorker", func() {
 		database := mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = connection
 		messageStatusUpdater = mocks.NewMessageStatusUpdater()
+		fanOutJobProcessor = mocks.NewV1DeliveryJobProcessor()
 
 		config := postal.DeliveryWorkerConfig{
 			ID:                     42,
@@ -46,6 +49,7 @@ var _ = Describe("DeliveryWorker", func() {
 			Database:               database,
 			UAAHost:                "my-uaa-host",
 			MessageStatusUpdater:   messageStatusUpdater,
+			FanOutJobProcessor:     fanOutJobProcessor,
 		}
 
 		v1DeliveryJobProcessor = mocks.NewV1DeliveryJobProcessor()
@@ -92,6 +96,20 @@ var _ = Describe("DeliveryWorker", func() {
 
 			Expect(v1DeliveryJobProcessor.ProcessCall.Receives.Job).To(Equal(job))
 			Expect(v1DeliveryJobProcessor.ProcessCall.Receives.Logger).ToNot(BeNil())
+			Expect(fanOutJobProcessor.ProcessCall.CallCount).To(Equal(0))
+		})
+
+		It("should hand fan-out jobs to the fan-out workflow", func() {
+			job = gobble.NewJob(services.FanOut{
+				JobType: services.JobTypeFanOut,
+				ID:      "some-fanout-id",
+			})
+
+			worker.Deliver(job)
+
+			Expect(fanOutJobProcessor.ProcessCall.Receives.Job).To(Equal(job))
+			Expect(fanOutJobProcessor.ProcessCall.Receives.Logger).ToNot(BeNil())
+			Expect(v1DeliveryJobProcessor.ProcessCall.CallCount).To(Equal(0))
 		})
 
 		Context("when the job cannot be unmarshalled", func() {
@@ -0,0 +1,84 @@
+package postal
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type archivedMessage struct {
+	ID         string              `json:"id"`
+	ClientID   string              `json:"client_id"`
+	KindID     string              `json:"kind_id"`
+	Status     string              `json:"status"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Recipients []archivedRecipient `json:"recipients"`
+}
+
+type archivedRecipient struct {
+	Address   string    `json:"address"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FileArchiver appends messages to a file as newline delimited JSON, one
+// object per message with its recipients, so that they can still be looked up after the
+// MessageGC has removed them from the database.
+type FileArchiver struct {
+	path string
+}
+
+func NewFileArchiver(path string) FileArchiver {
+	return FileArchiver{
+		path: path,
+	}
+}
+
+func (a FileArchiver) Archive(messages []models.Message, recipients []models.MessageRecipient) error {
+	recipientsByMessage := map[string][]archivedRecipient{}
+	for _, recipient := range recipients {
+		recipientsByMessage[recipient.MessageID] = append(recipientsByMessage[recipient.MessageID], archivedRecipient{
+			Address:   recipient.Address,
+			Type:      recipient.Type,
+			Status:    recipient.Status,
+			UpdatedAt: recipient.UpdatedAt.UTC(),
+		})
+	}
+
+	file, err := os.OpenFile(a.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, message := range messages {
+		archived := archivedMessage{
+			ID:         message.ID,
+			ClientID:   message.ClientID,
+			KindID:     message.KindID,
+			Status:     message.Status,
+			UpdatedAt:  message.UpdatedAt.UTC(),
+			Recipients: recipientsByMessage[message.ID],
+		}
+		if archived.Recipients == nil {
+			archived.Recipients = []archivedRecipient{}
+		}
+
+		err = encoder.Encode(archived)
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
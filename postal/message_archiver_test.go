@@ -0,0 +1,68 @@
+package postal_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileArchiver", func() {
+	var (
+		dir      string
+		path     string
+		archiver postal.FileArchiver
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "message-archive")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(dir, "messages.ndjson")
+		archiver = postal.NewFileArchiver(path)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("appends each message to the file as a line of JSON with its client, kind and recipients", func() {
+		updatedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		err := archiver.Archive([]models.Message{
+			{ID: "message-1", ClientID: "some-client", KindID: "some-kind", Status: "delivered", UpdatedAt: updatedAt},
+		}, []models.MessageRecipient{
+			{MessageID: "message-1", Address: "user@example.com", Type: "to", Status: "delivered", UpdatedAt: updatedAt},
+			{MessageID: "message-1", Address: "other@example.com", Type: "cc", Status: "bounced", UpdatedAt: updatedAt},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archiver.Archive([]models.Message{
+			{ID: "message-2", ClientID: "some-client", Status: "failed", UpdatedAt: updatedAt},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal(
+			`{"id":"message-1","client_id":"some-client","kind_id":"some-kind","status":"delivered","updated_at":"2026-10-19T12:00:00Z","recipients":[` +
+				`{"address":"user@example.com","type":"to","status":"delivered","updated_at":"2026-10-19T12:00:00Z"},` +
+				`{"address":"other@example.com","type":"cc","status":"bounced","updated_at":"2026-10-19T12:00:00Z"}]}` + "\n" +
+				`{"id":"message-2","client_id":"some-client","kind_id":"","status":"failed","updated_at":"2026-10-19T12:00:00Z","recipients":[]}` + "\n",
+		))
+	})
+
+	It("returns an error when the file cannot be opened", func() {
+		archiver = postal.NewFileArchiver(filepath.Join(dir, "missing", "messages.ndjson"))
+
+		err := archiver.Archive([]models.Message{{ID: "message-1"}}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
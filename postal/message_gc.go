@@ -9,43 +9,169 @@ import (
 )
 
 type messagesDeleter interface {
-	DeleteBefore(models.ConnectionInterface, time.Time) (int, error)
+	FindExpired(conn models.ConnectionInterface, policy models.RetentionPolicy, now time.Time, limit int) ([]models.Message, error)
+	Delete(conn models.ConnectionInterface, messageIDs []string) (int, error)
 }
 
+type attachmentsDeleter interface {
+	DeleteBefore(conn models.ConnectionInterface, threshold time.Time, limit int) (int, error)
+}
+
+type fanOutsDeleter interface {
+	DeleteBefore(conn models.ConnectionInterface, threshold time.Time, limit int) (int, error)
+}
+
+type recipientsFinder interface {
+	FindByMessageIDs(conn models.ConnectionInterface, messageIDs []string) ([]models.MessageRecipient, error)
+}
+
+type messageArchiver interface {
+	Archive(messages []models.Message, recipients []models.MessageRecipient) error
+}
+
+type MessageGCConfig struct {
+	Retention       models.RetentionPolicy
+	BatchSize       int
+	PollingInterval time.Duration
+
+	Database    db.DatabaseInterface
+	Messages    messagesDeleter
+	Attachments attachmentsDeleter
+	FanOuts     fanOutsDeleter
+	Recipients  recipientsFinder
+	Archiver    messageArchiver
+	Logger      *log.Logger
+}
+
+// MessageGC removes messages that have outlived the retention policy, along
+// with finished fan-outs older than its default lifetime and the attachments
+// that no remaining message or fan-out references. Records are deleted in
+// batches of at most BatchSize so that the tables are never locked for long.
+// When an Archiver is configured, each batch of messages is handed to it,
+// together with the recipients of those messages, before it is deleted.
 type MessageGC struct {
-	messages        messagesDeleter
-	db              db.DatabaseInterface
-	lifetime        time.Duration
-	logger          *log.Logger
-	timer           <-chan time.Time
+	retention       models.RetentionPolicy
+	batchSize       int
 	pollingInterval time.Duration
+
+	db          db.DatabaseInterface
+	messages    messagesDeleter
+	attachments attachmentsDeleter
+	fanOuts     fanOutsDeleter
+	recipients  recipientsFinder
+	archiver    messageArchiver
+	logger      *log.Logger
+	timer       <-chan time.Time
 }
 
-func NewMessageGC(lifetime time.Duration, db db.DatabaseInterface, messages messagesDeleter, pollingInterval time.Duration, logger *log.Logger) MessageGC {
+func NewMessageGC(config MessageGCConfig) MessageGC {
 	return MessageGC{
-		messages:        messages,
-		db:              db,
-		lifetime:        lifetime,
-		logger:          logger,
-		pollingInterval: pollingInterval,
-		timer:           time.After(0),
+		retention:       config.Retention,
+		batchSize:       config.BatchSize,
+		pollingInterval: config.PollingInterval,
+
+		db:          config.Database,
+		messages:    config.Messages,
+		attachments: config.Attachments,
+		fanOuts:     config.FanOuts,
+		recipients:  config.Recipients,
+		archiver:    config.Archiver,
+		logger:      config.Logger,
+		timer:       time.After(0),
 	}
 }
 
 func (gc MessageGC) Collect() {
-	threshold := time.Now().Add(-1 * gc.lifetime)
-	_, err := gc.messages.DeleteBefore(gc.db.Connection(), threshold)
-	if err != nil {
-		gc.logger.Printf("MessageGC.Collect() failed: " + err.Error())
+	now := time.Now()
+
+	gc.collectMessages(now)
+	gc.collectAttachments(now.Add(-1 * gc.retention.Default))
+	gc.collectFanOuts(now.Add(-1 * gc.retention.Default))
+}
+
+func (gc MessageGC) collectMessages(now time.Time) {
+	for {
+		messages, err := gc.messages.FindExpired(gc.db.Connection(), gc.retention, now, gc.batchSize)
+		if err != nil {
+			gc.logger.Printf("MessageGC.Collect() failed: " + err.Error())
+			return
+		}
+
+		if len(messages) == 0 {
+			return
+		}
+
+		var messageIDs []string
+		for _, message := range messages {
+			messageIDs = append(messageIDs, message.ID)
+		}
+
+		if gc.archiver != nil {
+			recipients, err := gc.recipients.FindByMessageIDs(gc.db.Connection(), messageIDs)
+			if err != nil {
+				gc.logger.Printf("MessageGC.Collect() failed to find the recipients of messages: " + err.Error())
+				return
+			}
+
+			err = gc.archiver.Archive(messages, recipients)
+			if err != nil {
+				gc.logger.Printf("MessageGC.Collect() failed to archive messages: " + err.Error())
+				return
+			}
+		}
+
+		_, err = gc.messages.Delete(gc.db.Connection(), messageIDs)
+		if err != nil {
+			gc.logger.Printf("MessageGC.Collect() failed: " + err.Error())
+			return
+		}
+
+		if len(messages) < gc.batchSize {
+			return
+		}
+	}
+}
+
+func (gc MessageGC) collectAttachments(threshold time.Time) {
+	for {
+		count, err := gc.attachments.DeleteBefore(gc.db.Connection(), threshold, gc.batchSize)
+		if err != nil {
+			gc.logger.Printf("MessageGC.Collect() failed to delete attachments: " + err.Error())
+			return
+		}
+
+		if count < gc.batchSize {
+			return
+		}
+	}
+}
+
+func (gc MessageGC) collectFanOuts(threshold time.Time) {
+	for {
+		count, err := gc.fanOuts.DeleteBefore(gc.db.Connection(), threshold, gc.batchSize)
+		if err != nil {
+			gc.logger.Printf("MessageGC.Collect() failed to delete fan-outs: " + err.Error())
+			return
+		}
+
+		if count < gc.batchSize {
+			return
+		}
 	}
 }
 
-func (gc MessageGC) Run() {
+// Run collects expired messages every polling interval, starting straight
+// away, until stop is closed.
+func (gc MessageGC) Run(stop <-chan struct{}) {
 	go func() {
 		for {
-			<-gc.timer
-			gc.Collect()
-			gc.timer = time.After(gc.pollingInterval)
+			select {
+			case <-gc.timer:
+				gc.Collect()
+				gc.timer = time.After(gc.pollingInterval)
+			case <-stop:
+				return
+			}
 		}
 	}()
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/postal"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -18,10 +19,15 @@ var _ = Describe("MessageGC", func() {
 	var (
 		messageGC       postal.MessageGC
 		repo            *mocks.MessagesRepo
+		attachmentsRepo *mocks.AttachmentsRepo
+		fanOutsRepo     *mocks.FanOutsRepo
+		recipientsRepo  *mocks.MessageRecipientsRepo
+		archiver        *mocks.MessageArchiver
 		database        *mocks.Database
 		conn            db.ConnectionInterface
 		loggerBuffer    *bytes.Buffer
-		lifetime        time.Duration
+		config          postal.MessageGCConfig
+		retention       models.RetentionPolicy
 		pollingInterval time.Duration
 	)
 
@@ -34,45 +40,197 @@ var _ = Describe("MessageGC", func() {
 		database.ConnectionCall.Returns.Connection = conn
 
 		repo = mocks.NewMessagesRepo()
-
-		lifetime = 2 * time.Minute
+		attachmentsRepo = mocks.NewAttachmentsRepo()
+		fanOutsRepo = mocks.NewFanOutsRepo()
+		recipientsRepo = mocks.NewMessageRecipientsRepo()
+		archiver = mocks.NewMessageArchiver()
+
+		retention = models.RetentionPolicy{
+			Default: 2 * time.Minute,
+			ByStatus: map[string]time.Duration{
+				"failed": 10 * time.Minute,
+			},
+		}
 		pollingInterval = 500 * time.Millisecond
 
-		messageGC = postal.NewMessageGC(lifetime, database, repo, pollingInterval, logger)
+		config = postal.MessageGCConfig{
+			Retention:       retention,
+			BatchSize:       2,
+			PollingInterval: pollingInterval,
+
+			Database:    database,
+			Messages:    repo,
+			Attachments: attachmentsRepo,
+			FanOuts:     fanOutsRepo,
+			Logger:      logger,
+		}
+
+		messageGC = postal.NewMessageGC(config)
 	})
 
 	Describe("Run", func() {
+		var stop chan struct{}
+
+		BeforeEach(func() {
+			stop = make(chan struct{})
+		})
+
 		It("It calls collect every passed in duration", func() {
-			messageGC.Run()
+			messageGC.Run(stop)
+			defer close(stop)
 
 			Eventually(func() int {
-				return repo.DeleteBeforeCall.CallCount
+				return repo.FindExpiredCall.CallCount
 			}).Should(BeNumerically(">=", 2))
 
-			call1 := repo.DeleteBeforeCall.InvocationTimes[0]
-			call2 := repo.DeleteBeforeCall.InvocationTimes[1]
+			call1 := repo.FindExpiredCall.InvocationTimes[0]
+			call2 := repo.FindExpiredCall.InvocationTimes[1]
 			Expect(call2).To(BeTemporally(">", call1.Add(pollingInterval-50*time.Millisecond)))
 			Expect(call2).To(BeTemporally("<", call1.Add(pollingInterval+50*time.Millisecond)))
 		})
+
+		It("stops collecting once stop is closed", func() {
+			messageGC.Run(stop)
+
+			Eventually(func() int {
+				return repo.FindExpiredCall.CallCount
+			}).Should(Equal(1))
+
+			close(stop)
+
+			Consistently(func() int {
+				return repo.FindExpiredCall.CallCount
+			}, pollingInterval+200*time.Millisecond).Should(Equal(1))
+		})
 	})
 
 	Describe("Collect", func() {
-		It("Deletes message statuses older than the specified time", func() {
+		It("Deletes messages that have outlived the retention policy", func() {
+			repo.FindExpiredCall.Returns.Batches = [][]models.Message{
+				{{ID: "message-1"}},
+			}
+
+			messageGC.Collect()
+
+			Expect(repo.FindExpiredCall.Receives.Connection).To(Equal(conn))
+			Expect(repo.FindExpiredCall.Receives.Policy).To(Equal(retention))
+			Expect(repo.FindExpiredCall.Receives.Now).To(BeTemporally("~", time.Now(), 10*time.Second))
+			Expect(repo.FindExpiredCall.Receives.Limit).To(Equal(2))
+
+			Expect(repo.DeleteCall.Receives.Connection).To(Equal(conn))
+			Expect(repo.DeleteCall.Receives.MessageIDs).To(Equal([][]string{{"message-1"}}))
+		})
+
+		It("Deletes messages in batches until there are none left", func() {
+			repo.FindExpiredCall.Returns.Batches = [][]models.Message{
+				{{ID: "message-1"}, {ID: "message-2"}},
+				{{ID: "message-3"}, {ID: "message-4"}},
+			}
+
+			messageGC.Collect()
+
+			Expect(repo.FindExpiredCall.CallCount).To(Equal(3))
+			Expect(repo.DeleteCall.Receives.MessageIDs).To(Equal([][]string{
+				{"message-1", "message-2"},
+				{"message-3", "message-4"},
+			}))
+		})
+
+		It("Deletes attachments older than the default lifetime in batches", func() {
+			attachmentsRepo.DeleteBeforeCall.Returns.RowsAffected = []int{2, 1}
+
+			messageGC.Collect()
+
+			Expect(attachmentsRepo.DeleteBeforeCall.CallCount).To(Equal(2))
+			Expect(attachmentsRepo.DeleteBeforeCall.Receives.Connection).To(Equal(conn))
+			Expect(attachmentsRepo.DeleteBeforeCall.Receives.ThresholdTime).To(BeTemporally("~", time.Now().Add(-2*time.Minute), 10*time.Second))
+			Expect(attachmentsRepo.DeleteBeforeCall.Receives.Limit).To(Equal(2))
+		})
+
+		It("Deletes fan-outs older than the default lifetime in batches", func() {
+			fanOutsRepo.DeleteBeforeCall.Returns.RowsAffected = []int{2, 1}
+
 			messageGC.Collect()
 
-			Expect(repo.DeleteBeforeCall.Receives.Connection).To(Equal(conn))
-			Expect(repo.DeleteBeforeCall.Receives.ThresholdTime).To(BeTemporally("~", time.Now().Add(-2*time.Minute), 10*time.Second))
+			Expect(fanOutsRepo.DeleteBeforeCall.CallCount).To(Equal(2))
+			Expect(fanOutsRepo.DeleteBeforeCall.Receives.Connection).To(Equal(conn))
+			Expect(fanOutsRepo.DeleteBeforeCall.Receives.ThresholdTime).To(BeTemporally("~", time.Now().Add(-2*time.Minute), 10*time.Second))
+			Expect(fanOutsRepo.DeleteBeforeCall.Receives.Limit).To(Equal(2))
+		})
+
+		Context("when an archiver is configured", func() {
+			BeforeEach(func() {
+				config.Recipients = recipientsRepo
+				config.Archiver = archiver
+				messageGC = postal.NewMessageGC(config)
+
+				repo.FindExpiredCall.Returns.Batches = [][]models.Message{
+					{{ID: "message-1"}, {ID: "message-2"}},
+					{{ID: "message-3"}},
+				}
+			})
+
+			It("archives each batch before deleting it", func() {
+				messageGC.Collect()
+
+				Expect(archiver.ArchiveCall.Receives.Batches).To(Equal([][]models.Message{
+					{{ID: "message-1"}, {ID: "message-2"}},
+					{{ID: "message-3"}},
+				}))
+				Expect(repo.DeleteCall.CallCount).To(Equal(2))
+			})
+
+			It("archives the recipients of each batch along with it", func() {
+				recipientsRepo.FindByMessageIDsCall.Returns.Recipients = []models.MessageRecipient{
+					{MessageID: "message-3", Address: "user@example.com", Type: "to", Status: "delivered"},
+				}
+
+				messageGC.Collect()
+
+				Expect(recipientsRepo.FindByMessageIDsCall.CallCount).To(Equal(2))
+				Expect(recipientsRepo.FindByMessageIDsCall.Receives.Connection).To(Equal(conn))
+				Expect(recipientsRepo.FindByMessageIDsCall.Receives.MessageIDs).To(Equal([]string{"message-3"}))
+				Expect(archiver.ArchiveCall.Receives.Recipients[1]).To(Equal([]models.MessageRecipient{
+					{MessageID: "message-3", Address: "user@example.com", Type: "to", Status: "delivered"},
+				}))
+			})
+
+			It("does not delete messages whose recipients could not be found", func() {
+				recipientsRepo.FindByMessageIDsCall.Returns.Error = errors.New("recipients table is locked")
+
+				messageGC.Collect()
+
+				Expect(archiver.ArchiveCall.CallCount).To(BeZero())
+				Expect(repo.DeleteCall.CallCount).To(BeZero())
+				Expect(loggerBuffer.String()).To(ContainSubstring("recipients table is locked"))
+			})
+
+			It("does not delete messages that could not be archived", func() {
+				archiver.ArchiveCall.Returns.Error = errors.New("disk is full")
+
+				messageGC.Collect()
+
+				Expect(repo.DeleteCall.CallCount).To(BeZero())
+				Expect(loggerBuffer.String()).To(ContainSubstring("disk is full"))
+			})
 		})
 
 		Context("When the repo errors unexpectantly", func() {
 			It("logs the error", func() {
-				repo.DeleteBeforeCall.Returns.Error = errors.New("messages table is totally corrupt")
+				repo.FindExpiredCall.Returns.Error = errors.New("messages table is totally corrupt")
 
 				messageGC.Collect()
 
 				Expect(loggerBuffer.String()).To(ContainSubstring("messages table is totally corrupt"))
 			})
-		})
 
+			It("still deletes the attachments", func() {
+				repo.FindExpiredCall.Returns.Error = errors.New("messages table is totally corrupt")
+
+				messageGC.Collect()
+
+				Expect(attachmentsRepo.DeleteBeforeCall.CallCount).To(Equal(1))
+			})
+		})
 	})
 })
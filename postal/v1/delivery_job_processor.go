@@ -1,12 +1,18 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/lager"
@@ -50,6 +56,30 @@ type globalUnsubscribesGetter interface {
 	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
 }
 
+type attachmentsFinder interface {
+	FindByID(connection models.ConnectionInterface, attachmentID string) (models.Attachment, error)
+}
+
+type recipientStatusUpdater interface {
+	UpdateStatus(connection models.ConnectionInterface, messageID, address, status string) error
+}
+
+type suppressionsMatcher interface {
+	Match(connection models.ConnectionInterface, addresses []string, userGUID string) (map[string][]models.Suppression, error)
+}
+
+type deliveryThrottle interface {
+	Reserve(addresses []string) time.Duration
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type spanStarter interface {
+	Start(name, kind string, parent tracing.SpanContext) *tracing.Span
+}
+
 type DeliveryJobProcessorConfig struct {
 	DBTrace bool
 	UAAHost string
@@ -66,8 +96,14 @@ type DeliveryJobProcessorConfig struct {
 	ReceiptsRepo           receiptsCreator
 	UnsubscribesRepo       unsubscribesGetter
 	GlobalUnsubscribesRepo globalUnsubscribesGetter
+	AttachmentsRepo        attachmentsFinder
+	RecipientsRepo         recipientStatusUpdater
+	SuppressionsRepo       suppressionsMatcher
 	MessageStatusUpdater   messageStatusUpdater
 	DeliveryFailureHandler deliveryFailureHandler
+	DomainThrottle         deliveryThrottle
+	Tracer                 spanStarter
+	Clock                  clock
 }
 
 type DeliveryJobProcessor struct {
@@ -86,8 +122,14 @@ type DeliveryJobProcessor struct {
 	receiptsRepo           receiptsCreator
 	unsubscribesRepo       unsubscribesGetter
 	globalUnsubscribesRepo globalUnsubscribesGetter
+	attachmentsRepo        attachmentsFinder
+	recipientsRepo         recipientStatusUpdater
+	suppressionsRepo       suppressionsMatcher
 	messageStatusUpdater   messageStatusUpdater
 	deliveryFailureHandler deliveryFailureHandler
+	domainThrottle         deliveryThrottle
+	tracer                 spanStarter
+	clock                  clock
 }
 
 func NewDeliveryJobProcessor(config DeliveryJobProcessorConfig) DeliveryJobProcessor {
@@ -107,8 +149,14 @@ func NewDeliveryJobProcessor(config DeliveryJobProcessorConfig) DeliveryJobProce
 		receiptsRepo:           config.ReceiptsRepo,
 		unsubscribesRepo:       config.UnsubscribesRepo,
 		globalUnsubscribesRepo: config.GlobalUnsubscribesRepo,
+		attachmentsRepo:        config.AttachmentsRepo,
+		recipientsRepo:         config.RecipientsRepo,
+		suppressionsRepo:       config.SuppressionsRepo,
 		messageStatusUpdater:   config.MessageStatusUpdater,
 		deliveryFailureHandler: config.DeliveryFailureHandler,
+		domainThrottle:         config.DomainThrottle,
+		tracer:                 config.Tracer,
+		clock:                  config.Clock,
 	}
 }
 
@@ -127,129 +175,378 @@ func (p DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) erro
 		"vcap_request_id": delivery.VCAPRequestID,
 	})
 
+	// Deliveries enqueued before tracing was enabled, or by requests without
+	// a span, start a trace of their own.
+	parent, _ := tracing.ParseTraceParent(delivery.TraceParent)
+	span := p.tracer.Start("deliver", tracing.SpanKindConsumer, parent)
+	span.SetAttribute("message_id", delivery.MessageID)
+	span.SetAttribute("client_id", delivery.ClientID)
+	span.SetAttribute("kind_id", delivery.Options.KindID)
+	defer span.End()
+
 	if p.dbTrace {
 		p.database.TraceOn("", gorpCompatibleLogger{logger})
 	}
 
-	err = p.receiptsRepo.CreateReceipts(p.database.Connection(), []string{delivery.UserGUID}, delivery.ClientID, delivery.Options.KindID)
-	if err != nil {
-		p.deliveryFailureHandler.Handle(job, logger)
-		return nil
-	}
-
-	if delivery.Email == "" {
-		var token string
-
-		token, err = p.tokenLoader.Load(p.uaaHost)
+	// The address of a user is looked up before the domain throttle is
+	// reserved, since its domain is not known until then. A delivery that
+	// has to wait keeps the address, so that it is only looked up once.
+	lookedUp := delivery.Email == ""
+	if lookedUp {
+		delivery.Email, err = p.loadEmail(delivery.UserGUID, span.Context)
 		if err != nil {
+			span.RecordError(err)
 			p.deliveryFailureHandler.Handle(job, logger)
 			return nil
 		}
-
-		users, err := p.userLoader.Load([]string{delivery.UserGUID}, token)
-		if err != nil || len(users) < 1 {
-			p.deliveryFailureHandler.Handle(job, logger)
-			return nil
-		}
-
-		emails := users[delivery.UserGUID].Emails
-		if len(emails) > 0 {
-			delivery.Email = emails[0]
-		}
 	}
 
 	logger = logger.WithData(lager.Data{
 		"recipient": delivery.Email,
 	})
 
-	if p.shouldDeliver(delivery, logger) {
-		status := p.process(delivery, logger)
+	deliver, suppressed, err := p.shouldDeliver(delivery, logger)
+	if err != nil {
+		span.RecordError(err)
+		p.deliveryFailureHandler.Handle(job, logger)
+		return nil
+	}
 
-		if status != common.StatusDelivered {
-			p.deliveryFailureHandler.Handle(job, logger)
+	if deliver {
+		wait := p.domainThrottle.Reserve(withoutSuppressed(envelopeAddresses(delivery), suppressed))
+		if wait > 0 {
+			if lookedUp {
+				p.keepEmail(job, delivery, logger)
+			}
+			job.Defer(p.clock.Now().Add(wait))
+
+			logger.Info("delivery-deferred", lager.Data{
+				"active_at": job.ActiveAt.Format(time.RFC3339),
+			})
+			span.SetAttribute("deferred_until", job.ActiveAt.Format(time.RFC3339))
+
+			metrics.GetOrRegisterCounter("notifications.worker.deferred", nil).Inc(1)
+			metrics.GetOrRegisterTimer("notifications.worker.deferral", nil).Update(wait)
 			return nil
-		} else {
+		}
+	}
+
+	err = p.receiptsRepo.CreateReceipts(p.database.Connection(), []string{delivery.UserGUID}, delivery.ClientID, delivery.Options.KindID)
+	if err != nil {
+		span.RecordError(err)
+		p.deliveryFailureHandler.Handle(job, logger)
+		return nil
+	}
+
+	if deliver {
+		status := p.process(delivery, suppressed, span.Context, logger)
+		countDelivery(delivery, status)
+		span.SetAttribute("status", status)
+
+		switch status {
+		case common.StatusDelivered:
 			metrics.GetOrRegisterCounter("notifications.worker.delivered", nil).Inc(1)
+		case common.StatusUndeliverable:
+			metrics.GetOrRegisterCounter("notifications.worker.undeliverable", nil).Inc(1)
+		default:
+			p.deliveryFailureHandler.Handle(job, logger)
 		}
 	} else {
+		countDelivery(delivery, common.StatusUndeliverable)
+		span.SetAttribute("status", common.StatusUndeliverable)
 		metrics.GetOrRegisterCounter("notifications.worker.unsubscribed", nil).Inc(1)
 	}
 
 	return nil
 }
 
-func (p DeliveryJobProcessor) process(delivery common.Delivery, logger lager.Logger) string {
-	context, err := p.packager.PrepareContext(delivery, p.sender, p.domain)
+// keepEmail stores the address that was looked up for a delivery in its job,
+// so that it is not looked up again when the job is performed later.
+func (p DeliveryJobProcessor) keepEmail(job *gobble.Job, delivery common.Delivery, logger lager.Logger) {
+	payload, err := json.Marshal(delivery)
 	if err != nil {
-		panic(err)
+		logger.Error("delivery-marshal-failed", err)
+		return
 	}
 
-	message, err := p.packager.Pack(context)
+	job.Payload = string(payload)
+}
+
+// countDelivery records the outcome of a delivery in a series labelled with
+// the client, kind and status, so that they can be told apart in Prometheus.
+func countDelivery(delivery common.Delivery, status string) {
+	metrics.GetOrRegisterCounter(prometheus.Labelled("notifications.worker.deliveries", prometheus.Labels{
+		"client_id": delivery.ClientID,
+		"kind_id":   delivery.Options.KindID,
+		"status":    status,
+	}), nil).Inc(1)
+}
+
+// loadEmail looks up the address of a user that a notification was sent to
+// by ID.
+func (p DeliveryJobProcessor) loadEmail(userGUID string, trace tracing.SpanContext) (string, error) {
+	span := p.tracer.Start("load-user", tracing.SpanKindClient, trace)
+	span.SetAttribute("user_guid", userGUID)
+	defer span.End()
+
+	token, err := p.tokenLoader.Load(p.uaaHost)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	users, err := p.userLoader.Load([]string{userGUID}, token)
+	if err == nil && len(users) < 1 {
+		err = fmt.Errorf("user %q could not be found", userGUID)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	emails := users[userGUID].Emails
+	if len(emails) > 0 {
+		return emails[0], nil
+	}
+
+	return "", nil
+}
+
+func (p DeliveryJobProcessor) process(delivery common.Delivery, suppressed map[string]bool, trace tracing.SpanContext, logger lager.Logger) string {
+	message, err := p.pack(delivery, trace)
 	if err != nil {
 		logger.Info("template-pack-failed")
 		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusFailed, "", logger)
 		return common.StatusFailed
 	}
 
-	status := p.sendMail(delivery.MessageID, message, logger)
+	message.Recipients = withoutSuppressed(message.Recipients, suppressed)
+
+	message.Attachments, err = p.loadAttachments(delivery.Options.Attachments)
+	if err != nil {
+		logger.Error("attachment-load-failed", err)
+		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusFailed, "", logger)
+		return common.StatusFailed
+	}
+
+	status, rejected := p.sendMail(delivery.MessageID, message, trace, logger)
 	p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, status, "", logger)
+	p.updateRecipients(delivery, status, rejected, suppressed, logger)
 
 	return status
 }
 
-func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, logger lager.Logger) bool {
+// updateRecipients records the outcome for each recipient of a message that
+// was sent to several addresses at once. Recipients the SMTP server rejected
+// for good and suppressed recipients are marked as undeliverable, those it
+// rejected temporarily as failed, and all others share the status of the
+// message.
+func (p DeliveryJobProcessor) updateRecipients(delivery common.Delivery, status string, rejected map[string]error, suppressed map[string]bool, logger lager.Logger) {
+	for _, recipient := range delivery.Options.Recipients {
+		recipientStatus := status
+		if err, ok := rejected[recipient.Address]; ok {
+			recipientStatus = common.StatusFailed
+			if mail.IsPermanent(err) {
+				recipientStatus = common.StatusUndeliverable
+			}
+		}
+
+		if suppressed[recipient.Address] {
+			recipientStatus = common.StatusUndeliverable
+		}
+
+		err := p.recipientsRepo.UpdateStatus(p.database.Connection(), delivery.MessageID, recipient.Address, recipientStatus)
+		if err != nil {
+			logger.Error("failed-recipient-status-update", err, lager.Data{
+				"address": recipient.Address,
+				"status":  recipientStatus,
+			})
+		}
+	}
+}
+
+func (p DeliveryJobProcessor) pack(delivery common.Delivery, trace tracing.SpanContext) (mail.Message, error) {
+	span := p.tracer.Start("pack-template", tracing.SpanKindInternal, trace)
+	span.SetAttribute("template_id", delivery.Options.TemplateID)
+	defer span.End()
+
+	context, err := p.packager.PrepareContext(delivery, p.sender, p.domain)
+	if err != nil {
+		panic(err)
+	}
+
+	message, err := p.packager.Pack(context)
+	span.RecordError(err)
+
+	return message, err
+}
+
+func (p DeliveryJobProcessor) loadAttachments(attachments []common.Attachment) ([]mail.Attachment, error) {
+	var loaded []mail.Attachment
+
+	for _, attachment := range attachments {
+		record, err := p.attachmentsRepo.FindByID(p.database.Connection(), attachment.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		loaded = append(loaded, mail.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			ContentID:   attachment.ContentID,
+			Inline:      attachment.Inline,
+			Content:     record.Content,
+		})
+	}
+
+	return loaded, nil
+}
+
+// shouldDeliver also returns the recipients of a message sent to several
+// addresses that are on the suppression list, which are left out when the
+// message is sent to the others. Critical notifications are only held back
+// by hard suppressions. The suppression list failing to load is returned as
+// an error, so that the delivery is retried rather than dropped.
+func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, logger lager.Logger) (bool, map[string]bool, error) {
 	conn := p.database.Connection()
-	if p.isCritical(conn, delivery.Options.KindID, delivery.ClientID) {
-		return true
+	critical := p.isCritical(conn, delivery.Options.KindID, delivery.ClientID)
+
+	suppressed, err := p.suppressedRecipients(conn, delivery, critical)
+	if err != nil {
+		logger.Error("suppressions-lookup-failed", err)
+		return false, nil, err
+	}
+
+	if len(suppressed) == len(envelopeAddresses(delivery)) {
+		logger.Info("address-suppressed")
+		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
+		return false, nil, nil
+	}
+
+	if critical {
+		return true, suppressed, nil
 	}
 
 	globallyUnsubscribed, err := p.globalUnsubscribesRepo.Get(conn, delivery.UserGUID)
 	if err != nil || globallyUnsubscribed {
 		logger.Info("user-unsubscribed")
 		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
-		return false
+		return false, nil, nil
 	}
 
 	isUnsubscribed, err := p.unsubscribesRepo.Get(conn, delivery.UserGUID, delivery.ClientID, delivery.Options.KindID)
 	if err != nil || isUnsubscribed {
 		logger.Info("user-unsubscribed")
 		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
-		return false
+		return false, nil, nil
 	}
 
 	if delivery.Email == "" {
 		logger.Info("no-email-address-for-user")
 		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
-		return false
+		return false, nil, nil
 	}
 
 	if !strings.Contains(delivery.Email, "@") {
 		logger.Info("malformatted-email-address")
 		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
-		return false
+		return false, nil, nil
+	}
+
+	return true, suppressed, nil
+}
+
+func (p DeliveryJobProcessor) suppressedRecipients(conn models.ConnectionInterface, delivery common.Delivery, critical bool) (map[string]bool, error) {
+	matched, err := p.suppressionsRepo.Match(conn, envelopeAddresses(delivery), delivery.UserGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed := map[string]bool{}
+	for address, suppressions := range matched {
+		for _, suppression := range suppressions {
+			if suppression.Hard || !critical {
+				suppressed[address] = true
+			}
+		}
+	}
+
+	return suppressed, nil
+}
+
+func envelopeAddresses(delivery common.Delivery) []string {
+	if len(delivery.Options.Recipients) == 0 {
+		return []string{delivery.Email}
+	}
+
+	var addresses []string
+	for _, recipient := range delivery.Options.Recipients {
+		addresses = append(addresses, recipient.Address)
 	}
 
-	return true
+	return addresses
 }
 
-func (p DeliveryJobProcessor) sendMail(messageID string, message mail.Message, logger lager.Logger) string {
+func withoutSuppressed(addresses []string, suppressed map[string]bool) []string {
+	if len(suppressed) == 0 {
+		return addresses
+	}
+
+	var remaining []string
+	for _, address := range addresses {
+		if !suppressed[address] {
+			remaining = append(remaining, address)
+		}
+	}
+
+	return remaining
+}
+
+// sendMail returns the status of the message along with the recipients the
+// SMTP server rejected. A message counts as delivered as long as at least one
+// of its recipients was accepted, so that it is not sent again to the others.
+// Permanent SMTP failures make the message undeliverable so that it is not
+// retried, while temporary ones and network errors leave it failed.
+func (p DeliveryJobProcessor) sendMail(messageID string, message mail.Message, trace tracing.SpanContext, logger lager.Logger) (string, map[string]error) {
+	span := p.tracer.Start("smtp-send", tracing.SpanKindClient, trace)
+	span.SetAttribute("recipients", strconv.Itoa(len(message.EnvelopeRecipients())))
+	defer span.End()
+
 	err := p.mailClient.Connect(logger)
 	if err != nil {
+		span.RecordError(err)
 		logger.Error("smtp-connection-error", err)
-		return common.StatusFailed
+		return common.StatusFailed, nil
 	}
 
 	logger.Info("delivery-start")
 
 	err = p.mailClient.Send(message, logger)
+	span.RecordError(err)
+	if recipientsErr, ok := err.(mail.RecipientsError); ok && len(recipientsErr.Accepted) > 0 {
+		logger.Error("delivery-partially-rejected", err)
+		return common.StatusDelivered, recipientsErr.Rejected
+	}
+
+	if err != nil && mail.IsPermanent(err) {
+		logger.Error("delivery-rejected-smtp-error", err)
+
+		var rejected map[string]error
+		if recipientsErr, ok := err.(mail.RecipientsError); ok {
+			rejected = recipientsErr.Rejected
+		}
+
+		return common.StatusUndeliverable, rejected
+	}
+
 	if err != nil {
 		logger.Error("delivery-failed-smtp-error", err)
-		return common.StatusFailed
+		return common.StatusFailed, nil
 	}
 
 	logger.Info("message-sent")
 
-	return common.StatusDelivered
+	return common.StatusDelivered, nil
 }
 
 func (p DeliveryJobProcessor) isCritical(conn db.ConnectionInterface, kindID, clientID string) bool {
@@ -3,6 +3,7 @@ package v1_test
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/rand"
 	"errors"
 	"strings"
 	"time"
@@ -11,11 +12,14 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/postal/v1"
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/conceal"
 	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,6 +46,13 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		messageID              string
 		messageStatusUpdater   *mocks.MessageStatusUpdater
 		deliveryFailureHandler *mocks.DeliveryFailureHandler
+		attachmentsRepo        *mocks.AttachmentsRepo
+		recipientsRepo         *mocks.MessageRecipientsRepo
+		suppressionsRepo       *mocks.SuppressionsRepo
+		domainThrottle         *mocks.DomainThrottle
+		clock                  *mocks.Clock
+		spanExporter           *mocks.SpanExporter
+		tracer                 *tracing.Tracer
 	)
 
 	BeforeEach(func() {
@@ -87,6 +98,14 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		receiptsRepo = mocks.NewReceiptsRepo()
 		messageStatusUpdater = mocks.NewMessageStatusUpdater()
 		deliveryFailureHandler = mocks.NewDeliveryFailureHandler()
+		attachmentsRepo = mocks.NewAttachmentsRepo()
+		recipientsRepo = mocks.NewMessageRecipientsRepo()
+		suppressionsRepo = mocks.NewSuppressionsRepo()
+		domainThrottle = mocks.NewDomainThrottle()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		spanExporter = mocks.NewSpanExporter()
+		tracer = tracing.NewTracer(spanExporter, mocks.NewClock(), rand.Reader)
 
 		cloak, err := conceal.NewCloak(encryptionKey)
 		Expect(err).NotTo(HaveOccurred())
@@ -107,8 +126,14 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			ReceiptsRepo:           receiptsRepo,
 			UnsubscribesRepo:       unsubscribesRepo,
 			GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+			AttachmentsRepo:        attachmentsRepo,
+			RecipientsRepo:         recipientsRepo,
+			SuppressionsRepo:       suppressionsRepo,
 			MessageStatusUpdater:   messageStatusUpdater,
 			DeliveryFailureHandler: deliveryFailureHandler,
+			DomainThrottle:         domainThrottle,
+			Tracer:                 tracer,
+			Clock:                  clock,
 		})
 
 		messageID = "randomly-generated-guid"
@@ -203,8 +228,13 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				ReceiptsRepo:           receiptsRepo,
 				UnsubscribesRepo:       unsubscribesRepo,
 				GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+				AttachmentsRepo:        attachmentsRepo,
+				SuppressionsRepo:       suppressionsRepo,
 				MessageStatusUpdater:   messageStatusUpdater,
 				DeliveryFailureHandler: deliveryFailureHandler,
+				DomainThrottle:         domainThrottle,
+				Tracer:                 tracer,
+				Clock:                  clock,
 			})
 			processor.Process(job, logger)
 
@@ -227,6 +257,19 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 		})
 
+		It("counts the delivery by client, kind and status", func() {
+			counter := metrics.GetOrRegisterCounter(prometheus.Labelled("notifications.worker.deliveries", prometheus.Labels{
+				"client_id": "some-client",
+				"kind_id":   "some-kind",
+				"status":    common.StatusDelivered,
+			}), nil)
+			count := counter.Count()
+
+			processor.Process(job, logger)
+
+			Expect(counter.Count()).To(Equal(count + 1))
+		})
+
 		It("creates a reciept for the delivery", func() {
 			processor.Process(job, logger)
 
@@ -341,6 +384,67 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				})
 			})
 
+			Context("because the SMTP server rejected the message for good", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.SMTPError{
+						Command: "DATA",
+						Code:    554,
+						Message: "Message rejected as spam",
+					}
+				})
+
+				It("does not retry the job", func() {
+					processor.Process(job, logger)
+
+					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(BeNil())
+				})
+
+				It("logs the rejection", func() {
+					processor.Process(job, logger)
+
+					lines, err := parseLogLines(buffer.Bytes())
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(lines).To(ContainElement(logLine{
+						Source:   "notifications",
+						Message:  "notifications.worker.delivery-rejected-smtp-error",
+						LogLevel: int(lager.ERROR),
+						Data: map[string]interface{}{
+							"session":         "1",
+							"error":           "554 Message rejected as spam",
+							"recipient":       "user-123@example.com",
+							"worker_id":       float64(1234),
+							"message_id":      "randomly-generated-guid",
+							"vcap_request_id": "some-request-id",
+						},
+					}))
+				})
+
+				It("updates the message status as undeliverable", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				})
+			})
+
+			Context("because the SMTP server rejected the message temporarily", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.SMTPError{
+						Command: "MAIL",
+						Code:    421,
+						Message: "Service not available",
+					}
+				})
+
+				It("marks the job for retry and the message as failed", func() {
+					processor.Process(job, logger)
+
+					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+				})
+			})
+
 			Context("and the error is a connect error", func() {
 				It("logs an SMTP connection error", func() {
 					mailClient.ConnectCall.Returns.Error = errors.New("server timeout")
@@ -420,6 +524,72 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			})
 		})
 
+		Context("when the recipient's address is suppressed", func() {
+			BeforeEach(func() {
+				suppressionsRepo.MatchCall.Returns.Suppressions = map[string][]models.Suppression{
+					"user-123@example.com": {{Type: "address", Value: "user-123@example.com"}},
+				}
+			})
+
+			It("does not send the email and updates the message status as undeliverable", func() {
+				processor.Process(job, logger)
+
+				Expect(suppressionsRepo.MatchCall.Receives.Connection).To(Equal(conn))
+				Expect(suppressionsRepo.MatchCall.Receives.Addresses).To(Equal([]string{"user-123@example.com"}))
+				Expect(suppressionsRepo.MatchCall.Receives.UserGUID).To(Equal("user-123"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				Expect(buffer).To(ContainSubstring(`"message":"notifications.worker.address-suppressed"`))
+			})
+
+			Context("and the notification is registered as critical", func() {
+				BeforeEach(func() {
+					kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+						{
+							ID:       "some-kind",
+							ClientID: "some-client",
+							Critical: true,
+						},
+					}
+				})
+
+				It("does send the email", func() {
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				})
+
+				Context("and the suppression is hard", func() {
+					BeforeEach(func() {
+						suppressionsRepo.MatchCall.Returns.Suppressions = map[string][]models.Suppression{
+							"user-123@example.com": {{Type: "domain", Value: "example.com", Hard: true}},
+						}
+					})
+
+					It("does not send the email", func() {
+						processor.Process(job, logger)
+
+						Expect(mailClient.SendCall.CallCount).To(Equal(0))
+						Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+					})
+				})
+			})
+		})
+
+		Context("when looking up the suppression list fails", func() {
+			It("does not send the email and hands the job to the failure handler to retry it", func() {
+				suppressionsRepo.MatchCall.Returns.Error = errors.New("database is down")
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(BeEmpty())
+				Expect(receiptsRepo.CreateReceiptsCall.Receives.UserGUIDs).To(BeEmpty())
+				Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
+				Expect(buffer).To(ContainSubstring(`"message":"notifications.worker.suppressions-lookup-failed"`))
+			})
+		})
+
 		Context("when the recipient hasn't unsubscribed, but doesn't have a valid email address", func() {
 			Context("when the recipient has no emails", func() {
 				BeforeEach(func() {
@@ -625,6 +795,353 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			})
 		})
 
+		Context("when the message has attachments", func() {
+			BeforeEach(func() {
+				delivery.Options.Attachments = []common.Attachment{
+					{ID: "invoice-id", Filename: "invoice.csv", ContentType: "text/csv"},
+					{ID: "logo-id", Filename: "logo.png", ContentType: "image/png", ContentID: "logo", Inline: true},
+				}
+				job = gobble.NewJob(delivery)
+
+				attachmentsRepo.FindByIDCall.Returns.Attachments = map[string]models.Attachment{
+					"invoice-id": {ID: "invoice-id", Content: []byte("item,price")},
+					"logo-id":    {ID: "logo-id", Content: []byte("png")},
+				}
+			})
+
+			It("loads the stored content and attaches it to the message", func() {
+				processor.Process(job, logger)
+
+				Expect(attachmentsRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+				Expect(attachmentsRepo.FindByIDCall.Receives.AttachmentIDs).To(Equal([]string{"invoice-id", "logo-id"}))
+
+				Expect(mailClient.SendCall.Receives.Message.Attachments).To(Equal([]mail.Attachment{
+					{Filename: "invoice.csv", ContentType: "text/csv", Content: []byte("item,price")},
+					{Filename: "logo.png", ContentType: "image/png", ContentID: "logo", Inline: true, Content: []byte("png")},
+				}))
+			})
+
+			Context("when the content cannot be loaded", func() {
+				BeforeEach(func() {
+					attachmentsRepo.FindByIDCall.Returns.Error = errors.New("BOOM!")
+				})
+
+				It("does not send the message and marks the job for retry", func() {
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
+				})
+			})
+		})
+
+		Context("when the recipient's domain is being throttled", func() {
+			BeforeEach(func() {
+				domainThrottle.ReserveCall.Returns.Wait = 3 * time.Second
+			})
+
+			It("defers the job without sending the message or counting a retry", func() {
+				processor.Process(job, logger)
+
+				Expect(domainThrottle.ReserveCall.Receives.Addresses).To(Equal([]string{"user-123@example.com"}))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(BeEmpty())
+				Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeFalse())
+
+				Expect(job.ShouldRetry).To(BeTrue())
+				Expect(job.RetryCount).To(Equal(0))
+				Expect(job.ActiveAt).To(Equal(clock.NowCall.Returns.Time.Add(3 * time.Second)))
+			})
+
+			It("does not create a receipt until the message is sent", func() {
+				processor.Process(job, logger)
+
+				Expect(receiptsRepo.CreateReceiptsCall.Receives.UserGUIDs).To(BeNil())
+			})
+
+			It("keeps the address that was looked up, so that it is not looked up again", func() {
+				processor.Process(job, logger)
+
+				var deferred common.Delivery
+				Expect(job.Unmarshal(&deferred)).To(Succeed())
+				Expect(deferred.Email).To(Equal("user-123@example.com"))
+				Expect(deferred.MessageID).To(Equal("randomly-generated-guid"))
+
+				kindsRepo.FindCall.Returns.Kinds = append(kindsRepo.FindCall.Returns.Kinds, kindsRepo.FindCall.Returns.Kinds[0])
+				domainThrottle.ReserveCall.Returns.Wait = 0
+
+				processor.Process(job, logger)
+
+				Expect(userLoader.LoadCall.CallCount).To(Equal(1))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			It("logs the deferral", func() {
+				processor.Process(job, logger)
+
+				lines, err := parseLogLines(buffer.Bytes())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(lines).To(ContainElement(logLine{
+					Source:   "notifications",
+					Message:  "notifications.worker.delivery-deferred",
+					LogLevel: int(lager.INFO),
+					Data: map[string]interface{}{
+						"session":         "1",
+						"recipient":       "user-123@example.com",
+						"worker_id":       float64(1234),
+						"message_id":      "randomly-generated-guid",
+						"vcap_request_id": "some-request-id",
+						"active_at":       job.ActiveAt.Format(time.RFC3339),
+					},
+				}))
+			})
+		})
+
+		Context("when the message is sent to several recipients", func() {
+			BeforeEach(func() {
+				delivery.Email = "to@example.com"
+				delivery.Options.Recipients = []common.Recipient{
+					{Address: "to@example.com", Type: common.RecipientTo},
+					{Address: "cc@example.com", Type: common.RecipientCC},
+					{Address: "bcc@example.com", Type: common.RecipientBCC},
+				}
+				job = gobble.NewJob(delivery)
+			})
+
+			It("sends a single message to all of them and marks each as delivered", func() {
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.To).To(Equal("to@example.com"))
+				Expect(mailClient.SendCall.Receives.Message.CC).To(Equal("cc@example.com"))
+				Expect(mailClient.SendCall.Receives.Message.Recipients).To(Equal([]string{"to@example.com", "cc@example.com", "bcc@example.com"}))
+
+				Expect(recipientsRepo.UpdateStatusCall.Receives.Connection).To(Equal(conn))
+				Expect(recipientsRepo.UpdateStatusCall.Receives.MessageID).To(Equal(messageID))
+				Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(Equal(map[string]string{
+					"to@example.com":  common.StatusDelivered,
+					"cc@example.com":  common.StatusDelivered,
+					"bcc@example.com": common.StatusDelivered,
+				}))
+			})
+
+			Context("when some of them are suppressed", func() {
+				BeforeEach(func() {
+					suppressionsRepo.MatchCall.Returns.Suppressions = map[string][]models.Suppression{
+						"cc@example.com": {{Type: "address", Value: "cc@example.com"}},
+					}
+				})
+
+				It("sends the message to the others and marks the suppressed ones as undeliverable", func() {
+					processor.Process(job, logger)
+
+					Expect(suppressionsRepo.MatchCall.CallCount).To(Equal(1))
+					Expect(suppressionsRepo.MatchCall.Receives.Addresses).To(Equal([]string{"to@example.com", "cc@example.com", "bcc@example.com"}))
+					Expect(domainThrottle.ReserveCall.Receives.Addresses).To(Equal([]string{"to@example.com", "bcc@example.com"}))
+
+					Expect(mailClient.SendCall.Receives.Message.Recipients).To(Equal([]string{"to@example.com", "bcc@example.com"}))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+					Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(Equal(map[string]string{
+						"to@example.com":  common.StatusDelivered,
+						"cc@example.com":  common.StatusUndeliverable,
+						"bcc@example.com": common.StatusDelivered,
+					}))
+				})
+			})
+
+			Context("when all of them are suppressed", func() {
+				BeforeEach(func() {
+					suppression := []models.Suppression{{Type: "user", Value: "user-123"}}
+					suppressionsRepo.MatchCall.Returns.Suppressions = map[string][]models.Suppression{
+						"to@example.com":  suppression,
+						"cc@example.com":  suppression,
+						"bcc@example.com": suppression,
+					}
+				})
+
+				It("does not send the message", func() {
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				})
+			})
+
+			Context("when the SMTP server rejects some of them", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.RecipientsError{
+						Accepted: []string{"to@example.com", "cc@example.com"},
+						Rejected: map[string]error{"bcc@example.com": errors.New("550 No such user here")},
+					}
+				})
+
+				It("marks the rejected recipients as failed without retrying the others", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+					Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(Equal(map[string]string{
+						"to@example.com":  common.StatusDelivered,
+						"cc@example.com":  common.StatusDelivered,
+						"bcc@example.com": common.StatusFailed,
+					}))
+					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(BeNil())
+				})
+
+				Context("and the rejection is permanent", func() {
+					BeforeEach(func() {
+						mailClient.SendCall.Returns.Error = mail.RecipientsError{
+							Accepted: []string{"to@example.com"},
+							Rejected: map[string]error{
+								"cc@example.com":  mail.SMTPError{Command: "RCPT", Code: 451, Message: "Greylisted"},
+								"bcc@example.com": mail.SMTPError{Command: "RCPT", Code: 550, Message: "No such user here"},
+							},
+						}
+					})
+
+					It("marks those recipients as undeliverable", func() {
+						processor.Process(job, logger)
+
+						Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(Equal(map[string]string{
+							"to@example.com":  common.StatusDelivered,
+							"cc@example.com":  common.StatusFailed,
+							"bcc@example.com": common.StatusUndeliverable,
+						}))
+					})
+				})
+			})
+
+			Context("when the SMTP server permanently rejects all of them", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.RecipientsError{
+						Rejected: map[string]error{
+							"to@example.com":  mail.SMTPError{Command: "RCPT", Code: 550, Message: "No such user here"},
+							"cc@example.com":  mail.SMTPError{Command: "RCPT", Code: 550, Message: "No such user here"},
+							"bcc@example.com": mail.SMTPError{Command: "RCPT", Code: 553, Message: "Mailbox name not allowed"},
+						},
+					}
+				})
+
+				It("marks the message and each recipient as undeliverable without retrying the job", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+					Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(HaveLen(3))
+					for _, status := range recipientsRepo.UpdateStatusCall.Receives.Statuses {
+						Expect(status).To(Equal(common.StatusUndeliverable))
+					}
+					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(BeNil())
+				})
+			})
+
+			Context("when the SMTP server rejects all of them", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.RecipientsError{
+						Rejected: map[string]error{
+							"to@example.com":  errors.New("550 No such user here"),
+							"cc@example.com":  errors.New("550 No such user here"),
+							"bcc@example.com": errors.New("550 No such user here"),
+						},
+					}
+				})
+
+				It("marks the message and each recipient as failed and retries the job", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+					Expect(recipientsRepo.UpdateStatusCall.Receives.Statuses).To(HaveLen(3))
+					for _, status := range recipientsRepo.UpdateStatusCall.Receives.Statuses {
+						Expect(status).To(Equal(common.StatusFailed))
+					}
+					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
+				})
+			})
+		})
+
+		Context("when the delivery is traced", func() {
+			var spans map[string]tracing.Span
+
+			BeforeEach(func() {
+				delivery.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+				job = gobble.NewJob(delivery)
+			})
+
+			JustBeforeEach(func() {
+				processor.Process(job, logger)
+
+				spans = map[string]tracing.Span{}
+				for _, span := range spanExporter.ExportCall.Receives.Spans {
+					spans[span.Name] = span
+				}
+			})
+
+			It("records the delivery as part of the trace of the request", func() {
+				Expect(spans).To(HaveKey("deliver"))
+
+				deliver := spans["deliver"]
+				Expect(deliver.Kind).To(Equal(tracing.SpanKindConsumer))
+				Expect(deliver.Context.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+				Expect(deliver.ParentSpanID).To(Equal("00f067aa0ba902b7"))
+				Expect(deliver.Attributes).To(Equal(map[string]string{
+					"message_id": "randomly-generated-guid",
+					"client_id":  "some-client",
+					"kind_id":    "some-kind",
+					"status":     common.StatusDelivered,
+				}))
+			})
+
+			It("records child spans for the user lookup, template packing and SMTP send", func() {
+				Expect(spans).To(HaveLen(4))
+
+				for _, name := range []string{"load-user", "pack-template", "smtp-send"} {
+					Expect(spans).To(HaveKey(name))
+					Expect(spans[name].Context.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+					Expect(spans[name].ParentSpanID).To(Equal(spans["deliver"].Context.SpanID))
+					Expect(spans[name].Error).To(BeEmpty())
+				}
+
+				Expect(spans["load-user"].Attributes["user_guid"]).To(Equal("user-123"))
+				Expect(spans["pack-template"].Attributes["template_id"]).To(Equal("some-template-id"))
+				Expect(spans["smtp-send"].Attributes["recipients"]).To(Equal("1"))
+			})
+
+			Context("when the SMTP server fails", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = errors.New("connection reset")
+				})
+
+				It("records the error on the send span", func() {
+					Expect(spans["smtp-send"].Error).To(Equal("connection reset"))
+					Expect(spans["deliver"].Attributes["status"]).To(Equal(common.StatusFailed))
+				})
+			})
+
+			Context("when the user cannot be found", func() {
+				BeforeEach(func() {
+					userLoader.LoadCall.Returns.Users = map[string]uaa.User{}
+				})
+
+				It("records the error on the lookup and delivery spans", func() {
+					Expect(spans).NotTo(HaveKey("smtp-send"))
+					Expect(spans["load-user"].Error).To(Equal(`user "user-123" could not be found`))
+					Expect(spans["deliver"].Error).To(Equal(`user "user-123" could not be found`))
+				})
+			})
+
+			Context("when the trace context is missing", func() {
+				BeforeEach(func() {
+					delivery.TraceParent = ""
+					job = gobble.NewJob(delivery)
+				})
+
+				It("starts a new trace", func() {
+					Expect(spans["deliver"].Context.IsValid()).To(BeTrue())
+					Expect(spans["deliver"].ParentSpanID).To(BeEmpty())
+					Expect(spans["smtp-send"].Context.TraceID).To(Equal(spans["deliver"].Context.TraceID))
+				})
+			})
+		})
+
 		Context("when the job contains malformed JSON", func() {
 			BeforeEach(func() {
 				job.Payload = `{"Space":"my-space","Options":{"HTML":"<p>some text that just abruptly ends`
@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+// fanOutConflictDeferral is how long a fan-out waits before checking again
+// on a fan-out that another worker is running, in case that worker stops.
+const fanOutConflictDeferral = 5 * time.Minute
+
+type fanOutsRepo interface {
+	FindByID(connection models.ConnectionInterface, fanOutID string) (models.FanOut, error)
+	Advance(connection models.ConnectionInterface, fanOut models.FanOut, enqueued int) (models.FanOut, error)
+	SetStatus(connection models.ConnectionInterface, fanOut models.FanOut, status string) (models.FanOut, error)
+}
+
+type deliveryEnqueuer interface {
+	EnqueueWithin(
+		transaction services.ConnectionInterface,
+		users []services.User,
+		opts services.Options,
+		space cf.CloudControllerSpace,
+		org cf.CloudControllerOrganization,
+		clientID string,
+		uaaHost string,
+		scope string,
+		vcapRequestID string,
+		traceParent string,
+		reqReceived time.Time) ([]services.Response, error)
+}
+
+type FanOutJobProcessorConfig struct {
+	UAAHost   string
+	ChunkSize int
+
+	Database    db.DatabaseInterface
+	TokenLoader tokenLoader
+	UserLoader  userLoader
+	Enqueuer    deliveryEnqueuer
+
+	FanOutsRepo            fanOutsRepo
+	DeliveryFailureHandler deliveryFailureHandler
+	Clock                  clock
+}
+
+// FanOutJobProcessor enqueues the deliveries of a notification sent to a
+// group of users. The email addresses of the users are looked up in chunks
+// of ChunkSize and filled in on their deliveries. The progress is recorded
+// in the same transaction as the deliveries of each chunk, so that a fan-out
+// that is retried carries on from the first user whose delivery has not been
+// enqueued yet, and no chunk is enqueued twice. When another worker runs the
+// same fan-out at once, only one of them records the progress of a chunk; the
+// other rolls its chunk back and checks on the fan-out again later.
+type FanOutJobProcessor struct {
+	uaaHost   string
+	chunkSize int
+
+	database    db.DatabaseInterface
+	tokenLoader tokenLoader
+	userLoader  userLoader
+	enqueuer    deliveryEnqueuer
+
+	fanOutsRepo            fanOutsRepo
+	deliveryFailureHandler deliveryFailureHandler
+	clock                  clock
+}
+
+func NewFanOutJobProcessor(config FanOutJobProcessorConfig) FanOutJobProcessor {
+	return FanOutJobProcessor{
+		uaaHost:   config.UAAHost,
+		chunkSize: config.ChunkSize,
+
+		database:    config.Database,
+		tokenLoader: config.TokenLoader,
+		userLoader:  config.UserLoader,
+		enqueuer:    config.Enqueuer,
+
+		fanOutsRepo:            config.FanOutsRepo,
+		deliveryFailureHandler: config.DeliveryFailureHandler,
+		clock:                  config.Clock,
+	}
+}
+
+func (p FanOutJobProcessor) Process(job *gobble.Job, logger lager.Logger) error {
+	var fanOut services.FanOut
+	err := job.Unmarshal(&fanOut)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.worker.panic.json", nil).Inc(1)
+		p.deliveryFailureHandler.Handle(job, logger)
+		return nil
+	}
+
+	logger = logger.WithData(lager.Data{
+		"fanout_id":       fanOut.ID,
+		"vcap_request_id": fanOut.VCAPRequestID,
+	})
+
+	conn := p.database.Connection()
+
+	record, err := p.fanOutsRepo.FindByID(conn, fanOut.ID)
+	if err != nil {
+		logger.Error("fanout-find-failed", err)
+		p.deliveryFailureHandler.Handle(job, logger)
+		return nil
+	}
+
+	if record.Status == models.FanOutStatusCompleted {
+		return nil
+	}
+
+	for record.Enqueued < len(fanOut.Users) {
+		end := record.Enqueued + p.chunkSize
+		if p.chunkSize <= 0 || end > len(fanOut.Users) {
+			end = len(fanOut.Users)
+		}
+
+		users, err := p.loadEmails(fanOut.Users[record.Enqueued:end])
+		if err != nil {
+			p.fail(conn, job, record, err, logger)
+			return nil
+		}
+
+		advanced, err := p.enqueueChunk(conn, fanOut, record, users, end)
+		if err != nil {
+			if _, ok := err.(models.ConflictError); ok {
+				logger.Info("fanout-running-elsewhere", lager.Data{
+					"enqueued": record.Enqueued,
+				})
+				job.Defer(p.clock.Now().Add(fanOutConflictDeferral))
+				return nil
+			}
+
+			p.fail(conn, job, record, err, logger)
+			return nil
+		}
+		record = advanced
+
+		metrics.GetOrRegisterCounter("notifications.worker.fanout.deliveries", nil).Inc(int64(len(users)))
+	}
+
+	_, err = p.fanOutsRepo.SetStatus(conn, record, models.FanOutStatusCompleted)
+	if err != nil {
+		logger.Error("fanout-update-failed", err)
+	}
+
+	logger.Info("fanout-completed", lager.Data{
+		"recipients": record.Enqueued,
+	})
+
+	return nil
+}
+
+// enqueueChunk enqueues the deliveries of a chunk of users and records the
+// progress of the fan-out up to end in a single transaction.
+func (p FanOutJobProcessor) enqueueChunk(conn db.ConnectionInterface, fanOut services.FanOut, record models.FanOut, users []services.User, end int) (models.FanOut, error) {
+	transaction := conn.Transaction()
+	if err := transaction.Begin(); err != nil {
+		return models.FanOut{}, err
+	}
+
+	_, err := p.enqueuer.EnqueueWithin(transaction, users, fanOut.Options, fanOut.Space, fanOut.Organization, fanOut.ClientID,
+		fanOut.UAAHost, fanOut.Scope, fanOut.VCAPRequestID, fanOut.TraceParent, fanOut.RequestReceived)
+	if err != nil {
+		transaction.Rollback()
+		return models.FanOut{}, err
+	}
+
+	record, err = p.fanOutsRepo.Advance(transaction, record, end)
+	if err != nil {
+		transaction.Rollback()
+		return models.FanOut{}, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return models.FanOut{}, err
+	}
+
+	return record, nil
+}
+
+// loadEmails fills in the email addresses of the users that can be found in
+// UAA. The deliveries of users that cannot be found are enqueued without an
+// address, so that the delivery looks the user up once more and fails in the
+// same way as a notification sent to that user alone.
+func (p FanOutJobProcessor) loadEmails(users []services.User) ([]services.User, error) {
+	var guids []string
+	for _, user := range users {
+		if user.Email == "" {
+			guids = append(guids, user.GUID)
+		}
+	}
+
+	if len(guids) == 0 {
+		return users, nil
+	}
+
+	token, err := p.tokenLoader.Load(p.uaaHost)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := p.userLoader.Load(guids, token)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make([]services.User, 0, len(users))
+	for _, user := range users {
+		if emails := found[user.GUID].Emails; user.Email == "" && len(emails) > 0 {
+			user.Email = emails[0]
+		}
+
+		loaded = append(loaded, user)
+	}
+
+	return loaded, nil
+}
+
+// fail retries the fan-out, or marks it as failed once it has run out of
+// retries.
+func (p FanOutJobProcessor) fail(conn models.ConnectionInterface, job *gobble.Job, record models.FanOut, err error, logger lager.Logger) {
+	logger.Error("fanout-failed", err, lager.Data{
+		"enqueued": record.Enqueued,
+	})
+
+	p.deliveryFailureHandler.Handle(job, logger)
+	if job.ShouldRetry {
+		return
+	}
+
+	_, err = p.fanOutsRepo.SetStatus(conn, record, models.FanOutStatusFailed)
+	if err != nil {
+		logger.Error("fanout-update-failed", err)
+	}
+}
@@ -0,0 +1,296 @@
+package v1_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/postal/v1"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FanOutJobProcessor", func() {
+	var (
+		processor              v1.FanOutJobProcessor
+		logger                 lager.Logger
+		database               *mocks.Database
+		conn                   *mocks.Connection
+		transaction            *mocks.Transaction
+		tokenLoader            *mocks.TokenLoader
+		userLoader             *mocks.UserLoader
+		enqueuer               *mocks.Enqueuer
+		fanOutsRepo            *mocks.FanOutsRepo
+		deliveryFailureHandler *mocks.DeliveryFailureHandler
+		clock                  *mocks.Clock
+		requestReceived        time.Time
+		fanOut                 services.FanOut
+		job                    *gobble.Job
+	)
+
+	BeforeEach(func() {
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+
+		conn = mocks.NewConnection()
+		transaction = mocks.NewTransaction()
+		transaction.Connection = conn
+		conn.TransactionCall.Returns.Transaction = transaction
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "some-token"
+
+		userLoader = mocks.NewUserLoader()
+		userLoader.LoadCall.Returns.Users = map[string]uaa.User{
+			"user-1": {Emails: []string{"user-1@example.com"}},
+			"user-2": {Emails: []string{"user-2@example.com"}},
+			"user-3": {Emails: []string{"user-3@example.com"}},
+		}
+
+		enqueuer = mocks.NewEnqueuer()
+
+		fanOutsRepo = mocks.NewFanOutsRepo()
+		fanOutsRepo.FindByIDCall.Returns.FanOut = models.FanOut{
+			ID:       "some-fanout-id",
+			ClientID: "some-client",
+			Status:   models.FanOutStatusQueued,
+			Total:    3,
+		}
+
+		deliveryFailureHandler = mocks.NewDeliveryFailureHandler()
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2015, 6, 8, 14, 45, 0, 0, time.UTC)
+
+		requestReceived, _ = time.Parse(time.RFC3339Nano, "2015-06-08T14:40:12.207187819-07:00")
+		fanOut = services.FanOut{
+			JobType: services.JobTypeFanOut,
+			ID:      "some-fanout-id",
+			Users: []services.User{
+				{GUID: "user-1"},
+				{GUID: "user-2"},
+				{GUID: "user-3"},
+			},
+			Options:         services.Options{KindID: "some-kind", Subject: "the subject"},
+			Space:           cf.CloudControllerSpace{GUID: "some-space"},
+			Organization:    cf.CloudControllerOrganization{GUID: "some-org"},
+			ClientID:        "some-client",
+			UAAHost:         "https://uaa.example.com",
+			VCAPRequestID:   "some-request-id",
+			RequestReceived: requestReceived,
+			TraceParent:     "some-trace-parent",
+		}
+		job = gobble.NewJob(fanOut)
+
+		processor = v1.NewFanOutJobProcessor(v1.FanOutJobProcessorConfig{
+			UAAHost:   "https://uaa.example.com",
+			ChunkSize: 2,
+
+			Database:    database,
+			TokenLoader: tokenLoader,
+			UserLoader:  userLoader,
+			Enqueuer:    enqueuer,
+
+			FanOutsRepo:            fanOutsRepo,
+			DeliveryFailureHandler: deliveryFailureHandler,
+			Clock:                  clock,
+		})
+	})
+
+	It("looks up the users in chunks and enqueues their deliveries with their email addresses", func() {
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fanOutsRepo.FindByIDCall.Receives.FanOutID).To(Equal("some-fanout-id"))
+
+		Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+		Expect(userLoader.LoadCall.CallCount).To(Equal(2))
+		Expect(userLoader.LoadCall.Receives.UserGUIDs).To(Equal([]string{"user-3"}))
+		Expect(userLoader.LoadCall.Receives.Token).To(Equal("some-token"))
+
+		Expect(enqueuer.EnqueueWithinCall.CallCount).To(Equal(2))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Connection).To(Equal(transaction))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Users).To(Equal([]services.User{
+			{GUID: "user-3", Email: "user-3@example.com"},
+		}))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Options).To(Equal(fanOut.Options))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Space).To(Equal(fanOut.Space))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Org).To(Equal(fanOut.Organization))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Client).To(Equal("some-client"))
+		Expect(enqueuer.EnqueueWithinCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+		Expect(enqueuer.EnqueueWithinCall.Receives.VCAPRequestID).To(Equal("some-request-id"))
+		Expect(enqueuer.EnqueueWithinCall.Receives.TraceParent).To(Equal("some-trace-parent"))
+		Expect(enqueuer.EnqueueWithinCall.Receives.RequestReceived).To(Equal(requestReceived))
+	})
+
+	It("records the progress of each chunk in the transaction of its deliveries and completes the fan-out", func() {
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fanOutsRepo.AdvanceCall.Receives.Connection).To(Equal(transaction))
+		Expect(fanOutsRepo.AdvanceCall.Receives.Enqueued).To(Equal([]int{2, 3}))
+		Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+
+		Expect(fanOutsRepo.SetStatusCall.Receives.Connection).To(Equal(conn))
+		Expect(fanOutsRepo.SetStatusCall.Receives.Statuses).To(Equal([]string{models.FanOutStatusCompleted}))
+		Expect(fanOutsRepo.SetStatusCall.Receives.FanOut.Enqueued).To(Equal(3))
+
+		Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeFalse())
+	})
+
+	It("carries on from the first user whose delivery has not been enqueued", func() {
+		fanOutsRepo.FindByIDCall.Returns.FanOut.Status = models.FanOutStatusInProgress
+		fanOutsRepo.FindByIDCall.Returns.FanOut.Enqueued = 2
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(enqueuer.EnqueueWithinCall.CallCount).To(Equal(1))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Users).To(Equal([]services.User{
+			{GUID: "user-3", Email: "user-3@example.com"},
+		}))
+	})
+
+	It("does nothing when the fan-out has already been completed", func() {
+		fanOutsRepo.FindByIDCall.Returns.FanOut.Status = models.FanOutStatusCompleted
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(enqueuer.EnqueueWithinCall.WasCalled).To(BeFalse())
+		Expect(fanOutsRepo.AdvanceCall.CallCount).To(Equal(0))
+		Expect(fanOutsRepo.SetStatusCall.CallCount).To(Equal(0))
+	})
+
+	It("does not look up users whose email addresses are already known", func() {
+		fanOut.Users = []services.User{
+			{GUID: "user-1", Email: "known@example.com"},
+			{GUID: "user-2"},
+		}
+		fanOutsRepo.FindByIDCall.Returns.FanOut.Total = 2
+
+		err := processor.Process(gobble.NewJob(fanOut), logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(userLoader.LoadCall.Receives.UserGUIDs).To(Equal([]string{"user-2"}))
+		Expect(enqueuer.EnqueueWithinCall.Receives.Users).To(Equal([]services.User{
+			{GUID: "user-1", Email: "known@example.com"},
+			{GUID: "user-2", Email: "user-2@example.com"},
+		}))
+	})
+
+	It("leaves the address of users that cannot be found for their deliveries to look up", func() {
+		userLoader.LoadCall.Returns.Users = map[string]uaa.User{
+			"user-1": {Emails: []string{"user-1@example.com"}},
+		}
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(enqueuer.EnqueueWithinCall.Receives.Users).To(Equal([]services.User{
+			{GUID: "user-3"},
+		}))
+	})
+
+	Context("when the users cannot be looked up", func() {
+		BeforeEach(func() {
+			userLoader.LoadCall.Returns.Error = errors.New("UAA is down")
+		})
+
+		It("records the failure once the fan-out has run out of retries", func() {
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeTrue())
+			Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
+			Expect(enqueuer.EnqueueWithinCall.WasCalled).To(BeFalse())
+
+			Expect(fanOutsRepo.SetStatusCall.Receives.Statuses).To(Equal([]string{models.FanOutStatusFailed}))
+		})
+
+		It("leaves the fan-out in progress when it is retried", func() {
+			processor = v1.NewFanOutJobProcessor(v1.FanOutJobProcessorConfig{
+				UAAHost:   "https://uaa.example.com",
+				ChunkSize: 2,
+
+				Database:    database,
+				TokenLoader: tokenLoader,
+				UserLoader:  userLoader,
+				Enqueuer:    enqueuer,
+
+				FanOutsRepo:            fanOutsRepo,
+				DeliveryFailureHandler: common.NewDeliveryFailureHandler(3, time.Minute),
+				Clock:                  clock,
+			})
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(job.ShouldRetry).To(BeTrue())
+			Expect(fanOutsRepo.SetStatusCall.CallCount).To(Equal(0))
+		})
+	})
+
+	It("rolls the chunk back and retries the fan-out when the deliveries cannot be enqueued", func() {
+		enqueuer.EnqueueWithinCall.Returns.Err = errors.New("BOOM!")
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		Expect(fanOutsRepo.AdvanceCall.CallCount).To(Equal(0))
+		Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeTrue())
+	})
+
+	It("rolls the deliveries of the chunk back when its progress cannot be recorded", func() {
+		fanOutsRepo.AdvanceCall.Returns.Error = errors.New("BOOM!")
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(enqueuer.EnqueueWithinCall.CallCount).To(Equal(1))
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeTrue())
+	})
+
+	It("rolls the chunk back and checks again later when another worker has recorded progress", func() {
+		fanOutsRepo.AdvanceCall.Returns.Error = models.ConflictError{Err: errors.New("already progressed")}
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeFalse())
+		Expect(fanOutsRepo.SetStatusCall.CallCount).To(Equal(0))
+
+		Expect(job.ShouldRetry).To(BeTrue())
+		Expect(job.RetryCount).To(Equal(0))
+		Expect(job.ActiveAt).To(Equal(clock.NowCall.Returns.Time.Add(5 * time.Minute)))
+	})
+
+	It("retries the fan-out when it cannot be found", func() {
+		fanOutsRepo.FindByIDCall.Returns.Error = errors.New("BOOM!")
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeTrue())
+		Expect(enqueuer.EnqueueWithinCall.WasCalled).To(BeFalse())
+	})
+})
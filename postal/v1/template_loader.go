@@ -18,20 +18,26 @@ type templateFinder interface {
 	FindByID(connection models.ConnectionInterface, templateID string) (models.Template, error)
 }
 
+type partialsFinder interface {
+	FindByNames(connection models.ConnectionInterface, names []string) ([]models.Partial, error)
+}
+
 type TemplatesLoader struct {
 	database db.DatabaseInterface
 
 	clientsRepo   clientFinder
 	kindsRepo     kindFinder
 	templatesRepo templateFinder
+	partialsRepo  partialsFinder
 }
 
-func NewTemplatesLoader(database db.DatabaseInterface, clientsRepo clientFinder, kindsRepo kindFinder, templatesRepo templateFinder) TemplatesLoader {
+func NewTemplatesLoader(database db.DatabaseInterface, clientsRepo clientFinder, kindsRepo kindFinder, templatesRepo templateFinder, partialsRepo partialsFinder) TemplatesLoader {
 	return TemplatesLoader{
 		database:      database,
 		clientsRepo:   clientsRepo,
 		kindsRepo:     kindsRepo,
 		templatesRepo: templatesRepo,
+		partialsRepo:  partialsRepo,
 	}
 }
 
@@ -63,9 +69,58 @@ func (loader TemplatesLoader) loadTemplate(conn db.ConnectionInterface, template
 		return common.Templates{}, err
 	}
 
+	references := common.PartialReferences(template.Subject, template.Text, template.HTML)
+	if template.Layout != "" {
+		references = append(references, template.Layout)
+	}
+
+	partials, err := loader.loadPartials(conn, references)
+	if err != nil {
+		return common.Templates{}, err
+	}
+
 	return common.Templates{
-		Subject: template.Subject,
-		Text:    template.Text,
-		HTML:    template.HTML,
+		Subject:  template.Subject,
+		Text:     template.Text,
+		HTML:     template.HTML,
+		Layout:   template.Layout,
+		Partials: partials,
 	}, nil
 }
+
+// loadPartials loads the partials with the given names along with the
+// partials they reference in turn, so that a delivery only reads the partials
+// its template needs. Partials that do not exist are left out for
+// ParseTemplateSet to report.
+func (loader TemplatesLoader) loadPartials(conn db.ConnectionInterface, names []string) (map[string]string, error) {
+	var contents map[string]string
+	requested := map[string]bool{}
+
+	for {
+		var pending []string
+		for _, name := range names {
+			if !requested[name] {
+				requested[name] = true
+				pending = append(pending, name)
+			}
+		}
+
+		if len(pending) == 0 {
+			return contents, nil
+		}
+
+		partials, err := loader.partialsRepo.FindByNames(conn, pending)
+		if err != nil {
+			return nil, err
+		}
+
+		names = nil
+		for _, partial := range partials {
+			if contents == nil {
+				contents = map[string]string{}
+			}
+			contents[partial.Name] = partial.Content
+			names = append(names, common.PartialReferences(partial.Content)...)
+		}
+	}
+}
@@ -19,6 +19,7 @@ var _ = Describe("TemplateLoader", func() {
 		clientsRepo   *mocks.ClientsRepository
 		kindsRepo     *mocks.KindsRepo
 		templatesRepo *mocks.TemplatesRepo
+		partialsRepo  *mocks.PartialsRepo
 		conn          db.ConnectionInterface
 		database      *mocks.Database
 	)
@@ -27,12 +28,13 @@ var _ = Describe("TemplateLoader", func() {
 		clientsRepo = mocks.NewClientsRepository()
 		kindsRepo = mocks.NewKindsRepo()
 		templatesRepo = mocks.NewTemplatesRepo()
+		partialsRepo = mocks.NewPartialsRepo()
 
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = conn
 
-		loader = v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo)
+		loader = v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo, partialsRepo)
 	})
 
 	Describe("LoadTemplates", func() {
@@ -156,6 +158,60 @@ var _ = Describe("TemplateLoader", func() {
 
 		})
 
+		Context("when partials exist", func() {
+			BeforeEach(func() {
+				templatesRepo.FindByIDCall.Returns.Template.Layout = "branded"
+				templatesRepo.FindByIDCall.Returns.Template.Text = `The default template {{template "signature" .}}`
+				partialsRepo.FindByNamesCall.Returns.Partials = []models.Partial{
+					{Name: "branded", Content: `<html>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</html>`},
+					{Name: "footer", Content: "the footer"},
+					{Name: "signature", Content: "the signature"},
+					{Name: "unused", Content: "not referenced"},
+				}
+			})
+
+			It("returns the layout and the partials the template references along with the template", func() {
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(templates).To(Equal(common.Templates{
+					HTML:    "<p>The default template</p>",
+					Text:    `The default template {{template "signature" .}}`,
+					Subject: "default subject",
+					Layout:  "branded",
+					Partials: map[string]string{
+						"branded":   `<html>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</html>`,
+						"footer":    "the footer",
+						"signature": "the signature",
+					},
+				}))
+
+				Expect(partialsRepo.FindByNamesCall.Receives.Connection).To(Equal(conn))
+				Expect(partialsRepo.FindByNamesCall.Receives.Names).To(Equal([][]string{
+					{"signature", "branded"},
+					{"footer"},
+				}))
+			})
+		})
+
+		Context("when the template does not reference any partials", func() {
+			It("does not look them up", func() {
+				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(partialsRepo.FindByNamesCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the partials repo has an error", func() {
+			It("bubbles up the error", func() {
+				templatesRepo.FindByIDCall.Returns.Template.Layout = "branded"
+				partialsRepo.FindByNamesCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+		})
+
 		Context("when the clients repo has an error", func() {
 			It("bubbles up the error", func() {
 				clientsRepo.FindCall.Returns.Error = errors.New("BOOM!")
@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99}
+
+// Handler serves the metrics in a go-metrics registry in the Prometheus text
+// exposition format. Counters and meters become counters, gauges stay
+// gauges, and histograms and timers become summaries, with timers reported in
+// seconds.
+type Handler struct {
+	registry metrics.Registry
+}
+
+func NewHandler(registry metrics.Registry) Handler {
+	return Handler{
+		registry: registry,
+	}
+}
+
+type family struct {
+	kind   string
+	series map[string][]string
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	families := map[string]*family{}
+	add := func(name, kind, labels string, lines ...string) {
+		f, ok := families[name]
+		if !ok {
+			f = &family{kind: kind, series: map[string][]string{}}
+			families[name] = f
+		}
+
+		f.series[labels] = lines
+	}
+
+	h.registry.Each(func(registered string, metric interface{}) {
+		name, labels := splitLabels(registered)
+		name = sanitize(name)
+
+		switch m := metric.(type) {
+		case metrics.Counter:
+			add(name+"_total", "counter", labels, sample(name+"_total", labels, float64(m.Count())))
+		case metrics.Meter:
+			add(name+"_total", "counter", labels, sample(name+"_total", labels, float64(m.Snapshot().Count())))
+		case metrics.Gauge:
+			add(name, "gauge", labels, sample(name, labels, float64(m.Value())))
+		case metrics.GaugeFloat64:
+			add(name, "gauge", labels, sample(name, labels, m.Value()))
+		case metrics.Histogram:
+			s := m.Snapshot()
+			add(name, "summary", labels, summary(name, labels, s.Count(), float64(s.Sum()), s.Percentiles(quantiles))...)
+		case metrics.Timer:
+			s := m.Snapshot()
+			values := s.Percentiles(quantiles)
+			for i := range values {
+				values[i] = values[i] / float64(time.Second)
+			}
+			add(name+"_seconds", "summary", labels, summary(name+"_seconds", labels, s.Count(), float64(s.Sum())/float64(time.Second), values)...)
+		}
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, name := range sortedKeys(families) {
+		f := families[name]
+
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, f.kind)
+
+		var labels []string
+		for l := range f.series {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+
+		for _, l := range labels {
+			for _, line := range f.series[l] {
+				fmt.Fprintln(w, line)
+			}
+		}
+	}
+}
+
+func summary(name, labels string, count int64, sum float64, values []float64) []string {
+	var lines []string
+	for i, quantile := range quantiles {
+		lines = append(lines, sample(name, joinLabels(labels, fmt.Sprintf(`quantile="%g"`, quantile)), values[i]))
+	}
+
+	return append(lines, sample(name+"_sum", labels, sum), sample(name+"_count", labels, float64(count)))
+}
+
+func sample(name, labels string, value float64) string {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+
+	return name + " " + strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func joinLabels(labels, label string) string {
+	if labels == "" {
+		return label
+	}
+
+	return labels + "," + label
+}
+
+func sortedKeys(families map[string]*family) []string {
+	var names []string
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
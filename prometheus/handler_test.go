@@ -0,0 +1,116 @@
+package prometheus_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
+	"github.com/rcrowley/go-metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		registry metrics.Registry
+		handler  prometheus.Handler
+		writer   *httptest.ResponseRecorder
+		request  *http.Request
+	)
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+		handler = prometheus.NewHandler(registry)
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/metrics", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("serves the text exposition format", func() {
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("text/plain; version=0.0.4; charset=utf-8"))
+	})
+
+	It("exposes counters", func() {
+		metrics.GetOrRegisterCounter("notifications.worker.delivered", registry).Inc(3)
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Body.String()).To(Equal("# TYPE notifications_worker_delivered_total counter\n" +
+			"notifications_worker_delivered_total 3\n"))
+	})
+
+	It("exposes gauges", func() {
+		metrics.GetOrRegisterGauge("notifications.queue.length", registry).Update(12)
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Body.String()).To(Equal("# TYPE notifications_queue_length gauge\n" +
+			"notifications_queue_length 12\n"))
+	})
+
+	It("exposes timers as summaries in seconds", func() {
+		timer := metrics.GetOrRegisterTimer("notifications.external-requests.uaa.client-token", registry)
+		timer.Update(2 * time.Second)
+		timer.Update(2 * time.Second)
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Body.String()).To(Equal("# TYPE notifications_external_requests_uaa_client_token_seconds summary\n" +
+			`notifications_external_requests_uaa_client_token_seconds{quantile="0.5"} 2` + "\n" +
+			`notifications_external_requests_uaa_client_token_seconds{quantile="0.75"} 2` + "\n" +
+			`notifications_external_requests_uaa_client_token_seconds{quantile="0.95"} 2` + "\n" +
+			`notifications_external_requests_uaa_client_token_seconds{quantile="0.99"} 2` + "\n" +
+			"notifications_external_requests_uaa_client_token_seconds_sum 4\n" +
+			"notifications_external_requests_uaa_client_token_seconds_count 2\n"))
+	})
+
+	It("groups labelled series under a single metric", func() {
+		metrics.GetOrRegisterCounter(prometheus.Labelled("notifications.worker.deliveries", prometheus.Labels{
+			"client_id": "some-client",
+			"status":    "delivered",
+		}), registry).Inc(2)
+		metrics.GetOrRegisterCounter(prometheus.Labelled("notifications.worker.deliveries", prometheus.Labels{
+			"client_id": "some-client",
+			"status":    "failed",
+		}), registry).Inc(1)
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Body.String()).To(Equal("# TYPE notifications_worker_deliveries_total counter\n" +
+			`notifications_worker_deliveries_total{client_id="some-client",status="delivered"} 2` + "\n" +
+			`notifications_worker_deliveries_total{client_id="some-client",status="failed"} 1` + "\n"))
+	})
+
+	It("adds the quantile to the labels of labelled timers", func() {
+		metrics.GetOrRegisterTimer(prometheus.Labelled("notifications.worker.deferral", prometheus.Labels{
+			"domain": "example.com",
+		}), registry).Update(time.Second)
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Body.String()).To(ContainSubstring(`notifications_worker_deferral_seconds{domain="example.com",quantile="0.5"} 1` + "\n"))
+		Expect(writer.Body.String()).To(ContainSubstring(`notifications_worker_deferral_seconds_count{domain="example.com"} 1` + "\n"))
+	})
+
+	It("sorts the metrics by name", func() {
+		metrics.GetOrRegisterGauge("notifications.queue.length", registry).Update(1)
+		metrics.GetOrRegisterCounter("notifications.web.GET./info", registry).Inc(1)
+		metrics.GetOrRegisterCounter("notifications.worker.delivered", registry).Inc(1)
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Body.String()).To(Equal("# TYPE notifications_queue_length gauge\n" +
+			"notifications_queue_length 1\n" +
+			"# TYPE notifications_web_GET__info_total counter\n" +
+			"notifications_web_GET__info_total 1\n" +
+			"# TYPE notifications_worker_delivered_total counter\n" +
+			"notifications_worker_delivered_total 1\n"))
+	})
+})
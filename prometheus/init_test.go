@@ -0,0 +1,13 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPrometheusSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "prometheus")
+}
@@ -0,0 +1,57 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Labels map[string]string
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Labelled returns the name under which a labelled series is registered with
+// go-metrics, e.g. `notifications.worker.deliveries{status="delivered"}`.
+// The Handler exposes such series as a single metric with labels.
+func Labelled(name string, labels Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	var keys []string
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, sanitize(key), labelValueEscaper.Replace(labels[key])))
+	}
+
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// splitLabels separates the name of a series from its labels, which are
+// returned without the surrounding braces.
+func splitLabels(name string) (string, string) {
+	index := strings.Index(name, "{")
+	if index < 0 || !strings.HasSuffix(name, "}") {
+		return name, ""
+	}
+
+	return name[:index], name[index+1 : len(name)-1]
+}
+
+// sanitize replaces the characters Prometheus does not allow in metric and
+// label names, such as the dots go-metrics names are separated by.
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,32 @@
+package prometheus_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Labelled", func() {
+	It("appends the labels to the name in a stable order", func() {
+		name := prometheus.Labelled("notifications.worker.deliveries", prometheus.Labels{
+			"status":    "delivered",
+			"client_id": "some-client",
+			"kind_id":   "some-kind",
+		})
+
+		Expect(name).To(Equal(`notifications.worker.deliveries{client_id="some-client",kind_id="some-kind",status="delivered"}`))
+	})
+
+	It("escapes the label values", func() {
+		name := prometheus.Labelled("notifications.worker.deliveries", prometheus.Labels{
+			"client_id": `some "quoted" \\ client`,
+		})
+
+		Expect(name).To(Equal(`notifications.worker.deliveries{client_id="some \"quoted\" \\\\ client"}`))
+	})
+
+	It("leaves the name alone without labels", func() {
+		Expect(prometheus.Labelled("notifications.worker.delivered", nil)).To(Equal("notifications.worker.delivered"))
+	})
+})
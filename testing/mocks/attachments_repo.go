@@ -0,0 +1,103 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type AttachmentsRepo struct {
+	CreateCall struct {
+		CallCount int
+		Receives  struct {
+			Connection  models.ConnectionInterface
+			Attachments []models.Attachment
+		}
+		Returns struct {
+			Attachments []models.Attachment
+			Error       error
+		}
+	}
+
+	ReferenceCall struct {
+		CallCount int
+		Receives  struct {
+			Connection    models.ConnectionInterface
+			AttachmentIDs []string
+			OwnerIDs      []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindByIDCall struct {
+		Receives struct {
+			Connection    models.ConnectionInterface
+			AttachmentIDs []string
+		}
+		Returns struct {
+			Attachments map[string]models.Attachment
+			Error       error
+		}
+	}
+
+	DeleteBeforeCall struct {
+		CallCount int
+		Receives  struct {
+			Connection    models.ConnectionInterface
+			ThresholdTime time.Time
+			Limit         int
+		}
+		Returns struct {
+			RowsAffected []int
+			Error        error
+		}
+	}
+}
+
+func NewAttachmentsRepo() *AttachmentsRepo {
+	return &AttachmentsRepo{}
+}
+
+func (ar *AttachmentsRepo) Create(conn models.ConnectionInterface, attachment models.Attachment) (models.Attachment, error) {
+	ar.CreateCall.Receives.Connection = conn
+	ar.CreateCall.Receives.Attachments = append(ar.CreateCall.Receives.Attachments, attachment)
+
+	if ar.CreateCall.CallCount < len(ar.CreateCall.Returns.Attachments) {
+		attachment = ar.CreateCall.Returns.Attachments[ar.CreateCall.CallCount]
+	}
+	ar.CreateCall.CallCount++
+
+	return attachment, ar.CreateCall.Returns.Error
+}
+
+func (ar *AttachmentsRepo) Reference(conn models.ConnectionInterface, attachmentID, ownerID string) error {
+	ar.ReferenceCall.Receives.Connection = conn
+	ar.ReferenceCall.Receives.AttachmentIDs = append(ar.ReferenceCall.Receives.AttachmentIDs, attachmentID)
+	ar.ReferenceCall.Receives.OwnerIDs = append(ar.ReferenceCall.Receives.OwnerIDs, ownerID)
+	ar.ReferenceCall.CallCount++
+
+	return ar.ReferenceCall.Returns.Error
+}
+
+func (ar *AttachmentsRepo) FindByID(conn models.ConnectionInterface, attachmentID string) (models.Attachment, error) {
+	ar.FindByIDCall.Receives.Connection = conn
+	ar.FindByIDCall.Receives.AttachmentIDs = append(ar.FindByIDCall.Receives.AttachmentIDs, attachmentID)
+
+	return ar.FindByIDCall.Returns.Attachments[attachmentID], ar.FindByIDCall.Returns.Error
+}
+
+func (ar *AttachmentsRepo) DeleteBefore(conn models.ConnectionInterface, thresholdTime time.Time, limit int) (int, error) {
+	ar.DeleteBeforeCall.Receives.Connection = conn
+	ar.DeleteBeforeCall.Receives.ThresholdTime = thresholdTime
+	ar.DeleteBeforeCall.Receives.Limit = limit
+
+	var rowsAffected int
+	if ar.DeleteBeforeCall.CallCount < len(ar.DeleteBeforeCall.Returns.RowsAffected) {
+		rowsAffected = ar.DeleteBeforeCall.Returns.RowsAffected[ar.DeleteBeforeCall.CallCount]
+	}
+	ar.DeleteBeforeCall.CallCount++
+
+	return rowsAffected, ar.DeleteBeforeCall.Returns.Error
+}
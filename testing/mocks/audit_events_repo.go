@@ -0,0 +1,46 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type AuditEventsRepo struct {
+	CreateCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Event      models.AuditEvent
+		}
+		Returns struct {
+			Event models.AuditEvent
+			Error error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.AuditEventFilter
+		}
+		Returns struct {
+			Events []models.AuditEvent
+			Total  int
+			Error  error
+		}
+	}
+}
+
+func NewAuditEventsRepo() *AuditEventsRepo {
+	return &AuditEventsRepo{}
+}
+
+func (r *AuditEventsRepo) Create(connection models.ConnectionInterface, event models.AuditEvent) (models.AuditEvent, error) {
+	r.CreateCall.Receives.Connection = connection
+	r.CreateCall.Receives.Event = event
+
+	return r.CreateCall.Returns.Event, r.CreateCall.Returns.Error
+}
+
+func (r *AuditEventsRepo) List(connection models.ConnectionInterface, filter models.AuditEventFilter) ([]models.AuditEvent, int, error) {
+	r.ListCall.Receives.Connection = connection
+	r.ListCall.Receives.Filter = filter
+
+	return r.ListCall.Returns.Events, r.ListCall.Returns.Total, r.ListCall.Returns.Error
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type AuditLog struct {
+	RecordCall struct {
+		CallCount int
+		Receives  struct {
+			Connection services.ConnectionInterface
+			Entry      services.AuditEntry
+			Entries    []services.AuditEntry
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Database services.DatabaseInterface
+			Filter   models.AuditEventFilter
+		}
+		Returns struct {
+			Events []models.AuditEvent
+			Total  int
+			Error  error
+		}
+	}
+}
+
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+func (l *AuditLog) Record(conn services.ConnectionInterface, entry services.AuditEntry) error {
+	l.RecordCall.CallCount++
+	l.RecordCall.Receives.Connection = conn
+	l.RecordCall.Receives.Entry = entry
+	l.RecordCall.Receives.Entries = append(l.RecordCall.Receives.Entries, entry)
+
+	return l.RecordCall.Returns.Error
+}
+
+func (l *AuditLog) List(database services.DatabaseInterface, filter models.AuditEventFilter) ([]models.AuditEvent, int, error) {
+	l.ListCall.Receives.Database = database
+	l.ListCall.Receives.Filter = filter
+
+	return l.ListCall.Returns.Events, l.ListCall.Returns.Total, l.ListCall.Returns.Error
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+)
+
+type BounceProcessor struct {
+	ProcessCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Reports    []bounces.Report
+			Logger     lager.Logger
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewBounceProcessor() *BounceProcessor {
+	return &BounceProcessor{}
+}
+
+func (p *BounceProcessor) Process(conn models.ConnectionInterface, report bounces.Report, logger lager.Logger) error {
+	p.ProcessCall.Receives.Connection = conn
+	p.ProcessCall.Receives.Reports = append(p.ProcessCall.Receives.Reports, report)
+	p.ProcessCall.Receives.Logger = logger
+	p.ProcessCall.CallCount++
+
+	return p.ProcessCall.Returns.Error
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type ClientLimitsUpdater struct {
+	UpdateCall struct {
+		Receives struct {
+			Database services.DatabaseInterface
+			ClientID string
+			Limits   services.ClientLimits
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewClientLimitsUpdater() *ClientLimitsUpdater {
+	return &ClientLimitsUpdater{}
+}
+
+func (u *ClientLimitsUpdater) Update(database services.DatabaseInterface, clientID string, limits services.ClientLimits) error {
+	u.UpdateCall.Receives.Database = database
+	u.UpdateCall.Receives.ClientID = clientID
+	u.UpdateCall.Receives.Limits = limits
+
+	return u.UpdateCall.Returns.Error
+}
@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type ClientRecipientCountsRepo struct {
+	CountCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			Day        time.Time
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+
+	ReserveCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			Day        time.Time
+			Recipients int
+			Quota      int
+		}
+		Returns struct {
+			Reserved bool
+			Error    error
+		}
+	}
+
+	IncrementCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			Day        time.Time
+			Recipients int
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewClientRecipientCountsRepo() *ClientRecipientCountsRepo {
+	return &ClientRecipientCountsRepo{}
+}
+
+func (r *ClientRecipientCountsRepo) Count(conn models.ConnectionInterface, clientID string, day time.Time) (int, error) {
+	r.CountCall.Receives.Connection = conn
+	r.CountCall.Receives.ClientID = clientID
+	r.CountCall.Receives.Day = day
+
+	return r.CountCall.Returns.Count, r.CountCall.Returns.Error
+}
+
+func (r *ClientRecipientCountsRepo) Reserve(conn models.ConnectionInterface, clientID string, day time.Time, recipients, quota int) (bool, error) {
+	r.ReserveCall.CallCount++
+	r.ReserveCall.Receives.Connection = conn
+	r.ReserveCall.Receives.ClientID = clientID
+	r.ReserveCall.Receives.Day = day
+	r.ReserveCall.Receives.Recipients = recipients
+	r.ReserveCall.Receives.Quota = quota
+
+	return r.ReserveCall.Returns.Reserved, r.ReserveCall.Returns.Error
+}
+
+func (r *ClientRecipientCountsRepo) Increment(conn models.ConnectionInterface, clientID string, day time.Time, recipients int) error {
+	r.IncrementCall.CallCount++
+	r.IncrementCall.Receives.Connection = conn
+	r.IncrementCall.Receives.ClientID = clientID
+	r.IncrementCall.Receives.Day = day
+	r.IncrementCall.Receives.Recipients = recipients
+
+	return r.IncrementCall.Returns.Error
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import "time"
+
+type DomainThrottle struct {
+	ReserveCall struct {
+		CallCount int
+		Receives  struct {
+			Addresses []string
+		}
+		Returns struct {
+			Wait time.Duration
+		}
+	}
+}
+
+func NewDomainThrottle() *DomainThrottle {
+	return &DomainThrottle{}
+}
+
+func (t *DomainThrottle) Reserve(addresses []string) time.Duration {
+	t.ReserveCall.CallCount++
+	t.ReserveCall.Receives.Addresses = addresses
+
+	return t.ReserveCall.Returns.Wait
+}
@@ -10,6 +10,7 @@ import (
 type Enqueuer struct {
 	EnqueueCall struct {
 		WasCalled bool
+		CallCount int
 		Receives  struct {
 			Connection      services.ConnectionInterface
 			Users           []services.User
@@ -17,8 +18,32 @@ type Enqueuer struct {
 			Space           cf.CloudControllerSpace
 			Org             cf.CloudControllerOrganization
 			Client          string
+			RecipientQuota  int
 			Scope           string
 			VCAPRequestID   string
+			TraceParent     string
+			RequestReceived time.Time
+			UAAHost         string
+		}
+		Returns struct {
+			Responses []services.Response
+			Err       error
+		}
+	}
+
+	EnqueueWithinCall struct {
+		WasCalled bool
+		CallCount int
+		Receives  struct {
+			Connection      services.ConnectionInterface
+			Users           []services.User
+			Options         services.Options
+			Space           cf.CloudControllerSpace
+			Org             cf.CloudControllerOrganization
+			Client          string
+			Scope           string
+			VCAPRequestID   string
+			TraceParent     string
 			RequestReceived time.Time
 			UAAHost         string
 		}
@@ -40,9 +65,11 @@ func (m *Enqueuer) Enqueue(
 	space cf.CloudControllerSpace,
 	org cf.CloudControllerOrganization,
 	client string,
+	recipientQuota int,
 	uaaHost string,
 	scope string,
 	vcapRequestID string,
+	traceParent string,
 	reqReceived time.Time) ([]services.Response, error) {
 
 	m.EnqueueCall.Receives.Connection = conn
@@ -51,11 +78,44 @@ func (m *Enqueuer) Enqueue(
 	m.EnqueueCall.Receives.Space = space
 	m.EnqueueCall.Receives.Org = org
 	m.EnqueueCall.Receives.Client = client
+	m.EnqueueCall.Receives.RecipientQuota = recipientQuota
 	m.EnqueueCall.Receives.UAAHost = uaaHost
 	m.EnqueueCall.Receives.Scope = scope
 	m.EnqueueCall.Receives.VCAPRequestID = vcapRequestID
+	m.EnqueueCall.Receives.TraceParent = traceParent
 	m.EnqueueCall.Receives.RequestReceived = reqReceived
 
 	m.EnqueueCall.WasCalled = true
+	m.EnqueueCall.CallCount++
 	return m.EnqueueCall.Returns.Responses, m.EnqueueCall.Returns.Err
 }
+
+func (m *Enqueuer) EnqueueWithin(
+	conn services.ConnectionInterface,
+	users []services.User,
+	options services.Options,
+	space cf.CloudControllerSpace,
+	org cf.CloudControllerOrganization,
+	client string,
+	uaaHost string,
+	scope string,
+	vcapRequestID string,
+	traceParent string,
+	reqReceived time.Time) ([]services.Response, error) {
+
+	m.EnqueueWithinCall.Receives.Connection = conn
+	m.EnqueueWithinCall.Receives.Users = users
+	m.EnqueueWithinCall.Receives.Options = options
+	m.EnqueueWithinCall.Receives.Space = space
+	m.EnqueueWithinCall.Receives.Org = org
+	m.EnqueueWithinCall.Receives.Client = client
+	m.EnqueueWithinCall.Receives.UAAHost = uaaHost
+	m.EnqueueWithinCall.Receives.Scope = scope
+	m.EnqueueWithinCall.Receives.VCAPRequestID = vcapRequestID
+	m.EnqueueWithinCall.Receives.TraceParent = traceParent
+	m.EnqueueWithinCall.Receives.RequestReceived = reqReceived
+
+	m.EnqueueWithinCall.WasCalled = true
+	m.EnqueueWithinCall.CallCount++
+	return m.EnqueueWithinCall.Returns.Responses, m.EnqueueWithinCall.Returns.Err
+}
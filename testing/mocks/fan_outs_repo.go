@@ -0,0 +1,127 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type FanOutsRepo struct {
+	CreateCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			FanOut     models.FanOut
+		}
+		Returns struct {
+			FanOut models.FanOut
+			Error  error
+		}
+	}
+
+	FindByIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			FanOutID   string
+		}
+		Returns struct {
+			FanOut models.FanOut
+			Error  error
+		}
+	}
+
+	AdvanceCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			FanOut     models.FanOut
+			Enqueued   []int
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	SetStatusCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			FanOut     models.FanOut
+			Statuses   []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DeleteBeforeCall struct {
+		CallCount int
+		Receives  struct {
+			Connection    models.ConnectionInterface
+			ThresholdTime time.Time
+			Limit         int
+		}
+		Returns struct {
+			RowsAffected []int
+			Error        error
+		}
+	}
+}
+
+func NewFanOutsRepo() *FanOutsRepo {
+	return &FanOutsRepo{}
+}
+
+func (r *FanOutsRepo) Create(conn models.ConnectionInterface, fanOut models.FanOut) (models.FanOut, error) {
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.FanOut = fanOut
+
+	return r.CreateCall.Returns.FanOut, r.CreateCall.Returns.Error
+}
+
+func (r *FanOutsRepo) FindByID(conn models.ConnectionInterface, fanOutID string) (models.FanOut, error) {
+	r.FindByIDCall.Receives.Connection = conn
+	r.FindByIDCall.Receives.FanOutID = fanOutID
+
+	return r.FindByIDCall.Returns.FanOut, r.FindByIDCall.Returns.Error
+}
+
+func (r *FanOutsRepo) Advance(conn models.ConnectionInterface, fanOut models.FanOut, enqueued int) (models.FanOut, error) {
+	r.AdvanceCall.Receives.Connection = conn
+	r.AdvanceCall.Receives.FanOut = fanOut
+	r.AdvanceCall.Receives.Enqueued = append(r.AdvanceCall.Receives.Enqueued, enqueued)
+	r.AdvanceCall.CallCount++
+
+	if r.AdvanceCall.Returns.Error != nil {
+		return models.FanOut{}, r.AdvanceCall.Returns.Error
+	}
+
+	fanOut.Status = models.FanOutStatusInProgress
+	fanOut.Enqueued = enqueued
+
+	return fanOut, nil
+}
+
+func (r *FanOutsRepo) SetStatus(conn models.ConnectionInterface, fanOut models.FanOut, status string) (models.FanOut, error) {
+	r.SetStatusCall.Receives.Connection = conn
+	r.SetStatusCall.Receives.FanOut = fanOut
+	r.SetStatusCall.Receives.Statuses = append(r.SetStatusCall.Receives.Statuses, status)
+	r.SetStatusCall.CallCount++
+
+	fanOut.Status = status
+
+	return fanOut, r.SetStatusCall.Returns.Error
+}
+
+func (r *FanOutsRepo) DeleteBefore(conn models.ConnectionInterface, thresholdTime time.Time, limit int) (int, error) {
+	r.DeleteBeforeCall.Receives.Connection = conn
+	r.DeleteBeforeCall.Receives.ThresholdTime = thresholdTime
+	r.DeleteBeforeCall.Receives.Limit = limit
+
+	var rowsAffected int
+	if r.DeleteBeforeCall.CallCount < len(r.DeleteBeforeCall.Returns.RowsAffected) {
+		rowsAffected = r.DeleteBeforeCall.Returns.RowsAffected[r.DeleteBeforeCall.CallCount]
+	}
+	r.DeleteBeforeCall.CallCount++
+
+	return rowsAffected, r.DeleteBeforeCall.Returns.Error
+}
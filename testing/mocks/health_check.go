@@ -0,0 +1,20 @@
+package mocks
+
+type HealthCheck struct {
+	CheckCall struct {
+		CallCount int
+		Returns   struct {
+			Error error
+		}
+	}
+}
+
+func NewHealthCheck() *HealthCheck {
+	return &HealthCheck{}
+}
+
+func (c *HealthCheck) Check() error {
+	c.CheckCall.CallCount++
+
+	return c.CheckCall.Returns.Error
+}
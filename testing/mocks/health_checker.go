@@ -0,0 +1,20 @@
+package mocks
+
+type HealthChecker struct {
+	RunCall struct {
+		CallCount int
+		Returns   struct {
+			Results map[string]error
+		}
+	}
+}
+
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{}
+}
+
+func (c *HealthChecker) Run() map[string]error {
+	c.RunCall.CallCount++
+
+	return c.RunCall.Returns.Results
+}
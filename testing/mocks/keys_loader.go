@@ -0,0 +1,19 @@
+package mocks
+
+import "time"
+
+type KeysLoader struct {
+	KeysLoadedAtCall struct {
+		Returns struct {
+			Time time.Time
+		}
+	}
+}
+
+func NewKeysLoader() *KeysLoader {
+	return &KeysLoader{}
+}
+
+func (l *KeysLoader) KeysLoadedAt() time.Time {
+	return l.KeysLoadedAtCall.Returns.Time
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import "time"
+
+type LeaseStore struct {
+	AcquireCall struct {
+		CallCount int
+		Receives  struct {
+			Name     string
+			Holder   string
+			Now      time.Time
+			Duration time.Duration
+		}
+		Returns struct {
+			Acquired bool
+			Error    error
+		}
+	}
+
+	ReleaseCall struct {
+		CallCount int
+		Receives  struct {
+			Name   string
+			Holder string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewLeaseStore() *LeaseStore {
+	return &LeaseStore{}
+}
+
+func (s *LeaseStore) Acquire(name, holder string, now time.Time, duration time.Duration) (bool, error) {
+	s.AcquireCall.CallCount++
+	s.AcquireCall.Receives.Name = name
+	s.AcquireCall.Receives.Holder = holder
+	s.AcquireCall.Receives.Now = now
+	s.AcquireCall.Receives.Duration = duration
+
+	return s.AcquireCall.Returns.Acquired, s.AcquireCall.Returns.Error
+}
+
+func (s *LeaseStore) Release(name, holder string) error {
+	s.ReleaseCall.CallCount++
+	s.ReleaseCall.Receives.Name = name
+	s.ReleaseCall.Receives.Holder = holder
+
+	return s.ReleaseCall.Returns.Error
+}
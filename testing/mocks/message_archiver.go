@@ -0,0 +1,28 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type MessageArchiver struct {
+	ArchiveCall struct {
+		CallCount int
+		Receives  struct {
+			Batches    [][]models.Message
+			Recipients [][]models.MessageRecipient
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewMessageArchiver() *MessageArchiver {
+	return &MessageArchiver{}
+}
+
+func (a *MessageArchiver) Archive(messages []models.Message, recipients []models.MessageRecipient) error {
+	a.ArchiveCall.Receives.Batches = append(a.ArchiveCall.Receives.Batches, messages)
+	a.ArchiveCall.Receives.Recipients = append(a.ArchiveCall.Receives.Recipients, recipients)
+	a.ArchiveCall.CallCount++
+
+	return a.ArchiveCall.Returns.Error
+}
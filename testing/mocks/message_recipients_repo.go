@@ -0,0 +1,90 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type MessageRecipientsRepo struct {
+	CreateCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Recipients []models.MessageRecipient
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindByMessageIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			MessageID  string
+		}
+		Returns struct {
+			Recipients []models.MessageRecipient
+			Error      error
+		}
+	}
+
+	FindByMessageIDsCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			MessageIDs []string
+		}
+		Returns struct {
+			Recipients []models.MessageRecipient
+			Error      error
+		}
+	}
+
+	UpdateStatusCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			MessageID  string
+			Statuses   map[string]string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewMessageRecipientsRepo() *MessageRecipientsRepo {
+	return &MessageRecipientsRepo{}
+}
+
+func (mr *MessageRecipientsRepo) Create(conn models.ConnectionInterface, recipient models.MessageRecipient) (models.MessageRecipient, error) {
+	mr.CreateCall.Receives.Connection = conn
+	mr.CreateCall.Receives.Recipients = append(mr.CreateCall.Receives.Recipients, recipient)
+	mr.CreateCall.CallCount++
+
+	return recipient, mr.CreateCall.Returns.Error
+}
+
+func (mr *MessageRecipientsRepo) FindByMessageID(conn models.ConnectionInterface, messageID string) ([]models.MessageRecipient, error) {
+	mr.FindByMessageIDCall.Receives.Connection = conn
+	mr.FindByMessageIDCall.Receives.MessageID = messageID
+
+	return mr.FindByMessageIDCall.Returns.Recipients, mr.FindByMessageIDCall.Returns.Error
+}
+
+func (mr *MessageRecipientsRepo) FindByMessageIDs(conn models.ConnectionInterface, messageIDs []string) ([]models.MessageRecipient, error) {
+	mr.FindByMessageIDsCall.Receives.Connection = conn
+	mr.FindByMessageIDsCall.Receives.MessageIDs = messageIDs
+	mr.FindByMessageIDsCall.CallCount++
+
+	return mr.FindByMessageIDsCall.Returns.Recipients, mr.FindByMessageIDsCall.Returns.Error
+}
+
+func (mr *MessageRecipientsRepo) UpdateStatus(conn models.ConnectionInterface, messageID, address, status string) error {
+	mr.UpdateStatusCall.Receives.Connection = conn
+	mr.UpdateStatusCall.Receives.MessageID = messageID
+	if mr.UpdateStatusCall.Receives.Statuses == nil {
+		mr.UpdateStatusCall.Receives.Statuses = map[string]string{}
+	}
+	mr.UpdateStatusCall.Receives.Statuses[address] = status
+	mr.UpdateStatusCall.CallCount++
+
+	return mr.UpdateStatusCall.Returns.Error
+}
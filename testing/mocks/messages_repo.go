@@ -41,12 +41,26 @@ type MessagesRepo struct {
 		}
 	}
 
-	DeleteBeforeCall struct {
+	FindExpiredCall struct {
 		InvocationTimes []time.Time
 		CallCount       int
 		Receives        struct {
-			Connection    models.ConnectionInterface
-			ThresholdTime time.Time
+			Connection models.ConnectionInterface
+			Policy     models.RetentionPolicy
+			Now        time.Time
+			Limit      int
+		}
+		Returns struct {
+			Batches [][]models.Message
+			Error   error
+		}
+	}
+
+	DeleteCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			MessageIDs [][]string
 		}
 		Returns struct {
 			RowsAffected int
@@ -83,11 +97,26 @@ func (mr *MessagesRepo) FindByID(conn models.ConnectionInterface, messageID stri
 	return mr.FindByIDCall.Returns.Message, mr.FindByIDCall.Returns.Error
 }
 
-func (mr *MessagesRepo) DeleteBefore(conn models.ConnectionInterface, thresholdTime time.Time) (int, error) {
-	mr.DeleteBeforeCall.Receives.Connection = conn
-	mr.DeleteBeforeCall.Receives.ThresholdTime = thresholdTime
-	mr.DeleteBeforeCall.InvocationTimes = append(mr.DeleteBeforeCall.InvocationTimes, time.Now())
-	mr.DeleteBeforeCall.CallCount++
+func (mr *MessagesRepo) FindExpired(conn models.ConnectionInterface, policy models.RetentionPolicy, now time.Time, limit int) ([]models.Message, error) {
+	mr.FindExpiredCall.Receives.Connection = conn
+	mr.FindExpiredCall.Receives.Policy = policy
+	mr.FindExpiredCall.Receives.Now = now
+	mr.FindExpiredCall.Receives.Limit = limit
+	mr.FindExpiredCall.InvocationTimes = append(mr.FindExpiredCall.InvocationTimes, time.Now())
+
+	var messages []models.Message
+	if mr.FindExpiredCall.CallCount < len(mr.FindExpiredCall.Returns.Batches) {
+		messages = mr.FindExpiredCall.Returns.Batches[mr.FindExpiredCall.CallCount]
+	}
+	mr.FindExpiredCall.CallCount++
+
+	return messages, mr.FindExpiredCall.Returns.Error
+}
+
+func (mr *MessagesRepo) Delete(conn models.ConnectionInterface, messageIDs []string) (int, error) {
+	mr.DeleteCall.Receives.Connection = conn
+	mr.DeleteCall.Receives.MessageIDs = append(mr.DeleteCall.Receives.MessageIDs, messageIDs)
+	mr.DeleteCall.CallCount++
 
-	return mr.DeleteBeforeCall.Returns.RowsAffected, mr.DeleteBeforeCall.Returns.Error
+	return mr.DeleteCall.Returns.RowsAffected, mr.DeleteCall.Returns.Error
 }
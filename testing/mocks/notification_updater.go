@@ -8,7 +8,7 @@ import (
 type NotificationUpdater struct {
 	UpdateCall struct {
 		Receives struct {
-			Database     services.DatabaseInterface
+			Connection   services.ConnectionInterface
 			Notification models.Kind
 		}
 		Returns struct {
@@ -17,8 +17,8 @@ type NotificationUpdater struct {
 	}
 }
 
-func (f *NotificationUpdater) Update(database services.DatabaseInterface, notification models.Kind) error {
-	f.UpdateCall.Receives.Database = database
+func (f *NotificationUpdater) Update(conn services.ConnectionInterface, notification models.Kind) error {
+	f.UpdateCall.Receives.Connection = conn
 	f.UpdateCall.Receives.Notification = notification
 
 	return f.UpdateCall.Returns.Error
@@ -0,0 +1,78 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/collections"
+
+type PartialsCollection struct {
+	SetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Partial    collections.Partial
+		}
+		Returns struct {
+			Partial collections.Partial
+			Error   error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Name       string
+		}
+		Returns struct {
+			Partial collections.Partial
+			Error   error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+		}
+		Returns struct {
+			Partials []collections.Partial
+			Error    error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Name       string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewPartialsCollection() *PartialsCollection {
+	return &PartialsCollection{}
+}
+
+func (c *PartialsCollection) Set(connection collections.ConnectionInterface, partial collections.Partial) (collections.Partial, error) {
+	c.SetCall.Receives.Connection = connection
+	c.SetCall.Receives.Partial = partial
+
+	return c.SetCall.Returns.Partial, c.SetCall.Returns.Error
+}
+
+func (c *PartialsCollection) Get(connection collections.ConnectionInterface, name string) (collections.Partial, error) {
+	c.GetCall.Receives.Connection = connection
+	c.GetCall.Receives.Name = name
+
+	return c.GetCall.Returns.Partial, c.GetCall.Returns.Error
+}
+
+func (c *PartialsCollection) List(connection collections.ConnectionInterface) ([]collections.Partial, error) {
+	c.ListCall.Receives.Connection = connection
+
+	return c.ListCall.Returns.Partials, c.ListCall.Returns.Error
+}
+
+func (c *PartialsCollection) Delete(connection collections.ConnectionInterface, name string) error {
+	c.DeleteCall.Receives.Connection = connection
+	c.DeleteCall.Receives.Name = name
+
+	return c.DeleteCall.Returns.Error
+}
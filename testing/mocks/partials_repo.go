@@ -0,0 +1,107 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type PartialsRepo struct {
+	FindByNameCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Name       string
+		}
+		Returns struct {
+			Partial models.Partial
+			Error   error
+		}
+	}
+
+	FindAllCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+		}
+		Returns struct {
+			Partials []models.Partial
+			Error    error
+		}
+	}
+
+	FindByNamesCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Names      [][]string
+		}
+		Returns struct {
+			Partials []models.Partial
+			Error    error
+		}
+	}
+
+	UpsertCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Partial    models.Partial
+		}
+		Returns struct {
+			Partial models.Partial
+			Error   error
+		}
+	}
+
+	DestroyCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Name       string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewPartialsRepo() *PartialsRepo {
+	return &PartialsRepo{}
+}
+
+func (r *PartialsRepo) FindByName(conn models.ConnectionInterface, name string) (models.Partial, error) {
+	r.FindByNameCall.Receives.Connection = conn
+	r.FindByNameCall.Receives.Name = name
+
+	return r.FindByNameCall.Returns.Partial, r.FindByNameCall.Returns.Error
+}
+
+func (r *PartialsRepo) FindAll(conn models.ConnectionInterface) ([]models.Partial, error) {
+	r.FindAllCall.Receives.Connection = conn
+
+	return r.FindAllCall.Returns.Partials, r.FindAllCall.Returns.Error
+}
+
+func (r *PartialsRepo) FindByNames(conn models.ConnectionInterface, names []string) ([]models.Partial, error) {
+	r.FindByNamesCall.Receives.Connection = conn
+	r.FindByNamesCall.Receives.Names = append(r.FindByNamesCall.Receives.Names, names)
+	r.FindByNamesCall.CallCount++
+
+	var partials []models.Partial
+	for _, partial := range r.FindByNamesCall.Returns.Partials {
+		for _, name := range names {
+			if partial.Name == name {
+				partials = append(partials, partial)
+			}
+		}
+	}
+
+	return partials, r.FindByNamesCall.Returns.Error
+}
+
+func (r *PartialsRepo) Upsert(conn models.ConnectionInterface, partial models.Partial) (models.Partial, error) {
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Partial = partial
+
+	return r.UpsertCall.Returns.Partial, r.UpsertCall.Returns.Error
+}
+
+func (r *PartialsRepo) Destroy(conn models.ConnectionInterface, name string) error {
+	r.DestroyCall.Receives.Connection = conn
+	r.DestroyCall.Receives.Name = name
+
+	return r.DestroyCall.Returns.Error
+}
@@ -0,0 +1,20 @@
+package mocks
+
+type Pinger struct {
+	PingCall struct {
+		CallCount int
+		Returns   struct {
+			Error error
+		}
+	}
+}
+
+func NewPinger() *Pinger {
+	return &Pinger{}
+}
+
+func (p *Pinger) Ping() error {
+	p.PingCall.CallCount++
+
+	return p.PingCall.Returns.Error
+}
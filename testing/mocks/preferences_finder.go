@@ -4,13 +4,15 @@ import "github.com/cloudfoundry-incubator/notifications/v1/services"
 
 type PreferencesFinder struct {
 	FindCall struct {
-		Receives struct {
-			Database services.DatabaseInterface
-			UserGUID string
+		CallCount int
+		Receives  struct {
+			Connection services.ConnectionInterface
+			UserGUID   string
 		}
 		Returns struct {
-			PreferencesBuilder services.PreferencesBuilder
-			Error              error
+			PreferencesBuilder  services.PreferencesBuilder
+			PreferencesBuilders []services.PreferencesBuilder
+			Error               error
 		}
 	}
 }
@@ -19,9 +21,15 @@ func NewPreferencesFinder() *PreferencesFinder {
 	return &PreferencesFinder{}
 }
 
-func (pb *PreferencesFinder) Find(database services.DatabaseInterface, userGUID string) (services.PreferencesBuilder, error) {
-	pb.FindCall.Receives.Database = database
+func (pb *PreferencesFinder) Find(conn services.ConnectionInterface, userGUID string) (services.PreferencesBuilder, error) {
+	pb.FindCall.Receives.Connection = conn
 	pb.FindCall.Receives.UserGUID = userGUID
 
-	return pb.FindCall.Returns.PreferencesBuilder, pb.FindCall.Returns.Error
+	builder := pb.FindCall.Returns.PreferencesBuilder
+	if pb.FindCall.CallCount < len(pb.FindCall.Returns.PreferencesBuilders) {
+		builder = pb.FindCall.Returns.PreferencesBuilders[pb.FindCall.CallCount]
+	}
+	pb.FindCall.CallCount++
+
+	return builder, pb.FindCall.Returns.Error
 }
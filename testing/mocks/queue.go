@@ -22,6 +22,12 @@ type Queue struct {
 		}
 	}
 
+	TouchCall struct {
+		Receives struct {
+			Job *gobble.Job
+		}
+	}
+
 	DequeueCall struct {
 		Receives struct {
 			Job *gobble.Job
@@ -75,6 +81,10 @@ func (q *Queue) Requeue(job *gobble.Job) {
 	q.RequeueCall.Receives.Job = job
 }
 
+func (q *Queue) Touch(job *gobble.Job) {
+	q.TouchCall.Receives.Job = job
+}
+
 func (q *Queue) Len() (int, error) {
 	return q.LenCall.Returns.Length, q.LenCall.Returns.Error
 }
@@ -0,0 +1,26 @@
+package mocks
+
+import "github.com/pivotal-golang/lager"
+
+type SMTPVerifier struct {
+	VerifyCall struct {
+		CallCount int
+		Receives  struct {
+			Logger lager.Logger
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewSMTPVerifier() *SMTPVerifier {
+	return &SMTPVerifier{}
+}
+
+func (v *SMTPVerifier) Verify(logger lager.Logger) error {
+	v.VerifyCall.Receives.Logger = logger
+	v.VerifyCall.CallCount++
+
+	return v.VerifyCall.Returns.Error
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/tracing"
+
+type SpanExporter struct {
+	ExportCall struct {
+		CallCount int
+		Receives  struct {
+			Spans []tracing.Span
+		}
+	}
+}
+
+func NewSpanExporter() *SpanExporter {
+	return &SpanExporter{}
+}
+
+func (e *SpanExporter) Export(span tracing.Span) {
+	e.ExportCall.CallCount++
+	e.ExportCall.Receives.Spans = append(e.ExportCall.Receives.Spans, span)
+}
@@ -0,0 +1,96 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/collections"
+
+type SuppressionsCollection struct {
+	CreateCall struct {
+		Receives struct {
+			Connection  collections.ConnectionInterface
+			Suppression collections.Suppression
+		}
+		Returns struct {
+			Suppression collections.Suppression
+			Error       error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			ID         string
+		}
+		Returns struct {
+			Suppression collections.Suppression
+			Error       error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+		}
+		Returns struct {
+			Suppressions []collections.Suppression
+			Error        error
+		}
+	}
+
+	UpdateCall struct {
+		Receives struct {
+			Connection  collections.ConnectionInterface
+			Suppression collections.Suppression
+		}
+		Returns struct {
+			Suppression collections.Suppression
+			Error       error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			ID         string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewSuppressionsCollection() *SuppressionsCollection {
+	return &SuppressionsCollection{}
+}
+
+func (c *SuppressionsCollection) Create(conn collections.ConnectionInterface, suppression collections.Suppression) (collections.Suppression, error) {
+	c.CreateCall.Receives.Connection = conn
+	c.CreateCall.Receives.Suppression = suppression
+
+	return c.CreateCall.Returns.Suppression, c.CreateCall.Returns.Error
+}
+
+func (c *SuppressionsCollection) Get(conn collections.ConnectionInterface, id string) (collections.Suppression, error) {
+	c.GetCall.Receives.Connection = conn
+	c.GetCall.Receives.ID = id
+
+	return c.GetCall.Returns.Suppression, c.GetCall.Returns.Error
+}
+
+func (c *SuppressionsCollection) List(conn collections.ConnectionInterface) ([]collections.Suppression, error) {
+	c.ListCall.Receives.Connection = conn
+
+	return c.ListCall.Returns.Suppressions, c.ListCall.Returns.Error
+}
+
+func (c *SuppressionsCollection) Update(conn collections.ConnectionInterface, suppression collections.Suppression) (collections.Suppression, error) {
+	c.UpdateCall.Receives.Connection = conn
+	c.UpdateCall.Receives.Suppression = suppression
+
+	return c.UpdateCall.Returns.Suppression, c.UpdateCall.Returns.Error
+}
+
+func (c *SuppressionsCollection) Delete(conn collections.ConnectionInterface, id string) error {
+	c.DeleteCall.Receives.Connection = conn
+	c.DeleteCall.Receives.ID = id
+
+	return c.DeleteCall.Returns.Error
+}
@@ -0,0 +1,141 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type SuppressionsRepo struct {
+	AddCall struct {
+		CallCount int
+		Receives  struct {
+			Connection   models.ConnectionInterface
+			Suppressions []models.Suppression
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	MatchCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Addresses  []string
+			UserGUID   string
+		}
+		Returns struct {
+			Suppressions map[string][]models.Suppression
+			Error        error
+		}
+	}
+
+	CreateCall struct {
+		Receives struct {
+			Connection  models.ConnectionInterface
+			Suppression models.Suppression
+		}
+		Returns struct {
+			Suppression models.Suppression
+			Error       error
+		}
+	}
+
+	FindByIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ID         string
+		}
+		Returns struct {
+			Suppression models.Suppression
+			Error       error
+		}
+	}
+
+	FindAllCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+		}
+		Returns struct {
+			Suppressions []models.Suppression
+			Error        error
+		}
+	}
+
+	UpdateCall struct {
+		Receives struct {
+			Connection  models.ConnectionInterface
+			Suppression models.Suppression
+		}
+		Returns struct {
+			Suppression models.Suppression
+			Error       error
+		}
+	}
+
+	DestroyCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ID         string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewSuppressionsRepo() *SuppressionsRepo {
+	return &SuppressionsRepo{}
+}
+
+func (r *SuppressionsRepo) Add(conn models.ConnectionInterface, suppression models.Suppression) error {
+	r.AddCall.Receives.Connection = conn
+	r.AddCall.Receives.Suppressions = append(r.AddCall.Receives.Suppressions, suppression)
+	r.AddCall.CallCount++
+
+	return r.AddCall.Returns.Error
+}
+
+func (r *SuppressionsRepo) Match(conn models.ConnectionInterface, addresses []string, userGUID string) (map[string][]models.Suppression, error) {
+	r.MatchCall.Receives.Connection = conn
+	r.MatchCall.Receives.Addresses = addresses
+	r.MatchCall.Receives.UserGUID = userGUID
+	r.MatchCall.CallCount++
+
+	if r.MatchCall.Returns.Suppressions == nil {
+		return map[string][]models.Suppression{}, r.MatchCall.Returns.Error
+	}
+
+	return r.MatchCall.Returns.Suppressions, r.MatchCall.Returns.Error
+}
+
+func (r *SuppressionsRepo) Create(conn models.ConnectionInterface, suppression models.Suppression) (models.Suppression, error) {
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.Suppression = suppression
+
+	return r.CreateCall.Returns.Suppression, r.CreateCall.Returns.Error
+}
+
+func (r *SuppressionsRepo) FindByID(conn models.ConnectionInterface, id string) (models.Suppression, error) {
+	r.FindByIDCall.Receives.Connection = conn
+	r.FindByIDCall.Receives.ID = id
+
+	return r.FindByIDCall.Returns.Suppression, r.FindByIDCall.Returns.Error
+}
+
+func (r *SuppressionsRepo) FindAll(conn models.ConnectionInterface) ([]models.Suppression, error) {
+	r.FindAllCall.Receives.Connection = conn
+
+	return r.FindAllCall.Returns.Suppressions, r.FindAllCall.Returns.Error
+}
+
+func (r *SuppressionsRepo) Update(conn models.ConnectionInterface, suppression models.Suppression) (models.Suppression, error) {
+	r.UpdateCall.Receives.Connection = conn
+	r.UpdateCall.Receives.Suppression = suppression
+
+	return r.UpdateCall.Returns.Suppression, r.UpdateCall.Returns.Error
+}
+
+func (r *SuppressionsRepo) Destroy(conn models.ConnectionInterface, id string) error {
+	r.DestroyCall.Receives.Connection = conn
+	r.DestroyCall.Receives.ID = id
+
+	return r.DestroyCall.Returns.Error
+}
@@ -0,0 +1,45 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/collections"
+
+type TemplateBundler struct {
+	ExportCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+		}
+		Returns struct {
+			Bundle collections.TemplateBundle
+			Error  error
+		}
+	}
+
+	ImportCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Bundle     collections.TemplateBundle
+			Options    collections.ImportOptions
+		}
+		Returns struct {
+			Report collections.ImportReport
+			Error  error
+		}
+	}
+}
+
+func NewTemplateBundler() *TemplateBundler {
+	return &TemplateBundler{}
+}
+
+func (b *TemplateBundler) Export(connection collections.ConnectionInterface) (collections.TemplateBundle, error) {
+	b.ExportCall.Receives.Connection = connection
+
+	return b.ExportCall.Returns.Bundle, b.ExportCall.Returns.Error
+}
+
+func (b *TemplateBundler) Import(connection collections.ConnectionInterface, bundle collections.TemplateBundle, options collections.ImportOptions) (collections.ImportReport, error) {
+	b.ImportCall.Receives.Connection = connection
+	b.ImportCall.Receives.Bundle = bundle
+	b.ImportCall.Receives.Options = options
+
+	return b.ImportCall.Returns.Report, b.ImportCall.Returns.Error
+}
@@ -8,7 +8,7 @@ import (
 type TemplateUpdater struct {
 	UpdateCall struct {
 		Receives struct {
-			Database   services.DatabaseInterface
+			Connection services.ConnectionInterface
 			TemplateID string
 			Template   models.Template
 		}
@@ -22,8 +22,8 @@ func NewTemplateUpdater() *TemplateUpdater {
 	return &TemplateUpdater{}
 }
 
-func (tu *TemplateUpdater) Update(database services.DatabaseInterface, templateID string, template models.Template) error {
-	tu.UpdateCall.Receives.Database = database
+func (tu *TemplateUpdater) Update(conn services.ConnectionInterface, templateID string, template models.Template) error {
+	tu.UpdateCall.Receives.Connection = conn
 	tu.UpdateCall.Receives.TemplateID = templateID
 	tu.UpdateCall.Receives.Template = template
 
@@ -25,7 +25,8 @@ type ZonedUAAClient struct {
 	}
 
 	GetClientTokenCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			Host string
 		}
 		Returns struct {
@@ -35,7 +36,8 @@ type ZonedUAAClient struct {
 	}
 
 	UsersEmailsByIDsCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			Token string
 			IDs   []string
 		}
@@ -64,12 +66,14 @@ func (c *ZonedUAAClient) UsersGUIDsByScope(token, scope string) ([]string, error
 }
 
 func (c *ZonedUAAClient) GetClientToken(host string) (string, error) {
+	c.GetClientTokenCall.CallCount++
 	c.GetClientTokenCall.Receives.Host = host
 
 	return c.GetClientTokenCall.Returns.Token, c.GetClientTokenCall.Returns.Error
 }
 
 func (c *ZonedUAAClient) UsersEmailsByIDs(token string, ids ...string) ([]uaa.User, error) {
+	c.UsersEmailsByIDsCall.CallCount++
 	c.UsersEmailsByIDsCall.Receives.Token = token
 	c.UsersEmailsByIDsCall.Receives.IDs = ids
 
@@ -4,7 +4,8 @@ import "github.com/cloudfoundry-incubator/notifications/uaa"
 
 type UserLoader struct {
 	LoadCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			UserGUIDs []string
 			Token     string
 		}
@@ -22,6 +23,7 @@ func NewUserLoader() *UserLoader {
 func (ul *UserLoader) Load(userGUIDs []string, token string) (map[string]uaa.User, error) {
 	ul.LoadCall.Receives.UserGUIDs = userGUIDs
 	ul.LoadCall.Receives.Token = token
+	ul.LoadCall.CallCount++
 
 	return ul.LoadCall.Returns.Users, ul.LoadCall.Returns.Error
 }
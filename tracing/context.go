@@ -0,0 +1,14 @@
+package tracing
+
+import "context"
+
+type contextKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, contextKey{}, span)
+}
+
+func SpanFromContext(ctx context.Context) (*Span, bool) {
+	span, ok := ctx.Value(contextKey{}).(*Span)
+	return span, ok
+}
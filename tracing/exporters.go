@@ -0,0 +1,10 @@
+package tracing
+
+// The exporters that can be configured with TRACING_EXPORTER.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+var Exporters = []string{ExporterNone, ExporterStdout, ExporterOTLP}
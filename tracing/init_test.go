@@ -0,0 +1,13 @@
+package tracing_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTracingSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "tracing")
+}
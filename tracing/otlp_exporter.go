@@ -0,0 +1,226 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var otlpSpanKinds = map[string]int{
+	SpanKindInternal: 1,
+	SpanKindServer:   2,
+	SpanKindClient:   3,
+	SpanKindProducer: 4,
+	SpanKindConsumer: 5,
+}
+
+const otlpStatusError = 2
+
+type OTLPExporterConfig struct {
+	// Endpoint is the base URL of the collector; spans are posted to its
+	// /v1/traces path.
+	Endpoint      string
+	ServiceName   string
+	BatchSize     int
+	FlushInterval time.Duration
+
+	Client *http.Client
+	Logger lager.Logger
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector using OTLP over
+// HTTP with JSON encoding. Spans are buffered and sent in batches, either
+// when a batch is full or when the flush interval passes.
+type OTLPExporter struct {
+	url           string
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+	client        *http.Client
+	logger        lager.Logger
+
+	mutex   *sync.Mutex
+	pending []Span
+}
+
+func NewOTLPExporter(config OTLPExporterConfig) *OTLPExporter {
+	return &OTLPExporter{
+		url:           strings.TrimSuffix(config.Endpoint, "/") + "/v1/traces",
+		serviceName:   config.ServiceName,
+		batchSize:     config.BatchSize,
+		flushInterval: config.FlushInterval,
+		client:        config.Client,
+		logger:        config.Logger,
+		mutex:         &sync.Mutex{},
+	}
+}
+
+func (e *OTLPExporter) Export(span Span) {
+	e.mutex.Lock()
+	e.pending = append(e.pending, span)
+	full := len(e.pending) >= e.batchSize
+	e.mutex.Unlock()
+
+	if full {
+		go e.Flush()
+	}
+}
+
+// Run flushes the buffered spans every flush interval.
+func (e *OTLPExporter) Run() {
+	go func() {
+		for range time.Tick(e.flushInterval) {
+			e.Flush()
+		}
+	}()
+}
+
+// Flush sends the buffered spans to the collector. Spans that could not be
+// sent are dropped, so that an unavailable collector does not make the
+// buffer grow without bounds.
+func (e *OTLPExporter) Flush() error {
+	e.mutex.Lock()
+	spans := e.pending
+	e.pending = nil
+	e.mutex.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	err := e.send(spans)
+	if err != nil {
+		e.logger.Error("trace-export-failed", err, lager.Data{
+			"dropped_spans": len(spans),
+		})
+	}
+
+	return err
+}
+
+func (e *OTLPExporter) send(spans []Span) error {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return err
+	}
+
+	response, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+type otlpPayload struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string          `json:"key"`
+	Value otlpStringValue `json:"value"`
+}
+
+type otlpStringValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *OTLPExporter) payload(spans []Span) otlpPayload {
+	var converted []otlpSpan
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.Context.TraceID,
+			SpanID:            span.Context.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              otlpSpanKinds[span.Kind],
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+
+		if span.Error != "" {
+			s.Status = &otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+
+		converted = append(converted, s)
+	}
+
+	return otlpPayload{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: otlpAttributes(map[string]string{"service.name": e.serviceName}),
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: e.serviceName},
+				Spans: converted,
+			}},
+		}},
+	}
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	var keys []string
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var converted []otlpAttribute
+	for _, key := range keys {
+		converted = append(converted, otlpAttribute{
+			Key:   key,
+			Value: otlpStringValue{StringValue: attributes[key]},
+		})
+	}
+
+	return converted
+}
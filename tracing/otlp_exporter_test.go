@@ -0,0 +1,136 @@
+package tracing_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OTLPExporter", func() {
+	var (
+		exporter  *tracing.OTLPExporter
+		collector *httptest.Server
+		requests  chan *http.Request
+		bodies    chan []byte
+		status    int
+		span      tracing.Span
+	)
+
+	BeforeEach(func() {
+		requests = make(chan *http.Request, 10)
+		bodies = make(chan []byte, 10)
+		status = http.StatusOK
+
+		collector = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				panic(err)
+			}
+
+			requests <- req
+			bodies <- body
+			w.WriteHeader(status)
+		}))
+
+		exporter = tracing.NewOTLPExporter(tracing.OTLPExporterConfig{
+			Endpoint:      collector.URL + "/",
+			ServiceName:   "notifications",
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+			Client:        http.DefaultClient,
+			Logger:        lager.NewLogger("test"),
+		})
+
+		start := time.Unix(1792411200, 0)
+		span = tracing.Span{
+			Name: "POST /users/{user_id}",
+			Kind: tracing.SpanKindServer,
+			Context: tracing.SpanContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			},
+			ParentSpanID: "b7ad6b7169203331",
+			StartTime:    start,
+			EndTime:      start.Add(time.Second),
+			Attributes: map[string]string{
+				"http.method":      "POST",
+				"http.status_code": "500",
+			},
+			Error: "500 Internal Server Error",
+		}
+	})
+
+	AfterEach(func() {
+		collector.Close()
+	})
+
+	It("posts the buffered spans to the collector when flushed", func() {
+		exporter.Export(span)
+		Expect(exporter.Flush()).To(Succeed())
+
+		var request *http.Request
+		Eventually(requests).Should(Receive(&request))
+		Expect(request.Method).To(Equal("POST"))
+		Expect(request.URL.Path).To(Equal("/v1/traces"))
+		Expect(request.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		var body []byte
+		Eventually(bodies).Should(Receive(&body))
+		Expect(body).To(MatchJSON(`{
+			"resourceSpans": [{
+				"resource": {
+					"attributes": [{"key": "service.name", "value": {"stringValue": "notifications"}}]
+				},
+				"scopeSpans": [{
+					"scope": {"name": "notifications"},
+					"spans": [{
+						"traceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+						"spanId": "00f067aa0ba902b7",
+						"parentSpanId": "b7ad6b7169203331",
+						"name": "POST /users/{user_id}",
+						"kind": 2,
+						"startTimeUnixNano": "1792411200000000000",
+						"endTimeUnixNano": "1792411201000000000",
+						"attributes": [
+							{"key": "http.method", "value": {"stringValue": "POST"}},
+							{"key": "http.status_code", "value": {"stringValue": "500"}}
+						],
+						"status": {"code": 2, "message": "500 Internal Server Error"}
+					}]
+				}]
+			}]
+		}`))
+	})
+
+	It("sends a batch as soon as it is full", func() {
+		exporter.Export(span)
+		Consistently(requests, "50ms").ShouldNot(Receive())
+
+		exporter.Export(span)
+		Eventually(requests).Should(Receive())
+	})
+
+	It("does not send anything when no spans are buffered", func() {
+		Expect(exporter.Flush()).To(Succeed())
+		Consistently(requests, "50ms").ShouldNot(Receive())
+	})
+
+	It("drops the spans when the collector rejects them", func() {
+		status = http.StatusServiceUnavailable
+
+		exporter.Export(span)
+		Expect(exporter.Flush()).To(MatchError("collector responded with status 503"))
+
+		status = http.StatusOK
+		Expect(exporter.Flush()).To(Succeed())
+		Eventually(requests).Should(Receive())
+		Consistently(requests, "50ms").ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,47 @@
+package tracing
+
+import "time"
+
+const (
+	SpanKindInternal = "internal"
+	SpanKindServer   = "server"
+	SpanKindClient   = "client"
+	SpanKindProducer = "producer"
+	SpanKindConsumer = "consumer"
+)
+
+// Span records a single timed operation. A span is not safe for use by
+// several goroutines at once and is handed to the exporter when it ends.
+type Span struct {
+	Name         string
+	Kind         string
+	Context      SpanContext
+	ParentSpanID string
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]string
+	Error        string
+
+	tracer *Tracer
+}
+
+func (s *Span) SetAttribute(key, value string) {
+	if s.Attributes == nil {
+		s.Attributes = map[string]string{}
+	}
+
+	s.Attributes[key] = value
+}
+
+// RecordError marks the span as failed. Nil errors are ignored so that the
+// result of an operation can be passed in unchecked.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.Error = err.Error()
+	}
+}
+
+func (s *Span) End() {
+	s.EndTime = s.tracer.clock.Now()
+	s.tracer.exporter.Export(*s)
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// SpanContext identifies a span within a trace. It travels between processes
+// as a W3C traceparent value, in HTTP headers and in queued jobs.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// ParseTraceParent reads a W3C traceparent value such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceParent(value string) (SpanContext, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || !isHex(parts[3], 2) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	context := SpanContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+	}
+
+	if !isHex(context.TraceID, 32) || !isHex(context.SpanID, 16) || !context.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	return context, nil
+}
+
+// IsValid reports whether both IDs are set. Spans started without a valid
+// parent begin a new trace.
+func (c SpanContext) IsValid() bool {
+	return strings.Trim(c.TraceID, "0") != "" && strings.Trim(c.SpanID, "0") != ""
+}
+
+// TraceParent formats the context as a W3C traceparent value, or returns an
+// empty string when the context is not valid. Every span is sampled.
+func (c SpanContext) TraceParent() string {
+	if !c.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf("00-%s-%s-01", c.TraceID, c.SpanID)
+}
+
+func isHex(value string, length int) bool {
+	if len(value) != length || strings.ToLower(value) != value {
+		return false
+	}
+
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
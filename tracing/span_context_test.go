@@ -0,0 +1,60 @@
+package tracing_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpanContext", func() {
+	Describe("ParseTraceParent", func() {
+		It("reads the trace and span IDs", func() {
+			context, err := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(context).To(Equal(tracing.SpanContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			}))
+		})
+
+		It("accepts extra fields from later versions", func() {
+			context, err := tracing.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(context.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		})
+
+		It("rejects malformed values", func() {
+			for _, value := range []string{
+				"",
+				"garbage",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+				"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+				"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+			} {
+				_, err := tracing.ParseTraceParent(value)
+				Expect(err).To(MatchError(`invalid traceparent "`+value+`"`), value)
+			}
+		})
+	})
+
+	Describe("TraceParent", func() {
+		It("formats the context as a sampled traceparent", func() {
+			context := tracing.SpanContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			}
+
+			Expect(context.TraceParent()).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+		})
+
+		It("is empty for an invalid context", func() {
+			Expect(tracing.SpanContext{}.TraceParent()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"io"
+	"time"
+)
+
+// Exporter receives every span once it has ended.
+type Exporter interface {
+	Export(Span)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+// Tracer starts spans and hands them to its exporter when they end.
+type Tracer struct {
+	exporter Exporter
+	clock    clock
+	random   io.Reader
+}
+
+func NewTracer(exporter Exporter, clock clock, random io.Reader) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		clock:    clock,
+		random:   random,
+	}
+}
+
+// Start begins a span as a child of parent, or as the root of a new trace
+// when parent is not valid.
+func (t *Tracer) Start(name, kind string, parent SpanContext) *Span {
+	span := &Span{
+		Name:      name,
+		Kind:      kind,
+		StartTime: t.clock.Now(),
+		tracer:    t,
+	}
+
+	if parent.IsValid() {
+		span.Context.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.Context.TraceID = t.generateID(16)
+	}
+	span.Context.SpanID = t.generateID(8)
+
+	return span
+}
+
+// generateID falls back to an all-zero ID when the random source fails,
+// which leaves the span invalid rather than interrupting the traced work.
+func (t *Tracer) generateID(size int) string {
+	id := make([]byte, size)
+	io.ReadFull(t.random, id)
+
+	return hex.EncodeToString(id)
+}
+
+// NopExporter discards spans. It is used when tracing is disabled, so that
+// trace context is still propagated without anything being recorded.
+type NopExporter struct{}
+
+func (NopExporter) Export(Span) {}
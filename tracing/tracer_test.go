@@ -0,0 +1,94 @@
+package tracing_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracer", func() {
+	var (
+		tracer   *tracing.Tracer
+		exporter *mocks.SpanExporter
+		clock    *mocks.Clock
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		exporter = mocks.NewSpanExporter()
+		clock = mocks.NewClock()
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		clock.NowCall.Returns.Time = now
+
+		random := bytes.NewBuffer([]byte{
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10,
+			0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18,
+			0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28,
+		})
+
+		tracer = tracing.NewTracer(exporter, clock, random)
+	})
+
+	It("starts a new trace when there is no parent", func() {
+		span := tracer.Start("some-operation", tracing.SpanKindServer, tracing.SpanContext{})
+
+		Expect(span.Name).To(Equal("some-operation"))
+		Expect(span.Kind).To(Equal(tracing.SpanKindServer))
+		Expect(span.StartTime).To(Equal(now))
+		Expect(span.ParentSpanID).To(BeEmpty())
+		Expect(span.Context).To(Equal(tracing.SpanContext{
+			TraceID: "0102030405060708090a0b0c0d0e0f10",
+			SpanID:  "1112131415161718",
+		}))
+	})
+
+	It("continues the trace of the parent", func() {
+		parent := tracer.Start("parent", tracing.SpanKindServer, tracing.SpanContext{})
+		child := tracer.Start("child", tracing.SpanKindClient, parent.Context)
+
+		Expect(child.Context).To(Equal(tracing.SpanContext{
+			TraceID: "0102030405060708090a0b0c0d0e0f10",
+			SpanID:  "2122232425262728",
+		}))
+		Expect(child.ParentSpanID).To(Equal("1112131415161718"))
+	})
+
+	It("exports spans when they end", func() {
+		span := tracer.Start("some-operation", tracing.SpanKindInternal, tracing.SpanContext{})
+		span.SetAttribute("some-key", "some-value")
+		span.RecordError(nil)
+
+		clock.NowCall.Returns.Time = now.Add(time.Second)
+		span.End()
+
+		Expect(exporter.ExportCall.Receives.Spans).To(HaveLen(1))
+
+		exported := exporter.ExportCall.Receives.Spans[0]
+		Expect(exported.Name).To(Equal("some-operation"))
+		Expect(exported.StartTime).To(Equal(now))
+		Expect(exported.EndTime).To(Equal(now.Add(time.Second)))
+		Expect(exported.Attributes).To(Equal(map[string]string{"some-key": "some-value"}))
+		Expect(exported.Error).To(BeEmpty())
+	})
+
+	It("records errors on the span", func() {
+		span := tracer.Start("some-operation", tracing.SpanKindInternal, tracing.SpanContext{})
+		span.RecordError(errors.New("something went wrong"))
+		span.End()
+
+		Expect(exporter.ExportCall.Receives.Spans[0].Error).To(Equal("something went wrong"))
+	})
+
+	It("leaves the IDs invalid when the random source is exhausted", func() {
+		tracer = tracing.NewTracer(exporter, clock, bytes.NewBuffer(nil))
+
+		span := tracer.Start("some-operation", tracing.SpanKindInternal, tracing.SpanContext{})
+		Expect(span.Context.IsValid()).To(BeFalse())
+	})
+})
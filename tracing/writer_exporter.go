@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// WriterExporter writes each span as a line of JSON. Pointed at stdout it
+// makes traces visible in the logs without running a collector.
+type WriterExporter struct {
+	writer io.Writer
+	mutex  *sync.Mutex
+}
+
+func NewWriterExporter(writer io.Writer) WriterExporter {
+	return WriterExporter{
+		writer: writer,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+type writtenSpan struct {
+	TraceID      string            `json:"trace_id"`
+	SpanID       string            `json:"span_id"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+func (e WriterExporter) Export(span Span) {
+	line, err := json.Marshal(writtenSpan{
+		TraceID:      span.Context.TraceID,
+		SpanID:       span.Context.SpanID,
+		ParentSpanID: span.ParentSpanID,
+		Name:         span.Name,
+		Kind:         span.Kind,
+		StartTime:    span.StartTime,
+		EndTime:      span.EndTime,
+		Attributes:   span.Attributes,
+		Error:        span.Error,
+	})
+	if err != nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.writer.Write(append(line, '\n'))
+}
@@ -0,0 +1,53 @@
+package tracing_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriterExporter", func() {
+	It("writes each span as a line of JSON", func() {
+		buffer := bytes.NewBuffer([]byte{})
+		exporter := tracing.NewWriterExporter(buffer)
+
+		start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		exporter.Export(tracing.Span{
+			Name: "smtp-send",
+			Kind: tracing.SpanKindClient,
+			Context: tracing.SpanContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			},
+			ParentSpanID: "b7ad6b7169203331",
+			StartTime:    start,
+			EndTime:      start.Add(250 * time.Millisecond),
+			Attributes:   map[string]string{"message_id": "some-message-id"},
+			Error:        "connection refused",
+		})
+		exporter.Export(tracing.Span{Name: "other"})
+
+		lines := bytes.Split(bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(MatchJSON(`{
+			"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id": "00f067aa0ba902b7",
+			"parent_span_id": "b7ad6b7169203331",
+			"name": "smtp-send",
+			"kind": "client",
+			"start_time": "2026-10-19T12:00:00Z",
+			"end_time": "2026-10-19T12:00:00.25Z",
+			"attributes": {"message_id": "some-message-id"},
+			"error": "connection refused"
+		}`))
+
+		var second map[string]interface{}
+		Expect(json.Unmarshal(lines[1], &second)).To(Succeed())
+		Expect(second["name"]).To(Equal("other"))
+	})
+})
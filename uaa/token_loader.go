@@ -1,30 +1,135 @@
 package uaa
 
 import (
+	"encoding/json"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
+	"github.com/dgrijalva/jwt-go"
 	metrics "github.com/rcrowley/go-metrics"
 )
 
+// TokenExpiryMargin is how long before it expires a cached client token is
+// replaced, so that it does not expire while a request is using it.
+const TokenExpiryMargin = 1 * time.Minute
+
 type uaaClient interface {
 	GetClientToken(string) (string, error)
 }
 
+type clock interface {
+	Now() time.Time
+}
+
+type cachedToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// tokenRequest is a request for the token of a host that is in flight. The
+// loads that ask for the same host meanwhile wait for it to be done and share
+// its result.
+type tokenRequest struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// TokenLoader loads client tokens for UAA hosts. The token of each host is
+// reused until shortly before it expires, and only one request for the token
+// of a host is made at a time, without holding up the loads for other hosts.
 type TokenLoader struct {
-	uaa uaaClient
+	uaa   uaaClient
+	clock clock
+
+	mutex    sync.Mutex
+	tokens   map[string]cachedToken
+	requests map[string]*tokenRequest
 }
 
-func NewTokenLoader(uaa uaaClient) *TokenLoader {
+func NewTokenLoader(uaa uaaClient, clock clock) *TokenLoader {
 	return &TokenLoader{
-		uaa: uaa,
+		uaa:      uaa,
+		clock:    clock,
+		tokens:   map[string]cachedToken{},
+		requests: map[string]*tokenRequest{},
 	}
 }
 
 func (t *TokenLoader) Load(uaaHost string) (string, error) {
+	t.mutex.Lock()
+
+	if cached, ok := t.tokens[uaaHost]; ok && t.clock.Now().Before(cached.expiresAt) {
+		t.mutex.Unlock()
+		countCacheLookup("token", "hit")
+		return cached.value, nil
+	}
+	countCacheLookup("token", "miss")
+
+	if request, ok := t.requests[uaaHost]; ok {
+		t.mutex.Unlock()
+		<-request.done
+		return request.token, request.err
+	}
+
+	request := &tokenRequest{done: make(chan struct{})}
+	t.requests[uaaHost] = request
+	t.mutex.Unlock()
+
 	then := time.Now()
 
-	token, err := t.uaa.GetClientToken(uaaHost)
+	request.token, request.err = t.uaa.GetClientToken(uaaHost)
 
 	metrics.GetOrRegisterTimer("notifications.external-requests.uaa.client-token", nil).Update(time.Since(then))
-	return token, err
+
+	t.mutex.Lock()
+	delete(t.requests, uaaHost)
+	if request.err != nil {
+		delete(t.tokens, uaaHost)
+	} else if expiresAt, ok := tokenExpiry(request.token); ok {
+		t.tokens[uaaHost] = cachedToken{
+			value:     request.token,
+			expiresAt: expiresAt.Add(-TokenExpiryMargin),
+		}
+	}
+	t.mutex.Unlock()
+
+	close(request.done)
+
+	return request.token, request.err
+}
+
+// tokenExpiry reads the exp claim of a token without verifying it. The
+// token was just issued to us by UAA, so there is nothing to verify.
+func tokenExpiry(token string) (time.Time, bool) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := jwt.DecodeSegment(segments[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
+
+// countCacheLookup records whether a lookup in one of the UAA caches was a
+// hit or a miss.
+func countCacheLookup(cache, result string) {
+	metrics.GetOrRegisterCounter(prometheus.Labelled("notifications.uaa.cache.lookups", prometheus.Labels{
+		"cache":  cache,
+		"result": result,
+	}), nil).Inc(1)
 }
@@ -1,25 +1,197 @@
 package uaa_test
 
 import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+type blockingUAAClient struct {
+	mutex    sync.Mutex
+	hosts    []string
+	started  chan string
+	released map[string]chan struct{}
+}
+
+func newBlockingUAAClient() *blockingUAAClient {
+	return &blockingUAAClient{
+		started: make(chan string, 10),
+		released: map[string]chan struct{}{
+			"my-uaa-zone":    make(chan struct{}),
+			"other-uaa-zone": make(chan struct{}),
+		},
+	}
+}
+
+func (c *blockingUAAClient) GetClientToken(host string) (string, error) {
+	c.mutex.Lock()
+	c.hosts = append(c.hosts, host)
+	c.mutex.Unlock()
+
+	c.started <- host
+	<-c.released[host]
+
+	return "token-for-" + host, nil
+}
+
+func (c *blockingUAAClient) Hosts() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.hosts...)
+}
+
 var _ = Describe("TokenLoader", func() {
+	var (
+		tokenLoader *uaa.TokenLoader
+		uaaClient   *mocks.ZonedUAAClient
+		clock       *mocks.Clock
+		now         time.Time
+	)
+
+	buildToken := func(expiresAt time.Time) string {
+		return helpers.BuildToken(map[string]interface{}{
+			"alg": "RS256",
+		}, map[string]interface{}{
+			"client_id": "notifications",
+			"exp":       expiresAt.Unix(),
+		})
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		uaaClient = mocks.NewZonedUAAClient()
+		uaaClient.GetClientTokenCall.Returns.Token = buildToken(now.Add(time.Hour))
+
+		tokenLoader = uaa.NewTokenLoader(uaaClient, clock)
+	})
+
 	Describe("#Load", func() {
 		It("Gets a zoned client token based on hostname", func() {
-			uaaClient := mocks.NewZonedUAAClient()
 			uaaClient.GetClientTokenCall.Returns.Token = "my-fake-token"
 
-			tokenLoader := uaa.NewTokenLoader(uaaClient)
-
 			token, err := tokenLoader.Load("my-uaa-zone")
 			Expect(token).To(Equal("my-fake-token"))
 			Expect(err).To(BeNil())
 
 			Expect(uaaClient.GetClientTokenCall.Receives.Host).To(Equal("my-uaa-zone"))
 		})
+
+		It("reuses the token of a host until shortly before it expires", func() {
+			firstToken, err := tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+
+			uaaClient.GetClientTokenCall.Returns.Token = buildToken(now.Add(2 * time.Hour))
+			clock.NowCall.Returns.Time = now.Add(time.Hour - uaa.TokenExpiryMargin - time.Second)
+
+			token, err := tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(firstToken))
+			Expect(uaaClient.GetClientTokenCall.CallCount).To(Equal(1))
+
+			clock.NowCall.Returns.Time = now.Add(time.Hour - uaa.TokenExpiryMargin)
+
+			token, err = tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(buildToken(now.Add(2 * time.Hour))))
+			Expect(uaaClient.GetClientTokenCall.CallCount).To(Equal(2))
+		})
+
+		It("caches the token of each host separately", func() {
+			_, err := tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = tokenLoader.Load("other-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uaaClient.GetClientTokenCall.CallCount).To(Equal(2))
+			Expect(uaaClient.GetClientTokenCall.Receives.Host).To(Equal("other-uaa-zone"))
+		})
+
+		It("does not cache tokens without an expiry", func() {
+			uaaClient.GetClientTokenCall.Returns.Token = "my-fake-token"
+
+			_, err := tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uaaClient.GetClientTokenCall.CallCount).To(Equal(2))
+		})
+
+		It("does not cache errors", func() {
+			uaaClient.GetClientTokenCall.Returns.Error = errors.New("uaa is down")
+
+			_, err := tokenLoader.Load("my-uaa-zone")
+			Expect(err).To(MatchError(errors.New("uaa is down")))
+
+			uaaClient.GetClientTokenCall.Returns.Error = nil
+
+			token, err := tokenLoader.Load("my-uaa-zone")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(buildToken(now.Add(time.Hour))))
+			Expect(uaaClient.GetClientTokenCall.CallCount).To(Equal(2))
+		})
+
+		Context("when tokens are loaded concurrently", func() {
+			var client *blockingUAAClient
+
+			BeforeEach(func() {
+				client = newBlockingUAAClient()
+				tokenLoader = uaa.NewTokenLoader(client, clock)
+			})
+
+			It("makes a single request for the token of a host and shares it", func() {
+				tokens := make(chan string, 2)
+				for i := 0; i < 2; i++ {
+					go func() {
+						defer GinkgoRecover()
+
+						token, err := tokenLoader.Load("my-uaa-zone")
+						Expect(err).NotTo(HaveOccurred())
+						tokens <- token
+					}()
+				}
+
+				Eventually(client.started).Should(Receive(Equal("my-uaa-zone")))
+				Consistently(client.started).ShouldNot(Receive())
+
+				close(client.released["my-uaa-zone"])
+
+				Eventually(tokens).Should(Receive(Equal("token-for-my-uaa-zone")))
+				Eventually(tokens).Should(Receive(Equal("token-for-my-uaa-zone")))
+				Expect(client.Hosts()).To(Equal([]string{"my-uaa-zone"}))
+			})
+
+			It("does not hold up the loads for other hosts while a request is in flight", func() {
+				go tokenLoader.Load("my-uaa-zone")
+				Eventually(client.started).Should(Receive(Equal("my-uaa-zone")))
+
+				tokens := make(chan string, 1)
+				go func() {
+					defer GinkgoRecover()
+
+					token, err := tokenLoader.Load("other-uaa-zone")
+					Expect(err).NotTo(HaveOccurred())
+					tokens <- token
+				}()
+
+				Eventually(client.started).Should(Receive(Equal("other-uaa-zone")))
+				close(client.released["other-uaa-zone"])
+				Eventually(tokens).Should(Receive(Equal("token-for-other-uaa-zone")))
+
+				close(client.released["my-uaa-zone"])
+			})
+		})
 	})
 })
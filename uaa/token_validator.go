@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pivotal-cf-experimental/warrant"
@@ -17,6 +18,7 @@ type keysFetcher interface {
 type TokenValidator struct {
 	keysFetcher keysFetcher
 	keyMap      map[string]warrant.SigningKey
+	keysLoaded  time.Time
 	keyMutex    sync.RWMutex
 	logger      lager.Logger
 }
@@ -54,10 +56,20 @@ func (v *TokenValidator) LoadSigningKeys() error {
 	v.keyMutex.Lock()
 	defer v.keyMutex.Unlock()
 	v.keyMap = keyMap
+	v.keysLoaded = time.Now()
 
 	return nil
 }
 
+// KeysLoadedAt returns when the signing keys were last loaded successfully,
+// or the zero time if they never were.
+func (v *TokenValidator) KeysLoadedAt() time.Time {
+	v.keyMutex.RLock()
+	defer v.keyMutex.RUnlock()
+
+	return v.keysLoaded
+}
+
 func (v *TokenValidator) findKey(id string) (warrant.SigningKey, bool) {
 	v.keyMutex.RLock()
 	defer v.keyMutex.RUnlock()
@@ -2,6 +2,7 @@ package uaa_test
 
 import (
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -41,6 +42,13 @@ var _ = Describe("TokenValidator", func() {
 			keyFetcher.GetSigningKeysCall.Returns.Error = errors.New("network failure")
 			err := validator.LoadSigningKeys()
 			Expect(err).To(HaveOccurred())
+			Expect(validator.KeysLoadedAt().IsZero()).To(BeTrue())
+		})
+
+		It("records when the keys were loaded", func() {
+			err := validator.LoadSigningKeys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validator.KeysLoadedAt()).To(BeTemporally("~", time.Now(), time.Second))
 		})
 	})
 
@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("health endpoints", func() {
+	It("reports that the service is live", func() {
+		resp, err := http.Get(Servers.Notifications.URL() + "/health/live")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(MatchJSON(`{"status": "ok"}`))
+	})
+})
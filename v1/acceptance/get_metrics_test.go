@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("prometheus metrics endpoint", func() {
+	It("returns 200 and exposes metrics in the text exposition format", func() {
+		_, err := http.Get(Servers.Notifications.URL() + "/info")
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := http.Get(Servers.Notifications.URL() + "/metrics")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(ContainSubstring("text/plain"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("# TYPE notifications_web_GET__info_total counter\n"))
+	})
+})
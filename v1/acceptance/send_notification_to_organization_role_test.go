@@ -77,9 +77,9 @@ var _ = Describe("Sending notifications to users with certain roles in an organi
 			Expect(responses).To(HaveLen(1))
 
 			response = responses[0]
-			Expect(response.Recipient).To(Equal("user-456"))
 			Expect(response.Status).To(Equal("queued"))
-			Expect(GUIDRegex.MatchString(response.NotificationID)).To(BeTrue())
+			Expect(GUIDRegex.MatchString(response.FanOutID)).To(BeTrue())
+			Expect(response.RecipientCount).To(Equal(1))
 			Expect(response.VCAPRequestID).To(Equal("some-totally-fake-vcap-request-id"))
 		})
 
@@ -97,7 +97,7 @@ var _ = Describe("Sending notifications to users with certain roles in an organi
 
 			data := strings.Split(string(delivery.Data), "\n")
 			Expect(data).To(ContainElement("X-CF-Client-ID: notifications-sender"))
-			Expect(data).To(ContainElement("X-CF-Notification-ID: " + response.NotificationID))
+			Expect(data).To(ContainElement(MatchRegexp("^X-CF-Notification-ID: " + GUIDRegex.String())))
 			Expect(data).To(ContainElement("Subject: Phone home organization-role-subject"))
 			Expect(data).To(ContainElement("Cat"))
 			Expect(data).To(ContainElement("this is an organization role test"))
@@ -125,9 +125,9 @@ var _ = Describe("Sending notifications to users with certain roles in an organi
 
 			response = responses[0]
 
-			Expect(response.Recipient).To(Equal("user-123"))
 			Expect(response.Status).To(Equal("queued"))
-			Expect(GUIDRegex.MatchString(response.NotificationID)).To(BeTrue())
+			Expect(GUIDRegex.MatchString(response.FanOutID)).To(BeTrue())
+			Expect(response.RecipientCount).To(Equal(1))
 		})
 
 		By("confirming that the messages were sent", func() {
@@ -144,7 +144,7 @@ var _ = Describe("Sending notifications to users with certain roles in an organi
 
 			data := strings.Split(string(delivery.Data), "\n")
 			Expect(data).To(ContainElement("X-CF-Client-ID: notifications-sender"))
-			Expect(data).To(ContainElement("X-CF-Notification-ID: " + response.NotificationID))
+			Expect(data).To(ContainElement(MatchRegexp("^X-CF-Notification-ID: " + GUIDRegex.String())))
 			Expect(data).To(ContainElement("Subject: Phone home organization-role-subject"))
 			Expect(data).To(ContainElement("Cat"))
 			Expect(data).To(ContainElement("this is an organization role test"))
@@ -172,9 +172,9 @@ var _ = Describe("Sending notifications to users with certain roles in an organi
 
 			response = responses[0]
 
-			Expect(response.Recipient).To(Equal("user-111"))
 			Expect(response.Status).To(Equal("queued"))
-			Expect(GUIDRegex.MatchString(response.NotificationID)).To(BeTrue())
+			Expect(GUIDRegex.MatchString(response.FanOutID)).To(BeTrue())
+			Expect(response.RecipientCount).To(Equal(1))
 		})
 
 		By("confirming that the messages were sent", func() {
@@ -191,7 +191,7 @@ var _ = Describe("Sending notifications to users with certain roles in an organi
 
 			data := strings.Split(string(delivery.Data), "\n")
 			Expect(data).To(ContainElement("X-CF-Client-ID: notifications-sender"))
-			Expect(data).To(ContainElement("X-CF-Notification-ID: " + response.NotificationID))
+			Expect(data).To(ContainElement(MatchRegexp("^X-CF-Notification-ID: " + GUIDRegex.String())))
 			Expect(data).To(ContainElement("Subject: Phone home organization-role-subject"))
 			Expect(data).To(ContainElement("Cat"))
 			Expect(data).To(ContainElement("this is an organization role test"))
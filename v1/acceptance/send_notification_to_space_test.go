@@ -19,7 +19,7 @@ var _ = Describe("Sending notifications to all users in a space", func() {
 		clientID := "notifications-sender"
 		clientToken := GetClientTokenFor(clientID)
 		spaceID := "space-123"
-		indexedResponses := map[string]support.NotifyResponse{}
+		var fanOutID string
 
 		By("registering a client with a notification", func() {
 			status, err := client.Notifications.Register(clientToken.Access, support.RegisterClient{
@@ -65,29 +65,26 @@ var _ = Describe("Sending notifications to all users in a space", func() {
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(status).To(Equal(http.StatusOK))
-			Expect(responses).To(HaveLen(3))
+			Expect(responses).To(HaveLen(1))
 
-			for _, response := range responses {
-				indexedResponses[response.Recipient] = response
-			}
-
-			response := indexedResponses["user-456"]
-			Expect(response.Recipient).To(Equal("user-456"))
+			response := responses[0]
 			Expect(response.Status).To(Equal("queued"))
-			Expect(GUIDRegex.MatchString(response.NotificationID)).To(BeTrue())
+			Expect(GUIDRegex.MatchString(response.FanOutID)).To(BeTrue())
+			Expect(response.RecipientCount).To(Equal(3))
 			Expect(response.VCAPRequestID).To(Equal("some-totally-fake-vcap-request-id"))
 
-			response = indexedResponses["user-789"]
-			Expect(response.Recipient).To(Equal("user-789"))
-			Expect(response.Status).To(Equal("queued"))
-			Expect(GUIDRegex.MatchString(response.NotificationID)).To(BeTrue())
-			Expect(response.VCAPRequestID).To(Equal("some-totally-fake-vcap-request-id"))
+			fanOutID = response.FanOutID
+		})
 
-			response = indexedResponses["user-000"]
-			Expect(response.Recipient).To(Equal("user-000"))
-			Expect(response.Status).To(Equal("queued"))
-			Expect(GUIDRegex.MatchString(response.NotificationID)).To(BeTrue())
-			Expect(response.VCAPRequestID).To(Equal("some-totally-fake-vcap-request-id"))
+		By("confirming the fan-out was completed", func() {
+			Eventually(func() string {
+				status, fanOut, err := client.FanOuts.Get(clientToken.Access, fanOutID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(fanOut.Total).To(Equal(3))
+
+				return fanOut.Status
+			}, 10*time.Second).Should(Equal("completed"))
 		})
 
 		By("confirming the messages were sent", func() {
@@ -104,7 +101,7 @@ var _ = Describe("Sending notifications to all users in a space", func() {
 
 			data := strings.Split(string(delivery.Data), "\n")
 			Expect(data).To(ContainElement("X-CF-Client-ID: notifications-sender"))
-			Expect(data).To(ContainElement("X-CF-Notification-ID: " + indexedResponses["user-456"].NotificationID))
+			Expect(data).To(ContainElement(MatchRegexp("^X-CF-Notification-ID: " + GUIDRegex.String())))
 			Expect(data).To(ContainElement("Subject: Aliens space-subject"))
 			Expect(data).To(ContainElement("\t\t<h1>Dogs</h1>this is a space test<h2>You received this message because you="))
 			Expect(data).To(ContainElement(` belong to the &#34;notifications-service&#34; space in the &#34;notificatio=`))
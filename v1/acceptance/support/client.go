@@ -19,6 +19,7 @@ type Client struct {
 	Notify        *NotifyService
 	Preferences   *PreferencesService
 	Messages      *MessagesService
+	FanOuts       *FanOutsService
 	API           *APIService
 	HTTPClient    *http.Client
 }
@@ -47,6 +48,9 @@ func NewClient(host string) *Client {
 	client.Messages = &MessagesService{
 		client: client,
 	}
+	client.FanOuts = &FanOutsService{
+		client: client,
+	}
 	client.API = &APIService{
 		client: client,
 	}
@@ -186,6 +190,10 @@ func (c Client) MessagePath(messageID string) string {
 	return c.host + "/messages/" + messageID
 }
 
+func (c Client) FanOutPath(fanOutID string) string {
+	return c.host + "/fanouts/" + fanOutID
+}
+
 func (c Client) InfoPath() string {
 	return c.host + "/info"
 }
@@ -49,6 +49,15 @@ type NotifyResponse struct {
 	Recipient      string `json:"recipient"`
 	NotificationID string `json:"notification_id"`
 	VCAPRequestID  string `json:"vcap_request_id"`
+	FanOutID       string `json:"fanout_id"`
+	RecipientCount int    `json:"recipient_count"`
+}
+
+type FanOut struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Total    int    `json:"total"`
+	Enqueued int    `json:"enqueued"`
 }
 
 type Message struct {
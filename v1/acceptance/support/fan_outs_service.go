@@ -0,0 +1,19 @@
+package support
+
+import "encoding/json"
+
+type FanOutsService struct {
+	client *Client
+}
+
+func (s FanOutsService) Get(token, fanOutID string) (int, FanOut, error) {
+	var fanOut FanOut
+
+	status, body, err := s.client.makeRequest("GET", s.client.FanOutPath(fanOutID), nil, token)
+	if err != nil {
+		return status, fanOut, err
+	}
+
+	err = json.Unmarshal(body, &fanOut)
+	return status, fanOut, err
+}
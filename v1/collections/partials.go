@@ -0,0 +1,113 @@
+package collections
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+var partialNameFormat = regexp.MustCompile(`^[0-9a-zA-Z_\-.]+$`)
+
+type PartialValidationError struct {
+	Err error
+}
+
+func (e PartialValidationError) Error() string {
+	return e.Err.Error()
+}
+
+type partialsRepository interface {
+	FindByName(connection models.ConnectionInterface, name string) (models.Partial, error)
+	FindAll(connection models.ConnectionInterface) ([]models.Partial, error)
+	Upsert(connection models.ConnectionInterface, partial models.Partial) (models.Partial, error)
+	Destroy(connection models.ConnectionInterface, name string) error
+}
+
+type Partial struct {
+	Name    string
+	Content string
+}
+
+type PartialsCollection struct {
+	partialsRepo partialsRepository
+}
+
+func NewPartialsCollection(partialsRepo partialsRepository) PartialsCollection {
+	return PartialsCollection{
+		partialsRepo: partialsRepo,
+	}
+}
+
+func (c PartialsCollection) Set(conn ConnectionInterface, partial Partial) (Partial, error) {
+	if !partialNameFormat.MatchString(partial.Name) {
+		return Partial{}, PartialValidationError{fmt.Errorf("Partial name %q is improperly formatted", partial.Name)}
+	}
+
+	existing, err := c.partialsRepo.FindAll(conn)
+	if err != nil {
+		return Partial{}, err
+	}
+
+	contents := map[string]string{}
+	for _, p := range existing {
+		contents[p.Name] = p.Content
+	}
+	contents[partial.Name] = partial.Content
+
+	// References to partials that do not exist yet are allowed so that partials
+	// can be created in any order; they are reported when a message is packed.
+	_, err = common.ParseTemplateSet("", contents)
+	switch err.(type) {
+	case nil, common.MissingPartialError:
+	default:
+		return Partial{}, PartialValidationError{err}
+	}
+
+	saved, err := c.partialsRepo.Upsert(conn, models.Partial{
+		Name:    partial.Name,
+		Content: partial.Content,
+	})
+	if err != nil {
+		return Partial{}, err
+	}
+
+	return Partial{
+		Name:    saved.Name,
+		Content: saved.Content,
+	}, nil
+}
+
+func (c PartialsCollection) Get(conn ConnectionInterface, name string) (Partial, error) {
+	partial, err := c.partialsRepo.FindByName(conn, name)
+	if err != nil {
+		return Partial{}, err
+	}
+
+	return Partial{
+		Name:    partial.Name,
+		Content: partial.Content,
+	}, nil
+}
+
+func (c PartialsCollection) List(conn ConnectionInterface) ([]Partial, error) {
+	partials, err := c.partialsRepo.FindAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []Partial{}
+	for _, partial := range partials {
+		list = append(list, Partial{
+			Name:    partial.Name,
+			Content: partial.Content,
+		})
+	}
+
+	return list, nil
+}
+
+func (c PartialsCollection) Delete(conn ConnectionInterface, name string) error {
+	return c.partialsRepo.Destroy(conn, name)
+}
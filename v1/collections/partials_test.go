@@ -0,0 +1,150 @@
+package collections_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartialsCollection", func() {
+	var (
+		partialsRepo *mocks.PartialsRepo
+		conn         *mocks.Connection
+
+		collection collections.PartialsCollection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		partialsRepo = mocks.NewPartialsRepo()
+
+		collection = collections.NewPartialsCollection(partialsRepo)
+	})
+
+	Describe("Set", func() {
+		BeforeEach(func() {
+			partialsRepo.UpsertCall.Returns.Partial = models.Partial{
+				Name:    "footer",
+				Content: "the footer",
+			}
+		})
+
+		It("upserts the partial via the partials repo", func() {
+			partial, err := collection.Set(conn, collections.Partial{
+				Name:    "footer",
+				Content: "the footer",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial).To(Equal(collections.Partial{
+				Name:    "footer",
+				Content: "the footer",
+			}))
+
+			Expect(partialsRepo.UpsertCall.Receives.Connection).To(Equal(conn))
+			Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.Partial{
+				Name:    "footer",
+				Content: "the footer",
+			}))
+		})
+
+		It("allows references to partials that do not exist yet", func() {
+			_, err := collection.Set(conn, collections.Partial{
+				Name:    "footer",
+				Content: `{{template "imprint" .}}`,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects improperly formatted names", func() {
+			_, err := collection.Set(conn, collections.Partial{
+				Name:    "foot er",
+				Content: "the footer",
+			})
+			Expect(err).To(BeAssignableToTypeOf(collections.PartialValidationError{}))
+		})
+
+		It("rejects content with malformed syntax", func() {
+			_, err := collection.Set(conn, collections.Partial{
+				Name:    "footer",
+				Content: "{{.Text",
+			})
+			Expect(err).To(BeAssignableToTypeOf(collections.PartialValidationError{}))
+		})
+
+		It("rejects content that would form a cycle with existing partials", func() {
+			partialsRepo.FindAllCall.Returns.Partials = []models.Partial{
+				{Name: "imprint", Content: `{{template "footer" .}}`},
+			}
+
+			_, err := collection.Set(conn, collections.Partial{
+				Name:    "footer",
+				Content: `{{template "imprint" .}}`,
+			})
+			Expect(err).To(BeAssignableToTypeOf(collections.PartialValidationError{}))
+			Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.Partial{}))
+		})
+
+		It("propagates errors from the repo", func() {
+			partialsRepo.UpsertCall.Returns.Error = errors.New("Boom!")
+
+			_, err := collection.Set(conn, collections.Partial{Name: "footer"})
+			Expect(err).To(MatchError(errors.New("Boom!")))
+		})
+	})
+
+	Describe("Get", func() {
+		It("finds the partial by name", func() {
+			partialsRepo.FindByNameCall.Returns.Partial = models.Partial{
+				Primary: 4,
+				Name:    "footer",
+				Content: "the footer",
+			}
+
+			partial, err := collection.Get(conn, "footer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial).To(Equal(collections.Partial{
+				Name:    "footer",
+				Content: "the footer",
+			}))
+			Expect(partialsRepo.FindByNameCall.Receives.Name).To(Equal("footer"))
+		})
+
+		It("propagates errors from the repo", func() {
+			partialsRepo.FindByNameCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			_, err := collection.Get(conn, "footer")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+		})
+	})
+
+	Describe("List", func() {
+		It("lists all of the partials", func() {
+			partialsRepo.FindAllCall.Returns.Partials = []models.Partial{
+				{Name: "footer", Content: "the footer"},
+				{Name: "header", Content: "the header"},
+			}
+
+			partials, err := collection.List(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(Equal([]collections.Partial{
+				{Name: "footer", Content: "the footer"},
+				{Name: "header", Content: "the header"},
+			}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("destroys the partial via the repo", func() {
+			err := collection.Delete(conn, "footer")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(partialsRepo.DestroyCall.Receives.Connection).To(Equal(conn))
+			Expect(partialsRepo.DestroyCall.Receives.Name).To(Equal("footer"))
+		})
+	})
+})
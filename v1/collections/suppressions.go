@@ -0,0 +1,170 @@
+package collections
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type SuppressionValidationError struct {
+	Err error
+}
+
+func (e SuppressionValidationError) Error() string {
+	return e.Err.Error()
+}
+
+type suppressionsRepository interface {
+	Create(connection models.ConnectionInterface, suppression models.Suppression) (models.Suppression, error)
+	FindByID(connection models.ConnectionInterface, id string) (models.Suppression, error)
+	FindAll(connection models.ConnectionInterface) ([]models.Suppression, error)
+	Update(connection models.ConnectionInterface, suppression models.Suppression) (models.Suppression, error)
+	Destroy(connection models.ConnectionInterface, id string) error
+}
+
+// Suppression is an entry on the suppression list. A zero ExpiresAt means
+// that the suppression never expires.
+type Suppression struct {
+	ID        string
+	Type      string
+	Value     string
+	Reason    string
+	Hard      bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type SuppressionsCollection struct {
+	suppressionsRepo suppressionsRepository
+}
+
+func NewSuppressionsCollection(suppressionsRepo suppressionsRepository) SuppressionsCollection {
+	return SuppressionsCollection{
+		suppressionsRepo: suppressionsRepo,
+	}
+}
+
+func (c SuppressionsCollection) Create(conn ConnectionInterface, suppression Suppression) (Suppression, error) {
+	err := validateSuppression(suppression)
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	created, err := c.suppressionsRepo.Create(conn, models.Suppression{
+		Type:      suppression.Type,
+		Value:     suppression.Value,
+		Reason:    suppression.Reason,
+		Hard:      suppression.Hard,
+		ExpiresAt: expiry(suppression.ExpiresAt),
+	})
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	return suppressionFromModel(created), nil
+}
+
+func (c SuppressionsCollection) Get(conn ConnectionInterface, id string) (Suppression, error) {
+	suppression, err := c.suppressionsRepo.FindByID(conn, id)
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	return suppressionFromModel(suppression), nil
+}
+
+func (c SuppressionsCollection) List(conn ConnectionInterface) ([]Suppression, error) {
+	suppressions, err := c.suppressionsRepo.FindAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []Suppression{}
+	for _, suppression := range suppressions {
+		list = append(list, suppressionFromModel(suppression))
+	}
+
+	return list, nil
+}
+
+func (c SuppressionsCollection) Update(conn ConnectionInterface, suppression Suppression) (Suppression, error) {
+	err := validateSuppression(suppression)
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	existing, err := c.suppressionsRepo.FindByID(conn, suppression.ID)
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	existing.Type = suppression.Type
+	existing.Value = suppression.Value
+	existing.Reason = suppression.Reason
+	existing.Hard = suppression.Hard
+	existing.ExpiresAt = expiry(suppression.ExpiresAt)
+
+	updated, err := c.suppressionsRepo.Update(conn, existing)
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	return suppressionFromModel(updated), nil
+}
+
+func (c SuppressionsCollection) Delete(conn ConnectionInterface, id string) error {
+	return c.suppressionsRepo.Destroy(conn, id)
+}
+
+func validateSuppression(suppression Suppression) error {
+	value := suppression.Value
+
+	switch suppression.Type {
+	case models.SuppressionTypeAddress:
+		if strings.Count(value, "@") != 1 || strings.HasPrefix(value, "@") || strings.HasSuffix(value, "@") || strings.ContainsAny(value, " <>") {
+			return SuppressionValidationError{fmt.Errorf("Suppressed address %q is improperly formatted", value)}
+		}
+	case models.SuppressionTypeDomain:
+		if value == "" || strings.ContainsAny(value, "@ <>") {
+			return SuppressionValidationError{fmt.Errorf("Suppressed domain %q is improperly formatted", value)}
+		}
+	case models.SuppressionTypeUser:
+		if value == "" {
+			return SuppressionValidationError{errors.New("Suppressed user GUID cannot be empty")}
+		}
+	default:
+		return SuppressionValidationError{fmt.Errorf("Suppression type %q is invalid, it must be one of %q, %q or %q", suppression.Type,
+			models.SuppressionTypeAddress, models.SuppressionTypeDomain, models.SuppressionTypeUser)}
+	}
+
+	return nil
+}
+
+func expiry(expiresAt time.Time) *time.Time {
+	if expiresAt.IsZero() {
+		return nil
+	}
+
+	expiresAt = expiresAt.Truncate(1 * time.Second).UTC()
+	return &expiresAt
+}
+
+func suppressionFromModel(suppression models.Suppression) Suppression {
+	var expiresAt time.Time
+	if suppression.ExpiresAt != nil {
+		expiresAt = *suppression.ExpiresAt
+	}
+
+	return Suppression{
+		ID:        suppression.ID,
+		Type:      suppression.Type,
+		Value:     suppression.Value,
+		Reason:    suppression.Reason,
+		Hard:      suppression.Hard,
+		ExpiresAt: expiresAt,
+		CreatedAt: suppression.CreatedAt,
+	}
+}
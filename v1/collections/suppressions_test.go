@@ -0,0 +1,227 @@
+package collections_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SuppressionsCollection", func() {
+	var (
+		suppressionsRepo *mocks.SuppressionsRepo
+		conn             *mocks.Connection
+		expiresAt        time.Time
+		createdAt        time.Time
+
+		collection collections.SuppressionsCollection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		suppressionsRepo = mocks.NewSuppressionsRepo()
+		expiresAt = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+		createdAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		collection = collections.NewSuppressionsCollection(suppressionsRepo)
+	})
+
+	Describe("Create", func() {
+		BeforeEach(func() {
+			suppressionsRepo.CreateCall.Returns.Suppression = models.Suppression{
+				ID:        "suppression-id",
+				Type:      "domain",
+				Value:     "example.com",
+				Reason:    "compliance request",
+				Hard:      true,
+				ExpiresAt: &expiresAt,
+				CreatedAt: createdAt,
+			}
+		})
+
+		It("creates the suppression via the suppressions repo", func() {
+			suppression, err := collection.Create(conn, collections.Suppression{
+				Type:      "domain",
+				Value:     "example.com",
+				Reason:    "compliance request",
+				Hard:      true,
+				ExpiresAt: expiresAt,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppression).To(Equal(collections.Suppression{
+				ID:        "suppression-id",
+				Type:      "domain",
+				Value:     "example.com",
+				Reason:    "compliance request",
+				Hard:      true,
+				ExpiresAt: expiresAt,
+				CreatedAt: createdAt,
+			}))
+
+			Expect(suppressionsRepo.CreateCall.Receives.Connection).To(Equal(conn))
+			Expect(suppressionsRepo.CreateCall.Receives.Suppression).To(Equal(models.Suppression{
+				Type:      "domain",
+				Value:     "example.com",
+				Reason:    "compliance request",
+				Hard:      true,
+				ExpiresAt: &expiresAt,
+			}))
+		})
+
+		It("stores suppressions without an expiry as never expiring", func() {
+			_, err := collection.Create(conn, collections.Suppression{
+				Type:  "user",
+				Value: "user-123",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressionsRepo.CreateCall.Receives.Suppression.ExpiresAt).To(BeNil())
+		})
+
+		Context("when the suppression is invalid", func() {
+			It("rejects unknown types", func() {
+				_, err := collection.Create(conn, collections.Suppression{Type: "team", Value: "ops"})
+				Expect(err).To(MatchError(collections.SuppressionValidationError{Err: errors.New(`Suppression type "team" is invalid, it must be one of "address", "domain" or "user"`)}))
+			})
+
+			It("rejects addresses without an @", func() {
+				_, err := collection.Create(conn, collections.Suppression{Type: "address", Value: "example.com"})
+				Expect(err).To(MatchError(collections.SuppressionValidationError{Err: errors.New(`Suppressed address "example.com" is improperly formatted`)}))
+			})
+
+			It("rejects addresses without a domain", func() {
+				_, err := collection.Create(conn, collections.Suppression{Type: "address", Value: "someone@"})
+				Expect(err).To(MatchError(collections.SuppressionValidationError{Err: errors.New(`Suppressed address "someone@" is improperly formatted`)}))
+			})
+
+			It("rejects domains that contain an @", func() {
+				_, err := collection.Create(conn, collections.Suppression{Type: "domain", Value: "someone@example.com"})
+				Expect(err).To(MatchError(collections.SuppressionValidationError{Err: errors.New(`Suppressed domain "someone@example.com" is improperly formatted`)}))
+			})
+
+			It("rejects empty user GUIDs", func() {
+				_, err := collection.Create(conn, collections.Suppression{Type: "user", Value: ""})
+				Expect(err).To(MatchError(collections.SuppressionValidationError{Err: errors.New("Suppressed user GUID cannot be empty")}))
+			})
+		})
+
+		It("returns errors from the repo", func() {
+			suppressionsRepo.CreateCall.Returns.Error = models.DuplicateError{Err: errors.New("already suppressed")}
+
+			_, err := collection.Create(conn, collections.Suppression{Type: "user", Value: "user-123"})
+			Expect(err).To(MatchError(models.DuplicateError{Err: errors.New("already suppressed")}))
+		})
+	})
+
+	Describe("Get", func() {
+		It("finds the suppression by its ID", func() {
+			suppressionsRepo.FindByIDCall.Returns.Suppression = models.Suppression{
+				ID:    "suppression-id",
+				Type:  "address",
+				Value: "someone@example.com",
+			}
+
+			suppression, err := collection.Get(conn, "suppression-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppression).To(Equal(collections.Suppression{
+				ID:    "suppression-id",
+				Type:  "address",
+				Value: "someone@example.com",
+			}))
+			Expect(suppressionsRepo.FindByIDCall.Receives.ID).To(Equal("suppression-id"))
+		})
+
+		It("returns errors from the repo", func() {
+			suppressionsRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			_, err := collection.Get(conn, "missing-id")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("List", func() {
+		It("lists every suppression", func() {
+			suppressionsRepo.FindAllCall.Returns.Suppressions = []models.Suppression{
+				{ID: "first-id", Type: "address", Value: "someone@example.com"},
+				{ID: "second-id", Type: "domain", Value: "example.com"},
+			}
+
+			suppressions, err := collection.List(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions).To(Equal([]collections.Suppression{
+				{ID: "first-id", Type: "address", Value: "someone@example.com"},
+				{ID: "second-id", Type: "domain", Value: "example.com"},
+			}))
+		})
+	})
+
+	Describe("Update", func() {
+		BeforeEach(func() {
+			suppressionsRepo.FindByIDCall.Returns.Suppression = models.Suppression{
+				Primary:   42,
+				ID:        "suppression-id",
+				Type:      "address",
+				Value:     "bounced@example.com",
+				Reason:    "bounce: 5.1.1",
+				MessageID: "message-id",
+				CreatedAt: createdAt,
+			}
+			suppressionsRepo.UpdateCall.Returns.Suppression = models.Suppression{
+				ID:    "suppression-id",
+				Type:  "address",
+				Value: "bounced@example.com",
+				Hard:  true,
+			}
+		})
+
+		It("updates the existing suppression", func() {
+			suppression, err := collection.Update(conn, collections.Suppression{
+				ID:     "suppression-id",
+				Type:   "address",
+				Value:  "bounced@example.com",
+				Reason: "confirmed by legal",
+				Hard:   true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppression.Hard).To(BeTrue())
+
+			Expect(suppressionsRepo.FindByIDCall.Receives.ID).To(Equal("suppression-id"))
+			Expect(suppressionsRepo.UpdateCall.Receives.Suppression).To(Equal(models.Suppression{
+				Primary:   42,
+				ID:        "suppression-id",
+				Type:      "address",
+				Value:     "bounced@example.com",
+				Reason:    "confirmed by legal",
+				MessageID: "message-id",
+				Hard:      true,
+				CreatedAt: createdAt,
+			}))
+		})
+
+		It("validates the suppression", func() {
+			_, err := collection.Update(conn, collections.Suppression{ID: "suppression-id", Type: "domain", Value: "a@b"})
+			Expect(err).To(BeAssignableToTypeOf(collections.SuppressionValidationError{}))
+			Expect(suppressionsRepo.UpdateCall.Receives.Suppression).To(Equal(models.Suppression{}))
+		})
+
+		It("returns an error when the suppression does not exist", func() {
+			suppressionsRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			_, err := collection.Update(conn, collections.Suppression{ID: "missing-id", Type: "user", Value: "user-123"})
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("destroys the suppression", func() {
+			err := collection.Delete(conn, "suppression-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressionsRepo.DestroyCall.Receives.Connection).To(Equal(conn))
+			Expect(suppressionsRepo.DestroyCall.Receives.ID).To(Equal("suppression-id"))
+		})
+	})
+})
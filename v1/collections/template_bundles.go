@@ -0,0 +1,679 @@
+package collections
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/cloudfoundry-incubator/notifications/markdown"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const TemplateBundleVersion = 1
+
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+const (
+	ImportCreated     = "created"
+	ImportOverwritten = "overwritten"
+	ImportRenamed     = "renamed"
+	ImportSkipped     = "skipped"
+)
+
+type TemplateBundleError struct {
+	Err error
+}
+
+func (e TemplateBundleError) Error() string {
+	return e.Err.Error()
+}
+
+type bundleTemplatesRepository interface {
+	FindByID(connection models.ConnectionInterface, templateID string) (models.Template, error)
+	ListIDsAndNames(connection models.ConnectionInterface) ([]models.Template, error)
+	Create(connection models.ConnectionInterface, template models.Template) (models.Template, error)
+	Update(connection models.ConnectionInterface, templateID string, template models.Template) (models.Template, error)
+}
+
+type bundlePartialsRepository interface {
+	FindByNames(connection models.ConnectionInterface, names []string) ([]models.Partial, error)
+	Upsert(connection models.ConnectionInterface, partial models.Partial) (models.Partial, error)
+}
+
+type bundleClientsRepository interface {
+	Find(connection models.ConnectionInterface, clientID string) (models.Client, error)
+}
+
+type bundleKindsRepository interface {
+	Find(connection models.ConnectionInterface, kindID, clientID string) (models.Kind, error)
+}
+
+type auditLog interface {
+	Record(connection services.ConnectionInterface, entry services.AuditEntry) error
+}
+
+type templateAssociationLister interface {
+	ListAssociations(connection ConnectionInterface, templateID string) ([]TemplateAssociation, error)
+}
+
+type templateAssigner interface {
+	AssignToClient(connection ConnectionInterface, clientID, templateID string) error
+	AssignToNotification(connection ConnectionInterface, clientID, notificationID, templateID string) error
+}
+
+type TemplateBundle struct {
+	Version   int
+	Templates []BundledTemplate
+	Partials  []Partial
+}
+
+type BundledTemplate struct {
+	Template
+	Associations []TemplateAssociation
+}
+
+type ImportOptions struct {
+	DryRun     bool
+	OnConflict string
+
+	// Token is the token of the request, which the audit events of the
+	// import are recorded for.
+	Token *jwt.Token
+}
+
+type ImportResult struct {
+	Name         string
+	ImportedName string
+	TemplateID   string
+	Action       string
+	Associations []TemplateAssociation
+}
+
+type PartialImportResult struct {
+	Name   string
+	Action string
+}
+
+type ImportReport struct {
+	Templates []ImportResult
+	Partials  []PartialImportResult
+}
+
+// auditedTemplate and auditedAssignment are how imported templates and their
+// assignments are recorded in the audit log, matching the documents of the
+// endpoints that make the same changes one at a time.
+type auditedTemplate struct {
+	Name     string          `json:"name"`
+	Subject  string          `json:"subject"`
+	Text     string          `json:"text"`
+	HTML     string          `json:"html"`
+	Metadata json.RawMessage `json:"metadata"`
+	Layout   string          `json:"layout,omitempty"`
+	Markdown string          `json:"markdown,omitempty"`
+}
+
+type auditedAssignment struct {
+	Template string `json:"template"`
+}
+
+type TemplateBundlesCollection struct {
+	templatesRepo bundleTemplatesRepository
+	partialsRepo  bundlePartialsRepository
+	clientsRepo   bundleClientsRepository
+	kindsRepo     bundleKindsRepository
+	lister        templateAssociationLister
+	assigner      templateAssigner
+	auditLog      auditLog
+}
+
+func NewTemplateBundlesCollection(templatesRepo bundleTemplatesRepository, partialsRepo bundlePartialsRepository, clientsRepo bundleClientsRepository, kindsRepo bundleKindsRepository, lister templateAssociationLister, assigner templateAssigner, auditLog auditLog) TemplateBundlesCollection {
+	return TemplateBundlesCollection{
+		templatesRepo: templatesRepo,
+		partialsRepo:  partialsRepo,
+		clientsRepo:   clientsRepo,
+		kindsRepo:     kindsRepo,
+		lister:        lister,
+		assigner:      assigner,
+		auditLog:      auditLog,
+	}
+}
+
+// Export collects every template other than the default template, together
+// with the clients and notifications it is assigned to and the layouts and
+// partials it references, into a bundle that can be imported into another
+// deployment.
+func (c TemplateBundlesCollection) Export(conn ConnectionInterface) (TemplateBundle, error) {
+	bundle := TemplateBundle{
+		Version:   TemplateBundleVersion,
+		Templates: []BundledTemplate{},
+		Partials:  []Partial{},
+	}
+
+	summaries, err := c.templatesRepo.ListIDsAndNames(conn)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	sort.Sort(templatesByName(summaries))
+
+	for _, summary := range summaries {
+		if summary.ID == models.DefaultTemplateID {
+			continue
+		}
+
+		tmpl, err := c.templatesRepo.FindByID(conn, summary.ID)
+		if err != nil {
+			return TemplateBundle{}, err
+		}
+
+		associations, err := c.lister.ListAssociations(conn, summary.ID)
+		if err != nil {
+			return TemplateBundle{}, err
+		}
+
+		bundle.Templates = append(bundle.Templates, BundledTemplate{
+			Template: Template{
+				Name:     tmpl.Name,
+				Text:     tmpl.Text,
+				HTML:     tmpl.HTML,
+				Subject:  tmpl.Subject,
+				Metadata: tmpl.Metadata,
+				Layout:   tmpl.Layout,
+				Markdown: tmpl.Markdown,
+			},
+			Associations: associations,
+		})
+	}
+
+	partials, err := c.referencedPartials(conn, bundle.Templates)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	for _, partial := range partials {
+		bundle.Partials = append(bundle.Partials, Partial{
+			Name:    partial.Name,
+			Content: partial.Content,
+		})
+	}
+
+	return bundle, nil
+}
+
+// referencedPartials loads the layouts and partials the templates reference,
+// along with the partials those reference in turn, ordered by name.
+// References to partials that do not exist are left out.
+func (c TemplateBundlesCollection) referencedPartials(conn ConnectionInterface, templates []BundledTemplate) ([]models.Partial, error) {
+	var (
+		partials []models.Partial
+		sources  []string
+		layouts  []string
+	)
+
+	for _, bundled := range templates {
+		sources = append(sources, bundled.Subject, bundled.Text, bundled.HTML)
+		if bundled.Layout != "" {
+			layouts = append(layouts, bundled.Layout)
+		}
+	}
+
+	seen := map[string]bool{}
+	names := append(common.PartialReferences(sources...), layouts...)
+	for len(names) > 0 {
+		var pending []string
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				pending = append(pending, name)
+			}
+		}
+
+		found, err := c.partialsRepo.FindByNames(conn, pending)
+		if err != nil {
+			return nil, err
+		}
+
+		var contents []string
+		for _, partial := range found {
+			partials = append(partials, partial)
+			contents = append(contents, partial.Content)
+		}
+
+		names = common.PartialReferences(contents...)
+	}
+
+	sort.Sort(partialsByName(partials))
+
+	return partials, nil
+}
+
+// Import creates the templates and partials in the bundle and restores the
+// associations of the templates. Templates are matched to existing templates
+// by name; options.OnConflict decides whether a matching template is skipped,
+// overwritten or imported under a new name. Partials are matched by name as
+// well, but since templates refer to them by name they are never renamed: a
+// conflicting partial is only replaced when options.OnConflict is overwrite.
+// Every layout and partial the templates reference must be in the bundle or
+// exist already. The templates that are created or overwritten and their
+// assignments are recorded in the audit log. The import runs in a single transaction which is rolled back
+// when options.DryRun is set, so a dry run reports exactly what a real import
+// would do.
+func (c TemplateBundlesCollection) Import(conn ConnectionInterface, bundle TemplateBundle, options ImportOptions) (ImportReport, error) {
+	if options.OnConflict == "" {
+		options.OnConflict = ConflictSkip
+	}
+
+	err := validateBundle(bundle, options)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	existingPartials, err := c.checkPartials(conn, bundle)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	summaries, err := c.templatesRepo.ListIDsAndNames(conn)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	existing := map[string]string{}
+	for _, summary := range summaries {
+		existing[summary.Name] = summary.ID
+	}
+
+	transaction := conn.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{
+		Templates: []ImportResult{},
+		Partials:  []PartialImportResult{},
+	}
+
+	for _, partial := range bundle.Partials {
+		result, err := c.importPartial(transaction, partial, options.OnConflict, existingPartials)
+		if err != nil {
+			transaction.Rollback()
+			return ImportReport{}, err
+		}
+
+		report.Partials = append(report.Partials, result)
+	}
+
+	for _, bundled := range bundle.Templates {
+		result, err := c.importTemplate(transaction, bundled, options, existing)
+		if err != nil {
+			transaction.Rollback()
+			return ImportReport{}, err
+		}
+
+		report.Templates = append(report.Templates, result)
+	}
+
+	if options.DryRun {
+		err = transaction.Rollback()
+	} else {
+		err = transaction.Commit()
+	}
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	return report, nil
+}
+
+// checkPartials makes sure that every layout and partial the bundle
+// references is either in the bundle or exists already, so that the imported
+// templates can be delivered. It returns the contents of the existing
+// partials that share a name with a bundled partial.
+func (c TemplateBundlesCollection) checkPartials(conn ConnectionInterface, bundle TemplateBundle) (map[string]string, error) {
+	bundled := map[string]bool{}
+	var names, sources []string
+	for _, partial := range bundle.Partials {
+		bundled[partial.Name] = true
+		names = append(names, partial.Name)
+		sources = append(sources, partial.Content)
+	}
+
+	referrers := map[string]string{}
+	for _, tmpl := range bundle.Templates {
+		references := common.PartialReferences(tmpl.Subject, tmpl.Text, tmpl.HTML)
+		if tmpl.Layout != "" {
+			references = append(references, tmpl.Layout)
+		}
+
+		for _, reference := range references {
+			if _, ok := referrers[reference]; !ok {
+				referrers[reference] = fmt.Sprintf("Template %q", tmpl.Name)
+			}
+		}
+	}
+
+	for _, partial := range bundle.Partials {
+		for _, reference := range common.PartialReferences(partial.Content) {
+			if _, ok := referrers[reference]; !ok {
+				referrers[reference] = fmt.Sprintf("Partial %q", partial.Name)
+			}
+		}
+	}
+
+	var missing []string
+	for reference := range referrers {
+		if !bundled[reference] {
+			missing = append(missing, reference)
+		}
+	}
+	sort.Strings(missing)
+
+	found, err := c.partialsRepo.FindByNames(conn, append(names, missing...))
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]string{}
+	for _, partial := range found {
+		existing[partial.Name] = partial.Content
+	}
+
+	for _, name := range missing {
+		if _, ok := existing[name]; !ok {
+			return nil, TemplateBundleError{fmt.Errorf("%s references partial %q, which is neither in the bundle nor in this deployment", referrers[name], name)}
+		}
+	}
+
+	return existing, nil
+}
+
+func (c TemplateBundlesCollection) importPartial(conn ConnectionInterface, partial Partial, onConflict string, existing map[string]string) (PartialImportResult, error) {
+	result := PartialImportResult{
+		Name:   partial.Name,
+		Action: ImportCreated,
+	}
+
+	content, conflict := existing[partial.Name]
+	if conflict {
+		if content == partial.Content || onConflict != ConflictOverwrite {
+			result.Action = ImportSkipped
+			return result, nil
+		}
+
+		result.Action = ImportOverwritten
+	}
+
+	_, err := c.partialsRepo.Upsert(conn, models.Partial{
+		Name:    partial.Name,
+		Content: partial.Content,
+	})
+	if err != nil {
+		return PartialImportResult{}, err
+	}
+
+	return result, nil
+}
+
+func (c TemplateBundlesCollection) importTemplate(conn ConnectionInterface, bundled BundledTemplate, options ImportOptions, existing map[string]string) (ImportResult, error) {
+	result := ImportResult{
+		Name:         bundled.Name,
+		ImportedName: bundled.Name,
+		Associations: []TemplateAssociation{},
+	}
+
+	tmpl := models.Template{
+		Name:     bundled.Name,
+		Text:     bundled.Text,
+		HTML:     bundled.HTML,
+		Subject:  bundled.Subject,
+		Metadata: bundled.Metadata,
+		Layout:   bundled.Layout,
+		Markdown: bundled.Markdown,
+	}
+	if tmpl.Metadata == "" {
+		tmpl.Metadata = "{}"
+	}
+
+	existingID, conflict := existing[bundled.Name]
+	switch {
+	case conflict && options.OnConflict == ConflictSkip:
+		result.TemplateID = existingID
+		result.Action = ImportSkipped
+		return result, nil
+
+	case conflict && options.OnConflict == ConflictOverwrite:
+		previous, err := c.templatesRepo.FindByID(conn, existingID)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		_, err = c.templatesRepo.Update(conn, existingID, tmpl)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		result.TemplateID = existingID
+		result.Action = ImportOverwritten
+
+		err = c.auditLog.Record(conn, services.AuditEntry{
+			Token:  options.Token,
+			Action: services.AuditActionUpdateTemplate,
+			Target: "templates/" + existingID,
+			Before: newAuditedTemplate(previous),
+			After:  newAuditedTemplate(tmpl),
+		})
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+	default:
+		result.Action = ImportCreated
+		if conflict {
+			tmpl.Name = availableName(bundled.Name, existing)
+			result.ImportedName = tmpl.Name
+			result.Action = ImportRenamed
+		}
+
+		created, err := c.templatesRepo.Create(conn, tmpl)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		existing[created.Name] = created.ID
+		result.TemplateID = created.ID
+
+		err = c.auditLog.Record(conn, services.AuditEntry{
+			Token:  options.Token,
+			Action: services.AuditActionCreateTemplate,
+			Target: "templates/" + created.ID,
+			After:  newAuditedTemplate(tmpl),
+		})
+		if err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	for _, association := range bundled.Associations {
+		err := c.assign(conn, association, result.TemplateID, options.Token)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		result.Associations = append(result.Associations, association)
+	}
+
+	return result, nil
+}
+
+func (c TemplateBundlesCollection) assign(conn ConnectionInterface, association TemplateAssociation, templateID string, token *jwt.Token) error {
+	var err error
+	if association.NotificationID == "" {
+		err = c.assignToClient(conn, association.ClientID, templateID, token)
+	} else {
+		err = c.assignToNotification(conn, association.ClientID, association.NotificationID, templateID, token)
+	}
+
+	if _, ok := err.(models.NotFoundError); ok {
+		if association.NotificationID == "" {
+			return TemplateBundleError{fmt.Errorf("Client %q does not exist", association.ClientID)}
+		}
+
+		return TemplateBundleError{fmt.Errorf("Notification %q of client %q does not exist", association.NotificationID, association.ClientID)}
+	}
+
+	return err
+}
+
+// assignToClient and assignToNotification restore an association of an
+// imported template and record it in the audit log, along with the template
+// that was assigned before.
+func (c TemplateBundlesCollection) assignToClient(conn ConnectionInterface, clientID, templateID string, token *jwt.Token) error {
+	client, err := c.clientsRepo.Find(conn, clientID)
+	if err != nil {
+		return err
+	}
+
+	err = c.assigner.AssignToClient(conn, clientID, templateID)
+	if err != nil {
+		return err
+	}
+
+	return c.auditLog.Record(conn, services.AuditEntry{
+		Token:  token,
+		Action: services.AuditActionAssignClientTemplate,
+		Target: "clients/" + clientID,
+		Before: auditedAssignment{Template: client.TemplateToUse()},
+		After:  auditedAssignment{Template: templateID},
+	})
+}
+
+func (c TemplateBundlesCollection) assignToNotification(conn ConnectionInterface, clientID, notificationID, templateID string, token *jwt.Token) error {
+	kind, err := c.kindsRepo.Find(conn, notificationID, clientID)
+	if err != nil {
+		return err
+	}
+
+	err = c.assigner.AssignToNotification(conn, clientID, notificationID, templateID)
+	if err != nil {
+		return err
+	}
+
+	return c.auditLog.Record(conn, services.AuditEntry{
+		Token:  token,
+		Action: services.AuditActionAssignNotificationTemplate,
+		Target: "clients/" + clientID + "/notifications/" + notificationID,
+		Before: auditedAssignment{Template: kind.TemplateID},
+		After:  auditedAssignment{Template: templateID},
+	})
+}
+
+func newAuditedTemplate(tmpl models.Template) auditedTemplate {
+	metadata := json.RawMessage(tmpl.Metadata)
+	if !json.Valid(metadata) {
+		metadata = json.RawMessage("{}")
+	}
+
+	return auditedTemplate{
+		Name:     tmpl.Name,
+		Subject:  tmpl.Subject,
+		Text:     tmpl.Text,
+		HTML:     tmpl.HTML,
+		Metadata: metadata,
+		Layout:   tmpl.Layout,
+		Markdown: tmpl.Markdown,
+	}
+}
+
+func validateBundle(bundle TemplateBundle, options ImportOptions) error {
+	if bundle.Version != TemplateBundleVersion {
+		return TemplateBundleError{fmt.Errorf("Unsupported bundle version %d", bundle.Version)}
+	}
+
+	switch options.OnConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return TemplateBundleError{fmt.Errorf("Conflict policy must be %q, %q or %q", ConflictSkip, ConflictOverwrite, ConflictRename)}
+	}
+
+	partials := map[string]string{}
+	for _, partial := range bundle.Partials {
+		if !partialNameFormat.MatchString(partial.Name) {
+			return TemplateBundleError{fmt.Errorf("Partial name %q is improperly formatted", partial.Name)}
+		}
+
+		if _, ok := partials[partial.Name]; ok {
+			return TemplateBundleError{fmt.Errorf("Partial %q appears more than once in the bundle", partial.Name)}
+		}
+		partials[partial.Name] = partial.Content
+	}
+
+	// References to partials that are not in the bundle are checked against
+	// the partials that exist already once the bundle is imported.
+	_, err := common.ParseTemplateSet("", partials)
+	switch err.(type) {
+	case nil, common.MissingPartialError:
+	default:
+		return TemplateBundleError{fmt.Errorf("Bundled partials are malformed: %s", err)}
+	}
+
+	names := map[string]bool{}
+	for _, bundled := range bundle.Templates {
+		if bundled.Name == "" {
+			return TemplateBundleError{fmt.Errorf("Bundled templates must have a name")}
+		}
+
+		if bundled.HTML == "" {
+			return TemplateBundleError{fmt.Errorf("Template %q is missing its html", bundled.Name)}
+		}
+
+		if names[bundled.Name] {
+			return TemplateBundleError{fmt.Errorf("Template %q appears more than once in the bundle", bundled.Name)}
+		}
+		names[bundled.Name] = true
+
+		for _, contents := range []string{bundled.Subject, bundled.Text, bundled.HTML} {
+			_, err := template.New("test").Funcs(markdown.TemplateFuncs).Parse(contents)
+			if err != nil {
+				return TemplateBundleError{fmt.Errorf("Template %q is malformed: %s", bundled.Name, err)}
+			}
+		}
+
+		for _, association := range bundled.Associations {
+			if association.ClientID == "" {
+				return TemplateBundleError{fmt.Errorf("Associations of template %q must name a client", bundled.Name)}
+			}
+		}
+	}
+
+	return nil
+}
+
+func availableName(name string, existing map[string]string) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if _, ok := existing[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+type templatesByName []models.Template
+
+func (t templatesByName) Len() int           { return len(t) }
+func (t templatesByName) Less(i, j int) bool { return t[i].Name < t[j].Name }
+func (t templatesByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+type partialsByName []models.Partial
+
+func (p partialsByName) Len() int           { return len(p) }
+func (p partialsByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
+func (p partialsByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
@@ -0,0 +1,540 @@
+package collections_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/dgrijalva/jwt-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateBundlesCollection", func() {
+	var (
+		templatesRepo *mocks.TemplatesRepo
+		partialsRepo  *mocks.PartialsRepo
+		clientsRepo   *mocks.ClientsRepository
+		kindsRepo     *mocks.KindsRepo
+		auditLog      *mocks.AuditLog
+		lister        *mocks.TemplateAssociationLister
+		assigner      *mocks.TemplateAssigner
+		conn          *mocks.Connection
+		transaction   *mocks.Transaction
+
+		collection collections.TemplateBundlesCollection
+	)
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		conn = mocks.NewConnection()
+		conn.TransactionCall.Returns.Transaction = transaction
+
+		templatesRepo = mocks.NewTemplatesRepo()
+		partialsRepo = mocks.NewPartialsRepo()
+		clientsRepo = mocks.NewClientsRepository()
+		kindsRepo = mocks.NewKindsRepo()
+		auditLog = mocks.NewAuditLog()
+		lister = mocks.NewTemplateAssociationLister()
+		assigner = mocks.NewTemplateAssigner()
+
+		collection = collections.NewTemplateBundlesCollection(templatesRepo, partialsRepo, clientsRepo, kindsRepo, lister, assigner, auditLog)
+	})
+
+	Describe("Export", func() {
+		BeforeEach(func() {
+			templatesRepo.ListIDsAndNamesCall.Returns.Templates = []models.Template{
+				{ID: models.DefaultTemplateID, Name: "Default Template"},
+				{ID: "some-template-id", Name: "Some Template"},
+			}
+			templatesRepo.FindByIDCall.Returns.Template = models.Template{
+				ID:       "some-template-id",
+				Name:     "Some Template",
+				Text:     "some-text",
+				HTML:     `some-html {{template "signature" .}}`,
+				Subject:  "some-subject",
+				Metadata: `{"some": "metadata"}`,
+				Layout:   "some-layout",
+			}
+			partialsRepo.FindByNamesCall.Returns.Partials = []models.Partial{
+				{Name: "some-layout", Content: `<html>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</html>`},
+				{Name: "signature", Content: "Regards"},
+				{Name: "footer", Content: "<footer>{{.Organization}}</footer>"},
+				{Name: "unreferenced", Content: "unused"},
+			}
+			lister.ListCall.Returns.Associations = []collections.TemplateAssociation{
+				{ClientID: "some-client"},
+				{ClientID: "some-client", NotificationID: "some-notification"},
+			}
+		})
+
+		It("bundles the templates together with their associations and partials, leaving out the default template", func() {
+			bundle, err := collection.Export(conn)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(bundle).To(Equal(collections.TemplateBundle{
+				Version: collections.TemplateBundleVersion,
+				Templates: []collections.BundledTemplate{
+					{
+						Template: collections.Template{
+							Name:     "Some Template",
+							Text:     "some-text",
+							HTML:     `some-html {{template "signature" .}}`,
+							Subject:  "some-subject",
+							Metadata: `{"some": "metadata"}`,
+							Layout:   "some-layout",
+						},
+						Associations: []collections.TemplateAssociation{
+							{ClientID: "some-client"},
+							{ClientID: "some-client", NotificationID: "some-notification"},
+						},
+					},
+				},
+				Partials: []collections.Partial{
+					{Name: "footer", Content: "<footer>{{.Organization}}</footer>"},
+					{Name: "signature", Content: "Regards"},
+					{Name: "some-layout", Content: `<html>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</html>`},
+				},
+			}))
+
+			Expect(partialsRepo.FindByNamesCall.Receives.Names).To(Equal([][]string{
+				{"signature", "some-layout"},
+				{"footer"},
+			}))
+
+			Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(lister.ListCall.Receives.Connection).To(Equal(conn))
+			Expect(lister.ListCall.Receives.TemplateID).To(Equal("some-template-id"))
+		})
+
+		Context("when loading the partials fails", func() {
+			It("returns the error", func() {
+				partialsRepo.FindByNamesCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := collection.Export(conn)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+		})
+
+		Context("when listing the associations fails", func() {
+			It("returns the error", func() {
+				lister.ListCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := collection.Export(conn)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+		})
+	})
+
+	Describe("Import", func() {
+		var (
+			bundle collections.TemplateBundle
+			token  *jwt.Token
+		)
+
+		BeforeEach(func() {
+			token = &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}}
+
+			bundle = collections.TemplateBundle{
+				Version: collections.TemplateBundleVersion,
+				Templates: []collections.BundledTemplate{
+					{
+						Template: collections.Template{
+							Name:    "Some Template",
+							HTML:    "some-html",
+							Subject: "{{.Subject}}",
+						},
+						Associations: []collections.TemplateAssociation{
+							{ClientID: "some-client", NotificationID: "some-notification"},
+						},
+					},
+				},
+			}
+
+			templatesRepo.ListIDsAndNamesCall.Returns.Templates = []models.Template{
+				{ID: "existing-template-id", Name: "Existing Template"},
+			}
+			templatesRepo.CreateCall.Returns.Template = models.Template{
+				ID:   "new-template-id",
+				Name: "Some Template",
+			}
+			clientsRepo.FindCall.Returns.Client = models.Client{ID: "some-client", TemplateID: "old-client-template-id"}
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+				{ID: "some-notification", ClientID: "some-client", TemplateID: "old-template-id"},
+			}
+		})
+
+		It("creates the template and assigns it within a transaction", func() {
+			report, err := collection.Import(conn, bundle, collections.ImportOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Templates).To(Equal([]collections.ImportResult{
+				{
+					Name:         "Some Template",
+					ImportedName: "Some Template",
+					TemplateID:   "new-template-id",
+					Action:       collections.ImportCreated,
+					Associations: []collections.TemplateAssociation{
+						{ClientID: "some-client", NotificationID: "some-notification"},
+					},
+				},
+			}))
+
+			Expect(templatesRepo.CreateCall.Receives.Connection).To(Equal(transaction))
+			Expect(templatesRepo.CreateCall.Receives.Template).To(Equal(models.Template{
+				Name:     "Some Template",
+				HTML:     "some-html",
+				Subject:  "{{.Subject}}",
+				Metadata: "{}",
+			}))
+
+			Expect(assigner.AssignToNotificationCall.Receives.Connection).To(Equal(transaction))
+			Expect(assigner.AssignToNotificationCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(assigner.AssignToNotificationCall.Receives.NotificationID).To(Equal("some-notification"))
+			Expect(assigner.AssignToNotificationCall.Receives.TemplateID).To(Equal("new-template-id"))
+
+			Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+		})
+
+		It("records the created template and its assignment in the audit log within the transaction", func() {
+			_, err := collection.Import(conn, bundle, collections.ImportOptions{Token: token})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(auditLog.RecordCall.Receives.Connection).To(Equal(transaction))
+			Expect(auditLog.RecordCall.Receives.Entries).To(HaveLen(2))
+
+			created := auditLog.RecordCall.Receives.Entries[0]
+			Expect(created.Token).To(Equal(token))
+			Expect(created.Action).To(Equal(services.AuditActionCreateTemplate))
+			Expect(created.Target).To(Equal("templates/new-template-id"))
+			Expect(created.Before).To(BeNil())
+			Expect(json.Marshal(created.After)).To(MatchJSON(`{
+				"name": "Some Template",
+				"subject": "{{.Subject}}",
+				"text": "",
+				"html": "some-html",
+				"metadata": {}
+			}`))
+
+			assigned := auditLog.RecordCall.Receives.Entries[1]
+			Expect(assigned.Token).To(Equal(token))
+			Expect(assigned.Action).To(Equal(services.AuditActionAssignNotificationTemplate))
+			Expect(assigned.Target).To(Equal("clients/some-client/notifications/some-notification"))
+			Expect(json.Marshal(assigned.Before)).To(MatchJSON(`{"template": "old-template-id"}`))
+			Expect(json.Marshal(assigned.After)).To(MatchJSON(`{"template": "new-template-id"}`))
+
+			Expect(kindsRepo.FindCall.Receives.Connection).To(Equal(transaction))
+			Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("some-notification"))
+			Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("some-client"))
+		})
+
+		It("records client-level assignments in the audit log", func() {
+			bundle.Templates[0].Associations = []collections.TemplateAssociation{{ClientID: "some-client"}}
+
+			_, err := collection.Import(conn, bundle, collections.ImportOptions{Token: token})
+			Expect(err).NotTo(HaveOccurred())
+
+			assigned := auditLog.RecordCall.Receives.Entry
+			Expect(assigned.Action).To(Equal(services.AuditActionAssignClientTemplate))
+			Expect(assigned.Target).To(Equal("clients/some-client"))
+			Expect(json.Marshal(assigned.Before)).To(MatchJSON(`{"template": "old-client-template-id"}`))
+			Expect(json.Marshal(assigned.After)).To(MatchJSON(`{"template": "new-template-id"}`))
+
+			Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(transaction))
+			Expect(clientsRepo.FindCall.Receives.ClientID).To(Equal("some-client"))
+		})
+
+		It("rolls back and returns the error when the audit event cannot be recorded", func() {
+			auditLog.RecordCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := collection.Import(conn, bundle, collections.ImportOptions{Token: token})
+			Expect(err).To(MatchError(errors.New("BOOM!")))
+
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+
+		It("assigns client-level associations to the client", func() {
+			bundle.Templates[0].Associations = []collections.TemplateAssociation{{ClientID: "some-client"}}
+
+			_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(assigner.AssignToClientCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(assigner.AssignToClientCall.Receives.TemplateID).To(Equal("new-template-id"))
+		})
+
+		Context("when performing a dry run", func() {
+			It("rolls back the transaction but still reports the results", func() {
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{DryRun: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Templates).To(HaveLen(1))
+				Expect(report.Templates[0].Action).To(Equal(collections.ImportCreated))
+
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		Context("when a template with the same name already exists", func() {
+			BeforeEach(func() {
+				bundle.Templates[0].Name = "Existing Template"
+			})
+
+			It("skips the template by default", func() {
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Templates[0].Action).To(Equal(collections.ImportSkipped))
+				Expect(report.Templates[0].TemplateID).To(Equal("existing-template-id"))
+				Expect(report.Templates[0].Associations).To(BeEmpty())
+
+				Expect(templatesRepo.CreateCall.Receives.Connection).To(BeNil())
+				Expect(templatesRepo.UpdateCall.Receives.Connection).To(BeNil())
+				Expect(assigner.AssignToNotificationCall.Receives.Connection).To(BeNil())
+				Expect(auditLog.RecordCall.CallCount).To(Equal(0))
+			})
+
+			It("overwrites the existing template when asked to", func() {
+				templatesRepo.FindByIDCall.Returns.Template = models.Template{
+					ID:       "existing-template-id",
+					Name:     "Existing Template",
+					HTML:     "old-html",
+					Subject:  "old-subject",
+					Metadata: `{"old": true}`,
+				}
+
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{OnConflict: collections.ConflictOverwrite, Token: token})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Templates[0].Action).To(Equal(collections.ImportOverwritten))
+				Expect(report.Templates[0].TemplateID).To(Equal("existing-template-id"))
+
+				Expect(templatesRepo.UpdateCall.Receives.Connection).To(Equal(transaction))
+				Expect(templatesRepo.UpdateCall.Receives.TemplateID).To(Equal("existing-template-id"))
+				Expect(templatesRepo.UpdateCall.Receives.Template.Name).To(Equal("Existing Template"))
+				Expect(assigner.AssignToNotificationCall.Receives.TemplateID).To(Equal("existing-template-id"))
+
+				updated := auditLog.RecordCall.Receives.Entries[0]
+				Expect(updated.Action).To(Equal(services.AuditActionUpdateTemplate))
+				Expect(updated.Target).To(Equal("templates/existing-template-id"))
+				Expect(json.Marshal(updated.Before)).To(MatchJSON(`{
+					"name": "Existing Template",
+					"subject": "old-subject",
+					"text": "",
+					"html": "old-html",
+					"metadata": {"old": true}
+				}`))
+				Expect(json.Marshal(updated.After)).To(MatchJSON(`{
+					"name": "Existing Template",
+					"subject": "{{.Subject}}",
+					"text": "",
+					"html": "some-html",
+					"metadata": {}
+				}`))
+			})
+
+			It("imports the template under a new name when asked to", func() {
+				templatesRepo.ListIDsAndNamesCall.Returns.Templates = append(templatesRepo.ListIDsAndNamesCall.Returns.Templates,
+					models.Template{ID: "another-template-id", Name: "Existing Template (2)"})
+				templatesRepo.CreateCall.Returns.Template = models.Template{
+					ID:   "new-template-id",
+					Name: "Existing Template (3)",
+				}
+
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{OnConflict: collections.ConflictRename})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Templates[0].Action).To(Equal(collections.ImportRenamed))
+				Expect(report.Templates[0].ImportedName).To(Equal("Existing Template (3)"))
+				Expect(report.Templates[0].TemplateID).To(Equal("new-template-id"))
+
+				Expect(templatesRepo.CreateCall.Receives.Template.Name).To(Equal("Existing Template (3)"))
+			})
+		})
+
+		Context("when the bundle contains partials", func() {
+			BeforeEach(func() {
+				bundle.Templates[0].HTML = `some-html {{template "signature" .}}`
+				bundle.Templates[0].Layout = "branded"
+				bundle.Partials = []collections.Partial{
+					{Name: "branded", Content: `<html>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</html>`},
+					{Name: "signature", Content: "Regards"},
+				}
+				partialsRepo.FindByNamesCall.Returns.Partials = []models.Partial{
+					{Name: "footer", Content: "<footer>{{.Organization}}</footer>"},
+					{Name: "signature", Content: "Cheers"},
+				}
+			})
+
+			It("creates the partials that do not exist and skips those that do within the transaction", func() {
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Partials).To(Equal([]collections.PartialImportResult{
+					{Name: "branded", Action: collections.ImportCreated},
+					{Name: "signature", Action: collections.ImportSkipped},
+				}))
+
+				Expect(partialsRepo.UpsertCall.Receives.Connection).To(Equal(transaction))
+				Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.Partial{
+					Name:    "branded",
+					Content: `<html>{{.HTMLComponents.BodyContent}}{{template "footer" .}}</html>`,
+				}))
+				Expect(partialsRepo.FindByNamesCall.Receives.Names).To(Equal([][]string{
+					{"branded", "signature", "footer"},
+				}))
+			})
+
+			It("overwrites existing partials when asked to", func() {
+				bundle.Partials = bundle.Partials[1:]
+				bundle.Templates[0].Layout = ""
+
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{OnConflict: collections.ConflictOverwrite})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Partials).To(Equal([]collections.PartialImportResult{
+					{Name: "signature", Action: collections.ImportOverwritten},
+				}))
+				Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.Partial{
+					Name:    "signature",
+					Content: "Regards",
+				}))
+			})
+
+			It("keeps existing partials under the rename policy, since templates refer to them by name", func() {
+				bundle.Partials = bundle.Partials[1:]
+				bundle.Templates[0].Layout = ""
+
+				report, err := collection.Import(conn, bundle, collections.ImportOptions{OnConflict: collections.ConflictRename})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Partials).To(Equal([]collections.PartialImportResult{
+					{Name: "signature", Action: collections.ImportSkipped},
+				}))
+				Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.Partial{}))
+			})
+
+			It("rejects layouts that do not exist", func() {
+				bundle.Partials = bundle.Partials[1:]
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New(`Template "Some Template" references partial "branded", which is neither in the bundle nor in this deployment`)}))
+			})
+
+			It("rejects partials that reference partials that do not exist", func() {
+				partialsRepo.FindByNamesCall.Returns.Partials = nil
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New(`Partial "branded" references partial "footer", which is neither in the bundle nor in this deployment`)}))
+				Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			})
+
+			It("rejects partials with improperly formatted names", func() {
+				bundle.Partials[0].Name = "not a name"
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New(`Partial name "not a name" is improperly formatted`)}))
+			})
+
+			It("rejects malformed partials", func() {
+				bundle.Partials[1].Content = "{{.Broken"
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(BeAssignableToTypeOf(collections.TemplateBundleError{}))
+			})
+
+			It("rolls back and returns the error when a partial cannot be saved", func() {
+				partialsRepo.UpsertCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		It("rejects templates that reference partials that do not exist", func() {
+			bundle.Templates[0].HTML = `some-html {{template "signature" .}}`
+
+			_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+			Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New(`Template "Some Template" references partial "signature", which is neither in the bundle nor in this deployment`)}))
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+		})
+
+		Context("when an associated notification does not exist", func() {
+			It("rolls back and returns a bundle error", func() {
+				assigner.AssignToNotificationCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New(`Notification "some-notification" of client "some-client" does not exist`)}))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the transaction cannot be started", func() {
+			It("returns the error without importing anything", func() {
+				transaction.BeginCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+				Expect(templatesRepo.CreateCall.Receives.Template).To(Equal(models.Template{}))
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when creating a template fails", func() {
+			It("rolls back and returns the error", func() {
+				templatesRepo.CreateCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		Context("when the bundle is invalid", func() {
+			It("rejects unsupported versions", func() {
+				bundle.Version = 2
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New("Unsupported bundle version 2")}))
+				Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			})
+
+			It("rejects unknown conflict policies", func() {
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{OnConflict: "merge"})
+				Expect(err).To(BeAssignableToTypeOf(collections.TemplateBundleError{}))
+			})
+
+			It("rejects templates with duplicate names", func() {
+				bundle.Templates = append(bundle.Templates, bundle.Templates[0])
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(MatchError(collections.TemplateBundleError{Err: errors.New(`Template "Some Template" appears more than once in the bundle`)}))
+			})
+
+			It("rejects templates with malformed syntax", func() {
+				bundle.Templates[0].HTML = "{{.Broken"
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(BeAssignableToTypeOf(collections.TemplateBundleError{}))
+			})
+
+			It("rejects associations without a client", func() {
+				bundle.Templates[0].Associations = []collections.TemplateAssociation{{NotificationID: "some-notification"}}
+
+				_, err := collection.Import(conn, bundle, collections.ImportOptions{})
+				Expect(err).To(BeAssignableToTypeOf(collections.TemplateBundleError{}))
+			})
+		})
+	})
+})
@@ -52,6 +52,8 @@ type Template struct {
 	HTML     string
 	Subject  string
 	Metadata string
+	Layout   string
+	Markdown string
 }
 
 type TemplatesCollection struct {
@@ -180,6 +182,8 @@ func (c TemplatesCollection) Create(connection ConnectionInterface, template Tem
 		HTML:     template.HTML,
 		Subject:  template.Subject,
 		Metadata: template.Metadata,
+		Layout:   template.Layout,
+		Markdown: template.Markdown,
 	})
 	if err != nil {
 		return Template{}, err
@@ -192,6 +196,8 @@ func (c TemplatesCollection) Create(connection ConnectionInterface, template Tem
 		HTML:     tmpl.HTML,
 		Subject:  tmpl.Subject,
 		Metadata: tmpl.Metadata,
+		Layout:   tmpl.Layout,
+		Markdown: tmpl.Markdown,
 	}, nil
 }
 
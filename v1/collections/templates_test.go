@@ -334,6 +334,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				Layout:   "some-layout",
 			}
 
 			template, err := collection.Create(conn, collections.Template{
@@ -342,6 +343,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				Layout:   "some-layout",
 			})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(template).To(Equal(collections.Template{
@@ -351,6 +353,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				Layout:   "some-layout",
 			}))
 
 			Expect(templatesRepo.CreateCall.Receives.Connection).To(Equal(conn))
@@ -360,6 +363,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				Layout:   "some-layout",
 			}))
 		})
 
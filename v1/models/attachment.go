@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+type Attachment struct {
+	ID        string    `db:"id"`
+	Content   []byte    `db:"content"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (a *Attachment) PreInsert(s gorp.SqlExecutor) error {
+	a.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
+
+// AttachmentReference records that a message or a fan-out, its owner, still
+// needs an attachment. An attachment is only collected once it has no
+// references left.
+type AttachmentReference struct {
+	AttachmentID string `db:"attachment_id"`
+	OwnerID      string `db:"owner_id"`
+}
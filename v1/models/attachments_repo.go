@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type AttachmentsRepo struct {
+	generateID IDGeneratorFunc
+}
+
+func NewAttachmentsRepo(guidGenerator IDGeneratorFunc) AttachmentsRepo {
+	return AttachmentsRepo{
+		generateID: guidGenerator,
+	}
+}
+
+func (repo AttachmentsRepo) Create(conn ConnectionInterface, attachment Attachment) (Attachment, error) {
+	if attachment.ID == "" {
+		var err error
+		attachment.ID, err = repo.generateID()
+		if err != nil {
+			return Attachment{}, err
+		}
+	}
+
+	err := conn.Insert(&attachment)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+func (repo AttachmentsRepo) FindByID(conn ConnectionInterface, attachmentID string) (Attachment, error) {
+	attachment := Attachment{}
+	err := conn.SelectOne(&attachment, "SELECT * FROM `attachments` WHERE `id`=?", attachmentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Attachment{}, NotFoundError{fmt.Errorf("Attachment with ID %q could not be found", attachmentID)}
+		}
+		return Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// Reference records that the message or fan-out with the given ID needs the
+// attachment, so that it is not collected before its owner is deleted.
+func (repo AttachmentsRepo) Reference(conn ConnectionInterface, attachmentID, ownerID string) error {
+	return conn.Insert(&AttachmentReference{
+		AttachmentID: attachmentID,
+		OwnerID:      ownerID,
+	})
+}
+
+// DeleteBefore removes up to limit attachments created before the threshold
+// that are no longer referenced by any message or fan-out.
+func (repo AttachmentsRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	result, err := conn.Exec("DELETE FROM `attachments` WHERE `created_at` < ? AND NOT EXISTS (SELECT 1 FROM `attachment_references` WHERE `attachment_references`.`attachment_id` = `attachments`.`id`) ORDER BY `created_at` LIMIT ?", threshold.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
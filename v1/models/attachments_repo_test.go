@@ -0,0 +1,121 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AttachmentsRepo", func() {
+	var (
+		repo          models.AttachmentsRepo
+		conn          db.ConnectionInterface
+		guidGenerator *mocks.IDGenerator
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{
+			"first-random-guid",
+		}
+
+		repo = models.NewAttachmentsRepo(guidGenerator.Generate)
+	})
+
+	Describe("Create", func() {
+		It("stores the attachment content", func() {
+			attachment, err := repo.Create(conn, models.Attachment{
+				Content: []byte("item,price\nbanana,1\n"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachment.ID).To(Equal("first-random-guid"))
+
+			attachment, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachment.Content).To(Equal([]byte("item,price\nbanana,1\n")))
+			Expect(attachment.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("returns an error when the guid generator errors", func() {
+			guidGenerator.GenerateCall.Returns.Error = errors.New("something bad")
+
+			_, err := repo.Create(conn, models.Attachment{})
+			Expect(err).To(MatchError(errors.New("something bad")))
+		})
+	})
+
+	Describe("FindByID", func() {
+		It("returns a not found error when the attachment does not exist", func() {
+			_, err := repo.FindByID(conn, "missing-id")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New(`Attachment with ID "missing-id" could not be found`)}))
+		})
+	})
+
+	Describe("DeleteBefore", func() {
+		It("deletes attachments older than the given time", func() {
+			_, err := repo.Create(conn, models.Attachment{Content: []byte("banana")})
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(0))
+
+			itemsDeleted, err = repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+
+			_, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("keeps attachments that a message or fan-out still references", func() {
+			_, err := repo.Create(conn, models.Attachment{Content: []byte("banana")})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Reference(conn, "first-random-guid", "some-message-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(0))
+
+			_, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.NewMessagesRepo(guidGenerator.Generate).Delete(conn, []string{"some-message-id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err = repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+		})
+
+		It("deletes no more than the limit", func() {
+			guidGenerator.GenerateCall.Returns.IDs = []string{
+				"first-random-guid",
+				"second-random-guid",
+			}
+
+			_, err := repo.Create(conn, models.Attachment{Content: []byte("banana")})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.Attachment{Content: []byte("kiwi")})
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+		})
+	})
+})
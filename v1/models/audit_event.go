@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	AuditActorTypeClient = "client"
+	AuditActorTypeUser   = "user"
+)
+
+// AuditEvent records an administrative change: who made it, what they did
+// to which target, and the JSON representation of the target before and
+// after the change. Audit events are only ever appended.
+type AuditEvent struct {
+	Primary   int       `db:"primary"`
+	ID        string    `db:"id"`
+	ActorType string    `db:"actor_type"`
+	ActorID   string    `db:"actor_id"`
+	ClientID  string    `db:"client_id"`
+	Action    string    `db:"action"`
+	Target    string    `db:"target"`
+	Before    string    `db:"state_before"`
+	After     string    `db:"state_after"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AuditEventFilter narrows the audit events that are listed. Empty fields
+// and zero times do not filter. Events are listed newest first, skipping
+// Offset events and returning at most Limit of them.
+type AuditEventFilter struct {
+	ActorID string
+	Action  string
+	Target  string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+	Offset  int
+}
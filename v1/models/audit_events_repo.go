@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type AuditEventsRepo struct {
+	generateID IDGeneratorFunc
+}
+
+func NewAuditEventsRepo(guidGenerator IDGeneratorFunc) AuditEventsRepo {
+	return AuditEventsRepo{
+		generateID: guidGenerator,
+	}
+}
+
+func (repo AuditEventsRepo) Create(conn ConnectionInterface, event AuditEvent) (AuditEvent, error) {
+	if event.ID == "" {
+		var err error
+		event.ID, err = repo.generateID()
+		if err != nil {
+			return AuditEvent{}, err
+		}
+	}
+
+	event.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	err := conn.Insert(&event)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	return event, nil
+}
+
+// List returns a page of the audit events matching the filter, newest
+// first, along with the number of events that match in total.
+func (repo AuditEventsRepo) List(conn ConnectionInterface, filter AuditEventFilter) ([]AuditEvent, int, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if filter.ActorID != "" {
+		conditions = append(conditions, "`actor_id` = ?")
+		args = append(args, filter.ActorID)
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "`action` = ?")
+		args = append(args, filter.Action)
+	}
+
+	if filter.Target != "" {
+		conditions = append(conditions, "`target` = ?")
+		args = append(args, filter.Target)
+	}
+
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "`created_at` >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "`created_at` < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	var where string
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := conn.SelectOne(&total, "SELECT COUNT(*) FROM `audit_events`"+where, args...)
+	if err != nil {
+		return []AuditEvent{}, 0, err
+	}
+
+	events := []AuditEvent{}
+	_, err = conn.Select(&events, "SELECT * FROM `audit_events`"+where+" ORDER BY `primary` DESC LIMIT ? OFFSET ?", append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return []AuditEvent{}, 0, err
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,119 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditEventsRepo", func() {
+	var (
+		repo          models.AuditEventsRepo
+		conn          db.ConnectionInterface
+		guidGenerator *mocks.IDGenerator
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{
+			"first-random-guid",
+			"second-random-guid",
+			"third-random-guid",
+		}
+
+		repo = models.NewAuditEventsRepo(guidGenerator.Generate)
+	})
+
+	Describe("Create", func() {
+		It("stores the audit event with a generated ID", func() {
+			event, err := repo.Create(conn, models.AuditEvent{
+				ActorType: models.AuditActorTypeClient,
+				ActorID:   "admin-client",
+				ClientID:  "admin-client",
+				Action:    "client.template.assign",
+				Target:    "clients/some-client",
+				Before:    `{"template":"default"}`,
+				After:     `{"template":"new-template"}`,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.ID).To(Equal("first-random-guid"))
+			Expect(event.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+
+			events, total, err := repo.List(conn, models.AuditEventFilter{Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(1))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].ActorID).To(Equal("admin-client"))
+			Expect(events[0].Before).To(Equal(`{"template":"default"}`))
+			Expect(events[0].After).To(Equal(`{"template":"new-template"}`))
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			for _, event := range []models.AuditEvent{
+				{ActorType: "client", ActorID: "admin-client", Action: "notification.update", Target: "clients/a/notifications/b", Before: "{}", After: "{}"},
+				{ActorType: "user", ActorID: "admin-user", Action: "user_preferences.update", Target: "user_preferences/c", Before: "{}", After: "{}"},
+				{ActorType: "client", ActorID: "admin-client", Action: "default_template.update", Target: "default_template", Before: "{}", After: "{}"},
+			} {
+				_, err := repo.Create(conn, event)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("lists the newest events first", func() {
+			events, total, err := repo.List(conn, models.AuditEventFilter{Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(3))
+			Expect(events).To(HaveLen(3))
+			Expect(events[0].ID).To(Equal("third-random-guid"))
+			Expect(events[2].ID).To(Equal("first-random-guid"))
+		})
+
+		It("filters by actor, action and target", func() {
+			events, total, err := repo.List(conn, models.AuditEventFilter{ActorID: "admin-client", Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(2))
+			Expect(events).To(HaveLen(2))
+
+			events, _, err = repo.List(conn, models.AuditEventFilter{Action: "user_preferences.update", Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].ID).To(Equal("second-random-guid"))
+
+			events, _, err = repo.List(conn, models.AuditEventFilter{Target: "default_template", Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].ID).To(Equal("third-random-guid"))
+		})
+
+		It("filters by when the events were created", func() {
+			events, _, err := repo.List(conn, models.AuditEventFilter{Since: time.Now().Add(-time.Hour), Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(3))
+
+			events, total, err := repo.List(conn, models.AuditEventFilter{Until: time.Now().Add(-time.Hour), Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(0))
+			Expect(events).To(BeEmpty())
+		})
+
+		It("pages through the events", func() {
+			events, total, err := repo.List(conn, models.AuditEventFilter{Limit: 2, Offset: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(3))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].ID).To(Equal("first-random-guid"))
+		})
+	})
+})
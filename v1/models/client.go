@@ -12,6 +12,14 @@ type Client struct {
 	Description string    `db:"description"`
 	CreatedAt   time.Time `db:"created_at"`
 	TemplateID  string    `db:"template_id"`
+
+	// RateLimit is the number of notify requests the client may make per
+	// minute, and RateLimitBurst how many of them it may make at once.
+	// DailyRecipientQuota caps the recipients it may send to in a UTC day.
+	// Zero means unlimited.
+	RateLimit           int `db:"rate_limit"`
+	RateLimitBurst      int `db:"rate_limit_burst"`
+	DailyRecipientQuota int `db:"daily_recipient_quota"`
 }
 
 func (c Client) TemplateToUse() string {
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type ClientRecipientCount struct {
+	Primary  int       `db:"primary"`
+	ClientID string    `db:"client_id"`
+	Day      time.Time `db:"day"`
+	Count    int       `db:"count"`
+}
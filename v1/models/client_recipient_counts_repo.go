@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ClientRecipientCountsRepo keeps a running count of the recipients each
+// client has sent to per UTC day, so that daily quotas can be enforced.
+type ClientRecipientCountsRepo struct{}
+
+func NewClientRecipientCountsRepo() ClientRecipientCountsRepo {
+	return ClientRecipientCountsRepo{}
+}
+
+func (repo ClientRecipientCountsRepo) Count(conn ConnectionInterface, clientID string, day time.Time) (int, error) {
+	record := ClientRecipientCount{}
+	err := conn.SelectOne(&record, "SELECT * FROM `client_recipient_counts` WHERE `client_id` = ? AND `day` = ?", clientID, utcDay(day))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return record.Count, nil
+}
+
+func (repo ClientRecipientCountsRepo) Increment(conn ConnectionInterface, clientID string, day time.Time, recipients int) error {
+	query := "INSERT INTO `client_recipient_counts` (`client_id`, `day`, `count`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `count`=`count`+VALUES(`count`)"
+	_, err := conn.Exec(query, clientID, utcDay(day), recipients)
+
+	return err
+}
+
+// Reserve adds recipients to the count of the client for the day, as long as
+// the count stays within quota, and reports whether it did. The check and the
+// increment are a single conditional update, so that concurrent requests
+// cannot both take the last of a quota.
+func (repo ClientRecipientCountsRepo) Reserve(conn ConnectionInterface, clientID string, day time.Time, recipients, quota int) (bool, error) {
+	_, err := conn.Exec("INSERT IGNORE INTO `client_recipient_counts` (`client_id`, `day`, `count`) VALUES (?, ?, 0)", clientID, utcDay(day))
+	if err != nil {
+		return false, err
+	}
+
+	result, err := conn.Exec("UPDATE `client_recipient_counts` SET `count` = `count` + ? WHERE `client_id` = ? AND `day` = ? AND `count` + ? <= ?", recipients, clientID, utcDay(day), recipients, quota)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return updated == 1, nil
+}
+
+func utcDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
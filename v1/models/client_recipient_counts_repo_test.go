@@ -0,0 +1,82 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientRecipientCountsRepo", func() {
+	var (
+		repo models.ClientRecipientCountsRepo
+		conn *db.Connection
+		day  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewClientRecipientCountsRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		conn = database.Connection().(*db.Connection)
+		day = time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC)
+	})
+
+	It("returns zero for a client that has not sent anything that day", func() {
+		count, err := repo.Count(conn, "some-client", day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+
+	It("adds up the recipients sent to during the day", func() {
+		Expect(repo.Increment(conn, "some-client", day, 3)).To(Succeed())
+		Expect(repo.Increment(conn, "some-client", day.Add(-time.Hour), 4)).To(Succeed())
+		Expect(repo.Increment(conn, "other-client", day, 100)).To(Succeed())
+
+		count, err := repo.Count(conn, "some-client", day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(7))
+	})
+
+	It("counts each UTC day separately", func() {
+		Expect(repo.Increment(conn, "some-client", day, 3)).To(Succeed())
+
+		count, err := repo.Count(conn, "some-client", day.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+
+	Describe("Reserve", func() {
+		It("adds the recipients when they fit within the quota", func() {
+			reserved, err := repo.Reserve(conn, "some-client", day, 4, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reserved).To(BeTrue())
+
+			reserved, err = repo.Reserve(conn, "some-client", day, 1, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reserved).To(BeTrue())
+
+			count, err := repo.Count(conn, "some-client", day)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(5))
+		})
+
+		It("refuses recipients that would exceed the quota without counting them", func() {
+			Expect(repo.Increment(conn, "some-client", day, 4)).To(Succeed())
+
+			reserved, err := repo.Reserve(conn, "some-client", day, 2, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reserved).To(BeFalse())
+
+			count, err := repo.Count(conn, "some-client", day)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(4))
+		})
+	})
+})
@@ -67,6 +67,10 @@ func (repo ClientsRepo) Upsert(conn ConnectionInterface, client Client) (Client,
 
 		return client, err
 	case nil:
+		client.RateLimit = existingClient.RateLimit
+		client.RateLimitBurst = existingClient.RateLimitBurst
+		client.DailyRecipientQuota = existingClient.DailyRecipientQuota
+
 		return repo.Update(conn, client)
 	default:
 		return client, err
@@ -179,6 +179,27 @@ var _ = Describe("ClientsRepo", func() {
 				Expect(client.Description).To(Equal("My Client"))
 				Expect(client.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
 			})
+
+			It("keeps the limits of the existing record", func() {
+				client, err := repo.Upsert(conn, models.Client{
+					ID:                  "my-client",
+					RateLimit:           60,
+					RateLimitBurst:      10,
+					DailyRecipientQuota: 5000,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				client, err = repo.Upsert(conn, models.Client{
+					ID:          "my-client",
+					Description: "My Client",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.Description).To(Equal("My Client"))
+				Expect(client.RateLimit).To(Equal(60))
+				Expect(client.RateLimitBurst).To(Equal(10))
+				Expect(client.DailyRecipientQuota).To(Equal(5000))
+			})
 		})
 
 		Context("when the record comes into existence after the Find, but before we create it", func() {
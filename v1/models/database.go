@@ -29,10 +29,18 @@ func NewDatabase(sqlDB *sql.DB, config Config) DatabaseInterface {
 
 func Setup(database *db.DB) {
 	database.TableMap().AddTableWithName(Client{}, "clients").SetKeys(true, "Primary").ColMap("ID").SetUnique(true)
+	database.TableMap().AddTableWithName(ClientRecipientCount{}, "client_recipient_counts").SetKeys(true, "Primary").SetUniqueTogether("client_id", "day")
 	database.TableMap().AddTableWithName(Kind{}, "kinds").SetKeys(true, "Primary").SetUniqueTogether("id", "client_id")
 	database.TableMap().AddTableWithName(Receipt{}, "receipts").SetKeys(true, "Primary").SetUniqueTogether("user_guid", "client_id", "kind_id")
 	database.TableMap().AddTableWithName(Unsubscribe{}, "unsubscribes").SetKeys(true, "Primary").SetUniqueTogether("user_id", "client_id", "kind_id")
 	database.TableMap().AddTableWithName(GlobalUnsubscribe{}, "global_unsubscribes").SetKeys(true, "Primary").ColMap("UserID").SetUnique(true)
 	database.TableMap().AddTableWithName(Template{}, "templates").SetKeys(true, "Primary").ColMap("Name").SetUnique(true)
 	database.TableMap().AddTableWithName(Message{}, "messages").SetKeys(false, "ID")
+	database.TableMap().AddTableWithName(Partial{}, "partials").SetKeys(true, "Primary").ColMap("Name").SetUnique(true)
+	database.TableMap().AddTableWithName(Attachment{}, "attachments").SetKeys(false, "ID")
+	database.TableMap().AddTableWithName(AttachmentReference{}, "attachment_references").SetKeys(false, "AttachmentID", "OwnerID")
+	database.TableMap().AddTableWithName(MessageRecipient{}, "message_recipients").SetKeys(true, "Primary").SetUniqueTogether("message_id", "address")
+	database.TableMap().AddTableWithName(Suppression{}, "suppressions").SetKeys(true, "Primary").SetUniqueTogether("type", "value")
+	database.TableMap().AddTableWithName(AuditEvent{}, "audit_events").SetKeys(true, "Primary").ColMap("ID").SetUnique(true)
+	database.TableMap().AddTableWithName(FanOut{}, "fanouts").SetKeys(false, "ID")
 }
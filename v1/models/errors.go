@@ -31,3 +31,11 @@ type TemplateUpdateError struct {
 func (e TemplateUpdateError) Error() string {
 	return e.Err.Error()
 }
+
+type ConflictError struct {
+	Err error
+}
+
+func (e ConflictError) Error() string {
+	return e.Err.Error()
+}
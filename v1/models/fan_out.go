@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+const (
+	FanOutStatusQueued     = "queued"
+	FanOutStatusInProgress = "in_progress"
+	FanOutStatusCompleted  = "completed"
+	FanOutStatusFailed     = "failed"
+)
+
+// FanOut tracks a notification sent to a group of users, such as the members
+// of a space, whose deliveries are enqueued in the background. Enqueued is
+// the number of the Total recipients whose deliveries have been enqueued so
+// far.
+type FanOut struct {
+	ID        string    `db:"id"`
+	ClientID  string    `db:"client_id"`
+	Status    string    `db:"status"`
+	Total     int       `db:"total"`
+	Enqueued  int       `db:"enqueued"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (f *FanOut) PreInsert(s gorp.SqlExecutor) error {
+	f.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	f.UpdatedAt = f.CreatedAt
+
+	return nil
+}
+
+func (f *FanOut) PreUpdate(s gorp.SqlExecutor) error {
+	f.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type FanOutsRepo struct {
+	generateID IDGeneratorFunc
+}
+
+func NewFanOutsRepo(guidGenerator IDGeneratorFunc) FanOutsRepo {
+	return FanOutsRepo{
+		generateID: guidGenerator,
+	}
+}
+
+func (repo FanOutsRepo) Create(conn ConnectionInterface, fanOut FanOut) (FanOut, error) {
+	if fanOut.ID == "" {
+		var err error
+		fanOut.ID, err = repo.generateID()
+		if err != nil {
+			return FanOut{}, err
+		}
+	}
+
+	err := conn.Insert(&fanOut)
+	if err != nil {
+		return FanOut{}, err
+	}
+
+	return fanOut, nil
+}
+
+func (repo FanOutsRepo) FindByID(conn ConnectionInterface, fanOutID string) (FanOut, error) {
+	fanOut := FanOut{}
+	err := conn.SelectOne(&fanOut, "SELECT * FROM `fanouts` WHERE `id`=?", fanOutID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return FanOut{}, NotFoundError{fmt.Errorf("Fan-out with ID %q could not be found", fanOutID)}
+		}
+		return FanOut{}, err
+	}
+
+	return fanOut, nil
+}
+
+// Advance records that the deliveries have been enqueued up to the user at
+// position enqueued. The progress is only recorded when no one else has
+// recorded any since the fan-out was read, so that a chunk is not enqueued
+// twice by workers that run the same fan-out at once. A ConflictError is
+// returned otherwise.
+func (repo FanOutsRepo) Advance(conn ConnectionInterface, fanOut FanOut, enqueued int) (FanOut, error) {
+	updatedAt := time.Now().Truncate(1 * time.Second).UTC()
+
+	result, err := conn.Exec("UPDATE `fanouts` SET `status` = ?, `enqueued` = ?, `updated_at` = ? WHERE `id` = ? AND `enqueued` = ?",
+		FanOutStatusInProgress, enqueued, updatedAt, fanOut.ID, fanOut.Enqueued)
+	if err != nil {
+		return FanOut{}, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return FanOut{}, err
+	}
+
+	if count == 0 {
+		return FanOut{}, ConflictError{fmt.Errorf("Fan-out with ID %q has already progressed past %d", fanOut.ID, fanOut.Enqueued)}
+	}
+
+	fanOut.Status = FanOutStatusInProgress
+	fanOut.Enqueued = enqueued
+	fanOut.UpdatedAt = updatedAt
+
+	return fanOut, nil
+}
+
+// SetStatus changes the status of the fan-out without touching its progress.
+func (repo FanOutsRepo) SetStatus(conn ConnectionInterface, fanOut FanOut, status string) (FanOut, error) {
+	updatedAt := time.Now().Truncate(1 * time.Second).UTC()
+
+	_, err := conn.Exec("UPDATE `fanouts` SET `status` = ?, `updated_at` = ? WHERE `id` = ?", status, updatedAt, fanOut.ID)
+	if err != nil {
+		return FanOut{}, err
+	}
+
+	fanOut.Status = status
+	fanOut.UpdatedAt = updatedAt
+
+	return fanOut, nil
+}
+
+// DeleteBefore removes up to limit completed or failed fan-outs whose
+// progress was last recorded before the threshold, along with their
+// references to attachments. Fan-outs that are still queued or in progress
+// are kept, as their jobs have yet to enqueue the deliveries.
+func (repo FanOutsRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	var fanOutIDs []string
+	_, err := conn.Select(&fanOutIDs, "SELECT `id` FROM `fanouts` WHERE `status` IN (?, ?) AND `updated_at` < ? ORDER BY `updated_at` LIMIT ?",
+		FanOutStatusCompleted, FanOutStatusFailed, threshold.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(fanOutIDs) == 0 {
+		return 0, nil
+	}
+
+	var args []interface{}
+	for _, id := range fanOutIDs {
+		args = append(args, id)
+	}
+
+	_, err = conn.Exec("DELETE FROM `attachment_references` WHERE `owner_id` IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := conn.Exec("DELETE FROM `fanouts` WHERE `id` IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
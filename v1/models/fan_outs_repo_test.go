@@ -0,0 +1,192 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FanOutsRepo", func() {
+	var (
+		repo          models.FanOutsRepo
+		conn          db.ConnectionInterface
+		guidGenerator *mocks.IDGenerator
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{
+			"first-random-guid",
+		}
+
+		repo = models.NewFanOutsRepo(guidGenerator.Generate)
+	})
+
+	Describe("Create", func() {
+		It("stores the fan-out", func() {
+			fanOut, err := repo.Create(conn, models.FanOut{
+				ClientID: "some-client",
+				Status:   models.FanOutStatusQueued,
+				Total:    3,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.ID).To(Equal("first-random-guid"))
+
+			fanOut, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.ClientID).To(Equal("some-client"))
+			Expect(fanOut.Status).To(Equal(models.FanOutStatusQueued))
+			Expect(fanOut.Total).To(Equal(3))
+			Expect(fanOut.Enqueued).To(Equal(0))
+			Expect(fanOut.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("returns an error when the guid generator errors", func() {
+			guidGenerator.GenerateCall.Returns.Error = errors.New("something bad")
+
+			_, err := repo.Create(conn, models.FanOut{})
+			Expect(err).To(MatchError(errors.New("something bad")))
+		})
+	})
+
+	Describe("FindByID", func() {
+		It("returns a not found error when the fan-out does not exist", func() {
+			_, err := repo.FindByID(conn, "missing-id")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New(`Fan-out with ID "missing-id" could not be found`)}))
+		})
+	})
+
+	Describe("Advance", func() {
+		var fanOut models.FanOut
+
+		BeforeEach(func() {
+			var err error
+			fanOut, err = repo.Create(conn, models.FanOut{
+				ClientID: "some-client",
+				Status:   models.FanOutStatusQueued,
+				Total:    3,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("records the progress of the fan-out", func() {
+			fanOut, err := repo.Advance(conn, fanOut, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.Status).To(Equal(models.FanOutStatusInProgress))
+			Expect(fanOut.Enqueued).To(Equal(2))
+
+			fanOut, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.Status).To(Equal(models.FanOutStatusInProgress))
+			Expect(fanOut.Enqueued).To(Equal(2))
+		})
+
+		It("returns a conflict error when the progress has been recorded by someone else", func() {
+			_, err := repo.Advance(conn, fanOut, 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Advance(conn, fanOut, 2)
+			Expect(err).To(MatchError(models.ConflictError{Err: errors.New(`Fan-out with ID "first-random-guid" has already progressed past 0`)}))
+
+			fanOut, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.Enqueued).To(Equal(2))
+		})
+	})
+
+	Describe("SetStatus", func() {
+		It("changes the status without touching the progress", func() {
+			fanOut, err := repo.Create(conn, models.FanOut{
+				ClientID: "some-client",
+				Status:   models.FanOutStatusQueued,
+				Total:    3,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Advance(conn, fanOut, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.SetStatus(conn, fanOut, models.FanOutStatusCompleted)
+			Expect(err).NotTo(HaveOccurred())
+
+			fanOut, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.Status).To(Equal(models.FanOutStatusCompleted))
+			Expect(fanOut.Enqueued).To(Equal(3))
+		})
+	})
+
+	Describe("DeleteBefore", func() {
+		It("deletes fan-outs that have not been updated since the given time", func() {
+			_, err := repo.Create(conn, models.FanOut{ClientID: "some-client", Status: models.FanOutStatusCompleted})
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(0))
+
+			itemsDeleted, err = repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+
+			_, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("keeps fan-outs that are still queued or in progress", func() {
+			guidGenerator.GenerateCall.Returns.IDs = []string{
+				"first-random-guid",
+				"second-random-guid",
+				"third-random-guid",
+			}
+
+			for _, status := range []string{models.FanOutStatusQueued, models.FanOutStatusInProgress, models.FanOutStatusFailed} {
+				_, err := repo.Create(conn, models.FanOut{ClientID: "some-client", Status: status})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+
+			_, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.FindByID(conn, "second-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.FindByID(conn, "third-random-guid")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("releases the attachments of the deleted fan-outs", func() {
+			_, err := repo.Create(conn, models.FanOut{ClientID: "some-client", Status: models.FanOutStatusCompleted})
+			Expect(err).NotTo(HaveOccurred())
+
+			attachmentsRepo := models.NewAttachmentsRepo(func() (string, error) { return "some-attachment-id", nil })
+			_, err = attachmentsRepo.Create(conn, models.Attachment{Content: []byte("banana")})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = attachmentsRepo.Reference(conn, "some-attachment-id", "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err := attachmentsRepo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+		})
+	})
+})
@@ -9,6 +9,8 @@ import (
 type Message struct {
 	ID         string    `db:"id"`
 	Status     string    `db:"status"`
+	ClientID   string    `db:"client_id"`
+	KindID     string    `db:"kind_id"`
 	UpdatedAt  time.Time `db:"updated_at"`
 }
 
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+type MessageRecipient struct {
+	Primary   int       `db:"primary"`
+	MessageID string    `db:"message_id"`
+	Address   string    `db:"address"`
+	Type      string    `db:"type"`
+	Status    string    `db:"status"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r *MessageRecipient) PreInsert(s gorp.SqlExecutor) error {
+	r.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
+
+func (r *MessageRecipient) PreUpdate(s gorp.SqlExecutor) error {
+	r.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+type MessageRecipientsRepo struct{}
+
+func NewMessageRecipientsRepo() MessageRecipientsRepo {
+	return MessageRecipientsRepo{}
+}
+
+func (repo MessageRecipientsRepo) Create(conn ConnectionInterface, recipient MessageRecipient) (MessageRecipient, error) {
+	err := conn.Insert(&recipient)
+	if err != nil {
+		return MessageRecipient{}, err
+	}
+
+	return recipient, nil
+}
+
+func (repo MessageRecipientsRepo) FindByMessageID(conn ConnectionInterface, messageID string) ([]MessageRecipient, error) {
+	recipients := []MessageRecipient{}
+	_, err := conn.Select(&recipients, "SELECT * FROM `message_recipients` WHERE `message_id` = ? ORDER BY `primary`", messageID)
+	if err != nil {
+		return []MessageRecipient{}, err
+	}
+
+	return recipients, nil
+}
+
+// FindByMessageIDs returns the recipients of all of the given messages at once.
+func (repo MessageRecipientsRepo) FindByMessageIDs(conn ConnectionInterface, messageIDs []string) ([]MessageRecipient, error) {
+	recipients := []MessageRecipient{}
+	if len(messageIDs) == 0 {
+		return recipients, nil
+	}
+
+	var args []interface{}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+
+	_, err := conn.Select(&recipients, "SELECT * FROM `message_recipients` WHERE `message_id` IN ("+placeholders(len(args))+") ORDER BY `primary`", args...)
+	if err != nil {
+		return []MessageRecipient{}, err
+	}
+
+	return recipients, nil
+}
+
+func (repo MessageRecipientsRepo) UpdateStatus(conn ConnectionInterface, messageID, address, status string) error {
+	_, err := conn.Exec("UPDATE `message_recipients` SET `status` = ?, `updated_at` = ? WHERE `message_id` = ? AND `address` = ?",
+		status, time.Now().Truncate(1*time.Second).UTC(), messageID, address)
+
+	return err
+}
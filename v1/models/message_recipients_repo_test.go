@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageRecipientsRepo", func() {
+	var (
+		repo models.MessageRecipientsRepo
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		repo = models.NewMessageRecipientsRepo()
+	})
+
+	Describe("Create", func() {
+		It("stores the recipients of a message in the order they were created", func() {
+			_, err := repo.Create(conn, models.MessageRecipient{MessageID: "message-id", Address: "to@example.com", Type: "to", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.MessageRecipient{MessageID: "message-id", Address: "bcc@example.com", Type: "bcc", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.MessageRecipient{MessageID: "other-message-id", Address: "to@example.com", Type: "to", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			recipients, err := repo.FindByMessageID(conn, "message-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients).To(HaveLen(2))
+			Expect(recipients[0].Address).To(Equal("to@example.com"))
+			Expect(recipients[0].Type).To(Equal("to"))
+			Expect(recipients[1].Address).To(Equal("bcc@example.com"))
+			Expect(recipients[1].UpdatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+	})
+
+	Describe("FindByMessageID", func() {
+		It("returns an empty list when the message has no recorded recipients", func() {
+			recipients, err := repo.FindByMessageID(conn, "missing-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients).To(BeEmpty())
+		})
+	})
+
+	Describe("FindByMessageIDs", func() {
+		It("returns the recipients of all of the messages", func() {
+			_, err := repo.Create(conn, models.MessageRecipient{MessageID: "message-id", Address: "to@example.com", Type: "to", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.MessageRecipient{MessageID: "other-message-id", Address: "cc@example.com", Type: "cc", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.MessageRecipient{MessageID: "unrelated-message-id", Address: "bcc@example.com", Type: "bcc", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			recipients, err := repo.FindByMessageIDs(conn, []string{"message-id", "other-message-id"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients).To(HaveLen(2))
+			Expect(recipients[0].MessageID).To(Equal("message-id"))
+			Expect(recipients[1].MessageID).To(Equal("other-message-id"))
+		})
+
+		It("returns an empty list when no messages are given", func() {
+			recipients, err := repo.FindByMessageIDs(conn, []string{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients).To(BeEmpty())
+		})
+	})
+
+	Describe("UpdateStatus", func() {
+		It("updates the status of a single recipient", func() {
+			_, err := repo.Create(conn, models.MessageRecipient{MessageID: "message-id", Address: "to@example.com", Type: "to", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.MessageRecipient{MessageID: "message-id", Address: "cc@example.com", Type: "cc", Status: "queued"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.UpdateStatus(conn, "message-id", "cc@example.com", "failed")
+			Expect(err).NotTo(HaveOccurred())
+
+			recipients, err := repo.FindByMessageID(conn, "message-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients[0].Status).To(Equal("queued"))
+			Expect(recipients[1].Status).To(Equal("failed"))
+		})
+	})
+})
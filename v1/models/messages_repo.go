@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -55,21 +56,79 @@ func (repo MessagesRepo) Update(conn ConnectionInterface, message Message) (Mess
 	return repo.FindByID(conn, message.ID)
 }
 
+// Upsert creates the message, or only updates the status of the message
+// when it exists already, so that the client and kind it was sent for are
+// kept.
 func (repo MessagesRepo) Upsert(conn ConnectionInterface, message Message) (Message, error) {
-	_, err := repo.FindByID(conn, message.ID)
+	existing, err := repo.FindByID(conn, message.ID)
 
 	switch err.(type) {
 	case NotFoundError:
 		return repo.Create(conn, message)
 	case nil:
-		return repo.Update(conn, message)
+		existing.Status = message.Status
+		return repo.Update(conn, existing)
 	default:
 		return message, err
 	}
 }
 
-func (repo MessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time) (int, error) {
-	result, err := conn.Exec("DELETE FROM `messages` WHERE `updated_at` < ?", threshold.UTC())
+// FindExpired returns up to limit messages that have outlived the retention
+// policy, oldest first.
+func (repo MessagesRepo) FindExpired(conn ConnectionInterface, policy RetentionPolicy, now time.Time, limit int) ([]Message, error) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+
+	statuses := policy.statuses()
+	for _, status := range statuses {
+		clauses = append(clauses, "(`status` = ? AND `updated_at` < ?)")
+		args = append(args, status, now.Add(-policy.ByStatus[status]).UTC())
+	}
+
+	if len(statuses) > 0 {
+		clauses = append(clauses, "(`status` NOT IN ("+placeholders(len(statuses))+") AND `updated_at` < ?)")
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	} else {
+		clauses = append(clauses, "`updated_at` < ?")
+	}
+	args = append(args, now.Add(-policy.Default).UTC(), limit)
+
+	messages := []Message{}
+	_, err := conn.Select(&messages, "SELECT * FROM `messages` WHERE "+strings.Join(clauses, " OR ")+" ORDER BY `updated_at` LIMIT ?", args...)
+	if err != nil {
+		return []Message{}, err
+	}
+
+	return messages, nil
+}
+
+// Delete removes the messages with the given IDs along with their recipients
+// and their references to attachments.
+func (repo MessagesRepo) Delete(conn ConnectionInterface, messageIDs []string) (int, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+
+	var args []interface{}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+
+	_, err := conn.Exec("DELETE FROM `message_recipients` WHERE `message_id` IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = conn.Exec("DELETE FROM `attachment_references` WHERE `owner_id` IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := conn.Exec("DELETE FROM `messages` WHERE `id` IN ("+placeholders(len(args))+")", args...)
 	if err != nil {
 		return 0, err
 	}
@@ -79,3 +138,7 @@ func (repo MessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.T
 	}
 	return int(count), nil
 }
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
@@ -109,33 +109,107 @@ var _ = Describe("MessagesRepo", func() {
 				Expect(messageFound.ID).To(Equal(message.ID))
 				Expect(messageFound.Status).To(Equal(message.Status))
 			})
+
+			It("keeps the client and kind the message was sent for", func() {
+				message.ClientID = "some-client"
+				message.KindID = "some-kind"
+				message, err := repo.Create(conn, message)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.Upsert(conn, models.Message{ID: message.ID, Status: common.StatusDelivered})
+				Expect(err).NotTo(HaveOccurred())
+
+				messageFound, err := repo.FindByID(conn, message.ID)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(messageFound.Status).To(Equal(common.StatusDelivered))
+				Expect(messageFound.ClientID).To(Equal("some-client"))
+				Expect(messageFound.KindID).To(Equal("some-kind"))
+			})
 		})
 	})
 
-	Describe("DeleteBefore", func() {
-		It("Deletes messages older than the input time", func() {
-			message, err := repo.Create(conn, message)
+	Describe("FindExpired", func() {
+		var policy models.RetentionPolicy
+
+		BeforeEach(func() {
+			guidGenerator.GenerateCall.Returns.IDs = []string{
+				"delivered-guid",
+				"failed-guid",
+			}
+
+			_, err := repo.Create(conn, models.Message{Status: common.StatusDelivered})
 			Expect(err).NotTo(HaveOccurred())
 
-			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour))
-			Expect(err).ToNot(HaveOccurred())
-			Expect(itemsDeleted).To(Equal(1))
+			_, err = repo.Create(conn, models.Message{Status: common.StatusFailed})
+			Expect(err).NotTo(HaveOccurred())
 
-			_, err = repo.FindByID(conn, message.ID)
-			Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Message with ID %q could not be found", message.ID)}))
+			policy = models.RetentionPolicy{
+				Default: 1 * time.Hour,
+				ByStatus: map[string]time.Duration{
+					common.StatusFailed: 48 * time.Hour,
+				},
+			}
+		})
 
+		It("finds messages that have outlived the lifetime of their status", func() {
+			messages, err := repo.FindExpired(conn, policy, time.Now().Add(2*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].ID).To(Equal("delivered-guid"))
+
+			messages, err = repo.FindExpired(conn, policy, time.Now().Add(72*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
 		})
 
-		It("Does not delete messages younger than the input time", func() {
-			message, err := repo.Create(conn, message)
+		It("uses the default lifetime when no status has its own", func() {
+			messages, err := repo.FindExpired(conn, models.RetentionPolicy{Default: 1 * time.Hour}, time.Now().Add(2*time.Hour), 10)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
+		})
 
-			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour))
-			Expect(err).ToNot(HaveOccurred())
-			Expect(itemsDeleted).To(Equal(0))
+		It("does not find messages younger than their lifetime", func() {
+			messages, err := repo.FindExpired(conn, policy, time.Now(), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(BeEmpty())
+		})
 
-			_, err = repo.FindByID(conn, message.ID)
-			Expect(err).ToNot(HaveOccurred())
+		It("finds no more than the limit", func() {
+			messages, err := repo.FindExpired(conn, policy, time.Now().Add(72*time.Hour), 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the messages with the given IDs", func() {
+			guidGenerator.GenerateCall.Returns.IDs = []string{
+				"first-random-guid",
+				"second-random-guid",
+			}
+
+			first, err := repo.Create(conn, message)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := repo.Create(conn, message)
+			Expect(err).NotTo(HaveOccurred())
+
+			itemsDeleted, err := repo.Delete(conn, []string{first.ID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(Equal(1))
+
+			_, err = repo.FindByID(conn, first.ID)
+			Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Message with ID %q could not be found", first.ID)}))
+
+			_, err = repo.FindByID(conn, second.ID)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does nothing without IDs", func() {
+			itemsDeleted, err := repo.Delete(conn, []string{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(itemsDeleted).To(BeZero())
 		})
 	})
 })
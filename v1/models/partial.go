@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+type Partial struct {
+	Primary   int       `db:"primary"`
+	Name      string    `db:"name"`
+	Content   string    `db:"content"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (p *Partial) PreInsert(s gorp.SqlExecutor) error {
+	now := time.Now().Truncate(1 * time.Second).UTC()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	return nil
+}
+
+func (p *Partial) PreUpdate(s gorp.SqlExecutor) error {
+	p.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type PartialsRepo struct{}
+
+func NewPartialsRepo() PartialsRepo {
+	return PartialsRepo{}
+}
+
+func (repo PartialsRepo) FindByName(conn ConnectionInterface, name string) (Partial, error) {
+	partial := Partial{}
+	err := conn.SelectOne(&partial, "SELECT * FROM `partials` WHERE `name` = ?", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return partial, NotFoundError{fmt.Errorf("Partial with name %q could not be found", name)}
+		}
+		return partial, err
+	}
+
+	return partial, nil
+}
+
+func (repo PartialsRepo) FindAll(conn ConnectionInterface) ([]Partial, error) {
+	partials := []Partial{}
+	_, err := conn.Select(&partials, "SELECT * FROM `partials` ORDER BY `name`")
+	if err != nil {
+		return []Partial{}, err
+	}
+
+	return partials, nil
+}
+
+// FindByNames returns the partials with the given names that exist, ordered
+// by name.
+func (repo PartialsRepo) FindByNames(conn ConnectionInterface, names []string) ([]Partial, error) {
+	partials := []Partial{}
+	if len(names) == 0 {
+		return partials, nil
+	}
+
+	var args []interface{}
+	for _, name := range names {
+		args = append(args, name)
+	}
+
+	_, err := conn.Select(&partials, "SELECT * FROM `partials` WHERE `name` IN ("+placeholders(len(args))+") ORDER BY `name`", args...)
+	if err != nil {
+		return []Partial{}, err
+	}
+
+	return partials, nil
+}
+
+func (repo PartialsRepo) Upsert(conn ConnectionInterface, partial Partial) (Partial, error) {
+	existingPartial, err := repo.FindByName(conn, partial.Name)
+	switch err.(type) {
+	case NotFoundError:
+		err = conn.Insert(&partial)
+		if err != nil {
+			return Partial{}, err
+		}
+
+		return partial, nil
+	case nil:
+		existingPartial.Content = partial.Content
+
+		_, err = conn.Update(&existingPartial)
+		if err != nil {
+			return Partial{}, err
+		}
+
+		return existingPartial, nil
+	default:
+		return Partial{}, err
+	}
+}
+
+func (repo PartialsRepo) Destroy(conn ConnectionInterface, name string) error {
+	partial, err := repo.FindByName(conn, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Delete(&partial)
+
+	return err
+}
@@ -0,0 +1,126 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartialsRepo", func() {
+	var (
+		repo models.PartialsRepo
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		repo = models.NewPartialsRepo()
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+	})
+
+	Describe("Upsert", func() {
+		It("inserts a new partial", func() {
+			partial, err := repo.Upsert(conn, models.Partial{
+				Name:    "footer",
+				Content: "<p>the footer</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial.Primary).NotTo(BeZero())
+
+			foundPartial, err := repo.FindByName(conn, "footer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(foundPartial.Content).To(Equal("<p>the footer</p>"))
+		})
+
+		It("updates the content of an existing partial", func() {
+			_, err := repo.Upsert(conn, models.Partial{
+				Name:    "footer",
+				Content: "<p>the footer</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Upsert(conn, models.Partial{
+				Name:    "footer",
+				Content: "<p>a new footer</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			partials, err := repo.FindAll(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(HaveLen(1))
+			Expect(partials[0].Content).To(Equal("<p>a new footer</p>"))
+		})
+	})
+
+	Describe("FindAll", func() {
+		It("returns the partials ordered by name", func() {
+			_, err := repo.Upsert(conn, models.Partial{Name: "header", Content: "header"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Upsert(conn, models.Partial{Name: "footer", Content: "footer"})
+			Expect(err).NotTo(HaveOccurred())
+
+			partials, err := repo.FindAll(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(HaveLen(2))
+			Expect(partials[0].Name).To(Equal("footer"))
+			Expect(partials[1].Name).To(Equal("header"))
+		})
+	})
+
+	Describe("FindByNames", func() {
+		It("returns the partials with the given names that exist, ordered by name", func() {
+			_, err := repo.Upsert(conn, models.Partial{Name: "header", Content: "header"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Upsert(conn, models.Partial{Name: "footer", Content: "footer"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Upsert(conn, models.Partial{Name: "sidebar", Content: "sidebar"})
+			Expect(err).NotTo(HaveOccurred())
+
+			partials, err := repo.FindByNames(conn, []string{"sidebar", "footer", "missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(HaveLen(2))
+			Expect(partials[0].Name).To(Equal("footer"))
+			Expect(partials[1].Name).To(Equal("sidebar"))
+		})
+
+		It("returns an empty list without any names", func() {
+			partials, err := repo.FindByNames(conn, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(BeEmpty())
+		})
+	})
+
+	Describe("FindByName", func() {
+		It("returns a not found error when the partial does not exist", func() {
+			_, err := repo.FindByName(conn, "missing")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New(`Partial with name "missing" could not be found`)}))
+		})
+	})
+
+	Describe("Destroy", func() {
+		It("deletes the partial", func() {
+			_, err := repo.Upsert(conn, models.Partial{Name: "footer", Content: "footer"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Destroy(conn, "footer")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.FindByName(conn, "footer")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("returns a not found error when the partial does not exist", func() {
+			err := repo.Destroy(conn, "missing")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
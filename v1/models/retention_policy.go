@@ -0,0 +1,24 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// RetentionPolicy decides how long a message is kept after it was last
+// updated. Statuses listed in ByStatus are kept for their own lifetime, all
+// others for the Default lifetime.
+type RetentionPolicy struct {
+	Default  time.Duration
+	ByStatus map[string]time.Duration
+}
+
+func (policy RetentionPolicy) statuses() []string {
+	var statuses []string
+	for status := range policy.ByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	return statuses
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	SuppressionTypeAddress = "address"
+	SuppressionTypeDomain  = "domain"
+	SuppressionTypeUser    = "user"
+)
+
+// Suppression stops email from being sent to an address, to every address
+// of a domain or to a user. Unless it is hard, a suppression does not apply
+// to critical notifications. Suppressions without an expiry never expire.
+type Suppression struct {
+	Primary   int        `db:"primary"`
+	ID        string     `db:"id"`
+	Type      string     `db:"type"`
+	Value     string     `db:"value"`
+	Reason    string     `db:"reason"`
+	MessageID string     `db:"message_id"`
+	Hard      bool       `db:"hard"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
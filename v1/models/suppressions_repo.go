@@ -0,0 +1,188 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SuppressionsRepo struct {
+	generateID IDGeneratorFunc
+}
+
+func NewSuppressionsRepo(guidGenerator IDGeneratorFunc) SuppressionsRepo {
+	return SuppressionsRepo{
+		generateID: guidGenerator,
+	}
+}
+
+func (repo SuppressionsRepo) Create(conn ConnectionInterface, suppression Suppression) (Suppression, error) {
+	if suppression.ID == "" {
+		var err error
+		suppression.ID, err = repo.generateID()
+		if err != nil {
+			return Suppression{}, err
+		}
+	}
+
+	suppression.Value = normalizeSuppressionValue(suppression.Type, suppression.Value)
+	suppression.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	err := conn.Insert(&suppression)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			err = DuplicateError{fmt.Errorf("%s %q is already suppressed", suppression.Type, suppression.Value)}
+		}
+		return Suppression{}, err
+	}
+
+	return suppression, nil
+}
+
+// Add suppresses the given value. Adding a value that is already suppressed
+// replaces the reason and message it was suppressed for and its expiry, so
+// that a suppression that has expired applies again. A suppression that was
+// hard stays hard.
+func (repo SuppressionsRepo) Add(conn ConnectionInterface, suppression Suppression) error {
+	existing, err := repo.find(conn, suppression.Type, normalizeSuppressionValue(suppression.Type, suppression.Value))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		_, err = repo.Create(conn, suppression)
+		return err
+	}
+
+	existing.Reason = suppression.Reason
+	existing.MessageID = suppression.MessageID
+	existing.ExpiresAt = suppression.ExpiresAt
+	existing.Hard = existing.Hard || suppression.Hard
+	_, err = conn.Update(&existing)
+
+	return err
+}
+
+func (repo SuppressionsRepo) FindByID(conn ConnectionInterface, id string) (Suppression, error) {
+	suppression := Suppression{}
+	err := conn.SelectOne(&suppression, "SELECT * FROM `suppressions` WHERE `id` = ?", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Suppression{}, NotFoundError{fmt.Errorf("Suppression with ID %q could not be found", id)}
+		}
+		return Suppression{}, err
+	}
+
+	return suppression, nil
+}
+
+func (repo SuppressionsRepo) FindAll(conn ConnectionInterface) ([]Suppression, error) {
+	suppressions := []Suppression{}
+	_, err := conn.Select(&suppressions, "SELECT * FROM `suppressions` ORDER BY `primary`")
+	if err != nil {
+		return []Suppression{}, err
+	}
+
+	return suppressions, nil
+}
+
+func (repo SuppressionsRepo) Update(conn ConnectionInterface, suppression Suppression) (Suppression, error) {
+	suppression.Value = normalizeSuppressionValue(suppression.Type, suppression.Value)
+
+	_, err := conn.Update(&suppression)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			err = DuplicateError{fmt.Errorf("%s %q is already suppressed", suppression.Type, suppression.Value)}
+		}
+		return Suppression{}, err
+	}
+
+	return repo.FindByID(conn, suppression.ID)
+}
+
+func (repo SuppressionsRepo) Destroy(conn ConnectionInterface, id string) error {
+	suppression, err := repo.FindByID(conn, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Delete(&suppression)
+
+	return err
+}
+
+// Match returns the suppressions that have not expired and apply to email
+// sent to each of the given addresses for the given user, either through the
+// address itself, its domain or the user. The suppressions are looked up in
+// a single query and keyed by the address they apply to, as it was given.
+func (repo SuppressionsRepo) Match(conn ConnectionInterface, addresses []string, userGUID string) (map[string][]Suppression, error) {
+	matched := map[string][]Suppression{}
+	if len(addresses) == 0 {
+		return matched, nil
+	}
+
+	var addressArgs, domainArgs []interface{}
+	for _, address := range addresses {
+		address = strings.ToLower(address)
+		addressArgs = append(addressArgs, address)
+		domainArgs = append(domainArgs, suppressionDomain(address))
+	}
+
+	args := append(append([]interface{}{SuppressionTypeAddress}, addressArgs...), SuppressionTypeDomain)
+	args = append(append(args, domainArgs...), SuppressionTypeUser, userGUID, time.Now().UTC())
+
+	suppressions := []Suppression{}
+	_, err := conn.Select(&suppressions, "SELECT * FROM `suppressions` WHERE "+
+		"((`type` = ? AND `value` IN ("+placeholders(len(addressArgs))+")) OR "+
+		"(`type` = ? AND `value` IN ("+placeholders(len(domainArgs))+")) OR "+
+		"(`type` = ? AND `value` = ?)) "+
+		"AND (`expires_at` IS NULL OR `expires_at` > ?) ORDER BY `primary`", args...)
+	if err != nil {
+		return map[string][]Suppression{}, err
+	}
+
+	for _, address := range addresses {
+		normalized := strings.ToLower(address)
+		for _, suppression := range suppressions {
+			switch {
+			case suppression.Type == SuppressionTypeAddress && suppression.Value == normalized,
+				suppression.Type == SuppressionTypeDomain && suppression.Value == suppressionDomain(normalized),
+				suppression.Type == SuppressionTypeUser:
+				matched[address] = append(matched[address], suppression)
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// suppressionDomain returns the domain of an address, or nothing when the
+// address has none.
+func suppressionDomain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+
+	return ""
+}
+
+func (repo SuppressionsRepo) find(conn ConnectionInterface, suppressionType, value string) (Suppression, error) {
+	suppression := Suppression{}
+	err := conn.SelectOne(&suppression, "SELECT * FROM `suppressions` WHERE `type` = ? AND `value` = ?", suppressionType, value)
+	if err != nil {
+		return Suppression{}, err
+	}
+
+	return suppression, nil
+}
+
+// normalizeSuppressionValue lower-cases addresses and domains, which are
+// matched regardless of case. User GUIDs are kept as they are.
+func normalizeSuppressionValue(suppressionType, value string) string {
+	if suppressionType == SuppressionTypeUser {
+		return value
+	}
+
+	return strings.ToLower(value)
+}
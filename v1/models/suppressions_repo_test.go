@@ -0,0 +1,203 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SuppressionsRepo", func() {
+	var (
+		repo          models.SuppressionsRepo
+		conn          db.ConnectionInterface
+		guidGenerator *mocks.IDGenerator
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{
+			"first-random-guid",
+			"second-random-guid",
+			"third-random-guid",
+			"fourth-random-guid",
+		}
+
+		repo = models.NewSuppressionsRepo(guidGenerator.Generate)
+	})
+
+	Describe("Create", func() {
+		It("stores the suppression with a generated ID", func() {
+			suppression, err := repo.Create(conn, models.Suppression{
+				Type:   models.SuppressionTypeAddress,
+				Value:  "Someone@Example.com",
+				Reason: "legal hold",
+				Hard:   true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppression.ID).To(Equal("first-random-guid"))
+
+			suppression, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppression.Value).To(Equal("someone@example.com"))
+			Expect(suppression.Reason).To(Equal("legal hold"))
+			Expect(suppression.Hard).To(BeTrue())
+			Expect(suppression.ExpiresAt).To(BeNil())
+			Expect(suppression.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("returns a duplicate error when the value is already suppressed", func() {
+			_, err := repo.Create(conn, models.Suppression{Type: models.SuppressionTypeDomain, Value: "example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.Suppression{Type: models.SuppressionTypeDomain, Value: "EXAMPLE.com"})
+			Expect(err).To(BeAssignableToTypeOf(models.DuplicateError{}))
+		})
+	})
+
+	Describe("Add", func() {
+		It("replaces the reason when the address is already suppressed", func() {
+			err := repo.Add(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "bounced@example.com", Reason: "bounce: 5.1.1", MessageID: "message-id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Add(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "Bounced@Example.com", Reason: "complaint: abuse", MessageID: "other-message-id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			suppressions, err := repo.FindAll(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions).To(HaveLen(1))
+			Expect(suppressions[0].Reason).To(Equal("complaint: abuse"))
+			Expect(suppressions[0].MessageID).To(Equal("other-message-id"))
+		})
+
+		It("suppresses an address again whose suppression has expired", func() {
+			expired := time.Now().Add(-1 * time.Hour).Truncate(time.Second).UTC()
+			_, err := repo.Create(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "bounced@example.com", ExpiresAt: &expired})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Add(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "bounced@example.com", Reason: "bounce: 5.1.1"})
+			Expect(err).NotTo(HaveOccurred())
+
+			suppressions, err := repo.Match(conn, []string{"bounced@example.com"}, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions["bounced@example.com"]).To(HaveLen(1))
+			Expect(suppressions["bounced@example.com"][0].ExpiresAt).To(BeNil())
+		})
+
+		It("sets the hard flag without clearing one that was set before", func() {
+			_, err := repo.Create(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "hard@example.com", Hard: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Add(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "hard@example.com", Reason: "bounce: 5.1.1"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Add(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "soft@example.com", Reason: "bounce: 5.1.1"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Add(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "soft@example.com", Reason: "bounce: 5.1.1", Hard: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			suppressions, err := repo.FindAll(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions).To(HaveLen(2))
+			Expect(suppressions[0].Hard).To(BeTrue())
+			Expect(suppressions[1].Hard).To(BeTrue())
+		})
+	})
+
+	Describe("Update", func() {
+		It("updates the suppression", func() {
+			suppression, err := repo.Create(conn, models.Suppression{Type: models.SuppressionTypeUser, Value: "user-123"})
+			Expect(err).NotTo(HaveOccurred())
+
+			expiry := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+			suppression.Reason = "compliance request"
+			suppression.ExpiresAt = &expiry
+
+			suppression, err = repo.Update(conn, suppression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppression.Reason).To(Equal("compliance request"))
+			Expect(*suppression.ExpiresAt).To(BeTemporally("==", expiry))
+		})
+	})
+
+	Describe("Destroy", func() {
+		It("deletes the suppression", func() {
+			_, err := repo.Create(conn, models.Suppression{Type: models.SuppressionTypeUser, Value: "user-123"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Destroy(conn, "first-random-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.FindByID(conn, "first-random-guid")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("returns a not found error when the suppression does not exist", func() {
+			err := repo.Destroy(conn, "missing-id")
+			Expect(err).To(MatchError(`Suppression with ID "missing-id" could not be found`))
+		})
+	})
+
+	Describe("Match", func() {
+		BeforeEach(func() {
+			expired := time.Now().Add(-1 * time.Hour).UTC()
+
+			_, err := repo.Create(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "someone@example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.Suppression{Type: models.SuppressionTypeDomain, Value: "blocked.example.com", Hard: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.Suppression{Type: models.SuppressionTypeUser, Value: "user-123"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.Suppression{Type: models.SuppressionTypeAddress, Value: "expired@example.com", ExpiresAt: &expired})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("matches suppressed addresses regardless of their case", func() {
+			suppressions, err := repo.Match(conn, []string{"SomeOne@example.com"}, "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions["SomeOne@example.com"]).To(HaveLen(1))
+			Expect(suppressions["SomeOne@example.com"][0].ID).To(Equal("first-random-guid"))
+		})
+
+		It("matches every address of a suppressed domain", func() {
+			suppressions, err := repo.Match(conn, []string{"anyone@blocked.example.com"}, "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions["anyone@blocked.example.com"]).To(HaveLen(1))
+			Expect(suppressions["anyone@blocked.example.com"][0].Hard).To(BeTrue())
+		})
+
+		It("matches suppressed users", func() {
+			suppressions, err := repo.Match(conn, []string{"other@example.com"}, "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions["other@example.com"]).To(HaveLen(1))
+			Expect(suppressions["other@example.com"][0].Value).To(Equal("user-123"))
+		})
+
+		It("ignores expired suppressions", func() {
+			suppressions, err := repo.Match(conn, []string{"expired@example.com"}, "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions).To(BeEmpty())
+		})
+
+		It("keys the suppressions of several addresses by the address they apply to", func() {
+			suppressions, err := repo.Match(conn, []string{"someone@example.com", "anyone@blocked.example.com", "other@example.com"}, "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suppressions).To(HaveLen(2))
+			Expect(suppressions["someone@example.com"][0].Type).To(Equal(models.SuppressionTypeAddress))
+			Expect(suppressions["anyone@blocked.example.com"][0].Type).To(Equal(models.SuppressionTypeDomain))
+		})
+	})
+})
@@ -24,6 +24,8 @@ type Template struct {
 	CreatedAt  time.Time `db:"created_at"`
 	UpdatedAt  time.Time `db:"updated_at"`
 	Overridden bool      `db:"overridden"`
+	Layout     string    `db:"layout"`
+	Markdown   string    `db:"markdown"`
 }
 
 func (t *Template) PreInsert(s gorp.SqlExecutor) error {
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	AuditActionUpdateDefaultTemplate      = "default_template.update"
+	AuditActionCreateTemplate             = "template.create"
+	AuditActionUpdateTemplate             = "template.update"
+	AuditActionAssignClientTemplate       = "client.template.assign"
+	AuditActionAssignNotificationTemplate = "notification.template.assign"
+	AuditActionUpdateNotification         = "notification.update"
+	AuditActionUpdateUserPreferences      = "user_preferences.update"
+)
+
+// AuditEntry describes an administrative change made with the given token.
+// Before and After are recorded as JSON.
+type AuditEntry struct {
+	Token  *jwt.Token
+	Action string
+	Target string
+	Before interface{}
+	After  interface{}
+}
+
+type AuditLog struct {
+	auditEventsRepo AuditEventsRepo
+}
+
+func NewAuditLog(auditEventsRepo AuditEventsRepo) AuditLog {
+	return AuditLog{
+		auditEventsRepo: auditEventsRepo,
+	}
+}
+
+// Record appends an audit event for the entry. The actor is the user the
+// token was issued for or, when it was issued to a client on its own
+// behalf, the client. The event should be recorded in the transaction that
+// makes the change, so that a change is never kept without its event.
+func (log AuditLog) Record(conn ConnectionInterface, entry AuditEntry) error {
+	before, err := json.Marshal(entry.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := json.Marshal(entry.After)
+	if err != nil {
+		return err
+	}
+
+	clientID, _ := entry.Token.Claims["client_id"].(string)
+	event := models.AuditEvent{
+		ActorType: models.AuditActorTypeClient,
+		ActorID:   clientID,
+		ClientID:  clientID,
+		Action:    entry.Action,
+		Target:    entry.Target,
+		Before:    string(before),
+		After:     string(after),
+	}
+
+	if userID, ok := entry.Token.Claims["user_id"].(string); ok && userID != "" {
+		event.ActorType = models.AuditActorTypeUser
+		event.ActorID = userID
+	}
+
+	_, err = log.auditEventsRepo.Create(conn, event)
+
+	return err
+}
+
+func (log AuditLog) List(database DatabaseInterface, filter models.AuditEventFilter) ([]models.AuditEvent, int, error) {
+	return log.auditEventsRepo.List(database.Connection(), filter)
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/dgrijalva/jwt-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditLog", func() {
+	var (
+		auditLog        services.AuditLog
+		auditEventsRepo *mocks.AuditEventsRepo
+		database        *mocks.Database
+		conn            *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		auditEventsRepo = mocks.NewAuditEventsRepo()
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		auditLog = services.NewAuditLog(auditEventsRepo)
+	})
+
+	Describe("Record", func() {
+		It("records the client as the actor of a client token", func() {
+			err := auditLog.Record(conn, services.AuditEntry{
+				Token:  &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}},
+				Action: services.AuditActionAssignClientTemplate,
+				Target: "clients/some-client",
+				Before: map[string]string{"template": "default"},
+				After:  map[string]string{"template": "new-template"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(auditEventsRepo.CreateCall.Receives.Connection).To(Equal(conn))
+			Expect(auditEventsRepo.CreateCall.Receives.Event).To(Equal(models.AuditEvent{
+				ActorType: "client",
+				ActorID:   "admin-client",
+				ClientID:  "admin-client",
+				Action:    "client.template.assign",
+				Target:    "clients/some-client",
+				Before:    `{"template":"default"}`,
+				After:     `{"template":"new-template"}`,
+			}))
+		})
+
+		It("records the user as the actor of a user token", func() {
+			err := auditLog.Record(conn, services.AuditEntry{
+				Token: &jwt.Token{Claims: map[string]interface{}{
+					"client_id": "admin-client",
+					"user_id":   "admin-user",
+				}},
+				Action: services.AuditActionUpdateUserPreferences,
+				Target: "user_preferences/some-user",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			event := auditEventsRepo.CreateCall.Receives.Event
+			Expect(event.ActorType).To(Equal("user"))
+			Expect(event.ActorID).To(Equal("admin-user"))
+			Expect(event.ClientID).To(Equal("admin-client"))
+			Expect(event.Before).To(Equal("null"))
+			Expect(event.After).To(Equal("null"))
+		})
+
+		It("returns errors from the repo", func() {
+			auditEventsRepo.CreateCall.Returns.Error = errors.New("database is down")
+
+			err := auditLog.Record(conn, services.AuditEntry{
+				Token: &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}},
+			})
+			Expect(err).To(MatchError(errors.New("database is down")))
+		})
+	})
+
+	Describe("List", func() {
+		It("lists the audit events matching the filter", func() {
+			auditEventsRepo.ListCall.Returns.Events = []models.AuditEvent{{ID: "some-event"}}
+			auditEventsRepo.ListCall.Returns.Total = 12
+			filter := models.AuditEventFilter{Action: "notification.update", Limit: 1}
+
+			events, total, err := auditLog.List(database, filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(Equal([]models.AuditEvent{{ID: "some-event"}}))
+			Expect(total).To(Equal(12))
+
+			Expect(auditEventsRepo.ListCall.Receives.Connection).To(Equal(conn))
+			Expect(auditEventsRepo.ListCall.Receives.Filter).To(Equal(filter))
+		})
+	})
+})
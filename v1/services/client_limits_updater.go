@@ -0,0 +1,43 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type ClientLimits struct {
+	RateLimit           int
+	RateLimitBurst      int
+	DailyRecipientQuota int
+}
+
+type ClientLimitsUpdater struct {
+	clientsRepo ClientsRepo
+}
+
+func NewClientLimitsUpdater(clientsRepo ClientsRepo) ClientLimitsUpdater {
+	return ClientLimitsUpdater{
+		clientsRepo: clientsRepo,
+	}
+}
+
+// Update sets the rate limit and quota of a client, registering the client
+// if it has not sent or registered anything yet.
+func (updater ClientLimitsUpdater) Update(database DatabaseInterface, clientID string, limits ClientLimits) error {
+	connection := database.Connection()
+
+	client, err := updater.clientsRepo.Find(connection, clientID)
+	switch err.(type) {
+	case nil:
+		_, err = updater.clientsRepo.Update(connection, limits.apply(client))
+	case models.NotFoundError:
+		_, err = updater.clientsRepo.Upsert(connection, limits.apply(models.Client{ID: clientID}))
+	}
+
+	return err
+}
+
+func (limits ClientLimits) apply(client models.Client) models.Client {
+	client.RateLimit = limits.RateLimit
+	client.RateLimitBurst = limits.RateLimitBurst
+	client.DailyRecipientQuota = limits.DailyRecipientQuota
+
+	return client
+}
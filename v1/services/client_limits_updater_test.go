@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientLimitsUpdater", func() {
+	var (
+		updater     services.ClientLimitsUpdater
+		clientsRepo *mocks.ClientsRepository
+		database    *mocks.Database
+		conn        *mocks.Connection
+		limits      services.ClientLimits
+	)
+
+	BeforeEach(func() {
+		clientsRepo = mocks.NewClientsRepository()
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		limits = services.ClientLimits{
+			RateLimit:           60,
+			RateLimitBurst:      5,
+			DailyRecipientQuota: 1000,
+		}
+
+		updater = services.NewClientLimitsUpdater(clientsRepo)
+	})
+
+	It("updates the limits of an existing client", func() {
+		clientsRepo.FindCall.Returns.Client = models.Client{
+			Primary:     4,
+			ID:          "my-client",
+			Description: "My Client",
+			TemplateID:  "my-template",
+		}
+
+		err := updater.Update(database, "my-client", limits)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(conn))
+		Expect(clientsRepo.FindCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(clientsRepo.UpdateCall.Receives.Connection).To(Equal(conn))
+		Expect(clientsRepo.UpdateCall.Receives.Client).To(Equal(models.Client{
+			Primary:             4,
+			ID:                  "my-client",
+			Description:         "My Client",
+			TemplateID:          "my-template",
+			RateLimit:           60,
+			RateLimitBurst:      5,
+			DailyRecipientQuota: 1000,
+		}))
+	})
+
+	It("registers a client that does not exist yet", func() {
+		clientsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		err := updater.Update(database, "my-client", limits)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clientsRepo.UpsertCall.Receives.Connection).To(Equal(conn))
+		Expect(clientsRepo.UpsertCall.Receives.Client).To(Equal(models.Client{
+			ID:                  "my-client",
+			RateLimit:           60,
+			RateLimitBurst:      5,
+			DailyRecipientQuota: 1000,
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns errors from finding the client", func() {
+			clientsRepo.FindCall.Returns.Error = errors.New("BOOM!")
+
+			err := updater.Update(database, "my-client", limits)
+			Expect(err).To(MatchError(errors.New("BOOM!")))
+		})
+
+		It("returns errors from updating the client", func() {
+			clientsRepo.FindCall.Returns.Client = models.Client{Primary: 4, ID: "my-client"}
+			clientsRepo.UpdateCall.Returns.Error = errors.New("BOOM!")
+
+			err := updater.Update(database, "my-client", limits)
+			Expect(err).To(MatchError(errors.New("BOOM!")))
+		})
+	})
+})
@@ -24,9 +24,25 @@ type HTML struct {
 	Doctype        string
 }
 
+// Attachment describes a file sent along with a message. The content is
+// stored separately when the message is enqueued and is never part of the
+// queued delivery, which only refers to it by ID.
+type Attachment struct {
+	ID          string
+	Filename    string
+	ContentType string
+	ContentID   string
+	Inline      bool
+	Content     []byte `json:"-"`
+}
+
 type DispatchVCAPRequest struct {
 	ID          string
 	ReceiptTime time.Time
+
+	// TraceParent identifies the span of the request, so that deliveries
+	// can be traced back to it.
+	TraceParent string
 }
 
 type DispatchMessage struct {
@@ -35,11 +51,32 @@ type DispatchMessage struct {
 	Subject string
 	Text    string
 	HTML    HTML
+	Data    map[string]interface{}
+
+	Attachments []Attachment
+	Recipients  []Recipient
+}
+
+const (
+	RecipientTo  = "to"
+	RecipientCC  = "cc"
+	RecipientBCC = "bcc"
+)
+
+// Recipient is one of the addresses an email is sent to. Type is one of
+// RecipientTo, RecipientCC or RecipientBCC.
+type Recipient struct {
+	Address string
+	Type    string
 }
 
 type DispatchClient struct {
 	ID          string
 	Description string
+
+	// DailyRecipientQuota limits how many recipients the client may send to
+	// per UTC day. Zero means there is no limit.
+	DailyRecipientQuota int
 }
 
 type DispatchKind struct {
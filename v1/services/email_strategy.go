@@ -20,9 +20,11 @@ type enqueuer interface {
 		space cf.CloudControllerSpace,
 		org cf.CloudControllerOrganization,
 		clientID string,
+		recipientQuota int,
 		uaaHost string,
 		scope string,
 		vcapRequestID string,
+		traceParent string,
 		reqReceived time.Time) ([]Response, error)
 }
 
@@ -43,6 +45,9 @@ func (strategy EmailStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		Endorsement:       EmailEndorsement,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		Data:              dispatch.Message.Data,
+		Attachments:       dispatch.Message.Attachments,
+		Recipients:        dispatch.Message.Recipients,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
 			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
@@ -60,8 +65,10 @@ func (strategy EmailStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		cf.CloudControllerSpace{},
 		cf.CloudControllerOrganization{},
 		dispatch.Client.ID,
+		dispatch.Client.DailyRecipientQuota,
 		dispatch.UAAHost,
 		"",
 		dispatch.VCAPRequest.ID,
+		dispatch.VCAPRequest.TraceParent,
 		dispatch.VCAPRequest.ReceiptTime)
 }
@@ -33,8 +33,9 @@ var _ = Describe("EmailStrategy", func() {
 				emailStrategy.Dispatch(services.Dispatch{
 					Connection: conn,
 					Client: services.DispatchClient{
-						ID:          "some-client-id",
-						Description: "description of a client",
+						ID:                  "some-client-id",
+						Description:         "description of a client",
+						DailyRecipientQuota: 500,
 					},
 					Kind: services.DispatchKind{
 						ID:          "some-kind-id",
@@ -46,6 +47,9 @@ var _ = Describe("EmailStrategy", func() {
 						Subject: "this is the subject",
 						To:      "dr@strangelove.com",
 						Text:    "email text",
+						Attachments: []services.Attachment{
+							{Filename: "invoice.csv", ContentType: "text/csv", Content: []byte("item,price")},
+						},
 						HTML: services.HTML{
 							BodyContent:    "some html body content",
 							BodyAttributes: "some html body attributes",
@@ -56,6 +60,7 @@ var _ = Describe("EmailStrategy", func() {
 					VCAPRequest: services.DispatchVCAPRequest{
 						ID:          "some-vcap-request-id",
 						ReceiptTime: requestReceived,
+						TraceParent: "some-trace-parent",
 					},
 					UAAHost: "uaahost",
 				})
@@ -71,6 +76,9 @@ var _ = Describe("EmailStrategy", func() {
 					SourceDescription: "description of a client",
 					Text:              "email text",
 					TemplateID:        "some-template-id",
+					Attachments: []services.Attachment{
+						{Filename: "invoice.csv", ContentType: "text/csv", Content: []byte("item,price")},
+					},
 					HTML: services.HTML{
 						BodyContent:    "some html body content",
 						BodyAttributes: "some html body attributes",
@@ -85,8 +93,10 @@ var _ = Describe("EmailStrategy", func() {
 				Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{}))
 				Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{}))
 				Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("some-client-id"))
+				Expect(enqueuer.EnqueueCall.Receives.RecipientQuota).To(Equal(500))
 				Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal(""))
 				Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+				Expect(enqueuer.EnqueueCall.Receives.TraceParent).To(Equal("some-trace-parent"))
 				Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
 				Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("uaahost"))
 			})
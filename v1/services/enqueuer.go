@@ -24,6 +24,9 @@ type Options struct {
 	Role              string
 	Endorsement       string
 	TemplateID        string
+	Data              map[string]interface{}
+	Attachments       []Attachment
+	Recipients        []Recipient
 }
 
 type Delivery struct {
@@ -38,12 +41,26 @@ type Delivery struct {
 	Scope           string
 	VCAPRequestID   string
 	RequestReceived time.Time
+	TraceParent     string
 }
 
 type messagesRepoUpserter interface {
 	Upsert(models.ConnectionInterface, models.Message) (models.Message, error)
 }
 
+type attachmentsRepoCreator interface {
+	Create(models.ConnectionInterface, models.Attachment) (models.Attachment, error)
+	Reference(conn models.ConnectionInterface, attachmentID, ownerID string) error
+}
+
+type messageRecipientsRepoCreator interface {
+	Create(models.ConnectionInterface, models.MessageRecipient) (models.MessageRecipient, error)
+}
+
+type recipientReserver interface {
+	Reserve(models.ConnectionInterface, string, time.Time, int, int) (bool, error)
+}
+
 type queueInterface interface {
 	Enqueue(job *gobble.Job, transaction gobble.ConnectionInterface) (*gobble.Job, error)
 }
@@ -55,13 +72,19 @@ type gobbleInitializer interface {
 type Enqueuer struct {
 	queue             queueInterface
 	messagesRepo      messagesRepoUpserter
+	attachmentsRepo   attachmentsRepoCreator
+	recipientsRepo    messageRecipientsRepoCreator
+	recipientCounts   recipientReserver
 	gobbleInitializer gobbleInitializer
 }
 
-func NewEnqueuer(queue queueInterface, messagesRepo messagesRepoUpserter, gobbleInitializer gobbleInitializer) Enqueuer {
+func NewEnqueuer(queue queueInterface, messagesRepo messagesRepoUpserter, attachmentsRepo attachmentsRepoCreator, recipientsRepo messageRecipientsRepoCreator, recipientCounts recipientReserver, gobbleInitializer gobbleInitializer) Enqueuer {
 	return Enqueuer{
 		queue:             queue,
 		messagesRepo:      messagesRepo,
+		attachmentsRepo:   attachmentsRepo,
+		recipientsRepo:    recipientsRepo,
+		recipientCounts:   recipientCounts,
 		gobbleInitializer: gobbleInitializer,
 	}
 }
@@ -72,27 +95,82 @@ func (enqueuer Enqueuer) Enqueue(
 	options Options,
 	space cf.CloudControllerSpace,
 	organization cf.CloudControllerOrganization,
+	clientID string,
+	recipientQuota int,
+	uaaHost,
+	scope,
+	vcapRequestID,
+	traceParent string,
+	reqReceived time.Time) ([]Response, error) {
+
+	transaction := conn.Transaction()
+
+	if err := transaction.Begin(); err != nil {
+		return []Response{}, err
+	}
+
+	recipients := len(users)
+	if len(options.Recipients) > 0 {
+		recipients *= len(options.Recipients)
+	}
+
+	err := reserveRecipients(transaction, enqueuer.recipientCounts, clientID, recipientQuota, reqReceived, recipients)
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+
+	responses, err := enqueuer.EnqueueWithin(transaction, users, options, space, organization, clientID, uaaHost, scope, vcapRequestID, traceParent, reqReceived)
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return []Response{}, err
+	}
+
+	return responses, nil
+}
+
+// EnqueueWithin enqueues the deliveries in a transaction that the caller has
+// already begun, so that the caller can record other changes alongside them.
+// The caller is responsible for committing or rolling back the transaction.
+func (enqueuer Enqueuer) EnqueueWithin(
+	transaction ConnectionInterface,
+	users []User,
+	options Options,
+	space cf.CloudControllerSpace,
+	organization cf.CloudControllerOrganization,
 	clientID,
 	uaaHost,
 	scope,
-	vcapRequestID string,
+	vcapRequestID,
+	traceParent string,
 	reqReceived time.Time) ([]Response, error) {
 
 	var responses []Response
 
-	transaction := conn.Transaction()
 	enqueuer.gobbleInitializer.InitializeDBMap(transaction.GetDbMap())
 
-	if err := transaction.Begin(); err != nil {
+	attachments, err := storeAttachments(transaction, enqueuer.attachmentsRepo, options.Attachments)
+	if err != nil {
 		return []Response{}, err
 	}
+	options.Attachments = attachments
 
 	for _, user := range users {
 		message, err := enqueuer.messagesRepo.Upsert(transaction, models.Message{
-			Status: StatusQueued,
+			Status:   StatusQueued,
+			ClientID: clientID,
+			KindID:   options.KindID,
 		})
 		if err != nil {
-			transaction.Rollback()
+			return []Response{}, err
+		}
+
+		err = referenceAttachments(transaction, enqueuer.attachmentsRepo, options.Attachments, message.ID)
+		if err != nil {
 			return []Response{}, err
 		}
 
@@ -108,14 +186,24 @@ func (enqueuer Enqueuer) Enqueue(
 			Scope:           scope,
 			VCAPRequestID:   vcapRequestID,
 			RequestReceived: reqReceived,
+			TraceParent:     traceParent,
 		})
 
 		_, err = enqueuer.queue.Enqueue(job, transaction)
 		if err != nil {
-			transaction.Rollback()
 			return []Response{}, err
 		}
 
+		if len(options.Recipients) > 0 {
+			recipientResponses, err := enqueuer.storeRecipients(transaction, message, options.Recipients, vcapRequestID)
+			if err != nil {
+				return []Response{}, err
+			}
+
+			responses = append(responses, recipientResponses...)
+			continue
+		}
+
 		recipient := user.Email
 		if recipient == "" {
 			recipient = user.GUID
@@ -129,9 +217,100 @@ func (enqueuer Enqueuer) Enqueue(
 		})
 	}
 
-	if err := transaction.Commit(); err != nil {
-		return []Response{}, err
+	return responses, nil
+}
+
+// reserveRecipients takes the recipients of a notification out of the daily
+// recipient quota of its client before any of its deliveries are enqueued,
+// refusing the notification when they do not fit. It is done in the enqueue
+// transaction, so that the reservation is given back if the notification
+// cannot be enqueued.
+func reserveRecipients(transaction ConnectionInterface, recipientCounts recipientReserver, clientID string, quota int, day time.Time, recipients int) error {
+	if quota <= 0 {
+		return nil
+	}
+
+	reserved, err := recipientCounts.Reserve(transaction, clientID, day, recipients, quota)
+	if err != nil {
+		return err
+	}
+
+	if !reserved {
+		return RecipientQuotaError{ClientID: clientID, Quota: quota}
+	}
+
+	return nil
+}
+
+// storeAttachments saves the content of each attachment once for all of the
+// recipients and returns the attachments with their IDs filled in, so that
+// the deliveries only need to carry a reference to the content. Attachments
+// that already have an ID, such as those of a fan-out, were stored before.
+func storeAttachments(conn models.ConnectionInterface, repo attachmentsRepoCreator, attachments []Attachment) ([]Attachment, error) {
+	if len(attachments) == 0 {
+		return attachments, nil
+	}
+
+	stored := make([]Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.ID != "" {
+			stored = append(stored, attachment)
+			continue
+		}
+
+		record, err := repo.Create(conn, models.Attachment{
+			Content: attachment.Content,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		attachment.ID = record.ID
+		attachment.Content = nil
+		stored = append(stored, attachment)
+	}
+
+	return stored, nil
+}
+
+// storeRecipients records every address of a message that is sent to several
+// recipients at once, so that the outcome for each of them can be tracked.
+// All of the returned responses share the ID of the message.
+func (enqueuer Enqueuer) storeRecipients(conn models.ConnectionInterface, message models.Message, recipients []Recipient, vcapRequestID string) ([]Response, error) {
+	var responses []Response
+
+	for _, recipient := range recipients {
+		_, err := enqueuer.recipientsRepo.Create(conn, models.MessageRecipient{
+			MessageID: message.ID,
+			Address:   recipient.Address,
+			Type:      recipient.Type,
+			Status:    message.Status,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, Response{
+			Status:         message.Status,
+			NotificationID: message.ID,
+			Recipient:      recipient.Address,
+			VCAPRequestID:  vcapRequestID,
+		})
 	}
 
 	return responses, nil
 }
+
+// referenceAttachments records that the message or fan-out with the given ID
+// needs each of the stored attachments, so that they outlive any delivery
+// that has yet to send them.
+func referenceAttachments(conn models.ConnectionInterface, repo attachmentsRepoCreator, attachments []Attachment, ownerID string) error {
+	for _, attachment := range attachments {
+		err := repo.Reference(conn, attachment.ID, ownerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
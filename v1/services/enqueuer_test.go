@@ -24,6 +24,9 @@ var _ = Describe("Enqueuer", func() {
 		org               cf.CloudControllerOrganization
 		reqReceived       time.Time
 		messagesRepo      *mocks.MessagesRepo
+		attachmentsRepo   *mocks.AttachmentsRepo
+		recipientsRepo    *mocks.MessageRecipientsRepo
+		recipientCounts   *mocks.ClientRecipientCountsRepo
 	)
 
 	BeforeEach(func() {
@@ -61,13 +64,17 @@ var _ = Describe("Enqueuer", func() {
 			},
 		}
 
-		enqueuer = services.NewEnqueuer(queue, messagesRepo, gobbleInitializer)
+		attachmentsRepo = mocks.NewAttachmentsRepo()
+		recipientsRepo = mocks.NewMessageRecipientsRepo()
+		recipientCounts = mocks.NewClientRecipientCountsRepo()
+
+		enqueuer = services.NewEnqueuer(queue, messagesRepo, attachmentsRepo, recipientsRepo, recipientCounts, gobbleInitializer)
 	})
 
 	Describe("Enqueue", func() {
 		It("returns the correct types of responses for users", func() {
 			users := []services.User{{GUID: "user-1"}, {Email: "user-2@example.com"}, {GUID: "user-3"}, {GUID: "user-4"}}
-			responses, err := enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind"}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			responses, err := enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind"}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(responses).To(HaveLen(4))
@@ -106,7 +113,7 @@ var _ = Describe("Enqueuer", func() {
 				{GUID: "user-3"},
 				{GUID: "user-4"},
 			}
-			enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 			var deliveries []services.Delivery
 			for _, job := range queue.EnqueueCall.Receives.Jobs {
@@ -131,6 +138,7 @@ var _ = Describe("Enqueuer", func() {
 					Scope:           "my.scope",
 					VCAPRequestID:   "some-request-id",
 					RequestReceived: reqReceived,
+					TraceParent:     "some-trace-parent",
 				},
 				{
 					Options:         services.Options{},
@@ -143,6 +151,7 @@ var _ = Describe("Enqueuer", func() {
 					Scope:           "my.scope",
 					VCAPRequestID:   "some-request-id",
 					RequestReceived: reqReceived,
+					TraceParent:     "some-trace-parent",
 				},
 				{
 					Options:         services.Options{},
@@ -155,6 +164,7 @@ var _ = Describe("Enqueuer", func() {
 					Scope:           "my.scope",
 					VCAPRequestID:   "some-request-id",
 					RequestReceived: reqReceived,
+					TraceParent:     "some-trace-parent",
 				},
 				{
 					Options:         services.Options{},
@@ -167,24 +177,218 @@ var _ = Describe("Enqueuer", func() {
 					Scope:           "my.scope",
 					VCAPRequestID:   "some-request-id",
 					RequestReceived: reqReceived,
+					TraceParent:     "some-trace-parent",
 				},
 			}))
 		})
 
-		It("upserts a StatusQueued for each of the jobs", func() {
+		It("upserts a StatusQueued for each of the jobs with the client and kind it was sent for", func() {
 			users := []services.User{{GUID: "user-1"}, {GUID: "user-2"}, {GUID: "user-3"}, {GUID: "user-4"}}
-			enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind"}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 			messages := messagesRepo.UpsertCall.Receives.Messages
 			Expect(messages).To(HaveLen(4))
 			Expect(messages).To(Equal([]models.Message{
-				{Status: services.StatusQueued},
-				{Status: services.StatusQueued},
-				{Status: services.StatusQueued},
-				{Status: services.StatusQueued},
+				{Status: services.StatusQueued, ClientID: "the-client", KindID: "the-kind"},
+				{Status: services.StatusQueued, ClientID: "the-client", KindID: "the-kind"},
+				{Status: services.StatusQueued, ClientID: "the-client", KindID: "the-kind"},
+				{Status: services.StatusQueued, ClientID: "the-client", KindID: "the-kind"},
 			}))
 		})
 
+		Context("when the client has a daily recipient quota", func() {
+			It("reserves every recipient in the enqueue transaction", func() {
+				recipientCounts.ReserveCall.Returns.Reserved = true
+				users := []services.User{{Email: "first@example.com"}}
+				options := services.Options{
+					Recipients: []services.Recipient{
+						{Address: "first@example.com", Type: services.RecipientTo},
+						{Address: "second@example.com", Type: services.RecipientCC},
+					},
+				}
+
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 10, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(recipientCounts.ReserveCall.Receives.Connection).To(Equal(transaction))
+				Expect(recipientCounts.ReserveCall.Receives.ClientID).To(Equal("the-client"))
+				Expect(recipientCounts.ReserveCall.Receives.Day).To(Equal(reqReceived))
+				Expect(recipientCounts.ReserveCall.Receives.Recipients).To(Equal(2))
+				Expect(recipientCounts.ReserveCall.Receives.Quota).To(Equal(10))
+			})
+
+			It("refuses the notification when the recipients do not fit in the quota", func() {
+				recipientCounts.ReserveCall.Returns.Reserved = false
+				users := []services.User{{GUID: "user-1"}}
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 10, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(services.RecipientQuotaError{ClientID: "the-client", Quota: 10}))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(messagesRepo.UpsertCall.Receives.Messages).To(BeEmpty())
+				Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			})
+
+			It("returns errors from reserving the recipients", func() {
+				recipientCounts.ReserveCall.Returns.Error = errors.New("BOOM!")
+				users := []services.User{{GUID: "user-1"}}
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 10, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		It("does not reserve recipients for clients without a quota", func() {
+			users := []services.User{{GUID: "user-1"}}
+
+			_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(recipientCounts.ReserveCall.CallCount).To(Equal(0))
+		})
+
+		Context("when the message has attachments", func() {
+			var (
+				users   []services.User
+				options services.Options
+			)
+
+			BeforeEach(func() {
+				users = []services.User{{GUID: "user-1"}, {GUID: "user-2"}}
+				options = services.Options{
+					Attachments: []services.Attachment{
+						{
+							Filename:    "invoice.csv",
+							ContentType: "text/csv",
+							Content:     []byte("item,price"),
+						},
+					},
+				}
+
+				attachmentsRepo.CreateCall.Returns.Attachments = []models.Attachment{
+					{ID: "some-attachment-id"},
+				}
+			})
+
+			It("stores the content once and only references it from the deliveries", func() {
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(attachmentsRepo.CreateCall.Receives.Connection).To(Equal(transaction))
+				Expect(attachmentsRepo.CreateCall.Receives.Attachments).To(Equal([]models.Attachment{
+					{Content: []byte("item,price")},
+				}))
+
+				Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(2))
+				for _, job := range queue.EnqueueCall.Receives.Jobs {
+					var delivery services.Delivery
+					err := job.Unmarshal(&delivery)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(delivery.Options.Attachments).To(Equal([]services.Attachment{
+						{
+							ID:          "some-attachment-id",
+							Filename:    "invoice.csv",
+							ContentType: "text/csv",
+						},
+					}))
+				}
+			})
+
+			It("references the attachments from each of the messages", func() {
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(attachmentsRepo.ReferenceCall.Receives.Connection).To(Equal(transaction))
+				Expect(attachmentsRepo.ReferenceCall.Receives.AttachmentIDs).To(Equal([]string{"some-attachment-id", "some-attachment-id"}))
+				Expect(attachmentsRepo.ReferenceCall.Receives.OwnerIDs).To(Equal([]string{"first-random-guid", "second-random-guid"}))
+			})
+
+			It("rolls back the transaction when the attachments cannot be referenced", func() {
+				attachmentsRepo.ReferenceCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			})
+
+			It("does not store attachments again that a fan-out has already stored", func() {
+				options.Attachments[0].ID = "stored-attachment-id"
+				options.Attachments[0].Content = nil
+
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(attachmentsRepo.CreateCall.CallCount).To(Equal(0))
+				Expect(attachmentsRepo.ReferenceCall.Receives.AttachmentIDs).To(Equal([]string{"stored-attachment-id", "stored-attachment-id"}))
+
+				var delivery services.Delivery
+				err = queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&delivery)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(delivery.Options.Attachments[0].ID).To(Equal("stored-attachment-id"))
+			})
+
+			It("rolls back the transaction when the attachments cannot be stored", func() {
+				attachmentsRepo.CreateCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			})
+		})
+
+		Context("when the message is sent to several recipients", func() {
+			var (
+				users   []services.User
+				options services.Options
+			)
+
+			BeforeEach(func() {
+				users = []services.User{{Email: "to@example.com"}}
+				options = services.Options{
+					Recipients: []services.Recipient{
+						{Address: "to@example.com", Type: services.RecipientTo},
+						{Address: "cc@example.com", Type: services.RecipientCC},
+						{Address: "bcc@example.com", Type: services.RecipientBCC},
+					},
+				}
+			})
+
+			It("records each recipient of the single message and responds for each of them", func() {
+				responses, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+				Expect(recipientsRepo.CreateCall.Receives.Connection).To(Equal(transaction))
+				Expect(recipientsRepo.CreateCall.Receives.Recipients).To(Equal([]models.MessageRecipient{
+					{MessageID: "first-random-guid", Address: "to@example.com", Type: "to", Status: services.StatusQueued},
+					{MessageID: "first-random-guid", Address: "cc@example.com", Type: "cc", Status: services.StatusQueued},
+					{MessageID: "first-random-guid", Address: "bcc@example.com", Type: "bcc", Status: services.StatusQueued},
+				}))
+
+				Expect(responses).To(Equal([]services.Response{
+					{Status: "queued", Recipient: "to@example.com", NotificationID: "first-random-guid", VCAPRequestID: "some-request-id"},
+					{Status: "queued", Recipient: "cc@example.com", NotificationID: "first-random-guid", VCAPRequestID: "some-request-id"},
+					{Status: "queued", Recipient: "bcc@example.com", NotificationID: "first-random-guid", VCAPRequestID: "some-request-id"},
+				}))
+			})
+
+			It("rolls back the transaction when the recipients cannot be stored", func() {
+				recipientsRepo.CreateCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+		})
+
 		Context("using a transaction", func() {
 			var users []services.User
 
@@ -198,7 +402,7 @@ var _ = Describe("Enqueuer", func() {
 			})
 
 			It("initializes the DbMap", func() {
-				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 				isSamePtr := (gobbleInitializer.InitializeDBMapCall.Receives.DbMap == transaction.GetDbMapCall.Returns.DbMap)
 				Expect(isSamePtr).To(BeTrue())
@@ -206,7 +410,7 @@ var _ = Describe("Enqueuer", func() {
 			})
 
 			It("commits the transaction when everything goes well", func() {
-				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
@@ -219,7 +423,7 @@ var _ = Describe("Enqueuer", func() {
 
 			It("rolls back the transaction when there is an error in message repo upserting", func() {
 				messagesRepo.UpsertCall.Returns.Error = errors.New("BOOM!")
-				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
 				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
@@ -229,7 +433,7 @@ var _ = Describe("Enqueuer", func() {
 
 			It("rolls back the transaction when there is an error in enqueuing", func() {
 				queue.EnqueueCall.Returns.Error = errors.New("BOOM!")
-				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
 				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
@@ -238,7 +442,7 @@ var _ = Describe("Enqueuer", func() {
 			})
 
 			It("uses the same transaction for the queue as it did for the messages repo", func() {
-				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 				Expect(messagesRepo.UpsertCall.Receives.Connection).To(Equal(transaction))
 				Expect(queue.EnqueueCall.Receives.Connection).To(Equal(transaction))
@@ -249,12 +453,12 @@ var _ = Describe("Enqueuer", func() {
 					Expect(transaction.CommitCall.WasCalled).To(BeFalse())
 				}
 
-				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 			})
 
 			It("returns an empty slice of Response if transaction fails", func() {
 				transaction.CommitCall.Returns.Error = errors.New("the commit blew up")
-				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
 
 				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
 				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
@@ -265,4 +469,30 @@ var _ = Describe("Enqueuer", func() {
 			})
 		})
 	})
+
+	Describe("EnqueueWithin", func() {
+		It("enqueues the deliveries in the given transaction without committing it", func() {
+			users := []services.User{{GUID: "user-1"}, {GUID: "user-2"}}
+			responses, err := enqueuer.EnqueueWithin(transaction, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(2))
+
+			Expect(messagesRepo.UpsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(transaction))
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(2))
+
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+		})
+
+		It("leaves rolling back to the caller when the deliveries cannot be enqueued", func() {
+			queue.EnqueueCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := enqueuer.EnqueueWithin(transaction, []services.User{{GUID: "user-1"}}, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).To(MatchError(errors.New("BOOM!")))
+
+			Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+		})
+	})
 })
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
@@ -64,6 +65,17 @@ func (e CCDownError) Error() string {
 	return e.Err.Error()
 }
 
+// RecipientQuotaError is returned when the recipients of a notification do
+// not fit in what is left of the daily recipient quota of its client.
+type RecipientQuotaError struct {
+	ClientID string
+	Quota    int
+}
+
+func (e RecipientQuotaError) Error() string {
+	return fmt.Sprintf("Client %q has exceeded its daily quota of %d recipients", e.ClientID, e.Quota)
+}
+
 type DefaultScopeError struct{}
 
 func (d DefaultScopeError) Error() string {
@@ -39,6 +39,7 @@ func (strategy EveryoneStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		SourceDescription: dispatch.Client.Description,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		Data:              dispatch.Message.Data,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
 			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
@@ -70,8 +71,10 @@ func (strategy EveryoneStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		cf.CloudControllerSpace{},
 		cf.CloudControllerOrganization{},
 		dispatch.Client.ID,
+		dispatch.Client.DailyRecipientQuota,
 		dispatch.UAAHost,
 		"",
 		dispatch.VCAPRequest.ID,
+		dispatch.VCAPRequest.TraceParent,
 		dispatch.VCAPRequest.ReceiptTime)
 }
@@ -57,8 +57,9 @@ var _ = Describe("Everyone Strategy", func() {
 					},
 					TemplateID: "some-template-id",
 					Client: services.DispatchClient{
-						ID:          "my-client",
-						Description: "Welcome system",
+						ID:                  "my-client",
+						Description:         "Welcome system",
+						DailyRecipientQuota: 500,
 					},
 					Message: services.DispatchMessage{
 						ReplyTo: "reply-to@example.com",
@@ -76,6 +77,7 @@ var _ = Describe("Everyone Strategy", func() {
 					VCAPRequest: services.DispatchVCAPRequest{
 						ID:          "some-vcap-request-id",
 						ReceiptTime: requestReceivedTime,
+						TraceParent: "some-trace-parent",
 					},
 				})
 				Expect(err).NotTo(HaveOccurred())
@@ -107,8 +109,10 @@ var _ = Describe("Everyone Strategy", func() {
 				Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{}))
 				Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{}))
 				Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("my-client"))
+				Expect(enqueuer.EnqueueCall.Receives.RecipientQuota).To(Equal(500))
 				Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal(""))
 				Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+				Expect(enqueuer.EnqueueCall.Receives.TraceParent).To(Equal("some-trace-parent"))
 				Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("my-uaa-host"))
 				Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceivedTime))
 				Expect(allUsers.AllUserGUIDsCall.Receives.Token).To(Equal(token))
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+const JobTypeFanOut = "fanout"
+
+// FanOut is the job that enqueues the deliveries of a notification sent to a
+// group of users. The worker that performs it looks up the email addresses
+// of the users in batches, so that the deliveries do not have to.
+type FanOut struct {
+	JobType         string
+	ID              string
+	Users           []User
+	Options         Options
+	Space           cf.CloudControllerSpace
+	Organization    cf.CloudControllerOrganization
+	ClientID        string
+	UAAHost         string
+	Scope           string
+	VCAPRequestID   string
+	RequestReceived time.Time
+	TraceParent     string
+}
+
+type fanOutsRepoCreator interface {
+	Create(models.ConnectionInterface, models.FanOut) (models.FanOut, error)
+}
+
+// FanOutEnqueuer enqueues a single fan-out job in place of a delivery for
+// each of the users, so that a request to a large group of users can be
+// answered without waiting for all of their deliveries to be enqueued.
+type FanOutEnqueuer struct {
+	queue             queueInterface
+	fanOutsRepo       fanOutsRepoCreator
+	attachmentsRepo   attachmentsRepoCreator
+	recipientCounts   recipientReserver
+	gobbleInitializer gobbleInitializer
+}
+
+func NewFanOutEnqueuer(queue queueInterface, fanOutsRepo fanOutsRepoCreator, attachmentsRepo attachmentsRepoCreator, recipientCounts recipientReserver, gobbleInitializer gobbleInitializer) FanOutEnqueuer {
+	return FanOutEnqueuer{
+		queue:             queue,
+		fanOutsRepo:       fanOutsRepo,
+		attachmentsRepo:   attachmentsRepo,
+		recipientCounts:   recipientCounts,
+		gobbleInitializer: gobbleInitializer,
+	}
+}
+
+func (enqueuer FanOutEnqueuer) Enqueue(
+	conn ConnectionInterface,
+	users []User,
+	options Options,
+	space cf.CloudControllerSpace,
+	organization cf.CloudControllerOrganization,
+	clientID string,
+	recipientQuota int,
+	uaaHost,
+	scope,
+	vcapRequestID,
+	traceParent string,
+	reqReceived time.Time) ([]Response, error) {
+
+	if len(users) == 0 {
+		return []Response{}, nil
+	}
+
+	transaction := conn.Transaction()
+	enqueuer.gobbleInitializer.InitializeDBMap(transaction.GetDbMap())
+
+	if err := transaction.Begin(); err != nil {
+		return []Response{}, err
+	}
+
+	err := reserveRecipients(transaction, enqueuer.recipientCounts, clientID, recipientQuota, reqReceived, len(users))
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+
+	attachments, err := storeAttachments(transaction, enqueuer.attachmentsRepo, options.Attachments)
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+	options.Attachments = attachments
+
+	fanOut, err := enqueuer.fanOutsRepo.Create(transaction, models.FanOut{
+		ClientID: clientID,
+		Status:   models.FanOutStatusQueued,
+		Total:    len(users),
+	})
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+
+	err = referenceAttachments(transaction, enqueuer.attachmentsRepo, options.Attachments, fanOut.ID)
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+
+	job := gobble.NewJob(FanOut{
+		JobType:         JobTypeFanOut,
+		ID:              fanOut.ID,
+		Users:           users,
+		Options:         options,
+		Space:           space,
+		Organization:    organization,
+		ClientID:        clientID,
+		UAAHost:         uaaHost,
+		Scope:           scope,
+		VCAPRequestID:   vcapRequestID,
+		RequestReceived: reqReceived,
+		TraceParent:     traceParent,
+	})
+
+	_, err = enqueuer.queue.Enqueue(job, transaction)
+	if err != nil {
+		transaction.Rollback()
+		return []Response{}, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return []Response{}, err
+	}
+
+	return []Response{
+		{
+			Status:         fanOut.Status,
+			FanOutID:       fanOut.ID,
+			RecipientCount: fanOut.Total,
+			VCAPRequestID:  vcapRequestID,
+		},
+	}, nil
+}
@@ -0,0 +1,252 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FanOutEnqueuer", func() {
+	var (
+		enqueuer          services.FanOutEnqueuer
+		queue             *mocks.Queue
+		gobbleInitializer *mocks.GobbleInitializer
+		conn              *mocks.Connection
+		transaction       *mocks.Transaction
+		space             cf.CloudControllerSpace
+		org               cf.CloudControllerOrganization
+		reqReceived       time.Time
+		fanOutsRepo       *mocks.FanOutsRepo
+		attachmentsRepo   *mocks.AttachmentsRepo
+		recipientCounts   *mocks.ClientRecipientCountsRepo
+		users             []services.User
+	)
+
+	BeforeEach(func() {
+		queue = mocks.NewQueue()
+
+		transaction = mocks.NewTransaction()
+		conn = mocks.NewConnection()
+
+		conn.TransactionCall.Returns.Transaction = transaction
+		transaction.Connection = conn
+
+		gobbleInitializer = mocks.NewGobbleInitializer()
+
+		space = cf.CloudControllerSpace{Name: "the-space"}
+		org = cf.CloudControllerOrganization{Name: "the-org"}
+		reqReceived, _ = time.Parse(time.RFC3339Nano, "2015-06-08T14:40:12.207187819-07:00")
+
+		fanOutsRepo = mocks.NewFanOutsRepo()
+		fanOutsRepo.CreateCall.Returns.FanOut = models.FanOut{
+			ID:       "some-fanout-id",
+			ClientID: "the-client",
+			Status:   models.FanOutStatusQueued,
+			Total:    3,
+		}
+
+		attachmentsRepo = mocks.NewAttachmentsRepo()
+		recipientCounts = mocks.NewClientRecipientCountsRepo()
+
+		users = []services.User{{GUID: "user-1"}, {GUID: "user-2"}, {GUID: "user-3"}}
+
+		enqueuer = services.NewFanOutEnqueuer(queue, fanOutsRepo, attachmentsRepo, recipientCounts, gobbleInitializer)
+	})
+
+	Describe("Enqueue", func() {
+		It("records the fan-out and responds with its ID", func() {
+			responses, err := enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind"}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fanOutsRepo.CreateCall.Receives.Connection).To(Equal(transaction))
+			Expect(fanOutsRepo.CreateCall.Receives.FanOut).To(Equal(models.FanOut{
+				ClientID: "the-client",
+				Status:   models.FanOutStatusQueued,
+				Total:    3,
+			}))
+
+			Expect(responses).To(Equal([]services.Response{
+				{
+					Status:         "queued",
+					FanOutID:       "some-fanout-id",
+					RecipientCount: 3,
+					VCAPRequestID:  "some-request-id",
+				},
+			}))
+		})
+
+		It("enqueues a single job for all of the users", func() {
+			_, err := enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind"}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(transaction))
+
+			var fanOut services.FanOut
+			err = queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&fanOut)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fanOut).To(Equal(services.FanOut{
+				JobType:         services.JobTypeFanOut,
+				ID:              "some-fanout-id",
+				Users:           users,
+				Options:         services.Options{KindID: "the-kind"},
+				Space:           space,
+				Organization:    org,
+				ClientID:        "the-client",
+				UAAHost:         "my-uaa-host",
+				Scope:           "my.scope",
+				VCAPRequestID:   "some-request-id",
+				RequestReceived: reqReceived,
+				TraceParent:     "some-trace-parent",
+			}))
+		})
+
+		It("stores the content of the attachments before the job is enqueued", func() {
+			attachmentsRepo.CreateCall.Returns.Attachments = []models.Attachment{
+				{ID: "some-attachment-id"},
+			}
+
+			options := services.Options{
+				Attachments: []services.Attachment{
+					{Filename: "invoice.csv", ContentType: "text/csv", Content: []byte("item,price")},
+				},
+			}
+
+			_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(attachmentsRepo.CreateCall.Receives.Attachments).To(Equal([]models.Attachment{
+				{Content: []byte("item,price")},
+			}))
+
+			var fanOut services.FanOut
+			err = queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&fanOut)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fanOut.Options.Attachments).To(Equal([]services.Attachment{
+				{ID: "some-attachment-id", Filename: "invoice.csv", ContentType: "text/csv"},
+			}))
+		})
+
+		It("references the attachments from the fan-out", func() {
+			attachmentsRepo.CreateCall.Returns.Attachments = []models.Attachment{
+				{ID: "some-attachment-id"},
+			}
+
+			options := services.Options{
+				Attachments: []services.Attachment{
+					{Filename: "invoice.csv", ContentType: "text/csv", Content: []byte("item,price")},
+				},
+			}
+
+			_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(attachmentsRepo.ReferenceCall.Receives.Connection).To(Equal(transaction))
+			Expect(attachmentsRepo.ReferenceCall.Receives.AttachmentIDs).To(Equal([]string{"some-attachment-id"}))
+			Expect(attachmentsRepo.ReferenceCall.Receives.OwnerIDs).To(Equal([]string{"some-fanout-id"}))
+		})
+
+		It("enqueues nothing when there are no users", func() {
+			responses, err := enqueuer.Enqueue(conn, nil, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(BeEmpty())
+
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+		})
+
+		Context("when the client has a daily recipient quota", func() {
+			It("reserves every user in the enqueue transaction", func() {
+				recipientCounts.ReserveCall.Returns.Reserved = true
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 10, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(recipientCounts.ReserveCall.Receives.Connection).To(Equal(transaction))
+				Expect(recipientCounts.ReserveCall.Receives.ClientID).To(Equal("the-client"))
+				Expect(recipientCounts.ReserveCall.Receives.Day).To(Equal(reqReceived))
+				Expect(recipientCounts.ReserveCall.Receives.Recipients).To(Equal(3))
+				Expect(recipientCounts.ReserveCall.Receives.Quota).To(Equal(10))
+				Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+			})
+
+			It("refuses the fan-out when the users do not fit in the quota", func() {
+				recipientCounts.ReserveCall.Returns.Reserved = false
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 2, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(services.RecipientQuotaError{ClientID: "the-client", Quota: 2}))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(fanOutsRepo.CreateCall.Receives.FanOut).To(Equal(models.FanOut{}))
+				Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			})
+
+			It("rolls back the reservation when the job cannot be enqueued", func() {
+				recipientCounts.ReserveCall.Returns.Reserved = true
+				queue.EnqueueCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 10, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(recipientCounts.ReserveCall.CallCount).To(Equal(1))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		It("does not reserve recipients for clients without a quota", func() {
+			_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(recipientCounts.ReserveCall.CallCount).To(Equal(0))
+		})
+
+		Context("using a transaction", func() {
+			It("commits the transaction when everything goes well", func() {
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				isSamePtr := (gobbleInitializer.InitializeDBMapCall.Receives.DbMap == transaction.GetDbMapCall.Returns.DbMap)
+				Expect(isSamePtr).To(BeTrue())
+				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+				Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+			})
+
+			It("rolls back the transaction when the fan-out cannot be recorded", func() {
+				fanOutsRepo.CreateCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			})
+
+			It("rolls back the transaction when the job cannot be enqueued", func() {
+				queue.EnqueueCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns an error when the transaction cannot be committed", func() {
+				transaction.CommitCall.Returns.Error = errors.New("the commit blew up")
+
+				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", 0, "my-uaa-host", "my.scope", "some-request-id", "some-trace-parent", reqReceived)
+				Expect(err).To(MatchError(errors.New("the commit blew up")))
+				Expect(responses).To(Equal([]services.Response{}))
+			})
+		})
+	})
+})
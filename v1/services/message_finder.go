@@ -3,28 +3,57 @@ package services
 import "github.com/cloudfoundry-incubator/notifications/v1/models"
 
 type Message struct {
-	Status string
+	Status     string
+	Recipients []MessageRecipient
+}
+
+type MessageRecipient struct {
+	Address string
+	Type    string
+	Status  string
 }
 
 type messagesRepoFinder interface {
 	FindByID(models.ConnectionInterface, string) (models.Message, error)
 }
 
+type messageRecipientsRepoFinder interface {
+	FindByMessageID(models.ConnectionInterface, string) ([]models.MessageRecipient, error)
+}
+
 type MessageFinder struct {
-	repo messagesRepoFinder
+	repo           messagesRepoFinder
+	recipientsRepo messageRecipientsRepoFinder
 }
 
-func NewMessageFinder(repo messagesRepoFinder) MessageFinder {
+func NewMessageFinder(repo messagesRepoFinder, recipientsRepo messageRecipientsRepoFinder) MessageFinder {
 	return MessageFinder{
-		repo: repo,
+		repo:           repo,
+		recipientsRepo: recipientsRepo,
 	}
 }
 
 func (finder MessageFinder) Find(database DatabaseInterface, messageID string) (Message, error) {
-	message, err := finder.repo.FindByID(database.Connection(), messageID)
+	connection := database.Connection()
+
+	message, err := finder.repo.FindByID(connection, messageID)
 	if err != nil {
 		return Message{}, err
 	}
 
-	return Message{Status: message.Status}, nil
+	recipients, err := finder.recipientsRepo.FindByMessageID(connection, messageID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	result := Message{Status: message.Status}
+	for _, recipient := range recipients {
+		result.Recipients = append(result.Recipients, MessageRecipient{
+			Address: recipient.Address,
+			Type:    recipient.Type,
+			Status:  recipient.Status,
+		})
+	}
+
+	return result, nil
 }
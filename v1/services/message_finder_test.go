@@ -14,19 +14,21 @@ import (
 
 var _ = Describe("MessageFinder.Find", func() {
 	var (
-		finder       services.MessageFinder
-		messagesRepo *mocks.MessagesRepo
-		database     *mocks.Database
-		conn         *mocks.Connection
+		finder         services.MessageFinder
+		messagesRepo   *mocks.MessagesRepo
+		recipientsRepo *mocks.MessageRecipientsRepo
+		database       *mocks.Database
+		conn           *mocks.Connection
 	)
 
 	BeforeEach(func() {
 		messagesRepo = mocks.NewMessagesRepo()
+		recipientsRepo = mocks.NewMessageRecipientsRepo()
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = conn
 
-		finder = services.NewMessageFinder(messagesRepo)
+		finder = services.NewMessageFinder(messagesRepo, recipientsRepo)
 	})
 
 	Context("when a message exists with the given id", func() {
@@ -40,6 +42,25 @@ var _ = Describe("MessageFinder.Find", func() {
 
 			Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
 			Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal("a-message-id"))
+			Expect(message.Recipients).To(BeEmpty())
+		})
+
+		It("includes the status of each recipient of the message", func() {
+			messagesRepo.FindByIDCall.Returns.Message = models.Message{Status: common.StatusDelivered}
+			recipientsRepo.FindByMessageIDCall.Returns.Recipients = []models.MessageRecipient{
+				{MessageID: "a-message-id", Address: "to@example.com", Type: "to", Status: common.StatusDelivered},
+				{MessageID: "a-message-id", Address: "bcc@example.com", Type: "bcc", Status: common.StatusFailed},
+			}
+
+			message, err := finder.Find(database, "a-message-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(message.Recipients).To(Equal([]services.MessageRecipient{
+				{Address: "to@example.com", Type: "to", Status: common.StatusDelivered},
+				{Address: "bcc@example.com", Type: "bcc", Status: common.StatusFailed},
+			}))
+			Expect(recipientsRepo.FindByMessageIDCall.Receives.Connection).To(Equal(conn))
+			Expect(recipientsRepo.FindByMessageIDCall.Receives.MessageID).To(Equal("a-message-id"))
 		})
 	})
 
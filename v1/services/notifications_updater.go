@@ -12,8 +12,8 @@ func NewNotificationsUpdater(kindsRepo KindsRepo) NotificationsUpdater {
 	}
 }
 
-func (updater NotificationsUpdater) Update(database DatabaseInterface, notification models.Kind) error {
-	_, err := updater.kindsRepo.Update(database.Connection(), notification)
+func (updater NotificationsUpdater) Update(conn ConnectionInterface, notification models.Kind) error {
+	_, err := updater.kindsRepo.Update(conn, notification)
 	if err != nil {
 		return err
 	}
@@ -15,15 +15,12 @@ var _ = Describe("NotificationUpdater", func() {
 	var (
 		notificationsUpdater services.NotificationsUpdater
 		kindsRepo            *mocks.KindsRepo
-		database             *mocks.Database
 		conn                 *mocks.Connection
 	)
 
 	BeforeEach(func() {
 		kindsRepo = mocks.NewKindsRepo()
 		conn = mocks.NewConnection()
-		database = mocks.NewDatabase()
-		database.ConnectionCall.Returns.Connection = conn
 
 		notificationsUpdater = services.NewNotificationsUpdater(kindsRepo)
 	})
@@ -38,7 +35,7 @@ var _ = Describe("NotificationUpdater", func() {
 				Critical:    false,
 			}
 
-			err := notificationsUpdater.Update(database, models.Kind{
+			err := notificationsUpdater.Update(conn, models.Kind{
 				ID:          "my-current-kind-id",
 				Description: "some-description",
 				Critical:    true,
@@ -60,7 +57,7 @@ var _ = Describe("NotificationUpdater", func() {
 		It("propagates errors returned by the repo", func() {
 			kindsRepo.UpdateCall.Returns.Error = errors.New("Boom")
 
-			err := notificationsUpdater.Update(database, models.Kind{})
+			err := notificationsUpdater.Update(conn, models.Kind{})
 			Expect(err).To(MatchError(errors.New("Boom")))
 		})
 	})
@@ -43,6 +43,7 @@ func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, er
 		Endorsement:       OrganizationEndorsement,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		Data:              dispatch.Message.Data,
 		Role:              dispatch.Role,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
@@ -83,8 +84,10 @@ func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, er
 		cf.CloudControllerSpace{},
 		organization,
 		dispatch.Client.ID,
+		dispatch.Client.DailyRecipientQuota,
 		dispatch.UAAHost,
 		"",
 		dispatch.VCAPRequest.ID,
+		dispatch.VCAPRequest.TraceParent,
 		dispatch.VCAPRequest.ReceiptTime)
 }
@@ -80,12 +80,14 @@ var _ = Describe("Organization Strategy", func() {
 						},
 						TemplateID: "some-template-id",
 						Client: services.DispatchClient{
-							ID:          "mister-client",
-							Description: "Login system",
+							ID:                  "mister-client",
+							Description:         "Login system",
+							DailyRecipientQuota: 500,
 						},
 						VCAPRequest: services.DispatchVCAPRequest{
 							ID:          "some-vcap-request-id",
 							ReceiptTime: requestReceived,
+							TraceParent: "some-trace-parent",
 						},
 						UAAHost: "testzone1",
 					})
@@ -124,8 +126,10 @@ var _ = Describe("Organization Strategy", func() {
 						GUID: "org-001",
 					}))
 					Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+					Expect(enqueuer.EnqueueCall.Receives.RecipientQuota).To(Equal(500))
 					Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal(""))
 					Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+					Expect(enqueuer.EnqueueCall.Receives.TraceParent).To(Equal("some-trace-parent"))
 					Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
 					Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("testzone1"))
 
@@ -165,6 +169,7 @@ var _ = Describe("Organization Strategy", func() {
 							VCAPRequest: services.DispatchVCAPRequest{
 								ID:          "some-vcap-request-id",
 								ReceiptTime: requestReceived,
+								TraceParent: "some-trace-parent",
 							},
 						})
 						Expect(err).NotTo(HaveOccurred())
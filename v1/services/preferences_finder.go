@@ -12,8 +12,7 @@ func NewPreferencesFinder(preferencesRepo PreferencesRepo, globalUnsubscribesRep
 	}
 }
 
-func (finder PreferencesFinder) Find(database DatabaseInterface, userGUID string) (PreferencesBuilder, error) {
-	conn := database.Connection()
+func (finder PreferencesFinder) Find(conn ConnectionInterface, userGUID string) (PreferencesBuilder, error) {
 	builder := NewPreferencesBuilder()
 
 	globallyUnsubscribed, err := finder.globalUnsubscribesRepo.Get(conn, userGUID)
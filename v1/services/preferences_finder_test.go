@@ -16,7 +16,6 @@ var _ = Describe("PreferencesFinder", func() {
 		finder          *services.PreferencesFinder
 		preferencesRepo *mocks.PreferencesRepo
 		preferences     []models.Preference
-		database        *mocks.Database
 		conn            *mocks.Connection
 	)
 
@@ -45,8 +44,6 @@ var _ = Describe("PreferencesFinder", func() {
 		preferencesRepo.FindNonCriticalPreferencesCall.Returns.Preferences = preferences
 
 		conn = mocks.NewConnection()
-		database = mocks.NewDatabase()
-		database.ConnectionCall.Returns.Connection = conn
 
 		finder = services.NewPreferencesFinder(preferencesRepo, fakeGlobalUnsubscribesRepo)
 	})
@@ -58,7 +55,7 @@ var _ = Describe("PreferencesFinder", func() {
 			expectedResult.Add(preferences[1])
 			expectedResult.GlobalUnsubscribe = true
 
-			resultPreferences, err := finder.Find(database, "correct-user")
+			resultPreferences, err := finder.Find(conn, "correct-user")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resultPreferences).To(Equal(expectedResult))
 
@@ -70,7 +67,7 @@ var _ = Describe("PreferencesFinder", func() {
 			It("should propagate the error", func() {
 				preferencesRepo.FindNonCriticalPreferencesCall.Returns.Error = errors.New("BOOM!")
 
-				_, err := finder.Find(database, "correct-user")
+				_, err := finder.Find(conn, "correct-user")
 				Expect(err).To(Equal(preferencesRepo.FindNonCriticalPreferencesCall.Returns.Error))
 			})
 		})
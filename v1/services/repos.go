@@ -39,3 +39,8 @@ type GlobalUnsubscribesRepo interface {
 	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
 	Set(connection models.ConnectionInterface, userGUID string, unsubscribe bool) error
 }
+
+type AuditEventsRepo interface {
+	Create(connection models.ConnectionInterface, event models.AuditEvent) (models.AuditEvent, error)
+	List(connection models.ConnectionInterface, filter models.AuditEventFilter) ([]models.AuditEvent, int, error)
+}
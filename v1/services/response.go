@@ -2,7 +2,13 @@ package services
 
 type Response struct {
 	Status         string `json:"status"`
-	Recipient      string `json:"recipient"`
-	NotificationID string `json:"notification_id"`
+	Recipient      string `json:"recipient,omitempty"`
+	NotificationID string `json:"notification_id,omitempty"`
 	VCAPRequestID  string `json:"vcap_request_id"`
+
+	// A notification sent to a group of users is answered with a single
+	// response for the fan-out that enqueues their deliveries, which counts
+	// the users it was sent to.
+	FanOutID       string `json:"fanout_id,omitempty"`
+	RecipientCount int    `json:"recipient_count,omitempty"`
 }
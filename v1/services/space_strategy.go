@@ -43,6 +43,7 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		Endorsement:       SpaceEndorsement,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		Data:              dispatch.Message.Data,
 		Role:              dispatch.Role,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
@@ -84,8 +85,10 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		space,
 		org,
 		dispatch.Client.ID,
+		dispatch.Client.DailyRecipientQuota,
 		dispatch.UAAHost,
 		"",
 		dispatch.VCAPRequest.ID,
+		dispatch.VCAPRequest.TraceParent,
 		dispatch.VCAPRequest.ReceiptTime)
 }
@@ -90,12 +90,14 @@ var _ = Describe("Space Strategy", func() {
 							Description: "Password reminder",
 						},
 						Client: services.DispatchClient{
-							ID:          "mister-client",
-							Description: "Login system",
+							ID:                  "mister-client",
+							Description:         "Login system",
+							DailyRecipientQuota: 500,
 						},
 						VCAPRequest: services.DispatchVCAPRequest{
 							ID:          "some-vcap-request-id",
 							ReceiptTime: requestReceived,
+							TraceParent: "some-trace-parent",
 						},
 						UAAHost: "uaa",
 					})
@@ -138,8 +140,10 @@ var _ = Describe("Space Strategy", func() {
 						GUID: "org-001",
 					}))
 					Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+					Expect(enqueuer.EnqueueCall.Receives.RecipientQuota).To(Equal(500))
 					Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal(""))
 					Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+					Expect(enqueuer.EnqueueCall.Receives.TraceParent).To(Equal("some-trace-parent"))
 					Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
 					Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("uaa"))
 
@@ -12,8 +12,8 @@ func NewTemplateUpdater(templatesRepo TemplatesRepo) TemplateUpdater {
 	}
 }
 
-func (updater TemplateUpdater) Update(database DatabaseInterface, templateID string, template models.Template) error {
-	_, err := updater.templatesRepo.Update(database.Connection(), templateID, template)
+func (updater TemplateUpdater) Update(conn ConnectionInterface, templateID string, template models.Template) error {
+	_, err := updater.templatesRepo.Update(conn, templateID, template)
 	if err != nil {
 		return err
 	}
@@ -15,22 +15,19 @@ var _ = Describe("Updater", func() {
 	Describe("Update", func() {
 		var (
 			conn          *mocks.Connection
-			database      *mocks.Database
 			templatesRepo *mocks.TemplatesRepo
 			updater       services.TemplateUpdater
 		)
 
 		BeforeEach(func() {
 			conn = mocks.NewConnection()
-			database = mocks.NewDatabase()
-			database.ConnectionCall.Returns.Connection = conn
 			templatesRepo = mocks.NewTemplatesRepo()
 
 			updater = services.NewTemplateUpdater(templatesRepo)
 		})
 
 		It("Inserts templates into the templates repo", func() {
-			err := updater.Update(database, "my-awesome-id", models.Template{
+			err := updater.Update(conn, "my-awesome-id", models.Template{
 				Name: "gobble template",
 				Text: "gobble",
 				HTML: "<p>gobble</p>",
@@ -49,7 +46,7 @@ var _ = Describe("Updater", func() {
 		It("propagates errors from repo", func() {
 			templatesRepo.UpdateCall.Returns.Error = errors.New("Boom!")
 
-			err := updater.Update(database, "unimportant", models.Template{})
+			err := updater.Update(conn, "unimportant", models.Template{})
 			Expect(err).To(MatchError(errors.New("Boom!")))
 		})
 	})
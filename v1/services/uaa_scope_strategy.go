@@ -36,6 +36,7 @@ func (strategy UAAScopeStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		SourceDescription: dispatch.Client.Description,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		Data:              dispatch.Message.Data,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
 			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
@@ -70,9 +71,11 @@ func (strategy UAAScopeStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		cf.CloudControllerSpace{},
 		cf.CloudControllerOrganization{},
 		dispatch.Client.ID,
+		dispatch.Client.DailyRecipientQuota,
 		dispatch.UAAHost,
 		dispatch.GUID,
 		dispatch.VCAPRequest.ID,
+		dispatch.VCAPRequest.TraceParent,
 		dispatch.VCAPRequest.ReceiptTime)
 }
 
@@ -87,12 +87,14 @@ var _ = Describe("UAA Scope Strategy", func() {
 							Description: "Water Bottle Reminder",
 						},
 						Client: services.DispatchClient{
-							ID:          "mister-client",
-							Description: "The Water Bottle System",
+							ID:                  "mister-client",
+							Description:         "The Water Bottle System",
+							DailyRecipientQuota: 500,
 						},
 						VCAPRequest: services.DispatchVCAPRequest{
 							ID:          "some-vcap-request-id",
 							ReceiptTime: requestReceived,
+							TraceParent: "some-trace-parent",
 						},
 						UAAHost: "uaa",
 					})
@@ -122,8 +124,10 @@ var _ = Describe("UAA Scope Strategy", func() {
 					Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{}))
 					Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{}))
 					Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+					Expect(enqueuer.EnqueueCall.Receives.RecipientQuota).To(Equal(500))
 					Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal("great.scope"))
 					Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+					Expect(enqueuer.EnqueueCall.Receives.TraceParent).To(Equal("some-trace-parent"))
 					Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
 					Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("uaa"))
 
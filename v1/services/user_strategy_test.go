@@ -37,6 +37,10 @@ var _ = Describe("UserStrategy", func() {
 					ReplyTo: "reply-to@example.com",
 					Subject: "this is the subject",
 					Text:    "Please make sure to leave your bottle in a place that is safe and dry",
+					Data:    map[string]interface{}{"bottle": "blue"},
+					Attachments: []services.Attachment{
+						{Filename: "bottle.png", ContentType: "image/png", ContentID: "bottle", Inline: true, Content: []byte("png")},
+					},
 					HTML: services.HTML{
 						BodyContent:    "<p>The water bottle needs to be safe and dry</p>",
 						BodyAttributes: "some-html-body-attributes",
@@ -51,12 +55,14 @@ var _ = Describe("UserStrategy", func() {
 					Description: "Water Bottle Reminder",
 				},
 				Client: services.DispatchClient{
-					ID:          "mister-client",
-					Description: "The Water Bottle System",
+					ID:                  "mister-client",
+					Description:         "The Water Bottle System",
+					DailyRecipientQuota: 500,
 				},
 				VCAPRequest: services.DispatchVCAPRequest{
 					ID:          "some-vcap-request-id",
 					ReceiptTime: requestReceived,
+					TraceParent: "some-trace-parent",
 				},
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -74,6 +80,10 @@ var _ = Describe("UserStrategy", func() {
 				SourceDescription: "The Water Bottle System",
 				Text:              "Please make sure to leave your bottle in a place that is safe and dry",
 				TemplateID:        "some-template-id",
+				Data:              map[string]interface{}{"bottle": "blue"},
+				Attachments: []services.Attachment{
+					{Filename: "bottle.png", ContentType: "image/png", ContentID: "bottle", Inline: true, Content: []byte("png")},
+				},
 				HTML: services.HTML{
 					BodyContent:    "<p>The water bottle needs to be safe and dry</p>",
 					BodyAttributes: "some-html-body-attributes",
@@ -85,9 +95,11 @@ var _ = Describe("UserStrategy", func() {
 			Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{}))
 			Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{}))
 			Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+			Expect(enqueuer.EnqueueCall.Receives.RecipientQuota).To(Equal(500))
 			Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal(""))
 			Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("uaa"))
 			Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+			Expect(enqueuer.EnqueueCall.Receives.TraceParent).To(Equal("some-trace-parent"))
 			Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
 		})
 	})
@@ -0,0 +1,7 @@
+package auditevents
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type DatabaseInterface interface {
+	services.DatabaseInterface
+}
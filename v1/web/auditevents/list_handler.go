@@ -0,0 +1,147 @@
+package auditevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultLimit = 50
+	MaximumLimit = 500
+)
+
+type errorWriter interface {
+	Write(writer http.ResponseWriter, err error)
+}
+
+type auditEventLister interface {
+	List(database services.DatabaseInterface, filter models.AuditEventFilter) ([]models.AuditEvent, int, error)
+}
+
+type AuditEventOutput struct {
+	ID        string          `json:"id"`
+	ActorType string          `json:"actor_type"`
+	ActorID   string          `json:"actor_id"`
+	ClientID  string          `json:"client_id"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ListOutput struct {
+	AuditEvents []AuditEventOutput `json:"audit_events"`
+	Total       int                `json:"total"`
+	Limit       int                `json:"limit"`
+	Offset      int                `json:"offset"`
+}
+
+type ListHandler struct {
+	lister      auditEventLister
+	errorWriter errorWriter
+}
+
+func NewListHandler(lister auditEventLister, errWriter errorWriter) ListHandler {
+	return ListHandler{
+		lister:      lister,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	filter, err := parseFilter(req.URL.Query())
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: err})
+		return
+	}
+
+	events, total, err := h.lister.List(context.Get("database").(DatabaseInterface), filter)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	output := ListOutput{
+		AuditEvents: []AuditEventOutput{},
+		Total:       total,
+		Limit:       filter.Limit,
+		Offset:      filter.Offset,
+	}
+
+	for _, event := range events {
+		output.AuditEvents = append(output.AuditEvents, AuditEventOutput{
+			ID:        event.ID,
+			ActorType: event.ActorType,
+			ActorID:   event.ActorID,
+			ClientID:  event.ClientID,
+			Action:    event.Action,
+			Target:    event.Target,
+			Before:    json.RawMessage(event.Before),
+			After:     json.RawMessage(event.After),
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+func parseFilter(query url.Values) (models.AuditEventFilter, error) {
+	filter := models.AuditEventFilter{
+		ActorID: query.Get("actor"),
+		Action:  query.Get("action"),
+		Target:  query.Get("target"),
+		Limit:   DefaultLimit,
+	}
+
+	var err error
+	if value := query.Get("since"); value != "" {
+		filter.Since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("since must be an RFC 3339 timestamp, got %q", value)
+		}
+	}
+
+	if value := query.Get("until"); value != "" {
+		filter.Until, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("until must be an RFC 3339 timestamp, got %q", value)
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 || filter.Limit > MaximumLimit {
+			return filter, fmt.Errorf("limit must be a number between 1 and %d, got %q", MaximumLimit, value)
+		}
+	}
+
+	if value := query.Get("offset"); value != "" {
+		filter.Offset, err = strconv.Atoi(value)
+		if err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("offset must be a number that is not negative, got %q", value)
+		}
+	}
+
+	return filter, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, object interface{}) {
+	output, err := json.Marshal(object)
+	if err != nil {
+		panic(err) // No JSON we write into a response should ever panic
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(output)
+}
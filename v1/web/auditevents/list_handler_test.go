@@ -0,0 +1,162 @@
+package auditevents_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/auditevents"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler     auditevents.ListHandler
+		lister      *mocks.AuditLog
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		database    *mocks.Database
+	)
+
+	BeforeEach(func() {
+		lister = mocks.NewAuditLog()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = auditevents.NewListHandler(lister, errorWriter)
+	})
+
+	It("responds with a page of audit events", func() {
+		createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		lister.ListCall.Returns.Total = 3
+		lister.ListCall.Returns.Events = []models.AuditEvent{
+			{
+				ID:        "some-event",
+				ActorType: "user",
+				ActorID:   "admin-user",
+				ClientID:  "admin-client",
+				Action:    "client.template.assign",
+				Target:    "clients/some-client",
+				Before:    `{"template":"default"}`,
+				After:     `{"template":"new-template"}`,
+				CreatedAt: createdAt,
+			},
+		}
+
+		request, err := http.NewRequest("GET", "/audit_events", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(lister.ListCall.Receives.Database).To(Equal(database))
+		Expect(lister.ListCall.Receives.Filter).To(Equal(models.AuditEventFilter{Limit: 50}))
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"audit_events": [
+				{
+					"id": "some-event",
+					"actor_type": "user",
+					"actor_id": "admin-user",
+					"client_id": "admin-client",
+					"action": "client.template.assign",
+					"target": "clients/some-client",
+					"before": {"template": "default"},
+					"after": {"template": "new-template"},
+					"created_at": "2026-10-19T12:00:00Z"
+				}
+			],
+			"total": 3,
+			"limit": 50,
+			"offset": 0
+		}`))
+	})
+
+	It("responds with an empty list when nothing has been audited", func() {
+		request, err := http.NewRequest("GET", "/audit_events", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Body.String()).To(MatchJSON(`{"audit_events": [], "total": 0, "limit": 50, "offset": 0}`))
+	})
+
+	It("filters and pages the audit events", func() {
+		request, err := http.NewRequest("GET", "/audit_events?actor=admin-user&action=notification.update&target=clients%2Fa%2Fnotifications%2Fb&since=2026-10-01T00:00:00Z&until=2026-10-19T00:00:00Z&limit=10&offset=20", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(lister.ListCall.Receives.Filter).To(Equal(models.AuditEventFilter{
+			ActorID: "admin-user",
+			Action:  "notification.update",
+			Target:  "clients/a/notifications/b",
+			Since:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Until:   time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			Limit:   10,
+			Offset:  20,
+		}))
+		Expect(writer.Body.String()).To(MatchJSON(`{"audit_events": [], "total": 0, "limit": 10, "offset": 20}`))
+	})
+
+	Context("when the query parameters are invalid", func() {
+		It("rejects a since that is not a timestamp", func() {
+			request, err := http.NewRequest("GET", "/audit_events?since=yesterday", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`since must be an RFC 3339 timestamp, got "yesterday"`)}))
+			Expect(lister.ListCall.Receives.Database).To(BeNil())
+		})
+
+		It("rejects an until that is not a timestamp", func() {
+			request, err := http.NewRequest("GET", "/audit_events?until=2026-10-19", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`until must be an RFC 3339 timestamp, got "2026-10-19"`)}))
+		})
+
+		It("rejects a limit outside of the allowed range", func() {
+			request, err := http.NewRequest("GET", "/audit_events?limit=501", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`limit must be a number between 1 and 500, got "501"`)}))
+		})
+
+		It("rejects a negative offset", func() {
+			request, err := http.NewRequest("GET", "/audit_events?offset=-1", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`offset must be a number that is not negative, got "-1"`)}))
+		})
+	})
+
+	It("writes errors from the lister", func() {
+		lister.ListCall.Returns.Error = errors.New("database is down")
+
+		request, err := http.NewRequest("GET", "/audit_events", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+})
@@ -0,0 +1,21 @@
+package auditevents
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestCounter               stack.Middleware
+	RequestLogging               stack.Middleware
+	DatabaseAllocator            stack.Middleware
+	AuditEventsReadAuthenticator stack.Middleware
+
+	ErrorWriter      errorWriter
+	AuditEventLister auditEventLister
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/audit_events", NewListHandler(r.AuditEventLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.AuditEventsReadAuthenticator, r.DatabaseAllocator)
+}
@@ -0,0 +1,44 @@
+package auditevents_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/auditevents"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		auditevents.Routes{
+			RequestCounter:               middleware.RequestCounter{},
+			RequestLogging:               middleware.RequestLogging{},
+			DatabaseAllocator:            middleware.DatabaseAllocator{},
+			AuditEventsReadAuthenticator: middleware.Authenticator{Scopes: []string{"audit_events.read"}},
+
+			ErrorWriter:      mocks.NewErrorWriter(),
+			AuditEventLister: mocks.NewAuditLog(),
+		}.Register(muxer)
+	})
+
+	It("routes GET /audit_events", func() {
+		request, err := http.NewRequest("GET", "/audit_events", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(auditevents.ListHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"audit_events.read"}))
+	})
+})
@@ -0,0 +1,62 @@
+package bounces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+)
+
+type errorWriter interface {
+	Write(writer http.ResponseWriter, err error)
+}
+
+type reportProcessor interface {
+	Process(conn models.ConnectionInterface, report bounces.Report, logger lager.Logger) error
+}
+
+// CreateHandler accepts a delivery status notification or feedback report
+// as a raw RFC 822 message, as forwarded by the MTA that receives mail for
+// the bounce address. The report is read in full before it is parsed, so that
+// a report that is too large is rejected as such.
+type CreateHandler struct {
+	processor   reportProcessor
+	errorWriter errorWriter
+}
+
+func NewCreateHandler(processor reportProcessor, errWriter errorWriter) CreateHandler {
+	return CreateHandler{
+		processor:   processor,
+		errorWriter: errWriter,
+	}
+}
+
+func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	report, err := bounces.Parse(bytes.NewReader(body))
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: err})
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+	logger := context.Get("logger").(lager.Logger)
+
+	err = h.processor.Process(database.Connection(), report, logger)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,135 @@
+package bounces_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	postalbounces "github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/bounces"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var deliveryStatusNotification = strings.Replace(`From: MAILER-DAEMON@mail.example.com
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="dsn-boundary"
+
+--dsn-boundary
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mail.example.com
+
+Final-Recipient: rfc822; missing@example.com
+Action: failed
+Status: 5.1.1
+
+--dsn-boundary
+Content-Type: text/rfc822-headers
+
+To: missing@example.com
+X-CF-Notification-ID: message-123
+
+--dsn-boundary--
+`, "\n", "\r\n", -1)
+
+type erroringReader struct {
+	err error
+}
+
+func (r erroringReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+var _ = Describe("CreateHandler", func() {
+	var (
+		handler     bounces.CreateHandler
+		processor   *mocks.BounceProcessor
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		database    *mocks.Database
+		conn        *mocks.Connection
+		logger      lager.Logger
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		processor = mocks.NewBounceProcessor()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("logger", logger)
+
+		handler = bounces.NewCreateHandler(processor, errorWriter)
+	})
+
+	It("processes the report", func() {
+		request, err := http.NewRequest("POST", "/bounces", strings.NewReader(deliveryStatusNotification))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(processor.ProcessCall.Receives.Connection).To(Equal(conn))
+		Expect(processor.ProcessCall.Receives.Logger).To(Equal(logger))
+		Expect(processor.ProcessCall.Receives.Reports).To(Equal([]postalbounces.Report{
+			{
+				Kind:           postalbounces.KindBounce,
+				NotificationID: "message-123",
+				Recipients: []postalbounces.Recipient{
+					{Address: "missing@example.com", Action: "failed", Status: "5.1.1"},
+				},
+			},
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("writes a validation error when the body is not a report", func() {
+			request, err := http.NewRequest("POST", "/bounces", strings.NewReader("From: someone@example.com\r\n\r\nOut of office\r\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(processor.ProcessCall.CallCount).To(Equal(0))
+		})
+
+		It("writes the error when the body cannot be read", func() {
+			request, err := http.NewRequest("POST", "/bounces", ioutil.NopCloser(erroringReader{webutil.RequestTooLargeError{Limit: 10}}))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.RequestTooLargeError{Limit: 10}))
+			Expect(processor.ProcessCall.CallCount).To(Equal(0))
+		})
+
+		It("writes the error when the report cannot be processed", func() {
+			processor.ProcessCall.Returns.Error = errors.New("database is down")
+
+			request, err := http.NewRequest("POST", "/bounces", strings.NewReader(deliveryStatusNotification))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+	})
+})
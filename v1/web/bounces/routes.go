@@ -0,0 +1,22 @@
+package bounces
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestCounter            stack.Middleware
+	RequestLogging            stack.Middleware
+	DatabaseAllocator         stack.Middleware
+	BouncesWriteAuthenticator stack.Middleware
+	BodyLimit                 stack.Middleware
+
+	ErrorWriter     errorWriter
+	BounceProcessor reportProcessor
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("POST", "/bounces", NewCreateHandler(r.BounceProcessor, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.BouncesWriteAuthenticator, r.BodyLimit, r.DatabaseAllocator)
+}
@@ -6,7 +6,10 @@ import (
 	"regexp"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
@@ -18,14 +21,26 @@ type assignsTemplates interface {
 	AssignToClient(connection collections.ConnectionInterface, clientID, templateID string) error
 }
 
+type clientFinder interface {
+	Find(connection models.ConnectionInterface, clientID string) (models.Client, error)
+}
+
+type auditLog interface {
+	Record(conn services.ConnectionInterface, entry services.AuditEntry) error
+}
+
 type AssignTemplateHandler struct {
 	templateAssigner assignsTemplates
+	clientFinder     clientFinder
+	auditLog         auditLog
 	errorWriter      errorWriter
 }
 
-func NewAssignTemplateHandler(assigner assignsTemplates, errWriter errorWriter) AssignTemplateHandler {
+func NewAssignTemplateHandler(assigner assignsTemplates, finder clientFinder, auditLog auditLog, errWriter errorWriter) AssignTemplateHandler {
 	return AssignTemplateHandler{
 		templateAssigner: assigner,
+		clientFinder:     finder,
+		auditLog:         auditLog,
 		errorWriter:      errWriter,
 	}
 }
@@ -46,11 +61,49 @@ func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 	}
 
 	database := context.Get("database").(DatabaseInterface)
-	err = h.templateAssigner.AssignToClient(database.Connection(), clientID, templateAssignment.Template)
+	transaction := database.Connection().Transaction()
+	err = transaction.Begin()
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
 	}
 
+	client, err := h.clientFinder.Find(transaction, clientID)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.templateAssigner.AssignToClient(transaction, clientID, templateAssignment.Template)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	if templateAssignment.Template == "" {
+		templateAssignment.Template = models.DefaultTemplateID
+	}
+
+	err = h.auditLog.Record(transaction, services.AuditEntry{
+		Token:  context.Get("token").(*jwt.Token),
+		Action: services.AuditActionAssignClientTemplate,
+		Target: "clients/" + clientID,
+		Before: TemplateAssignment{Template: client.TemplateToUse()},
+		After:  templateAssignment,
+	})
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
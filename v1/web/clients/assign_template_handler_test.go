@@ -8,8 +8,11 @@ import (
 	"net/http/httptest"
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -20,22 +23,34 @@ var _ = Describe("AssignTemplateHandler", func() {
 	var (
 		handler          clients.AssignTemplateHandler
 		templateAssigner *mocks.TemplateAssigner
+		clientFinder     *mocks.ClientsRepository
+		auditLog         *mocks.AuditLog
+		token            *jwt.Token
 		errorWriter      *mocks.ErrorWriter
 		context          stack.Context
 		database         *mocks.Database
 		connection       *mocks.Connection
+		transaction      *mocks.Transaction
 	)
 
 	BeforeEach(func() {
 		templateAssigner = mocks.NewTemplateAssigner()
+		clientFinder = mocks.NewClientsRepository()
+		clientFinder.FindCall.Returns.Client = models.Client{ID: "my-client", TemplateID: "old-template"}
+		auditLog = mocks.NewAuditLog()
+		token = &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}}
 		errorWriter = mocks.NewErrorWriter()
 		connection = mocks.NewConnection()
+		transaction = mocks.NewTransaction()
+		transaction.Connection = connection
+		connection.TransactionCall.Returns.Transaction = transaction
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = connection
 		context = stack.NewContext()
 		context.Set("database", database)
+		context.Set("token", token)
 
-		handler = clients.NewAssignTemplateHandler(templateAssigner, errorWriter)
+		handler = clients.NewAssignTemplateHandler(templateAssigner, clientFinder, auditLog, errorWriter)
 	})
 
 	It("associates a template with a client", func() {
@@ -51,11 +66,88 @@ var _ = Describe("AssignTemplateHandler", func() {
 		handler.ServeHTTP(w, request, context)
 
 		Expect(w.Code).To(Equal(http.StatusNoContent))
-		Expect(templateAssigner.AssignToClientCall.Receives.Connection).To(Equal(connection))
+		Expect(templateAssigner.AssignToClientCall.Receives.Connection).To(Equal(transaction))
 		Expect(templateAssigner.AssignToClientCall.Receives.ClientID).To(Equal("my-client"))
 		Expect(templateAssigner.AssignToClientCall.Receives.TemplateID).To(Equal("my-template"))
 	})
 
+	It("records the assignment in the audit log in the same transaction", func() {
+		body, err := json.Marshal(map[string]string{
+			"template": "my-template",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(clientFinder.FindCall.Receives.Connection).To(Equal(transaction))
+		Expect(clientFinder.FindCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(auditLog.RecordCall.Receives.Connection).To(Equal(transaction))
+		Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		Expect(auditLog.RecordCall.Receives.Entry).To(Equal(services.AuditEntry{
+			Token:  token,
+			Action: "client.template.assign",
+			Target: "clients/my-client",
+			Before: clients.TemplateAssignment{Template: "old-template"},
+			After:  clients.TemplateAssignment{Template: "my-template"},
+		}))
+	})
+
+	It("records an assignment of the default template when no template is given", func() {
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBufferString(`{"template": ""}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(auditLog.RecordCall.Receives.Entry.After).To(Equal(clients.TemplateAssignment{Template: models.DefaultTemplateID}))
+	})
+
+	It("delegates to the error writer when the client cannot be found", func() {
+		clientFinder.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("client not found")}
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBufferString(`{"template": "my-template"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("client not found")}))
+		Expect(templateAssigner.AssignToClientCall.Receives.ClientID).To(BeEmpty())
+		Expect(auditLog.RecordCall.CallCount).To(Equal(0))
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+	})
+
+	It("rolls the assignment back when the audit log errors", func() {
+		auditLog.RecordCall.Returns.Error = errors.New("audit log is down")
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBufferString(`{"template": "my-template"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("audit log is down")))
+		Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+	})
+
+	It("delegates to the error writer when the transaction cannot be committed", func() {
+		transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBufferString(`{"template": "my-template"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+	})
+
 	It("delegates to the error writer when the assigner errors", func() {
 		templateAssigner.AssignToClientCall.Returns.Error = errors.New("banana")
 		body, err := json.Marshal(map[string]string{
@@ -69,6 +161,8 @@ var _ = Describe("AssignTemplateHandler", func() {
 
 		handler.ServeHTTP(w, request, context)
 		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("banana")))
+		Expect(auditLog.RecordCall.CallCount).To(Equal(0))
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
 	})
 
 	It("writes a ParseError to the error writer when request body is invalid", func() {
@@ -14,8 +14,12 @@ type Routes struct {
 
 	ErrorWriter      errorWriter
 	TemplateAssigner assignsTemplates
+	ClientFinder     clientFinder
+	AuditLog         auditLog
+	LimitsUpdater    updatesLimits
 }
 
 func (r Routes) Register(m muxer) {
-	m.Handle("PUT", "/clients/{client_id}/template", NewAssignTemplateHandler(r.TemplateAssigner, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/clients/{client_id}/template", NewAssignTemplateHandler(r.TemplateAssigner, r.ClientFinder, r.AuditLog, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/clients/{client_id}/limits", NewSetLimitsHandler(r.LimitsUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
 }
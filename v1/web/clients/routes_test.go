@@ -27,6 +27,9 @@ var _ = Describe("Routes", func() {
 
 			ErrorWriter:      mocks.NewErrorWriter(),
 			TemplateAssigner: mocks.NewTemplateAssigner(),
+			ClientFinder:     mocks.NewClientsRepository(),
+			AuditLog:         mocks.NewAuditLog(),
+			LimitsUpdater:    mocks.NewClientLimitsUpdater(),
 		}.Register(muxer)
 	})
 
@@ -41,4 +44,16 @@ var _ = Describe("Routes", func() {
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
 	})
+
+	It("routes PUT /clients/{client_id}/limits", func() {
+		request, err := http.NewRequest("PUT", "/clients/some-client-id/limits", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(clients.SetLimitsHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+	})
 })
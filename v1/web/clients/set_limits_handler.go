@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type updatesLimits interface {
+	Update(database services.DatabaseInterface, clientID string, limits services.ClientLimits) error
+}
+
+type SetLimitsHandler struct {
+	limitsUpdater updatesLimits
+	errorWriter   errorWriter
+}
+
+func NewSetLimitsHandler(updater updatesLimits, errWriter errorWriter) SetLimitsHandler {
+	return SetLimitsHandler{
+		limitsUpdater: updater,
+		errorWriter:   errWriter,
+	}
+}
+
+type Limits struct {
+	RateLimit           int `json:"rate_limit"`
+	RateLimitBurst      int `json:"rate_limit_burst"`
+	DailyRecipientQuota int `json:"daily_recipient_quota"`
+}
+
+func (h SetLimitsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	routeRegex := regexp.MustCompile("/clients/(.*)/limits")
+	clientID := routeRegex.FindStringSubmatch(req.URL.Path)[1]
+
+	var limits Limits
+	err := json.NewDecoder(req.Body).Decode(&limits)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	if limits.RateLimit < 0 || limits.RateLimitBurst < 0 || limits.DailyRecipientQuota < 0 {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New("Limits cannot be negative")})
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+	err = h.limitsUpdater.Update(database, clientID, services.ClientLimits{
+		RateLimit:           limits.RateLimit,
+		RateLimitBurst:      limits.RateLimitBurst,
+		DailyRecipientQuota: limits.DailyRecipientQuota,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package clients_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetLimitsHandler", func() {
+	var (
+		handler       clients.SetLimitsHandler
+		limitsUpdater *mocks.ClientLimitsUpdater
+		errorWriter   *mocks.ErrorWriter
+		context       stack.Context
+		database      *mocks.Database
+	)
+
+	BeforeEach(func() {
+		limitsUpdater = mocks.NewClientLimitsUpdater()
+		errorWriter = mocks.NewErrorWriter()
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = clients.NewSetLimitsHandler(limitsUpdater, errorWriter)
+	})
+
+	It("sets the limits of the client", func() {
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/limits", bytes.NewBufferString(`{
+			"rate_limit": 60,
+			"rate_limit_burst": 10,
+			"daily_recipient_quota": 5000
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(limitsUpdater.UpdateCall.Receives.Database).To(Equal(database))
+		Expect(limitsUpdater.UpdateCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(limitsUpdater.UpdateCall.Receives.Limits).To(Equal(services.ClientLimits{
+			RateLimit:           60,
+			RateLimitBurst:      10,
+			DailyRecipientQuota: 5000,
+		}))
+	})
+
+	It("writes a ValidationError to the error writer when a limit is negative", func() {
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/limits", bytes.NewBufferString(`{"rate_limit": -1}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ValidationError{Err: errors.New("Limits cannot be negative")}))
+	})
+
+	It("delegates to the error writer when the updater errors", func() {
+		limitsUpdater.UpdateCall.Returns.Error = errors.New("banana")
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/limits", bytes.NewBufferString(`{"rate_limit": 60}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("banana")))
+	})
+
+	It("writes a ParseError to the error writer when request body is invalid", func() {
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/limits", bytes.NewBufferString(`{ "this is" : not-valid-json }`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ParseError{}))
+	})
+})
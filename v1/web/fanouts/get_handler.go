@@ -0,0 +1,66 @@
+package fanouts
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/ryanmoran/stack"
+)
+
+type GetHandler struct {
+	finder      fanOutFinder
+	errorWriter errorWriter
+}
+
+type errorWriter interface {
+	Write(writer http.ResponseWriter, err error)
+}
+
+type fanOutFinder interface {
+	FindByID(models.ConnectionInterface, string) (models.FanOut, error)
+}
+
+func NewGetHandler(finder fanOutFinder, errWriter errorWriter) GetHandler {
+	return GetHandler{
+		finder:      finder,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	fanOutID := strings.Split(req.URL.Path, "/fanouts/")[1]
+
+	fanOut, err := h.finder.FindByID(context.Get("database").(DatabaseInterface).Connection(), fanOutID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	document := struct {
+		ID        string    `json:"id"`
+		Status    string    `json:"status"`
+		Total     int       `json:"total"`
+		Enqueued  int       `json:"enqueued"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}{
+		ID:        fanOut.ID,
+		Status:    fanOut.Status,
+		Total:     fanOut.Total,
+		Enqueued:  fanOut.Enqueued,
+		CreatedAt: fanOut.CreatedAt,
+		UpdatedAt: fanOut.UpdatedAt,
+	}
+
+	output, err := json.Marshal(document)
+	if err != nil {
+		panic(err) // No JSON we write into a response should ever panic
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
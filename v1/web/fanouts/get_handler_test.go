@@ -0,0 +1,82 @@
+package fanouts_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/fanouts"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetHandler", func() {
+	var (
+		handler     fanouts.GetHandler
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		fanOutsRepo *mocks.FanOutsRepo
+		conn        *mocks.Connection
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		errorWriter = mocks.NewErrorWriter()
+		fanOutsRepo = mocks.NewFanOutsRepo()
+		writer = httptest.NewRecorder()
+
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		var err error
+		request, err = http.NewRequest("GET", "/fanouts/some-fanout-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = fanouts.NewGetHandler(fanOutsRepo, errorWriter)
+	})
+
+	It("returns the progress of the fan-out", func() {
+		createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+		fanOutsRepo.FindByIDCall.Returns.FanOut = models.FanOut{
+			ID:        "some-fanout-id",
+			ClientID:  "some-client",
+			Status:    models.FanOutStatusInProgress,
+			Total:     250,
+			Enqueued:  100,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt.Add(2 * time.Second),
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.Bytes()).To(MatchJSON(`{
+			"id": "some-fanout-id",
+			"status": "in_progress",
+			"total": 250,
+			"enqueued": 100,
+			"created_at": "2026-10-19T12:00:00Z",
+			"updated_at": "2026-10-19T12:00:02Z"
+		}`))
+
+		Expect(fanOutsRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+		Expect(fanOutsRepo.FindByIDCall.Receives.FanOutID).To(Equal("some-fanout-id"))
+	})
+
+	It("delegates to the error writer when the fan-out cannot be found", func() {
+		findError := models.NotFoundError{Err: errors.New("Fan-out with ID \"some-fanout-id\" could not be found")}
+		fanOutsRepo.FindByIDCall.Returns.Error = findError
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(findError))
+	})
+})
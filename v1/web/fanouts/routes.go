@@ -0,0 +1,21 @@
+package fanouts
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestCounter                  stack.Middleware
+	RequestLogging                  stack.Middleware
+	NotificationsWriteAuthenticator stack.Middleware
+	DatabaseAllocator               stack.Middleware
+
+	FanOutFinder fanOutFinder
+	ErrorWriter  errorWriter
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/fanouts/{fanout_id}", NewGetHandler(r.FanOutFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
+}
@@ -0,0 +1,44 @@
+package fanouts_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/fanouts"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		fanouts.Routes{
+			RequestCounter:                  middleware.RequestCounter{},
+			RequestLogging:                  middleware.RequestLogging{},
+			DatabaseAllocator:               middleware.DatabaseAllocator{},
+			NotificationsWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.write"}},
+
+			ErrorWriter:  mocks.NewErrorWriter(),
+			FanOutFinder: mocks.NewFanOutsRepo(),
+		}.Register(muxer)
+	})
+
+	It("routes GET /fanouts/{fanout_id}", func() {
+		request, err := http.NewRequest("GET", "/fanouts/some-fanout-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(fanouts.GetHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.write"}))
+	})
+})
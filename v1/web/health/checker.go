@@ -0,0 +1,85 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+type Check interface {
+	Check() error
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type result struct {
+	err       error
+	checkedAt time.Time
+}
+
+// Checker runs a set of named checks and remembers their results for the
+// cache duration, so that frequent probes do not put load on the
+// dependencies being checked.
+type Checker struct {
+	checks        map[string]Check
+	cacheDuration time.Duration
+	clock         clock
+
+	mutex   *sync.Mutex
+	results map[string]result
+}
+
+func NewChecker(checks map[string]Check, cacheDuration time.Duration, clock clock) Checker {
+	return Checker{
+		checks:        checks,
+		cacheDuration: cacheDuration,
+		clock:         clock,
+		mutex:         &sync.Mutex{},
+		results:       map[string]result{},
+	}
+}
+
+// Run returns the result of each check, running those whose cached result
+// has expired concurrently.
+func (c Checker) Run() map[string]error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.clock.Now()
+
+	var (
+		wg         sync.WaitGroup
+		freshMutex sync.Mutex
+	)
+	fresh := map[string]error{}
+
+	for name, check := range c.checks {
+		if r, ok := c.results[name]; ok && now.Sub(r.checkedAt) < c.cacheDuration {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			err := check.Check()
+
+			freshMutex.Lock()
+			defer freshMutex.Unlock()
+			fresh[name] = err
+		}(name, check)
+	}
+	wg.Wait()
+
+	for name, err := range fresh {
+		c.results[name] = result{err: err, checkedAt: now}
+	}
+
+	results := map[string]error{}
+	for name, r := range c.results {
+		results[name] = r.err
+	}
+
+	return results
+}
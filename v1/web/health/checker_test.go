@@ -0,0 +1,64 @@
+package health_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		checker       health.Checker
+		databaseCheck *mocks.HealthCheck
+		smtpCheck     *mocks.HealthCheck
+		clock         *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		databaseCheck = mocks.NewHealthCheck()
+		smtpCheck = mocks.NewHealthCheck()
+		smtpCheck.CheckCall.Returns.Error = errors.New("server timeout")
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		checker = health.NewChecker(map[string]health.Check{
+			"database": databaseCheck,
+			"smtp":     smtpCheck,
+		}, 10*time.Second, clock)
+	})
+
+	It("returns the result of each check", func() {
+		Expect(checker.Run()).To(Equal(map[string]error{
+			"database": nil,
+			"smtp":     errors.New("server timeout"),
+		}))
+	})
+
+	It("remembers the results for the cache duration", func() {
+		checker.Run()
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(9 * time.Second)
+		smtpCheck.CheckCall.Returns.Error = nil
+
+		Expect(checker.Run()).To(HaveKeyWithValue("smtp", errors.New("server timeout")))
+		Expect(databaseCheck.CheckCall.CallCount).To(Equal(1))
+		Expect(smtpCheck.CheckCall.CallCount).To(Equal(1))
+	})
+
+	It("runs the checks again once the results have expired", func() {
+		checker.Run()
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(10 * time.Second)
+		smtpCheck.CheckCall.Returns.Error = nil
+
+		Expect(checker.Run()).To(HaveKeyWithValue("smtp", BeNil()))
+		Expect(databaseCheck.CheckCall.CallCount).To(Equal(2))
+		Expect(smtpCheck.CheckCall.CallCount).To(Equal(2))
+	})
+})
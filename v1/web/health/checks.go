@@ -0,0 +1,149 @@
+package health
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+type pinger interface {
+	Ping() error
+}
+
+type DatabaseCheck struct {
+	db pinger
+}
+
+func NewDatabaseCheck(db pinger) DatabaseCheck {
+	return DatabaseCheck{
+		db: db,
+	}
+}
+
+func (c DatabaseCheck) Check() error {
+	return c.db.Ping()
+}
+
+type queueLengther interface {
+	Len() (int, error)
+}
+
+// QueueCheck fails when more jobs are waiting in the queue than the
+// threshold, which suggests that the workers cannot keep up.
+type QueueCheck struct {
+	queue     queueLengther
+	threshold int
+}
+
+func NewQueueCheck(queue queueLengther, threshold int) QueueCheck {
+	return QueueCheck{
+		queue:     queue,
+		threshold: threshold,
+	}
+}
+
+func (c QueueCheck) Check() error {
+	length, err := c.queue.Len()
+	if err != nil {
+		return err
+	}
+
+	if length > c.threshold {
+		return fmt.Errorf("queue has %d jobs, more than the threshold of %d", length, c.threshold)
+	}
+
+	return nil
+}
+
+type keysLoader interface {
+	KeysLoadedAt() time.Time
+}
+
+// SigningKeysCheck fails when the UAA signing keys have not been loaded
+// successfully within the maximum age.
+type SigningKeysCheck struct {
+	keys   keysLoader
+	maxAge time.Duration
+	clock  clock
+}
+
+func NewSigningKeysCheck(keys keysLoader, maxAge time.Duration, clock clock) SigningKeysCheck {
+	return SigningKeysCheck{
+		keys:   keys,
+		maxAge: maxAge,
+		clock:  clock,
+	}
+}
+
+func (c SigningKeysCheck) Check() error {
+	loadedAt := c.keys.KeysLoadedAt()
+	if loadedAt.IsZero() {
+		return fmt.Errorf("UAA signing keys have not been loaded")
+	}
+
+	age := c.clock.Now().Sub(loadedAt)
+	if age > c.maxAge {
+		return fmt.Errorf("UAA signing keys were last loaded %s ago", age)
+	}
+
+	return nil
+}
+
+type smtpVerifier interface {
+	Verify(lager.Logger) error
+}
+
+type SMTPCheck struct {
+	client smtpVerifier
+	logger lager.Logger
+}
+
+func NewSMTPCheck(client smtpVerifier, logger lager.Logger) SMTPCheck {
+	return SMTPCheck{
+		client: client,
+		logger: logger,
+	}
+}
+
+func (c SMTPCheck) Check() error {
+	return c.client.Verify(c.logger)
+}
+
+// CloudControllerCheck fails when the Cloud Controller info endpoint cannot
+// be reached.
+type CloudControllerCheck struct {
+	url    string
+	client *http.Client
+}
+
+func NewCloudControllerCheck(host string, skipVerifySSL bool) CloudControllerCheck {
+	return CloudControllerCheck{
+		url: strings.TrimSuffix(host, "/") + "/v2/info",
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: skipVerifySSL,
+				},
+			},
+		},
+	}
+}
+
+func (c CloudControllerCheck) Check() error {
+	response, err := c.client.Get(c.url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("Cloud Controller responded with %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package health_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DatabaseCheck", func() {
+	It("pings the database", func() {
+		pinger := mocks.NewPinger()
+		check := health.NewDatabaseCheck(pinger)
+
+		Expect(check.Check()).To(Succeed())
+		Expect(pinger.PingCall.CallCount).To(Equal(1))
+
+		pinger.PingCall.Returns.Error = errors.New("connection refused")
+		Expect(check.Check()).To(MatchError("connection refused"))
+	})
+})
+
+var _ = Describe("QueueCheck", func() {
+	var (
+		queue *mocks.Queue
+		check health.QueueCheck
+	)
+
+	BeforeEach(func() {
+		queue = mocks.NewQueue()
+		check = health.NewQueueCheck(queue, 100)
+	})
+
+	It("passes while the queue is within the threshold", func() {
+		queue.LenCall.Returns.Length = 100
+
+		Expect(check.Check()).To(Succeed())
+	})
+
+	It("fails when the queue is deeper than the threshold", func() {
+		queue.LenCall.Returns.Length = 101
+
+		Expect(check.Check()).To(MatchError("queue has 101 jobs, more than the threshold of 100"))
+	})
+
+	It("fails when the queue cannot be measured", func() {
+		queue.LenCall.Returns.Error = errors.New("database is down")
+
+		Expect(check.Check()).To(MatchError("database is down"))
+	})
+})
+
+var _ = Describe("SigningKeysCheck", func() {
+	var (
+		keys  *mocks.KeysLoader
+		clock *mocks.Clock
+		check health.SigningKeysCheck
+	)
+
+	BeforeEach(func() {
+		keys = mocks.NewKeysLoader()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		check = health.NewSigningKeysCheck(keys, 2*time.Minute, clock)
+	})
+
+	It("passes when the keys were loaded recently", func() {
+		keys.KeysLoadedAtCall.Returns.Time = clock.NowCall.Returns.Time.Add(-2 * time.Minute)
+
+		Expect(check.Check()).To(Succeed())
+	})
+
+	It("fails when the keys are stale", func() {
+		keys.KeysLoadedAtCall.Returns.Time = clock.NowCall.Returns.Time.Add(-3 * time.Minute)
+
+		Expect(check.Check()).To(MatchError("UAA signing keys were last loaded 3m0s ago"))
+	})
+
+	It("fails when the keys were never loaded", func() {
+		Expect(check.Check()).To(MatchError("UAA signing keys have not been loaded"))
+	})
+})
+
+var _ = Describe("SMTPCheck", func() {
+	It("verifies the SMTP configuration", func() {
+		verifier := mocks.NewSMTPVerifier()
+		logger := lager.NewLogger("notifications")
+		check := health.NewSMTPCheck(verifier, logger)
+
+		Expect(check.Check()).To(Succeed())
+		Expect(verifier.VerifyCall.Receives.Logger).To(Equal(logger))
+
+		verifier.VerifyCall.Returns.Error = errors.New("server timeout")
+		Expect(check.Check()).To(MatchError("server timeout"))
+	})
+})
+
+var _ = Describe("CloudControllerCheck", func() {
+	var (
+		server *httptest.Server
+		status int
+		path   string
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+			w.WriteHeader(status)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("requests the Cloud Controller info", func() {
+		check := health.NewCloudControllerCheck(server.URL, false)
+
+		Expect(check.Check()).To(Succeed())
+		Expect(path).To(Equal("/v2/info"))
+	})
+
+	It("fails when the Cloud Controller responds with an error", func() {
+		status = http.StatusBadGateway
+		check := health.NewCloudControllerCheck(server.URL, false)
+
+		Expect(check.Check()).To(MatchError("Cloud Controller responded with 502"))
+	})
+})
@@ -0,0 +1,21 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/ryanmoran/stack"
+)
+
+// LiveHandler reports that the process is up and serving requests, without
+// checking any of its dependencies.
+type LiveHandler struct{}
+
+func NewLiveHandler() LiveHandler {
+	return LiveHandler{}
+}
+
+func (h LiveHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"ok"}`))
+}
@@ -0,0 +1,24 @@
+package health_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LiveHandler", func() {
+	It("returns a 200 response code", func() {
+		writer := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/health/live", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		health.NewLiveHandler().ServeHTTP(writer, request, nil)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"status": "ok"}`))
+	})
+})
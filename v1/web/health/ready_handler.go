@@ -0,0 +1,59 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ryanmoran/stack"
+)
+
+type checkRunner interface {
+	Run() map[string]error
+}
+
+type checkStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ReadyHandler reports the status of each dependency, responding with 503
+// Service Unavailable when any of them is failing.
+type ReadyHandler struct {
+	checker checkRunner
+}
+
+func NewReadyHandler(checker checkRunner) ReadyHandler {
+	return ReadyHandler{
+		checker: checker,
+	}
+}
+
+func (h ReadyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	status := "ok"
+	code := http.StatusOK
+	checks := map[string]checkStatus{}
+
+	for name, err := range h.checker.Run() {
+		if err != nil {
+			status = "unavailable"
+			code = http.StatusServiceUnavailable
+			checks[name] = checkStatus{Status: "failing", Error: err.Error()}
+			continue
+		}
+
+		checks[name] = checkStatus{Status: "ok"}
+	}
+
+	output, err := json.Marshal(map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(output)
+}
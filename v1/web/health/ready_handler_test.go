@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadyHandler", func() {
+	var (
+		handler health.ReadyHandler
+		checker *mocks.HealthChecker
+		writer  *httptest.ResponseRecorder
+		request *http.Request
+	)
+
+	BeforeEach(func() {
+		checker = mocks.NewHealthChecker()
+		handler = health.NewReadyHandler(checker)
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/health/ready", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns a 200 response code when every check passes", func() {
+		checker.RunCall.Returns.Results = map[string]error{
+			"database": nil,
+			"smtp":     nil,
+		}
+
+		handler.ServeHTTP(writer, request, nil)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"status": "ok",
+			"checks": {
+				"database": {"status": "ok"},
+				"smtp": {"status": "ok"}
+			}
+		}`))
+	})
+
+	It("returns a 503 response code when a check fails", func() {
+		checker.RunCall.Returns.Results = map[string]error{
+			"database": nil,
+			"smtp":     errors.New("server timeout"),
+		}
+
+		handler.ServeHTTP(writer, request, nil)
+
+		Expect(writer.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"status": "unavailable",
+			"checks": {
+				"database": {"status": "ok"},
+				"smtp": {"status": "failing", "error": "server timeout"}
+			}
+		}`))
+	})
+})
@@ -0,0 +1,20 @@
+package health
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestCounter stack.Middleware
+
+	Checker checkRunner
+}
+
+// Register leaves out request logging, as the endpoints are polled
+// frequently by health probes.
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/health/live", NewLiveHandler(), r.RequestCounter)
+	m.Handle("GET", "/health/ready", NewReadyHandler(r.Checker), r.RequestCounter)
+}
@@ -0,0 +1,43 @@
+package health_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		health.Routes{
+			RequestCounter: middleware.RequestCounter{},
+		}.Register(muxer)
+	})
+
+	It("routes GET /health/live", func() {
+		request, err := http.NewRequest("GET", "/health/live", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(health.LiveHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestCounter{})
+	})
+
+	It("routes GET /health/ready", func() {
+		request, err := http.NewRequest("GET", "/health/ready", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(health.ReadyHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestCounter{})
+	})
+})
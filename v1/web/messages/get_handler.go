@@ -38,11 +38,26 @@ func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		return
 	}
 
+	type recipient struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Status  string `json:"status"`
+	}
+
 	var document struct {
-		Status string `json:"status"`
+		Status     string      `json:"status"`
+		Recipients []recipient `json:"recipients,omitempty"`
 	}
 	document.Status = message.Status
 
+	for _, r := range message.Recipients {
+		document.Recipients = append(document.Recipients, recipient{
+			Address: r.Address,
+			Type:    r.Type,
+			Status:  r.Status,
+		})
+	}
+
 	writeJSON(w, http.StatusOK, document)
 }
 
@@ -61,6 +61,27 @@ var _ = Describe("GetHandler", func() {
 			Expect(messageFinder.FindCall.Receives.MessageID).To(Equal(messageID))
 		})
 
+		It("includes the status of each recipient when the message has several", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status: "delivered",
+				Recipients: []services.MessageRecipient{
+					{Address: "to@example.com", Type: "to", Status: "delivered"},
+					{Address: "cc@example.com", Type: "cc", Status: "failed"},
+				},
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "delivered",
+				"recipients": [
+					{"address": "to@example.com", "type": "to", "status": "delivered"},
+					{"address": "cc@example.com", "type": "cc", "status": "failed"}
+				]
+			}`))
+		})
+
 		Context("When the finder errors", func() {
 			It("Delegates to the error writer", func() {
 				findError := errors.New("The finder returns a generic error")
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+// BodyLimit rejects requests whose body is larger than the limit. Bodies that
+// do not announce their length are cut off once they pass it, and reading
+// further returns a webutil.RequestTooLargeError.
+type BodyLimit struct {
+	Limit       int64
+	errorWriter errorWriter
+}
+
+func NewBodyLimit(limit int64, errorWriter errorWriter) BodyLimit {
+	return BodyLimit{
+		Limit:       limit,
+		errorWriter: errorWriter,
+	}
+}
+
+func (ware BodyLimit) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	if req.ContentLength > ware.Limit {
+		ware.errorWriter.Write(w, webutil.RequestTooLargeError{Limit: ware.Limit})
+		return false
+	}
+
+	req.Body = &limitedBody{
+		ReadCloser: http.MaxBytesReader(w, req.Body, ware.Limit),
+		limit:      ware.Limit,
+	}
+
+	return true
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (body *limitedBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	body.read += int64(n)
+
+	if err != nil && err != io.EOF && body.read >= body.limit {
+		return n, webutil.RequestTooLargeError{Limit: body.limit}
+	}
+
+	return n, err
+}
@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BodyLimit", func() {
+	var (
+		writer      *httptest.ResponseRecorder
+		errorWriter *mocks.ErrorWriter
+		ware        middleware.BodyLimit
+	)
+
+	BeforeEach(func() {
+		writer = httptest.NewRecorder()
+		errorWriter = mocks.NewErrorWriter()
+		ware = middleware.NewBodyLimit(10, errorWriter)
+	})
+
+	It("lets bodies up to the limit through", func() {
+		request, err := http.NewRequest("POST", "/emails", strings.NewReader("0123456789"))
+		Expect(err).NotTo(HaveOccurred())
+
+		result := ware.ServeHTTP(writer, request, nil)
+		Expect(result).To(BeTrue())
+
+		body, err := ioutil.ReadAll(request.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("0123456789"))
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+	})
+
+	It("rejects bodies that announce a length over the limit", func() {
+		request, err := http.NewRequest("POST", "/emails", strings.NewReader("0123456789A"))
+		Expect(err).NotTo(HaveOccurred())
+
+		result := ware.ServeHTTP(writer, request, nil)
+		Expect(result).To(BeFalse())
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.RequestTooLargeError{Limit: 10}))
+	})
+
+	It("cuts off bodies of unknown length once they pass the limit", func() {
+		request, err := http.NewRequest("POST", "/emails", ioutil.NopCloser(strings.NewReader("0123456789A")))
+		Expect(err).NotTo(HaveOccurred())
+		request.ContentLength = -1
+
+		result := ware.ServeHTTP(writer, request, nil)
+		Expect(result).To(BeTrue())
+
+		_, err = ioutil.ReadAll(request.Body)
+		Expect(err).To(Equal(webutil.RequestTooLargeError{Limit: 10}))
+	})
+})
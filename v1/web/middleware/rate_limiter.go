@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type clientFinder interface {
+	Find(models.ConnectionInterface, string) (models.Client, error)
+}
+
+type errorWriter interface {
+	Write(http.ResponseWriter, error)
+}
+
+// RateLimiter enforces the per-minute request limit configured on each client
+// with a token bucket. Buckets are kept in memory, so every instance of the
+// service enforces the limit on its own.
+type RateLimiter struct {
+	clients     clientFinder
+	errorWriter errorWriter
+	clock       clock
+
+	mutex   *sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func NewRateLimiter(clients clientFinder, errorWriter errorWriter, clock clock) RateLimiter {
+	return RateLimiter{
+		clients:     clients,
+		errorWriter: errorWriter,
+		clock:       clock,
+		mutex:       &sync.Mutex{},
+		buckets:     map[string]*tokenBucket{},
+	}
+}
+
+func (ware RateLimiter) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	clientID, _ := context.Get("client_id").(string)
+	database := context.Get("database").(models.DatabaseInterface)
+
+	client, err := ware.clients.Find(database.Connection(), clientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return true
+		}
+
+		ware.errorWriter.Write(w, err)
+		return false
+	}
+
+	if client.RateLimit <= 0 {
+		return true
+	}
+
+	wait := ware.take(client)
+	if wait > 0 {
+		ware.errorWriter.Write(w, webutil.TooManyRequestsError{
+			Err:        fmt.Errorf("Client %q has exceeded its rate limit of %d requests per minute", client.ID, client.RateLimit),
+			RetryAfter: wait,
+		})
+		return false
+	}
+
+	return true
+}
+
+// take removes a token from the client's bucket, returning how long the
+// client must wait for one if the bucket is empty.
+func (ware RateLimiter) take(client models.Client) time.Duration {
+	ware.mutex.Lock()
+	defer ware.mutex.Unlock()
+
+	capacity := float64(client.RateLimitBurst)
+	if capacity <= 0 {
+		capacity = float64(client.RateLimit)
+	}
+	perSecond := float64(client.RateLimit) / 60
+
+	now := ware.clock.Now()
+	bucket, ok := ware.buckets[client.ID]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, refilledAt: now}
+		ware.buckets[client.ID] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.refilledAt).Seconds() * perSecond
+	if bucket.tokens > capacity {
+		bucket.tokens = capacity
+	}
+	bucket.refilledAt = now
+
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return 0
+}
+
+type tokenBucket struct {
+	tokens     float64
+	refilledAt time.Time
+}
@@ -0,0 +1,123 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		ware        middleware.RateLimiter
+		clientsRepo *mocks.ClientsRepository
+		errorWriter *mocks.ErrorWriter
+		clock       *mocks.Clock
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		clientsRepo = mocks.NewClientsRepository()
+		clientsRepo.FindCall.Returns.Client = models.Client{
+			ID:             "some-client",
+			RateLimit:      60,
+			RateLimitBurst: 2,
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		writer = httptest.NewRecorder()
+		request = &http.Request{}
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client")
+		context.Set("database", database)
+
+		ware = middleware.NewRateLimiter(clientsRepo, errorWriter, clock)
+	})
+
+	It("lets requests through until the burst is used up", func() {
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+
+		Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(connection))
+		Expect(clientsRepo.FindCall.Receives.ClientID).To(Equal("some-client"))
+
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.TooManyRequestsError{
+			Err:        errors.New(`Client "some-client" has exceeded its rate limit of 60 requests per minute`),
+			RetryAfter: time.Second,
+		}))
+	})
+
+	It("refills the bucket at the configured rate", func() {
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+
+		clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(time.Second)
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+	})
+
+	It("uses the rate as the burst when no burst is configured", func() {
+		clientsRepo.FindCall.Returns.Client.RateLimit = 3
+		clientsRepo.FindCall.Returns.Client.RateLimitBurst = 0
+
+		for i := 0; i < 3; i++ {
+			Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		}
+
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(errorWriter.WriteCall.Receives.Error.(webutil.TooManyRequestsError).RetryAfter).To(Equal(20 * time.Second))
+	})
+
+	It("keeps a separate bucket for each client", func() {
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+
+		clientsRepo.FindCall.Returns.Client.ID = "other-client"
+		context.Set("client_id", "other-client")
+
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+	})
+
+	It("does not limit clients without a rate limit", func() {
+		clientsRepo.FindCall.Returns.Client.RateLimit = 0
+
+		for i := 0; i < 10; i++ {
+			Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		}
+	})
+
+	It("does not limit clients that have not been registered yet", func() {
+		clientsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+	})
+
+	It("writes errors from looking up the client", func() {
+		clientsRepo.FindCall.Returns.Error = errors.New("database is down")
+
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+	})
+})
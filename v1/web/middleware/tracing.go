@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+	"github.com/gorilla/mux"
+	"github.com/ryanmoran/stack"
+)
+
+const TraceSpan = "trace_span"
+
+type spanStarter interface {
+	Start(name, kind string, parent tracing.SpanContext) *tracing.Span
+}
+
+// Tracing records a server span for every request, continuing the trace of
+// the caller when the request carries a traceparent header.
+//
+// The middleware stack does not hand control back once the handler has run,
+// so the span is started and ended by Wrap, around the whole router. As a
+// stack middleware, Tracing only makes that span available to the handler.
+type Tracing struct {
+	tracer  spanStarter
+	matcher routeMatcher
+}
+
+func NewTracing(tracer spanStarter, matcher routeMatcher) Tracing {
+	return Tracing{
+		tracer:  tracer,
+		matcher: matcher,
+	}
+}
+
+func (ware Tracing) Wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		parent, _ := tracing.ParseTraceParent(req.Header.Get("traceparent"))
+
+		span := ware.tracer.Start(ware.spanName(req), tracing.SpanKindServer, parent)
+		span.SetAttribute("http.method", req.Method)
+		span.SetAttribute("http.target", req.URL.Path)
+		if requestID := req.Header.Get("X-Vcap-Request-Id"); requestID != "" {
+			span.SetAttribute(VCAPRequestIDKey, requestID)
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, req.WithContext(tracing.ContextWithSpan(req.Context(), span)))
+
+		span.SetAttribute("http.status_code", strconv.Itoa(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("%d %s", recorder.status, http.StatusText(recorder.status)))
+		}
+		span.End()
+	})
+}
+
+func (ware Tracing) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	if span, ok := tracing.SpanFromContext(req.Context()); ok {
+		context.Set(TraceSpan, span)
+	}
+
+	return true
+}
+
+func (ware Tracing) spanName(req *http.Request) string {
+	var match mux.RouteMatch
+	if ok := ware.matcher.Match(req, &match); ok {
+		if name := match.Route.GetName(); name != "" {
+			return name
+		}
+
+		return req.Method + " " + req.URL.Path
+	}
+
+	return req.Method
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
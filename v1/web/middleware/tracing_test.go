@@ -0,0 +1,138 @@
+package middleware_test
+
+import (
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/gorilla/mux"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		ware     middleware.Tracing
+		exporter *mocks.SpanExporter
+		router   *mux.Router
+		request  *http.Request
+		writer   *httptest.ResponseRecorder
+		status   int
+		handled  *tracing.Span
+	)
+
+	BeforeEach(func() {
+		exporter = mocks.NewSpanExporter()
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		status = http.StatusOK
+		handled = nil
+
+		router = mux.NewRouter()
+		router.HandleFunc("/users/{user_id}", func(w http.ResponseWriter, req *http.Request) {
+			context := stack.NewContext()
+			Expect(ware.ServeHTTP(w, req, context)).To(BeTrue())
+
+			handled, _ = context.Get(middleware.TraceSpan).(*tracing.Span)
+			w.WriteHeader(status)
+		}).Name("POST /users/{user_id}")
+		router.HandleFunc("/metrics", func(http.ResponseWriter, *http.Request) {})
+
+		var err error
+		request, err = http.NewRequest("POST", "/users/some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("X-Vcap-Request-Id", "some-request-id")
+
+		writer = httptest.NewRecorder()
+
+		ware = middleware.NewTracing(tracing.NewTracer(exporter, clock, rand.Reader), router)
+	})
+
+	It("records a server span named after the route", func() {
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		Expect(exporter.ExportCall.Receives.Spans).To(HaveLen(1))
+
+		span := exporter.ExportCall.Receives.Spans[0]
+		Expect(span.Name).To(Equal("POST /users/{user_id}"))
+		Expect(span.Kind).To(Equal(tracing.SpanKindServer))
+		Expect(span.Context.IsValid()).To(BeTrue())
+		Expect(span.ParentSpanID).To(BeEmpty())
+		Expect(span.Error).To(BeEmpty())
+		Expect(span.Attributes).To(Equal(map[string]string{
+			"http.method":      "POST",
+			"http.target":      "/users/some-user",
+			"http.status_code": "200",
+			"vcap_request_id":  "some-request-id",
+		}))
+	})
+
+	It("makes the span available to the handler", func() {
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		Expect(handled).NotTo(BeNil())
+		Expect(handled.Context).To(Equal(exporter.ExportCall.Receives.Spans[0].Context))
+	})
+
+	It("continues the trace of the caller", func() {
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		span := exporter.ExportCall.Receives.Spans[0]
+		Expect(span.Context.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(span.ParentSpanID).To(Equal("00f067aa0ba902b7"))
+	})
+
+	It("ignores a malformed traceparent", func() {
+		request.Header.Set("traceparent", "garbage")
+
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		span := exporter.ExportCall.Receives.Spans[0]
+		Expect(span.Context.IsValid()).To(BeTrue())
+		Expect(span.ParentSpanID).To(BeEmpty())
+	})
+
+	It("marks server errors as failed", func() {
+		status = http.StatusInternalServerError
+
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+
+		span := exporter.ExportCall.Receives.Spans[0]
+		Expect(span.Attributes["http.status_code"]).To(Equal("500"))
+		Expect(span.Error).To(Equal("500 Internal Server Error"))
+	})
+
+	It("uses the path for routes without a name", func() {
+		request, _ = http.NewRequest("GET", "/metrics", nil)
+
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		Expect(exporter.ExportCall.Receives.Spans[0].Name).To(Equal("GET /metrics"))
+	})
+
+	It("uses the method for requests that match no route", func() {
+		request, _ = http.NewRequest("GET", "/missing", nil)
+
+		ware.Wrap(router).ServeHTTP(writer, request)
+
+		Expect(exporter.ExportCall.Receives.Spans[0].Name).To(Equal("GET"))
+	})
+
+	It("leaves the context alone for requests that were not wrapped", func() {
+		context := stack.NewContext()
+
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(context.Get(middleware.TraceSpan)).To(BeNil())
+	})
+})
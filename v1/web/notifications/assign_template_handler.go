@@ -6,7 +6,10 @@ import (
 	"regexp"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
@@ -20,12 +23,16 @@ type assignsTemplates interface {
 
 type AssignTemplateHandler struct {
 	templateAssigner assignsTemplates
+	kindFinder       kindFinder
+	auditLog         auditLog
 	errorWriter      errorWriter
 }
 
-func NewAssignTemplateHandler(assigner assignsTemplates, errWriter errorWriter) AssignTemplateHandler {
+func NewAssignTemplateHandler(assigner assignsTemplates, finder kindFinder, auditLog auditLog, errWriter errorWriter) AssignTemplateHandler {
 	return AssignTemplateHandler{
 		templateAssigner: assigner,
+		kindFinder:       finder,
+		auditLog:         auditLog,
 		errorWriter:      errWriter,
 	}
 }
@@ -41,12 +48,50 @@ func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 	}
 
 	database := context.Get("database").(DatabaseInterface)
-	err = h.templateAssigner.AssignToNotification(database.Connection(), clientID, notificationID, templateAssignment.Template)
+	transaction := database.Connection().Transaction()
+	err = transaction.Begin()
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
 	}
 
+	kind, err := h.kindFinder.Find(transaction, notificationID, clientID)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.templateAssigner.AssignToNotification(transaction, clientID, notificationID, templateAssignment.Template)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	if templateAssignment.Template == "" {
+		templateAssignment.Template = models.DefaultTemplateID
+	}
+
+	err = h.auditLog.Record(transaction, services.AuditEntry{
+		Token:  context.Get("token").(*jwt.Token),
+		Action: services.AuditActionAssignNotificationTemplate,
+		Target: "clients/" + clientID + "/notifications/" + notificationID,
+		Before: TemplateAssignment{Template: kind.TemplateID},
+		After:  templateAssignment,
+	})
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -8,8 +8,11 @@ import (
 	"net/http/httptest"
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -20,22 +23,36 @@ var _ = Describe("AssignTemplateHandler", func() {
 	var (
 		handler          notifications.AssignTemplateHandler
 		templateAssigner *mocks.TemplateAssigner
+		kindFinder       *mocks.KindsRepo
+		auditLog         *mocks.AuditLog
+		token            *jwt.Token
 		errorWriter      *mocks.ErrorWriter
 		context          stack.Context
 		database         *mocks.Database
 		connection       *mocks.Connection
+		transaction      *mocks.Transaction
 	)
 
 	BeforeEach(func() {
 		templateAssigner = mocks.NewTemplateAssigner()
+		kindFinder = mocks.NewKindsRepo()
+		kindFinder.FindCall.Returns.Kinds = []models.Kind{
+			{ID: "my-notification", ClientID: "my-client", TemplateID: "old-template"},
+		}
+		auditLog = mocks.NewAuditLog()
+		token = &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}}
 		errorWriter = mocks.NewErrorWriter()
 		connection = mocks.NewConnection()
+		transaction = mocks.NewTransaction()
+		transaction.Connection = connection
+		connection.TransactionCall.Returns.Transaction = transaction
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = connection
 		context = stack.NewContext()
 		context.Set("database", database)
+		context.Set("token", token)
 
-		handler = notifications.NewAssignTemplateHandler(templateAssigner, errorWriter)
+		handler = notifications.NewAssignTemplateHandler(templateAssigner, kindFinder, auditLog, errorWriter)
 	})
 
 	It("associates a template with a notification", func() {
@@ -51,12 +68,72 @@ var _ = Describe("AssignTemplateHandler", func() {
 		handler.ServeHTTP(w, request, context)
 
 		Expect(w.Code).To(Equal(http.StatusNoContent))
-		Expect(templateAssigner.AssignToNotificationCall.Receives.Connection).To(Equal(connection))
+		Expect(templateAssigner.AssignToNotificationCall.Receives.Connection).To(Equal(transaction))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.ClientID).To(Equal("my-client"))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.NotificationID).To(Equal("my-notification"))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.TemplateID).To(Equal("my-template"))
 	})
 
+	It("records the assignment in the audit log in the same transaction", func() {
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/notifications/my-notification/template", bytes.NewBufferString(`{"template": "my-template"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(kindFinder.FindCall.Receives.Connection).To(Equal(transaction))
+		Expect(kindFinder.FindCall.Receives.KindID).To(Equal("my-notification"))
+		Expect(kindFinder.FindCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(auditLog.RecordCall.Receives.Connection).To(Equal(transaction))
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		Expect(auditLog.RecordCall.Receives.Entry).To(Equal(services.AuditEntry{
+			Token:  token,
+			Action: "notification.template.assign",
+			Target: "clients/my-client/notifications/my-notification",
+			Before: notifications.TemplateAssignment{Template: "old-template"},
+			After:  notifications.TemplateAssignment{Template: "my-template"},
+		}))
+	})
+
+	It("records an assignment of the default template when no template is given", func() {
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/notifications/my-notification/template", bytes.NewBufferString(`{"template": ""}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(auditLog.RecordCall.Receives.Entry.After).To(Equal(notifications.TemplateAssignment{Template: models.DefaultTemplateID}))
+	})
+
+	It("delegates to the error writer when the notification cannot be found", func() {
+		kindFinder.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("kind not found")}
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/notifications/my-notification/template", bytes.NewBufferString(`{"template": "my-template"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("kind not found")}))
+		Expect(templateAssigner.AssignToNotificationCall.Receives.ClientID).To(BeEmpty())
+		Expect(auditLog.RecordCall.CallCount).To(Equal(0))
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+	})
+
+	It("rolls the assignment back when the audit log errors", func() {
+		auditLog.RecordCall.Returns.Error = errors.New("audit log is down")
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/notifications/my-notification/template", bytes.NewBufferString(`{"template": "my-template"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("audit log is down")))
+		Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+	})
+
 	It("delegates to the error writer when the assigner errors", func() {
 		templateAssigner.AssignToNotificationCall.Returns.Error = errors.New("banana")
 		body, err := json.Marshal(map[string]string{
@@ -70,6 +147,7 @@ var _ = Describe("AssignTemplateHandler", func() {
 
 		handler.ServeHTTP(w, request, context)
 		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("banana")))
+		Expect(auditLog.RecordCall.CallCount).To(Equal(0))
 	})
 
 	It("writes a ParseError to the error writer when request body is invalid", func() {
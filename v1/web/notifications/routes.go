@@ -18,12 +18,14 @@ type Routes struct {
 	TemplateAssigner     assignsTemplates
 	NotificationsFinder  listsAllClientsAndNotifications
 	NotificationsUpdater notificationsUpdater
+	KindFinder           kindFinder
+	AuditLog             auditLog
 }
 
 func (r Routes) Register(m muxer) {
 	m.Handle("PUT", "/registration", NewRegistrationHandler(r.Registrar, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("PUT", "/notifications", NewPutHandler(r.Registrar, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/notifications", NewListHandler(r.NotificationsFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
-	m.Handle("PUT", "/clients/{client_id}/notifications/{notification_id}", NewUpdateHandler(r.NotificationsUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
-	m.Handle("PUT", "/clients/{client_id}/notifications/{notification_id}/template", NewAssignTemplateHandler(r.TemplateAssigner, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/clients/{client_id}/notifications/{notification_id}", NewUpdateHandler(r.NotificationsUpdater, r.KindFinder, r.AuditLog, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/clients/{client_id}/notifications/{notification_id}/template", NewAssignTemplateHandler(r.TemplateAssigner, r.KindFinder, r.AuditLog, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
 }
@@ -30,6 +30,8 @@ var _ = Describe("Routes", func() {
 			ErrorWriter:          mocks.NewErrorWriter(),
 			NotificationsFinder:  mocks.NewNotificationsFinder(),
 			NotificationsUpdater: &mocks.NotificationUpdater{},
+			KindFinder:           mocks.NewKindsRepo(),
+			AuditLog:             mocks.NewAuditLog(),
 		}.Register(muxer)
 	})
 
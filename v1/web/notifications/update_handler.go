@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
@@ -14,17 +15,29 @@ type errorWriter interface {
 }
 
 type notificationsUpdater interface {
-	Update(services.DatabaseInterface, models.Kind) error
+	Update(services.ConnectionInterface, models.Kind) error
+}
+
+type kindFinder interface {
+	Find(connection models.ConnectionInterface, kindID string, clientID string) (models.Kind, error)
+}
+
+type auditLog interface {
+	Record(conn services.ConnectionInterface, entry services.AuditEntry) error
 }
 
 type UpdateHandler struct {
 	updater     notificationsUpdater
+	kindFinder  kindFinder
+	auditLog    auditLog
 	errorWriter errorWriter
 }
 
-func NewUpdateHandler(updater notificationsUpdater, errWriter errorWriter) UpdateHandler {
+func NewUpdateHandler(updater notificationsUpdater, finder kindFinder, auditLog auditLog, errWriter errorWriter) UpdateHandler {
 	return UpdateHandler{
 		updater:     updater,
+		kindFinder:  finder,
+		auditLog:    auditLog,
 		errorWriter: errWriter,
 	}
 }
@@ -42,11 +55,50 @@ func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	matches := regex.FindStringSubmatch(req.URL.Path)
 	clientID, notificationID := matches[1], matches[2]
 
-	err = h.updater.Update(context.Get("database").(DatabaseInterface), updateParams.ToModel(clientID, notificationID))
+	database := context.Get("database").(DatabaseInterface)
+	transaction := database.Connection().Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	kind, err := h.kindFinder.Find(transaction, notificationID, clientID)
 	if err != nil {
+		transaction.Rollback()
 		h.errorWriter.Write(w, err)
 		return
 	}
 
+	err = h.updater.Update(transaction, updateParams.ToModel(clientID, notificationID))
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.auditLog.Record(transaction, services.AuditEntry{
+		Token:  context.Get("token").(*jwt.Token),
+		Action: services.AuditActionUpdateNotification,
+		Target: "clients/" + clientID + "/notifications/" + notificationID,
+		Before: NotificationUpdateParams{
+			Description: kind.Description,
+			Critical:    kind.Critical,
+			TemplateID:  kind.TemplateID,
+		},
+		After: updateParams,
+	})
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -8,8 +8,10 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -24,31 +26,48 @@ var _ = Describe("UpdateHandler", func() {
 		request     *http.Request
 		context     stack.Context
 		updater     *mocks.NotificationUpdater
+		kindFinder  *mocks.KindsRepo
+		auditLog    *mocks.AuditLog
+		token       *jwt.Token
 		errorWriter *mocks.ErrorWriter
 		database    *mocks.Database
+		connection  *mocks.Connection
+		transaction *mocks.Transaction
 	)
 
 	Describe("ServeHTTP", func() {
 		BeforeEach(func() {
 			updater = &mocks.NotificationUpdater{}
+			kindFinder = mocks.NewKindsRepo()
+			kindFinder.FindCall.Returns.Kinds = []models.Kind{
+				{ID: "this-kind", ClientID: "this-client", Description: "old kind", Critical: true, TemplateID: "old-template"},
+			}
+			auditLog = mocks.NewAuditLog()
 			errorWriter = mocks.NewErrorWriter()
 			writer = httptest.NewRecorder()
 			body := []byte(`{"description": "test kind", "critical": false, "template": "template-name"}`)
 			request, err = http.NewRequest("PUT", "/clients/this-client/notifications/this-kind", bytes.NewBuffer(body))
 			Expect(err).NotTo(HaveOccurred())
 
+			connection = mocks.NewConnection()
+			transaction = mocks.NewTransaction()
+			transaction.Connection = connection
+			connection.TransactionCall.Returns.Transaction = transaction
 			database = mocks.NewDatabase()
+			database.ConnectionCall.Returns.Connection = connection
+			token = &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}}
 			context = stack.NewContext()
 			context.Set("database", database)
+			context.Set("token", token)
 
-			handler = notifications.NewUpdateHandler(updater, errorWriter)
+			handler = notifications.NewUpdateHandler(updater, kindFinder, auditLog, errorWriter)
 		})
 
 		It("calls update on its updater with appropriate arguments", func() {
 			handler.ServeHTTP(writer, request, context)
 			Expect(writer.Code).To(Equal(http.StatusNoContent))
 
-			Expect(updater.UpdateCall.Receives.Database).To(Equal(database))
+			Expect(updater.UpdateCall.Receives.Connection).To(Equal(transaction))
 			Expect(updater.UpdateCall.Receives.Notification).To(Equal(models.Kind{
 				Description: "test kind",
 				Critical:    false,
@@ -58,11 +77,60 @@ var _ = Describe("UpdateHandler", func() {
 			}))
 		})
 
+		It("records the change in the audit log in the same transaction", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(kindFinder.FindCall.Receives.Connection).To(Equal(transaction))
+			Expect(kindFinder.FindCall.Receives.KindID).To(Equal("this-kind"))
+			Expect(kindFinder.FindCall.Receives.ClientID).To(Equal("this-client"))
+			Expect(auditLog.RecordCall.Receives.Connection).To(Equal(transaction))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			Expect(auditLog.RecordCall.Receives.Entry).To(Equal(services.AuditEntry{
+				Token:  token,
+				Action: "notification.update",
+				Target: "clients/this-client/notifications/this-kind",
+				Before: notifications.NotificationUpdateParams{
+					Description: "old kind",
+					Critical:    true,
+					TemplateID:  "old-template",
+				},
+				After: notifications.NotificationUpdateParams{
+					Description: "test kind",
+					Critical:    false,
+					TemplateID:  "template-name",
+				},
+			}))
+		})
+
 		Context("when an error occurs", func() {
 			It("propagates the error returned from the updater into the error writer", func() {
 				updater.UpdateCall.Returns.Error = errors.New("error occurred while updating notification")
 				handler.ServeHTTP(writer, request, context)
 				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("error occurred while updating notification")))
+				Expect(auditLog.RecordCall.CallCount).To(Equal(0))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("propagates the error returned when the notification cannot be found", func() {
+				kindFinder.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("kind not found")}
+				handler.ServeHTTP(writer, request, context)
+				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(models.NotFoundError{Err: errors.New("kind not found")}))
+				Expect(updater.UpdateCall.Receives.Connection).To(BeNil())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("rolls the change back when it cannot be recorded in the audit log", func() {
+				auditLog.RecordCall.Returns.Error = errors.New("audit log is down")
+				handler.ServeHTTP(writer, request, context)
+				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("audit log is down")))
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("propagates the error returned when the transaction cannot be committed", func() {
+				transaction.CommitCall.Returns.Error = errors.New("commit failed")
+				handler.ServeHTTP(writer, request, context)
+				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(models.TransactionCommitError{Err: errors.New("commit failed")}))
 			})
 
 			It("writes a params validation error when the request is semantically invalid", func() {
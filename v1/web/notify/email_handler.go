@@ -10,6 +10,7 @@ import (
 const (
 	VCAPRequestIDKey    = "vcap_request_id"
 	RequestReceivedTime = "request_received_time"
+	TraceSpan           = "trace_span"
 )
 
 type notifyExecutor interface {
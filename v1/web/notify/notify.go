@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
@@ -24,15 +25,21 @@ type registrar interface {
 	Prune(services.ConnectionInterface, models.Client, []models.Kind) error
 }
 
+type clock interface {
+	Now() time.Time
+}
+
 type Notify struct {
 	finder    clientAndKindFinder
 	registrar registrar
+	clock     clock
 }
 
-func NewNotify(finder clientAndKindFinder, registrar registrar) Notify {
+func NewNotify(finder clientAndKindFinder, registrar registrar, clock clock) Notify {
 	return Notify{
 		finder:    finder,
 		registrar: registrar,
+		clock:     clock,
 	}
 }
 
@@ -56,6 +63,12 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 	if !ok {
 		panic("programmer error: missing RequestReceivedTime in http context")
 	}
+
+	var traceParent string
+	if span, ok := context.Get(TraceSpan).(*tracing.Span); ok {
+		traceParent = span.Context.TraceParent()
+	}
+
 	token := context.Get("token").(*jwt.Token) // TODO: (rm) get rid of the context object, just pass in the token
 	clientID := token.Claims["client_id"].(string)
 
@@ -79,6 +92,13 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 		return []byte{}, err
 	}
 
+	attachments := attachmentsFor(parameters.Attachments)
+
+	var to string
+	if len(parameters.To) > 0 {
+		to = parameters.To[0]
+	}
+
 	var responses []services.Response
 
 	responses, err = strategy.Dispatch(services.Dispatch{
@@ -86,8 +106,9 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 		Connection: connection,
 		Role:       parameters.Role,
 		Client: services.DispatchClient{
-			ID:          clientID,
-			Description: client.Description,
+			ID:                  clientID,
+			Description:         client.Description,
+			DailyRecipientQuota: client.DailyRecipientQuota,
 		},
 		Kind: services.DispatchKind{
 			ID:          parameters.KindID,
@@ -97,21 +118,28 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 		VCAPRequest: services.DispatchVCAPRequest{
 			ID:          vcapRequestID,
 			ReceiptTime: requestReceivedTime,
+			TraceParent: traceParent,
 		},
 		Message: services.DispatchMessage{
-			To:      parameters.To,
+			To:      to,
 			ReplyTo: parameters.ReplyTo,
 			Subject: parameters.Subject,
 			Text:    parameters.Text,
+			Data:    parameters.Data,
 			HTML: services.HTML{
 				BodyContent:    parameters.ParsedHTML.BodyContent,
 				BodyAttributes: parameters.ParsedHTML.BodyAttributes,
 				Head:           parameters.ParsedHTML.Head,
 				Doctype:        parameters.ParsedHTML.Doctype,
 			},
+			Attachments: attachments,
+			Recipients:  recipients(parameters),
 		},
 	})
 	if err != nil {
+		if quotaErr, ok := err.(services.RecipientQuotaError); ok {
+			return []byte{}, h.quotaExceeded(quotaErr)
+		}
 		return []byte{}, err
 	}
 
@@ -123,6 +151,60 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 	return output, nil
 }
 
+// quotaExceeded refuses a request whose recipients do not fit in the daily
+// recipient quota of its client until the quota starts over at midnight UTC.
+func (h Notify) quotaExceeded(err services.RecipientQuotaError) error {
+	now := h.clock.Now()
+	midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+	return webutil.TooManyRequestsError{
+		Err:        err,
+		RetryAfter: midnight.Sub(now),
+	}
+}
+
+// attachmentsFor converts the attachments of a request into the ones that are
+// dispatched, using the content that the validator has already decoded.
+func attachmentsFor(attachments []Attachment) []services.Attachment {
+	var converted []services.Attachment
+
+	for _, attachment := range attachments {
+		converted = append(converted, services.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			ContentID:   attachment.ContentID,
+			Inline:      attachment.Inline(),
+			Content:     attachment.Decoded,
+		})
+	}
+
+	return converted
+}
+
+// recipients lists the addresses of an email that is sent to several people
+// at once. A message with a single "to" address and no "cc" or "bcc" needs no
+// per-recipient tracking and is sent as before.
+func recipients(parameters NotifyParams) []services.Recipient {
+	if len(parameters.To) < 2 && len(parameters.CC) == 0 && len(parameters.BCC) == 0 {
+		return nil
+	}
+
+	var list []services.Recipient
+	for _, address := range parameters.To {
+		list = append(list, services.Recipient{Address: address, Type: services.RecipientTo})
+	}
+
+	for _, address := range parameters.CC {
+		list = append(list, services.Recipient{Address: address, Type: services.RecipientCC})
+	}
+
+	for _, address := range parameters.BCC {
+		list = append(list, services.Recipient{Address: address, Type: services.RecipientBCC})
+	}
+
+	return list
+}
+
 func (h Notify) hasCriticalNotificationsWriteScope(elements interface{}) bool {
 	for _, elem := range elements.([]interface{}) {
 		if elem.(string) == "critical_notifications.write" {
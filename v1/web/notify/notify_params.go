@@ -2,12 +2,14 @@ package notify
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/cloudfoundry-incubator/notifications/markdown"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 )
 
@@ -19,13 +21,21 @@ var (
 )
 
 type NotifyParams struct {
-	ReplyTo string `json:"reply_to"`
-	Subject string `json:"subject"`
-	Text    string `json:"text"`
-	RawHTML string `json:"html"`
-	KindID  string `json:"kind_id"`
-	To      string `json:"to"`
-	Role    string `json:"role"`
+	ReplyTo string    `json:"reply_to"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+	RawHTML string    `json:"html"`
+	KindID  string    `json:"kind_id"`
+	To      Addresses `json:"to"`
+	CC      Addresses `json:"cc"`
+	BCC     Addresses `json:"bcc"`
+	Role    string    `json:"role"`
+
+	Markdown string `json:"markdown"`
+
+	Data map[string]interface{} `json:"data"`
+
+	Attachments []Attachment `json:"attachments"`
 
 	ParsedHTML        HTML
 	KindDescription   string
@@ -33,6 +43,64 @@ type NotifyParams struct {
 	Errors            []string
 }
 
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id"`
+
+	// Decoded holds the content once the validator has decoded it, so that
+	// it is only decoded once.
+	Decoded []byte `json:"-"`
+}
+
+// Decode returns the attachment content, which clients send base64 encoded.
+func (attachment Attachment) Decode() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(attachment.Content)
+}
+
+func (attachment Attachment) Inline() bool {
+	return attachment.Disposition == "inline"
+}
+
+// Addresses is a list of email addresses. Clients may send either a single
+// address as a string or an array of addresses.
+type Addresses []string
+
+func (addresses *Addresses) UnmarshalJSON(data []byte) error {
+	var address string
+	if err := json.Unmarshal(data, &address); err == nil {
+		*addresses = Addresses{}
+		if address != "" {
+			*addresses = Addresses{address}
+		}
+		return nil
+	}
+
+	var list []string
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return err
+	}
+
+	*addresses = Addresses(list)
+	return nil
+}
+
+func (addresses Addresses) format() Addresses {
+	if addresses == nil {
+		return nil
+	}
+
+	formatted := Addresses{}
+	for _, address := range addresses {
+		formatted = append(formatted, EmailFormatter{}.Format(address))
+	}
+
+	return formatted
+}
+
 type HTML struct {
 	BodyContent    string
 	BodyAttributes string
@@ -48,6 +116,8 @@ func NewNotifyParams(body io.ReadCloser) (NotifyParams, error) {
 		return notify, err
 	}
 
+	notify.renderMarkdown()
+
 	err = notify.FormatEmailAndExtractHTML()
 	if err != nil {
 		return notify, err
@@ -60,7 +130,14 @@ func (notify *NotifyParams) parseRequestBody(body io.ReadCloser) error {
 	defer body.Close()
 
 	buffer := bytes.NewBuffer([]byte{})
-	buffer.ReadFrom(body)
+	_, err := buffer.ReadFrom(body)
+	if err != nil {
+		if tooLarge, ok := err.(webutil.RequestTooLargeError); ok {
+			return tooLarge
+		}
+		return webutil.ParseError{}
+	}
+
 	if buffer.Len() > 0 {
 		err := json.Unmarshal(buffer.Bytes(), &notify)
 		if err != nil {
@@ -70,8 +147,27 @@ func (notify *NotifyParams) parseRequestBody(body io.ReadCloser) error {
 	return nil
 }
 
+// renderMarkdown derives the html and text bodies from the markdown body, as
+// if the client had supplied them, unless they were given explicitly.
+func (notify *NotifyParams) renderMarkdown() {
+	if notify.Markdown == "" {
+		return
+	}
+
+	html, text := markdown.Render(notify.Markdown)
+	if notify.RawHTML == "" {
+		notify.RawHTML = html
+	}
+
+	if notify.Text == "" {
+		notify.Text = text
+	}
+}
+
 func (notify *NotifyParams) FormatEmailAndExtractHTML() error {
-	notify.To = EmailFormatter{}.Format(notify.To)
+	notify.To = notify.To.format()
+	notify.CC = notify.CC.format()
+	notify.BCC = notify.BCC.format()
 
 	doctype, head, bodyContent, bodyAttributes, err := HTMLExtractor{}.Extract(notify.RawHTML)
 	if err != nil {
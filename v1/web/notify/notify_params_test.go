@@ -6,11 +6,20 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+type erroringReader struct {
+	err error
+}
+
+func (r erroringReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
 var _ = Describe("NotifyParams", func() {
 	Describe("NewNotifyParams", func() {
 		It("parses the body of the given request", func() {
@@ -30,6 +39,60 @@ var _ = Describe("NotifyParams", func() {
 			Expect(parameters.Text).To(Equal("Contents of the email message"))
 		})
 
+		It("parses the data object", func() {
+			parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+				"kind_id": "test_email",
+				"text": "Contents of the email message",
+				"data": {"quota": 10, "links": {"docs": "https://example.com/docs"}}
+			}`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(parameters.Data).To(Equal(map[string]interface{}{
+				"quota": float64(10),
+				"links": map[string]interface{}{"docs": "https://example.com/docs"},
+			}))
+		})
+
+		Context("when a markdown body is given", func() {
+			It("renders the html and text bodies from it", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+					"kind_id": "test_email",
+					"markdown": "# Maintenance\n\nSee [the status page](https://status.example.com) <script>"
+				}`)))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parameters.Text).To(Equal("Maintenance\n\nSee the status page (https://status.example.com) <script>"))
+				Expect(parameters.ParsedHTML.BodyContent).To(Equal(`<h1>Maintenance</h1>
+<p>See <a href="https://status.example.com">the status page</a> &lt;script&gt;</p>`))
+			})
+
+			It("prefers explicitly given text and html bodies", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+					"kind_id": "test_email",
+					"text": "the text",
+					"markdown": "the *markdown*"
+				}`)))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parameters.Text).To(Equal("the text"))
+				Expect(parameters.ParsedHTML.BodyContent).To(Equal("<p>the <em>markdown</em></p>"))
+			})
+		})
+
+		It("returns a parse error when the data is not an object", func() {
+			_, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+				"data": ["not", "an", "object"]
+			}`)))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns the error when the body is too large to read", func() {
+			body := io.MultiReader(strings.NewReader(`{"text":`), erroringReader{webutil.RequestTooLargeError{Limit: 8}})
+
+			_, err := notify.NewNotifyParams(ioutil.NopCloser(body))
+			Expect(err).To(Equal(webutil.RequestTooLargeError{Limit: 8}))
+		})
+
 		It("does not blow up if the request body is empty", func() {
 			Expect(func() {
 				notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader("")))
@@ -42,7 +105,7 @@ var _ = Describe("NotifyParams", func() {
 					"to": "The User <user@example.com>"
 				}`)))
 				Expect(err).NotTo(HaveOccurred())
-				Expect(parameters.To).To(Equal("user@example.com"))
+				Expect(parameters.To).To(Equal(notify.Addresses{"user@example.com"}))
 			})
 
 			It("populates the To field with the parsed email address", func() {
@@ -50,7 +113,7 @@ var _ = Describe("NotifyParams", func() {
                     "to": "user@example.com"
 				}`)))
 				Expect(err).NotTo(HaveOccurred())
-				Expect(parameters.To).To(Equal("user@example.com"))
+				Expect(parameters.To).To(Equal(notify.Addresses{"user@example.com"}))
 			})
 
 			It("sets the to field to InvalidEmail cannot be parsed", func() {
@@ -58,7 +121,7 @@ var _ = Describe("NotifyParams", func() {
                     "to": "<The User"
 				}`)))
 				Expect(err).NotTo(HaveOccurred())
-				Expect(parameters.To).To(Equal(notify.InvalidEmail))
+				Expect(parameters.To).To(Equal(notify.Addresses{notify.InvalidEmail}))
 			})
 
 			It("Sets the To field to empty of if it is not specified", func() {
@@ -66,7 +129,26 @@ var _ = Describe("NotifyParams", func() {
                     "to": ""
 				}`)))
 				Expect(err).NotTo(HaveOccurred())
-				Expect(parameters.To).To(Equal(""))
+				Expect(parameters.To).To(BeEmpty())
+			})
+
+			It("accepts lists of to, cc and bcc addresses", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+					"to": ["The User <user@example.com>", "other@example.com"],
+					"cc": "Copied <cc@example.com>",
+					"bcc": ["bcc@example.com", "<Broken"]
+				}`)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parameters.To).To(Equal(notify.Addresses{"user@example.com", "other@example.com"}))
+				Expect(parameters.CC).To(Equal(notify.Addresses{"cc@example.com"}))
+				Expect(parameters.BCC).To(Equal(notify.Addresses{"bcc@example.com", notify.InvalidEmail}))
+			})
+
+			It("returns a parse error when the addresses are neither a string nor a list", func() {
+				_, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+					"to": {"address": "user@example.com"}
+				}`)))
+				Expect(err).To(HaveOccurred())
 			})
 		})
 
@@ -215,7 +297,7 @@ var _ = Describe("NotifyParams", func() {
 							"text": "Contents of the email message"
 						}`)))
 						Expect(err).NotTo(HaveOccurred())
-						Expect(parameters.To).To(Equal(notify.InvalidEmail))
+						Expect(parameters.To).To(Equal(notify.Addresses{notify.InvalidEmail}))
 					})
 				})
 
@@ -226,7 +308,7 @@ var _ = Describe("NotifyParams", func() {
 							"text": "Contents of the email message"
 						}`)))
 						Expect(err).NotTo(HaveOccurred())
-						Expect(parameters.To).To(Equal(notify.InvalidEmail))
+						Expect(parameters.To).To(Equal(notify.Addresses{notify.InvalidEmail}))
 					})
 				})
 			})
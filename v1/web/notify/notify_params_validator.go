@@ -1,30 +1,61 @@
 package notify
 
-import "regexp"
-
-var kindIDFormat = regexp.MustCompile(`^[0-9a-zA-Z_\-.]+$`)
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// MaxDataSize is the largest JSON-encoded "data" object, in bytes, that will
+// be accepted alongside a notification.
+const MaxDataSize = 16 * 1024
+
+// MaxAttachments is the largest number of files that can be attached to a
+// single notification.
+const MaxAttachments = 10
+
+// MaxAttachmentsSize is the largest combined size, in bytes, of the decoded
+// attachments of a single notification.
+const MaxAttachmentsSize = 10 * 1024 * 1024
+
+// MaxRecipients is the largest number of "to", "cc" and "bcc" addresses,
+// combined, that a single email can be sent to.
+const MaxRecipients = 50
+
+var (
+	kindIDFormat    = regexp.MustCompile(`^[0-9a-zA-Z_\-.]+$`)
+	contentIDFormat = regexp.MustCompile(`^[^\s<>"()\\]+$`)
+)
 
 type EmailValidator struct{}
 
 func (validator EmailValidator) Validate(notify *NotifyParams) bool {
 	notify.Errors = []string{}
 
-	if notify.To == "" {
+	if len(notify.To) == 0 {
 		notify.Errors = append(notify.Errors, `"to" is a required field`)
 	}
 
-	if notify.To == InvalidEmail {
-		notify.Errors = append(notify.Errors, `"to" is improperly formatted`)
-	}
+	checkRecipientsFields(notify)
 
 	if missingTextOrHTMLFields(notify) {
 		notify.Errors = append(notify.Errors, `"text" or "html" fields must be supplied`)
 	}
 
+	checkDataField(notify)
+	checkAttachmentsField(notify, true)
+
 	return len(notify.Errors) == 0
 }
 
-type GUIDValidator struct{}
+// GUIDValidator validates notifications addressed by GUID. Attachments are
+// only accepted when AllowAttachments is set, as is the case for notifications
+// sent to a single user.
+type GUIDValidator struct {
+	AllowAttachments bool
+}
 
 func (validator GUIDValidator) Validate(notify *NotifyParams) bool {
 	notify.Errors = []string{}
@@ -39,6 +70,9 @@ func (validator GUIDValidator) Validate(notify *NotifyParams) bool {
 		notify.Errors = append(notify.Errors, `"role" must be "OrgManager", "OrgAuditor", "BillingManager" or unset`)
 	}
 
+	checkDataField(notify)
+	checkAttachmentsField(notify, validator.AllowAttachments)
+
 	return len(notify.Errors) == 0
 }
 
@@ -46,6 +80,104 @@ func missingTextOrHTMLFields(notify *NotifyParams) bool {
 	return notify.Text == "" && notify.ParsedHTML.BodyContent == ""
 }
 
+func checkDataField(notify *NotifyParams) {
+	if notify.Data == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(notify.Data)
+	if err != nil || len(encoded) > MaxDataSize {
+		notify.Errors = append(notify.Errors, fmt.Sprintf(`"data" must not exceed %d bytes`, MaxDataSize))
+	}
+}
+
+func checkRecipientsFields(notify *NotifyParams) {
+	fields := []struct {
+		name      string
+		addresses Addresses
+	}{
+		{"to", notify.To},
+		{"cc", notify.CC},
+		{"bcc", notify.BCC},
+	}
+
+	seen := map[string]bool{}
+	for _, field := range fields {
+		for _, address := range field.addresses {
+			if address == "" || address == InvalidEmail {
+				notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s" is improperly formatted`, field.name))
+				break
+			}
+
+			key := strings.ToLower(address)
+			if seen[key] {
+				notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s" is listed as a recipient more than once`, address))
+			}
+			seen[key] = true
+		}
+	}
+
+	if len(notify.To)+len(notify.CC)+len(notify.BCC) > MaxRecipients {
+		notify.Errors = append(notify.Errors, fmt.Sprintf(`"to", "cc" and "bcc" must not contain more than %d addresses in total`, MaxRecipients))
+	}
+}
+
+func checkAttachmentsField(notify *NotifyParams, allowed bool) {
+	if len(notify.Attachments) == 0 {
+		return
+	}
+
+	if !allowed {
+		notify.Errors = append(notify.Errors, `"attachments" can only be sent to a user or an email address`)
+		return
+	}
+
+	if len(notify.Attachments) > MaxAttachments {
+		notify.Errors = append(notify.Errors, fmt.Sprintf(`"attachments" must not contain more than %d files`, MaxAttachments))
+		return
+	}
+
+	size := 0
+	for i, attachment := range notify.Attachments {
+		field := fmt.Sprintf("attachments[%d]", i)
+
+		if attachment.Filename == "" {
+			notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.filename" is a required field`, field))
+		} else if strings.ContainsAny(attachment.Filename, "/\\\r\n\x00") {
+			notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.filename" is improperly formatted`, field))
+		}
+
+		if attachment.ContentType != "" {
+			if _, _, err := mime.ParseMediaType(attachment.ContentType); err != nil {
+				notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.content_type" is improperly formatted`, field))
+			}
+		}
+
+		switch attachment.Disposition {
+		case "", "attachment", "inline":
+		default:
+			notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.disposition" must be "attachment", "inline" or unset`, field))
+		}
+
+		if attachment.ContentID == "" && attachment.Inline() {
+			notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.content_id" is required for inline attachments`, field))
+		} else if attachment.ContentID != "" && !contentIDFormat.MatchString(attachment.ContentID) {
+			notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.content_id" is improperly formatted`, field))
+		}
+
+		content, err := attachment.Decode()
+		if err != nil {
+			notify.Errors = append(notify.Errors, fmt.Sprintf(`"%s.content" must be base64 encoded`, field))
+		}
+		notify.Attachments[i].Decoded = content
+		size += len(content)
+	}
+
+	if size > MaxAttachmentsSize {
+		notify.Errors = append(notify.Errors, fmt.Sprintf(`"attachments" must not exceed %d bytes in total`, MaxAttachmentsSize))
+	}
+}
+
 func (validator GUIDValidator) invalidRoleField(roleName string) bool {
 	if roleName == "" {
 		return false
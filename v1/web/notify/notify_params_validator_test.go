@@ -1,6 +1,10 @@
 package notify_test
 
 import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 
 	. "github.com/onsi/ginkgo"
@@ -17,7 +21,7 @@ var _ = Describe("Validator", func() {
 		BeforeEach(func() {
 			params = &notify.NotifyParams{
 				Text: "my silly text",
-				To:   "bob@example.com",
+				To:   notify.Addresses{"bob@example.com"},
 			}
 			validator = notify.EmailValidator{}
 		})
@@ -27,7 +31,7 @@ var _ = Describe("Validator", func() {
 				Expect(validator.Validate(params)).To(BeTrue())
 				Expect(len(params.Errors)).To(Equal(0))
 
-				params.To = ""
+				params.To = nil
 
 				Expect(validator.Validate(params)).To(BeFalse())
 				Expect(len(params.Errors)).To(Equal(1))
@@ -40,7 +44,7 @@ var _ = Describe("Validator", func() {
 				Expect(params.Errors).To(ContainElement(`"to" is a required field`))
 				Expect(params.Errors).To(ContainElement(`"text" or "html" fields must be supplied`))
 
-				params.To = "otherUser@example.com"
+				params.To = notify.Addresses{"otherUser@example.com"}
 				params.ParsedHTML = notify.HTML{BodyContent: "<p>Contents of this email message</p>"}
 
 				Expect(validator.Validate(params)).To(BeTrue())
@@ -49,13 +53,126 @@ var _ = Describe("Validator", func() {
 
 			Context("When the notify params object finds an invalid email", func() {
 				It("Reports a validation error", func() {
-					params.To = notify.InvalidEmail
+					params.To = notify.Addresses{notify.InvalidEmail}
 
 					Expect(validator.Validate(params)).To(BeFalse())
 					Expect(len(params.Errors)).To(Equal(1))
 					Expect(params.Errors).To(ContainElement(`"to" is improperly formatted`))
 				})
 			})
+
+			Context("when the email has several recipients", func() {
+				BeforeEach(func() {
+					params.To = notify.Addresses{"bob@example.com", "alice@example.com"}
+					params.CC = notify.Addresses{"carol@example.com"}
+					params.BCC = notify.Addresses{"dave@example.com"}
+				})
+
+				It("accepts them", func() {
+					Expect(validator.Validate(params)).To(BeTrue())
+					Expect(params.Errors).To(BeEmpty())
+				})
+
+				It("reports which field holds an invalid address", func() {
+					params.CC = notify.Addresses{"carol@example.com", notify.InvalidEmail}
+					params.BCC = notify.Addresses{""}
+
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf(`"cc" is improperly formatted`, `"bcc" is improperly formatted`))
+				})
+
+				It("rejects addresses that are listed more than once", func() {
+					params.BCC = notify.Addresses{"Bob@example.com"}
+
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf(`"Bob@example.com" is listed as a recipient more than once`))
+				})
+
+				It("rejects more than the maximum number of recipients", func() {
+					params.BCC = nil
+					for i := 0; i < notify.MaxRecipients; i++ {
+						params.BCC = append(params.BCC, fmt.Sprintf("user-%d@example.com", i))
+					}
+
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf(`"to", "cc" and "bcc" must not contain more than 50 addresses in total`))
+				})
+			})
+
+			It("validates that the data does not exceed the maximum size", func() {
+				params.Data = map[string]interface{}{"link": "https://example.com"}
+
+				Expect(validator.Validate(params)).To(BeTrue())
+				Expect(len(params.Errors)).To(Equal(0))
+
+				params.Data = map[string]interface{}{"blob": strings.Repeat("a", notify.MaxDataSize)}
+
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(len(params.Errors)).To(Equal(1))
+				Expect(params.Errors).To(ContainElement(`"data" must not exceed 16384 bytes`))
+			})
+
+			Describe("attachments", func() {
+				BeforeEach(func() {
+					params.Attachments = []notify.Attachment{
+						{Filename: "report.csv", ContentType: "text/csv", Content: "aXRlbSxwcmljZQ=="},
+						{Filename: "logo.png", Content: "cG5n", Disposition: "inline", ContentID: "logo@example.com"},
+					}
+				})
+
+				It("accepts well formed attachments", func() {
+					Expect(validator.Validate(params)).To(BeTrue())
+					Expect(params.Errors).To(BeEmpty())
+				})
+
+				It("keeps the decoded content of the attachments", func() {
+					Expect(validator.Validate(params)).To(BeTrue())
+					Expect(params.Attachments[0].Decoded).To(Equal([]byte("item,price")))
+					Expect(params.Attachments[1].Decoded).To(Equal([]byte("png")))
+				})
+
+				It("validates the fields of each attachment", func() {
+					params.Attachments = []notify.Attachment{
+						{Content: "not base64!"},
+						{Filename: "../etc/passwd", ContentType: "text/", Content: "cG5n", Disposition: "download"},
+						{Filename: "logo.png", Content: "cG5n", Disposition: "inline"},
+						{Filename: "logo.png", Content: "cG5n", ContentID: "<logo>"},
+					}
+
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf([]string{
+						`"attachments[0].filename" is a required field`,
+						`"attachments[0].content" must be base64 encoded`,
+						`"attachments[1].filename" is improperly formatted`,
+						`"attachments[1].content_type" is improperly formatted`,
+						`"attachments[1].disposition" must be "attachment", "inline" or unset`,
+						`"attachments[2].content_id" is required for inline attachments`,
+						`"attachments[3].content_id" is improperly formatted`,
+					}))
+				})
+
+				It("validates the number of attachments", func() {
+					for len(params.Attachments) <= notify.MaxAttachments {
+						params.Attachments = append(params.Attachments, params.Attachments[0])
+					}
+
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf(`"attachments" must not contain more than 10 files`))
+				})
+
+				It("validates that the attachments do not exceed the maximum size", func() {
+					content := base64.StdEncoding.EncodeToString(make([]byte, notify.MaxAttachmentsSize/2))
+					params.Attachments[0].Content = content
+					params.Attachments[1].Content = content
+
+					Expect(validator.Validate(params)).To(BeTrue())
+
+					params.Attachments[1].Content = base64.StdEncoding.EncodeToString(make([]byte, notify.MaxAttachmentsSize/2+1))
+
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf(`"attachments" must not exceed 10485760 bytes in total`))
+				})
+			})
 		})
 	})
 
@@ -124,6 +241,32 @@ var _ = Describe("Validator", func() {
 				Expect(len(params.Errors)).To(Equal(1))
 				Expect(params.Errors).To(ContainElement(`"role" must be "OrgManager", "OrgAuditor", "BillingManager" or unset`))
 			})
+
+			It("validates that the data does not exceed the maximum size", func() {
+				params.Data = map[string]interface{}{"blob": strings.Repeat("a", notify.MaxDataSize)}
+
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(len(params.Errors)).To(Equal(1))
+				Expect(params.Errors).To(ContainElement(`"data" must not exceed 16384 bytes`))
+			})
+
+			Context("when the notification has attachments", func() {
+				BeforeEach(func() {
+					params.Attachments = []notify.Attachment{
+						{Filename: "report.csv", Content: "aXRlbSxwcmljZQ=="},
+					}
+				})
+
+				It("rejects them unless attachments are allowed", func() {
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(params.Errors).To(ConsistOf(`"attachments" can only be sent to a user or an email address`))
+
+					validator.AllowAttachments = true
+
+					Expect(validator.Validate(params)).To(BeTrue())
+					Expect(params.Errors).To(BeEmpty())
+				})
+			})
 		})
 	})
 })
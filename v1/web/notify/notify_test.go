@@ -10,6 +10,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
@@ -29,6 +30,7 @@ var _ = Describe("Notify", func() {
 				finder          *mocks.NotificationsFinder
 				validator       *mocks.Validator
 				registrar       *mocks.Registrar
+				clock           *mocks.Clock
 				request         *http.Request
 				rawToken        string
 				client          models.Client
@@ -60,9 +62,23 @@ var _ = Describe("Notify", func() {
 
 				registrar = mocks.NewRegistrar()
 
-				body, err := json.Marshal(map[string]string{
-					"kind_id":  "test_email",
-					"text":     "This is the plain text body of the email",
+				body, err := json.Marshal(map[string]interface{}{
+					"kind_id": "test_email",
+					"text":    "This is the plain text body of the email",
+					"data":    map[string]string{"instance": "db-1"},
+					"attachments": []map[string]string{
+						{
+							"filename":     "report.csv",
+							"content_type": "text/csv",
+							"content":      "aXRlbSxwcmljZQ==",
+						},
+						{
+							"filename":    "logo.png",
+							"content":     "cG5n",
+							"disposition": "inline",
+							"content_id":  "logo",
+						},
+					},
 					"html":     "<!DOCTYPE html><html><head><script type='javascript'></script></head><body class='hello'><p>This is the HTML Body of the email</p><body></html>",
 					"subject":  "Your instance is down",
 					"reply_to": "me@example.com",
@@ -108,11 +124,14 @@ var _ = Describe("Notify", func() {
 				validator = mocks.NewValidator()
 				validator.ValidateCall.Returns.Valid = true
 
-				handler = notify.NewNotify(finder, registrar)
+				clock = mocks.NewClock()
+				clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+
+				handler = notify.NewNotify(finder, registrar, clock)
 			})
 
 			It("delegates to the strategy", func() {
-				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				_, err := handler.Execute(conn, request, context, "space-001", strategy, notify.GUIDValidator{AllowAttachments: true}, vcapRequestID)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(strategy.DispatchCallsCount).To(Equal(1))
@@ -136,16 +155,89 @@ var _ = Describe("Notify", func() {
 						ReplyTo: "me@example.com",
 						Subject: "Your instance is down",
 						Text:    "This is the plain text body of the email",
+						Data:    map[string]interface{}{"instance": "db-1"},
 						HTML: services.HTML{
 							BodyContent:    "<p>This is the HTML Body of the email</p>",
 							BodyAttributes: `class="hello"`,
 							Head:           `<script type="javascript"></script>`,
 							Doctype:        "<!DOCTYPE html>",
 						},
+						Attachments: []services.Attachment{
+							{
+								Filename:    "report.csv",
+								ContentType: "text/csv",
+								Content:     []byte("item,price"),
+							},
+							{
+								Filename:  "logo.png",
+								ContentID: "logo",
+								Inline:    true,
+								Content:   []byte("png"),
+							},
+						},
 					},
 				}))
 			})
 
+			It("passes the trace context of the request on to the strategy", func() {
+				context.Set(notify.TraceSpan, &tracing.Span{
+					Context: tracing.SpanContext{
+						TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+						SpanID:  "00f067aa0ba902b7",
+					},
+				})
+
+				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(strategy.DispatchCalls[0].Receives.Dispatch.VCAPRequest.TraceParent).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+			})
+
+			It("dispatches an email to several recipients with the first to address as the primary one", func() {
+				body, err := json.Marshal(map[string]interface{}{
+					"kind_id": "test_email",
+					"text":    "This is the plain text body of the email",
+					"to":      []string{"first@example.com", "second@example.com"},
+					"cc":      "Copied <cc@example.com>",
+					"bcc":     []string{"bcc@example.com"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				request, err = http.NewRequest("POST", "/emails", bytes.NewBuffer(body))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = handler.Execute(conn, request, context, "", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				message := strategy.DispatchCalls[0].Receives.Dispatch.Message
+				Expect(message.To).To(Equal("first@example.com"))
+				Expect(message.Recipients).To(Equal([]services.Recipient{
+					{Address: "first@example.com", Type: services.RecipientTo},
+					{Address: "second@example.com", Type: services.RecipientTo},
+					{Address: "cc@example.com", Type: services.RecipientCC},
+					{Address: "bcc@example.com", Type: services.RecipientBCC},
+				}))
+			})
+
+			It("does not track recipients for an email to a single address", func() {
+				body, err := json.Marshal(map[string]interface{}{
+					"kind_id": "test_email",
+					"text":    "This is the plain text body of the email",
+					"to":      "only@example.com",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				request, err = http.NewRequest("POST", "/emails", bytes.NewBuffer(body))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = handler.Execute(conn, request, context, "", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				message := strategy.DispatchCalls[0].Receives.Dispatch.Message
+				Expect(message.To).To(Equal("only@example.com"))
+				Expect(message.Recipients).To(BeNil())
+			})
+
 			It("registers the client and kind", func() {
 				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
 				Expect(err).NotTo(HaveOccurred())
@@ -159,6 +251,39 @@ var _ = Describe("Notify", func() {
 				Expect(registrar.RegisterCall.Receives.Kinds).To(ConsistOf([]models.Kind{kind}))
 			})
 
+			Context("when the client has a daily recipient quota", func() {
+				BeforeEach(func() {
+					client.DailyRecipientQuota = 10
+					finder.ClientAndKindCall.Returns.Client = client
+				})
+
+				It("passes the quota along for the recipients to be reserved once they are known", func() {
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.Client.DailyRecipientQuota).To(Equal(10))
+				})
+
+				It("refuses the request when the recipients do not fit in the quota, until midnight UTC", func() {
+					quotaErr := services.RecipientQuotaError{ClientID: "mister-client", Quota: 10}
+					strategy.DispatchCalls = append(strategy.DispatchCalls, mocks.NewStrategyDispatchCall(nil, quotaErr))
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).To(Equal(webutil.TooManyRequestsError{
+						Err:        quotaErr,
+						RetryAfter: 6 * time.Hour,
+					}))
+					Expect(err).To(MatchError(`Client "mister-client" has exceeded its daily quota of 10 recipients`))
+				})
+			})
+
+			It("does not limit clients without a quota", func() {
+				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(strategy.DispatchCalls[0].Receives.Dispatch.Client.DailyRecipientQuota).To(Equal(0))
+			})
+
 			Context("failure cases", func() {
 				Context("when validating params", func() {
 					It("returns a error response when params are missing", func() {
@@ -32,8 +32,11 @@ var _ = Describe("Routes", func() {
 			RequestCounter:                  middleware.RequestCounter{},
 			RequestLogging:                  middleware.RequestLogging{},
 			DatabaseAllocator:               middleware.DatabaseAllocator{},
+			RateLimiter:                     middleware.RateLimiter{},
+			Tracing:                         middleware.Tracing{},
 			NotificationsWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.write"}},
 			EmailsWriteAuthenticator:        middleware.Authenticator{Scopes: []string{"emails.write"}},
+			BodyLimit:                       middleware.BodyLimit{},
 		}.Register(muxer)
 	})
 
@@ -43,7 +46,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.UserHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.BodyLimit{}, middleware.DatabaseAllocator{}, middleware.RateLimiter{}, middleware.Tracing{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
@@ -55,7 +58,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.SpaceHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, middleware.RateLimiter{}, middleware.Tracing{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
@@ -67,7 +70,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.OrganizationHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, middleware.RateLimiter{}, middleware.Tracing{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
@@ -79,7 +82,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.EveryoneHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, middleware.RateLimiter{}, middleware.Tracing{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
@@ -91,7 +94,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.UAAScopeHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, middleware.RateLimiter{}, middleware.Tracing{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
@@ -103,7 +106,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.EmailHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.BodyLimit{}, middleware.DatabaseAllocator{}, middleware.RateLimiter{}, middleware.Tracing{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"emails.write"}))
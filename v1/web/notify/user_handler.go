@@ -26,7 +26,7 @@ func (h UserHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 	userGUID := strings.TrimPrefix(req.URL.Path, "/users/")
 	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
 
-	output, err := h.notify.Execute(conn, req, context, userGUID, h.strategy, GUIDValidator{}, vcapRequestID)
+	output, err := h.notify.Execute(conn, req, context, userGUID, h.strategy, GUIDValidator{AllowAttachments: true}, vcapRequestID)
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
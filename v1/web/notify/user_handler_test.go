@@ -64,7 +64,7 @@ var _ = Describe("NotifyUser", func() {
 				Expect(notifyObj.ExecuteCall.Receives.Context).To(Equal(context))
 				Expect(notifyObj.ExecuteCall.Receives.GUID).To(Equal("user-123"))
 				Expect(notifyObj.ExecuteCall.Receives.Strategy).To(Equal(strategy))
-				Expect(notifyObj.ExecuteCall.Receives.Validator).To(BeAssignableToTypeOf(notify.GUIDValidator{}))
+				Expect(notifyObj.ExecuteCall.Receives.Validator).To(Equal(notify.GUIDValidator{AllowAttachments: true}))
 				Expect(notifyObj.ExecuteCall.Receives.VCAPRequestID).To(Equal("some-request-id"))
 			})
 		})
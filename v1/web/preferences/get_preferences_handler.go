@@ -15,7 +15,7 @@ type errorWriter interface {
 }
 
 type preferencesFinder interface {
-	Find(database services.DatabaseInterface, userGUID string) (services.PreferencesBuilder, error)
+	Find(conn services.ConnectionInterface, userGUID string) (services.PreferencesBuilder, error)
 }
 
 type GetPreferencesHandler struct {
@@ -40,7 +40,7 @@ func (h GetPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 
 	userID := token.Claims["user_id"].(string)
 
-	parsed, err := h.preferences.Find(context.Get("database").(DatabaseInterface), userID)
+	parsed, err := h.preferences.Find(context.Get("database").(DatabaseInterface).Connection(), userID)
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
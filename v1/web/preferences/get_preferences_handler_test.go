@@ -30,6 +30,7 @@ var _ = Describe("GetPreferencesHandler", func() {
 		builder           services.PreferencesBuilder
 		context           stack.Context
 		database          *mocks.Database
+		conn              *mocks.Connection
 
 		TRUE  = true
 		FALSE = false
@@ -64,7 +65,9 @@ var _ = Describe("GetPreferencesHandler", func() {
 			return []byte(helpers.UAAPublicKey), nil
 		})
 
+		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
 
 		context = stack.NewContext()
 		context.Set("token", token)
@@ -92,7 +95,7 @@ var _ = Describe("GetPreferencesHandler", func() {
 	It("passes the proper user guid into execute", func() {
 		handler.ServeHTTP(writer, request, context)
 
-		Expect(preferencesFinder.FindCall.Receives.Database).To(Equal(database))
+		Expect(preferencesFinder.FindCall.Receives.Connection).To(Equal(conn))
 		Expect(preferencesFinder.FindCall.Receives.UserGUID).To(Equal("correct-user"))
 	})
 
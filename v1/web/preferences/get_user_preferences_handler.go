@@ -22,7 +22,7 @@ func NewGetUserPreferencesHandler(preferences preferencesFinder, errWriter error
 func (h GetUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	userGUID := strings.TrimPrefix(req.URL.Path, "/user_preferences/")
 
-	parsed, err := h.preferences.Find(context.Get("database").(DatabaseInterface), userGUID)
+	parsed, err := h.preferences.Find(context.Get("database").(DatabaseInterface).Connection(), userGUID)
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
@@ -27,6 +27,7 @@ var _ = Describe("GetUserPreferencesHandler", func() {
 		builder           services.PreferencesBuilder
 		context           stack.Context
 		database          *mocks.Database
+		conn              *mocks.Connection
 	)
 
 	BeforeEach(func() {
@@ -53,7 +54,9 @@ var _ = Describe("GetUserPreferencesHandler", func() {
 			Email:    true,
 		})
 
+		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
 		context = stack.NewContext()
 		context.Set("database", database)
 
@@ -66,7 +69,7 @@ var _ = Describe("GetUserPreferencesHandler", func() {
 	Context("when a client is making a request for an arbitrary user", func() {
 		It("passes the proper user guid to the finder", func() {
 			handler.ServeHTTP(writer, request, context)
-			Expect(preferencesFinder.FindCall.Receives.Database).To(Equal(database))
+			Expect(preferencesFinder.FindCall.Receives.Connection).To(Equal(conn))
 			Expect(preferencesFinder.FindCall.Receives.UserGUID).To(Equal("af02af02-af02-af02-af02-af02af02af02"))
 		})
 
@@ -26,6 +26,7 @@ type Routes struct {
 	ErrorWriter       errorWriter
 	PreferencesFinder preferencesFinder
 	PreferenceUpdater preferenceUpdater
+	AuditLog          auditLog
 }
 
 func (r Routes) Register(m muxer) {
@@ -34,5 +35,5 @@ func (r Routes) Register(m muxer) {
 	m.Handle("GET", "/user_preferences", NewGetPreferencesHandler(r.PreferencesFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesReadAuthenticator, r.DatabaseAllocator)
 	m.Handle("PATCH", "/user_preferences", NewUpdatePreferencesHandler(r.PreferenceUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/user_preferences/{user_id}", NewGetUserPreferencesHandler(r.PreferencesFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesAdminAuthenticator, r.DatabaseAllocator)
-	m.Handle("PATCH", "/user_preferences/{user_id}", NewUpdateUserPreferencesHandler(r.PreferenceUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("PATCH", "/user_preferences/{user_id}", NewUpdateUserPreferencesHandler(r.PreferenceUpdater, r.PreferencesFinder, r.AuditLog, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesAdminAuthenticator, r.DatabaseAllocator)
 }
@@ -23,6 +23,7 @@ var _ = Describe("Routes", func() {
 			ErrorWriter:       mocks.NewErrorWriter(),
 			PreferencesFinder: mocks.NewPreferencesFinder(),
 			PreferenceUpdater: mocks.NewPreferenceUpdater(),
+			AuditLog:          mocks.NewAuditLog(),
 
 			CORS:                                      middleware.CORS{},
 			RequestCounter:                            middleware.RequestCounter{},
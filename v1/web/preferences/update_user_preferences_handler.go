@@ -9,17 +9,26 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/cloudfoundry-incubator/notifications/valiant"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
+type auditLog interface {
+	Record(conn services.ConnectionInterface, entry services.AuditEntry) error
+}
+
 type UpdateUserPreferencesHandler struct {
 	preferences preferenceUpdater
+	finder      preferencesFinder
+	auditLog    auditLog
 	errorWriter errorWriter
 }
 
-func NewUpdateUserPreferencesHandler(preferences preferenceUpdater, errWriter errorWriter) UpdateUserPreferencesHandler {
+func NewUpdateUserPreferencesHandler(preferences preferenceUpdater, finder preferencesFinder, auditLog auditLog, errWriter errorWriter) UpdateUserPreferencesHandler {
 	return UpdateUserPreferencesHandler{
 		preferences: preferences,
+		finder:      finder,
+		auditLog:    auditLog,
 		errorWriter: errWriter,
 	}
 }
@@ -45,7 +54,19 @@ func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http
 	}
 
 	transaction := connection.Transaction()
-	transaction.Begin()
+	err = transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	previous, err := h.finder.Find(transaction, userGUID)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
 	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userGUID)
 	if err != nil {
 		transaction.Rollback()
@@ -59,6 +80,26 @@ func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http
 		return
 	}
 
+	current, err := h.finder.Find(transaction, userGUID)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.auditLog.Record(transaction, services.AuditEntry{
+		Token:  context.Get("token").(*jwt.Token),
+		Action: services.AuditActionUpdateUserPreferences,
+		Target: "user_preferences/" + userGUID,
+		Before: previous,
+		After:  current,
+	})
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
 	err = transaction.Commit()
 	if err != nil {
 		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
@@ -31,6 +31,13 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			transaction *mocks.Transaction
 			context     stack.Context
 			updater     *mocks.PreferenceUpdater
+			finder      *mocks.PreferencesFinder
+			auditLog    *mocks.AuditLog
+			database    *mocks.Database
+			token       *jwt.Token
+			builder     services.PreferencesBuilder
+			previous    services.PreferencesBuilder
+			current     services.PreferencesBuilder
 			userGUID    string
 			errorWriter *mocks.ErrorWriter
 		)
@@ -41,10 +48,10 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			connection = mocks.NewConnection()
 			connection.TransactionCall.Returns.Transaction = transaction
 
-			database := mocks.NewDatabase()
+			database = mocks.NewDatabase()
 			database.ConnectionCall.Returns.Connection = connection
 
-			builder := services.NewPreferencesBuilder()
+			builder = services.NewPreferencesBuilder()
 			builder.Add(models.Preference{
 				ClientID: "raptors",
 				KindID:   "door-opening",
@@ -79,7 +86,7 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			rawToken := helpers.BuildToken(tokenHeader, tokenClaims)
 			request.Header.Set("Authorization", "Bearer "+rawToken)
 
-			token, err := jwt.Parse(rawToken, func(*jwt.Token) (interface{}, error) {
+			token, err = jwt.Parse(rawToken, func(*jwt.Token) (interface{}, error) {
 				return []byte(helpers.UAAPublicKey), nil
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -88,11 +95,29 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			context.Set("token", token)
 			context.Set("database", database)
 
+			previous = services.NewPreferencesBuilder()
+			previous.Add(models.Preference{
+				ClientID: "raptors",
+				KindID:   "door-opening",
+				Email:    true,
+			})
+
+			current = services.NewPreferencesBuilder()
+			current.Add(models.Preference{
+				ClientID: "raptors",
+				KindID:   "door-opening",
+				Email:    false,
+			})
+			current.GlobalUnsubscribe = true
+
 			updater = mocks.NewPreferenceUpdater()
+			finder = mocks.NewPreferencesFinder()
+			finder.FindCall.Returns.PreferencesBuilders = []services.PreferencesBuilder{previous, current}
+			auditLog = mocks.NewAuditLog()
 			errorWriter = mocks.NewErrorWriter()
 			writer = httptest.NewRecorder()
 
-			handler = preferences.NewUpdateUserPreferencesHandler(updater, errorWriter)
+			handler = preferences.NewUpdateUserPreferencesHandler(updater, finder, auditLog, errorWriter)
 		})
 
 		It("Passes the correct arguments to PreferenceUpdater Execute", func() {
@@ -127,6 +152,25 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			Expect(writer.Code).To(Equal(http.StatusNoContent))
 		})
 
+		It("records the change in the audit log in the same transaction", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(finder.FindCall.CallCount).To(Equal(2))
+			Expect(reflect.ValueOf(finder.FindCall.Receives.Connection).Pointer()).To(Equal(reflect.ValueOf(transaction).Pointer()))
+			Expect(finder.FindCall.Receives.UserGUID).To(Equal(userGUID))
+
+			Expect(auditLog.RecordCall.CallCount).To(Equal(1))
+			Expect(reflect.ValueOf(auditLog.RecordCall.Receives.Connection).Pointer()).To(Equal(reflect.ValueOf(transaction).Pointer()))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+
+			entry := auditLog.RecordCall.Receives.Entry
+			Expect(entry.Token).To(Equal(token))
+			Expect(entry.Action).To(Equal("user_preferences.update"))
+			Expect(entry.Target).To(Equal("user_preferences/the-correct-user"))
+			Expect(entry.Before).To(Equal(previous))
+			Expect(entry.After).To(Equal(current))
+		})
+
 		Context("Failure cases", func() {
 			Context("when global_unsubscribe is not set", func() {
 				It("returns an error when the clients key is missing", func() {
@@ -156,6 +200,37 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 				})
 			})
 
+			It("delegates errors finding the current preferences to the ErrorWriter", func() {
+				finder.FindCall.Returns.Error = errors.New("BOOM!")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("BOOM!")))
+				Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("rolls the change back when it cannot be recorded in the audit log", func() {
+				auditLog.RecordCall.Returns.Error = errors.New("audit log is down")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("audit log is down")))
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("delegates errors beginning the transaction to the ErrorWriter", func() {
+				transaction.BeginCall.Returns.Error = errors.New("cannot begin")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("cannot begin")))
+				Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
 			It("delegates MissingKindOrClientErrors as webutil.ValidationError to the ErrorWriter", func() {
 				updateError := services.MissingKindOrClientError{Err: errors.New("BOOM!")}
 				updater.UpdateCall.Returns.Error = updateError
@@ -192,6 +267,7 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
 				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
 				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(auditLog.RecordCall.CallCount).To(Equal(0))
 			})
 
 			It("delegates transaction errors to the error writer", func() {
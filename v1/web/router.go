@@ -8,18 +8,28 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/keyring"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	postalbounces "github.com/cloudfoundry-incubator/notifications/postal/bounces"
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/util"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/auditevents"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/bounces"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/fanouts"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/info"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/gorilla/mux"
@@ -47,6 +57,15 @@ type Config struct {
 	CORSOrigin           string
 	SQLDB                *sql.DB
 	QueueWaitMaxDuration int
+	KeyRing              keyring.KeyRing
+
+	MailClient                func() *mail.Client
+	UAAKeyRefreshInterval     int
+	HealthCacheDuration       int
+	HealthQueueDepthThreshold int
+	MaxRequestBodySize        int
+
+	Tracer *tracing.Tracer
 }
 
 func NewRouter(mx muxer, config Config) http.Handler {
@@ -59,32 +78,47 @@ func NewRouter(mx muxer, config Config) http.Handler {
 	preferencesRepo := models.NewPreferencesRepo()
 	unsubscribesRepo := models.NewUnsubscribesRepo()
 	messagesRepo := models.NewMessagesRepo(guidGenerator.Generate)
+	attachmentsRepo := models.NewAttachmentsRepo(guidGenerator.Generate)
+	messageRecipientsRepo := models.NewMessageRecipientsRepo()
+	suppressionsRepo := models.NewSuppressionsRepo(guidGenerator.Generate)
+	clientRecipientCountsRepo := models.NewClientRecipientCountsRepo()
 	templatesRepo := models.NewTemplatesRepo()
+	partialsRepo := models.NewPartialsRepo()
+	auditEventsRepo := models.NewAuditEventsRepo(guidGenerator.Generate)
+	fanOutsRepo := models.NewFanOutsRepo(guidGenerator.Generate)
 
 	registrar := services.NewRegistrar(clientsRepo, kindsRepo)
 	notificationsFinder := services.NewNotificationsFinder(clientsRepo, kindsRepo)
 	preferencesFinder := services.NewPreferencesFinder(preferencesRepo, globalUnsubscribesRepo)
 	preferenceUpdater := services.NewPreferenceUpdater(globalUnsubscribesRepo, unsubscribesRepo, kindsRepo)
 	notificationsUpdater := services.NewNotificationsUpdater(kindsRepo)
-	messageFinder := services.NewMessageFinder(messagesRepo)
+	messageFinder := services.NewMessageFinder(messagesRepo, messageRecipientsRepo)
+	clientLimitsUpdater := services.NewClientLimitsUpdater(clientsRepo)
+	bounceProcessor := postalbounces.NewProcessor(messagesRepo, messageRecipientsRepo, suppressionsRepo)
+	auditLog := services.NewAuditLog(auditEventsRepo)
 
 	templatesCollection := collections.NewTemplatesCollection(clientsRepo, kindsRepo, templatesRepo)
+	partialsCollection := collections.NewPartialsCollection(partialsRepo)
+	templateBundlesCollection := collections.NewTemplateBundlesCollection(templatesRepo, partialsRepo, clientsRepo, kindsRepo, templatesCollection, templatesCollection, auditLog)
+	suppressionsCollection := collections.NewSuppressionsCollection(suppressionsRepo)
 
 	templateFinder := services.NewTemplateFinder(templatesRepo)
 	templateUpdater := services.NewTemplateUpdater(templatesRepo)
 	templateLister := services.NewTemplateLister(templatesRepo)
 
-	notifyObj := notify.NewNotify(notificationsFinder, registrar)
+	notifyObj := notify.NewNotify(notificationsFinder, registrar, clock)
 
 	gobbleQueue := gobble.NewQueue(gobble.NewDatabase(config.SQLDB), clock, gobble.Config{
 		WaitMaxDuration: time.Duration(config.QueueWaitMaxDuration) * time.Millisecond,
+		Sealer:          config.KeyRing,
 	})
 
-	v1enqueuer := services.NewEnqueuer(gobbleQueue, messagesRepo, gobble.Initializer{})
+	v1enqueuer := services.NewEnqueuer(gobbleQueue, messagesRepo, attachmentsRepo, messageRecipientsRepo, clientRecipientCountsRepo, gobble.Initializer{})
+	fanOutEnqueuer := services.NewFanOutEnqueuer(gobbleQueue, fanOutsRepo, attachmentsRepo, clientRecipientCountsRepo, gobble.Initializer{})
 
 	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAATokenValidator)
 	cloudController := cf.NewCloudController(config.CCHost, !config.VerifySSL)
-	tokenLoader := uaa.NewTokenLoader(uaaClient)
+	tokenLoader := uaa.NewTokenLoader(uaaClient, clock)
 	spaceLoader := services.NewSpaceLoader(cloudController)
 	organizationLoader := services.NewOrganizationLoader(cloudController)
 	findsUserIDs := services.NewFindsUserIDs(cloudController, uaaClient)
@@ -92,10 +126,10 @@ func NewRouter(mx muxer, config Config) http.Handler {
 
 	emailStrategy := services.NewEmailStrategy(v1enqueuer)
 	userStrategy := services.NewUserStrategy(v1enqueuer)
-	spaceStrategy := services.NewSpaceStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer)
-	organizationStrategy := services.NewOrganizationStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer)
-	everyoneStrategy := services.NewEveryoneStrategy(tokenLoader, allUsers, v1enqueuer)
-	uaaScopeStrategy := services.NewUAAScopeStrategy(tokenLoader, findsUserIDs, v1enqueuer, config.DefaultUAAScopes)
+	spaceStrategy := services.NewSpaceStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, fanOutEnqueuer)
+	organizationStrategy := services.NewOrganizationStrategy(tokenLoader, organizationLoader, findsUserIDs, fanOutEnqueuer)
+	everyoneStrategy := services.NewEveryoneStrategy(tokenLoader, allUsers, fanOutEnqueuer)
+	uaaScopeStrategy := services.NewUAAScopeStrategy(tokenLoader, findsUserIDs, fanOutEnqueuer, config.DefaultUAAScopes)
 
 	errorWriter := webutil.NewErrorWriter()
 
@@ -103,17 +137,36 @@ func NewRouter(mx muxer, config Config) http.Handler {
 	requestLogging := middleware.NewRequestLogging(config.Logger, clock)
 	databaseAllocator := middleware.NewDatabaseAllocator(config.SQLDB, config.DBLoggingEnabled)
 	cors := middleware.NewCORS(config.CORSOrigin)
+	rateLimiter := middleware.NewRateLimiter(clientsRepo, errorWriter, clock)
+	tracingMiddleware := middleware.NewTracing(config.Tracer, mx.GetRouter())
+	bodyLimit := middleware.NewBodyLimit(int64(config.MaxRequestBodySize), errorWriter)
 	auth := func(scope ...string) middleware.Authenticator {
 		return middleware.NewAuthenticator(config.UAATokenValidator, scope...)
 	}
 
 	mx.GetRouter().Handle("/debug/metrics", exp.ExpHandler(metrics.DefaultRegistry)).Methods("GET")
+	mx.GetRouter().Handle("/metrics", prometheus.NewHandler(metrics.DefaultRegistry)).Methods("GET")
 
 	info.Routes{
 		RequestCounter: requestCounter,
 		RequestLogging: requestLogging,
 	}.Register(mx)
 
+	// The signing keys are refreshed periodically, so they are only reported
+	// as stale once a refresh has been missed.
+	keysMaxAge := 2 * time.Duration(config.UAAKeyRefreshInterval) * time.Millisecond
+
+	health.Routes{
+		RequestCounter: requestCounter,
+		Checker: health.NewChecker(map[string]health.Check{
+			"database":         health.NewDatabaseCheck(config.SQLDB),
+			"queue":            health.NewQueueCheck(gobbleQueue, config.HealthQueueDepthThreshold),
+			"uaa":              health.NewSigningKeysCheck(config.UAATokenValidator, keysMaxAge, clock),
+			"smtp":             health.NewSMTPCheck(config.MailClient(), config.Logger.Session("health")),
+			"cloud_controller": health.NewCloudControllerCheck(config.CCHost, !config.VerifySSL),
+		}, time.Duration(config.HealthCacheDuration)*time.Millisecond, clock),
+	}.Register(mx)
+
 	preferences.Routes{
 		CORS:                                      cors,
 		RequestCounter:                            requestCounter,
@@ -126,6 +179,7 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		ErrorWriter:       errorWriter,
 		PreferencesFinder: preferencesFinder,
 		PreferenceUpdater: preferenceUpdater,
+		AuditLog:          auditLog,
 	}.Register(mx)
 
 	clients.Routes{
@@ -136,6 +190,9 @@ func NewRouter(mx muxer, config Config) http.Handler {
 
 		ErrorWriter:      errorWriter,
 		TemplateAssigner: templatesCollection,
+		ClientFinder:     clientsRepo,
+		AuditLog:         auditLog,
+		LimitsUpdater:    clientLimitsUpdater,
 	}.Register(mx)
 
 	messages.Routes{
@@ -148,6 +205,41 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		MessageFinder: messageFinder,
 	}.Register(mx)
 
+	fanouts.Routes{
+		RequestCounter:                  requestCounter,
+		RequestLogging:                  requestLogging,
+		DatabaseAllocator:               databaseAllocator,
+		NotificationsWriteAuthenticator: auth("notifications.write"),
+
+		ErrorWriter:  errorWriter,
+		FanOutFinder: fanOutsRepo,
+	}.Register(mx)
+
+	bounces.Routes{
+		RequestCounter:            requestCounter,
+		RequestLogging:            requestLogging,
+		DatabaseAllocator:         databaseAllocator,
+		BouncesWriteAuthenticator: auth("bounces.write"),
+		BodyLimit:                 bodyLimit,
+
+		ErrorWriter:     errorWriter,
+		BounceProcessor: bounceProcessor,
+	}.Register(mx)
+
+	suppressions.Routes{
+		RequestCounter:                 requestCounter,
+		RequestLogging:                 requestLogging,
+		DatabaseAllocator:              databaseAllocator,
+		SuppressionsAdminAuthenticator: auth("suppressions.admin"),
+
+		ErrorWriter:        errorWriter,
+		SuppressionCreator: suppressionsCollection,
+		SuppressionGetter:  suppressionsCollection,
+		SuppressionLister:  suppressionsCollection,
+		SuppressionUpdater: suppressionsCollection,
+		SuppressionDeleter: suppressionsCollection,
+	}.Register(mx)
+
 	templates.Routes{
 		RequestCounter:                          requestCounter,
 		RequestLogging:                          requestLogging,
@@ -157,12 +249,19 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		NotificationsManageAuthenticator:        auth("notifications.manage"),
 
 		ErrorWriter:               errorWriter,
+		AuditLog:                  auditLog,
 		TemplateFinder:            templateFinder,
 		TemplateUpdater:           templateUpdater,
 		TemplateCreator:           templatesCollection,
 		TemplateDeleter:           templatesCollection,
 		TemplateLister:            templateLister,
 		TemplateAssociationLister: templatesCollection,
+		PartialSetter:             partialsCollection,
+		PartialGetter:             partialsCollection,
+		PartialLister:             partialsCollection,
+		PartialDeleter:            partialsCollection,
+		TemplateExporter:          templateBundlesCollection,
+		TemplateImporter:          templateBundlesCollection,
 	}.Register(mx)
 
 	notifications.Routes{
@@ -177,14 +276,29 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		NotificationsFinder:  notificationsFinder,
 		NotificationsUpdater: notificationsUpdater,
 		TemplateAssigner:     templatesCollection,
+		KindFinder:           kindsRepo,
+		AuditLog:             auditLog,
+	}.Register(mx)
+
+	auditevents.Routes{
+		RequestCounter:               requestCounter,
+		RequestLogging:               requestLogging,
+		DatabaseAllocator:            databaseAllocator,
+		AuditEventsReadAuthenticator: auth("audit_events.read"),
+
+		ErrorWriter:      errorWriter,
+		AuditEventLister: auditLog,
 	}.Register(mx)
 
 	notify.Routes{
 		RequestCounter:                  requestCounter,
 		RequestLogging:                  requestLogging,
 		DatabaseAllocator:               databaseAllocator,
+		RateLimiter:                     rateLimiter,
+		Tracing:                         tracingMiddleware,
 		NotificationsWriteAuthenticator: auth("notifications.write"),
 		EmailsWriteAuthenticator:        auth("emails.write"),
+		BodyLimit:                       bodyLimit,
 
 		ErrorWriter:          errorWriter,
 		Notify:               notifyObj,
@@ -196,5 +310,5 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		EmailStrategy:        emailStrategy,
 	}.Register(mx)
 
-	return mx
+	return tracingMiddleware.Wrap(mx)
 }
@@ -0,0 +1,58 @@
+package suppressions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type errorWriter interface {
+	Write(writer http.ResponseWriter, err error)
+}
+
+type suppressionCreator interface {
+	Create(connection collections.ConnectionInterface, suppression collections.Suppression) (collections.Suppression, error)
+}
+
+type CreateHandler struct {
+	creator     suppressionCreator
+	errorWriter errorWriter
+}
+
+func NewCreateHandler(creator suppressionCreator, errWriter errorWriter) CreateHandler {
+	return CreateHandler{
+		creator:     creator,
+		errorWriter: errWriter,
+	}
+}
+
+func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	params, err := NewSuppressionParams(req.Body)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	suppression, err := h.creator.Create(connection, params.ToSuppression(""))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, NewSuppressionOutput(suppression))
+}
+
+func writeJSON(w http.ResponseWriter, status int, object interface{}) {
+	output, err := json.Marshal(object)
+	if err != nil {
+		panic(err) // No JSON we write into a response should ever panic
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(output)
+}
@@ -0,0 +1,135 @@
+package suppressions_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CreateHandler", func() {
+	var (
+		handler     suppressions.CreateHandler
+		creator     *mocks.SuppressionsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		creator = mocks.NewSuppressionsCollection()
+		creator.CreateCall.Returns.Suppression = collections.Suppression{
+			ID:        "suppression-id",
+			Type:      "domain",
+			Value:     "example.com",
+			Reason:    "compliance request",
+			Hard:      true,
+			ExpiresAt: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("POST", "/suppressions", bytes.NewBufferString(`{
+			"type": "domain",
+			"value": "example.com",
+			"reason": "compliance request",
+			"hard": true,
+			"expires_at": "2027-01-01T00:00:00Z"
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = suppressions.NewCreateHandler(creator, errorWriter)
+	})
+
+	It("creates the suppression and responds with its representation", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(creator.CreateCall.Receives.Connection).To(Equal(connection))
+		Expect(creator.CreateCall.Receives.Suppression).To(Equal(collections.Suppression{
+			Type:      "domain",
+			Value:     "example.com",
+			Reason:    "compliance request",
+			Hard:      true,
+			ExpiresAt: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		}))
+
+		Expect(writer.Code).To(Equal(http.StatusCreated))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "suppression-id",
+			"type": "domain",
+			"value": "example.com",
+			"reason": "compliance request",
+			"hard": true,
+			"expires_at": "2027-01-01T00:00:00Z",
+			"created_at": "2026-10-19T12:00:00Z"
+		}`))
+	})
+
+	It("responds with a null expiry for suppressions that never expire", func() {
+		creator.CreateCall.Returns.Suppression.ExpiresAt = time.Time{}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Body.String()).To(ContainSubstring(`"expires_at":null`))
+	})
+
+	Context("failure cases", func() {
+		It("writes a validation error when a required field is missing", func() {
+			request, err := http.NewRequest("POST", "/suppressions", bytes.NewBufferString(`{"type": "domain"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+
+		It("writes a validation error when the expiry is not a timestamp", func() {
+			request, err := http.NewRequest("POST", "/suppressions", bytes.NewBufferString(`{"type": "domain", "value": "example.com", "expires_at": "tomorrow"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`"expires_at" must be an RFC 3339 timestamp, got "tomorrow"`)}))
+		})
+
+		It("writes a parse error when the body is not valid JSON", func() {
+			request, err := http.NewRequest("POST", "/suppressions", bytes.NewBufferString(`{`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("writes errors from the collection", func() {
+			creator.CreateCall.Returns.Error = errors.New("something bad happened")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("something bad happened")))
+		})
+	})
+})
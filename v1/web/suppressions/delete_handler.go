@@ -0,0 +1,38 @@
+package suppressions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type suppressionDeleter interface {
+	Delete(connection collections.ConnectionInterface, id string) error
+}
+
+type DeleteHandler struct {
+	deleter     suppressionDeleter
+	errorWriter errorWriter
+}
+
+func NewDeleteHandler(deleter suppressionDeleter, errWriter errorWriter) DeleteHandler {
+	return DeleteHandler{
+		deleter:     deleter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeleteHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	id := strings.Split(req.URL.Path, "/suppressions/")[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	err := h.deleter.Delete(connection, id)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,62 @@
+package suppressions_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeleteHandler", func() {
+	var (
+		handler     suppressions.DeleteHandler
+		deleter     *mocks.SuppressionsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		deleter = mocks.NewSuppressionsCollection()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("DELETE", "/suppressions/suppression-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = suppressions.NewDeleteHandler(deleter, errorWriter)
+	})
+
+	It("deletes the suppression", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(deleter.DeleteCall.Receives.Connection).To(Equal(connection))
+		Expect(deleter.DeleteCall.Receives.ID).To(Equal("suppression-id"))
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("writes errors from the collection", func() {
+		deleter.DeleteCall.Returns.Error = errors.New("not found")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("not found")))
+	})
+})
@@ -0,0 +1,38 @@
+package suppressions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type suppressionGetter interface {
+	Get(connection collections.ConnectionInterface, id string) (collections.Suppression, error)
+}
+
+type GetHandler struct {
+	getter      suppressionGetter
+	errorWriter errorWriter
+}
+
+func NewGetHandler(getter suppressionGetter, errWriter errorWriter) GetHandler {
+	return GetHandler{
+		getter:      getter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	id := strings.Split(req.URL.Path, "/suppressions/")[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	suppression, err := h.getter.Get(connection, id)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NewSuppressionOutput(suppression))
+}
@@ -0,0 +1,82 @@
+package suppressions_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetHandler", func() {
+	var (
+		handler     suppressions.GetHandler
+		getter      *mocks.SuppressionsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		getter = mocks.NewSuppressionsCollection()
+		getter.GetCall.Returns.Suppression = collections.Suppression{
+			ID:        "suppression-id",
+			Type:      "address",
+			Value:     "someone@example.com",
+			Reason:    "bounce: 5.1.1",
+			CreatedAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("GET", "/suppressions/suppression-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = suppressions.NewGetHandler(getter, errorWriter)
+	})
+
+	It("responds with the suppression", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(getter.GetCall.Receives.Connection).To(Equal(connection))
+		Expect(getter.GetCall.Receives.ID).To(Equal("suppression-id"))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "suppression-id",
+			"type": "address",
+			"value": "someone@example.com",
+			"reason": "bounce: 5.1.1",
+			"hard": false,
+			"expires_at": null,
+			"created_at": "2026-10-19T12:00:00Z"
+		}`))
+	})
+
+	It("writes errors from the collection", func() {
+		getter.GetCall.Returns.Error = errors.New("not found")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("not found")))
+	})
+})
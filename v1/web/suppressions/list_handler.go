@@ -0,0 +1,44 @@
+package suppressions
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type suppressionLister interface {
+	List(connection collections.ConnectionInterface) ([]collections.Suppression, error)
+}
+
+type ListHandler struct {
+	lister      suppressionLister
+	errorWriter errorWriter
+}
+
+func NewListHandler(lister suppressionLister, errWriter errorWriter) ListHandler {
+	return ListHandler{
+		lister:      lister,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	suppressions, err := h.lister.List(connection)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	structure := map[string][]SuppressionOutput{
+		"suppressions": {},
+	}
+
+	for _, suppression := range suppressions {
+		structure["suppressions"] = append(structure["suppressions"], NewSuppressionOutput(suppression))
+	}
+
+	writeJSON(w, http.StatusOK, structure)
+}
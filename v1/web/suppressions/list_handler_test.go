@@ -0,0 +1,81 @@
+package suppressions_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler     suppressions.ListHandler
+		lister      *mocks.SuppressionsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		lister = mocks.NewSuppressionsCollection()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("GET", "/suppressions", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = suppressions.NewListHandler(lister, errorWriter)
+	})
+
+	It("responds with every suppression", func() {
+		createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		lister.ListCall.Returns.Suppressions = []collections.Suppression{
+			{ID: "first-id", Type: "address", Value: "someone@example.com", CreatedAt: createdAt},
+			{ID: "second-id", Type: "user", Value: "user-123", Hard: true, CreatedAt: createdAt},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(lister.ListCall.Receives.Connection).To(Equal(connection))
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"suppressions": [
+				{"id": "first-id", "type": "address", "value": "someone@example.com", "reason": "", "hard": false, "expires_at": null, "created_at": "2026-10-19T12:00:00Z"},
+				{"id": "second-id", "type": "user", "value": "user-123", "reason": "", "hard": true, "expires_at": null, "created_at": "2026-10-19T12:00:00Z"}
+			]
+		}`))
+	})
+
+	It("responds with an empty list when nothing is suppressed", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Body.String()).To(MatchJSON(`{"suppressions": []}`))
+	})
+
+	It("writes errors from the collection", func() {
+		lister.ListCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+})
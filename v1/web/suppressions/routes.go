@@ -0,0 +1,29 @@
+package suppressions
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestCounter                 stack.Middleware
+	RequestLogging                 stack.Middleware
+	DatabaseAllocator              stack.Middleware
+	SuppressionsAdminAuthenticator stack.Middleware
+
+	ErrorWriter        errorWriter
+	SuppressionCreator suppressionCreator
+	SuppressionGetter  suppressionGetter
+	SuppressionLister  suppressionLister
+	SuppressionUpdater suppressionUpdater
+	SuppressionDeleter suppressionDeleter
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/suppressions", NewListHandler(r.SuppressionLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SuppressionsAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/suppressions", NewCreateHandler(r.SuppressionCreator, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SuppressionsAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/suppressions/{suppression_id}", NewGetHandler(r.SuppressionGetter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SuppressionsAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/suppressions/{suppression_id}", NewUpdateHandler(r.SuppressionUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SuppressionsAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/suppressions/{suppression_id}", NewDeleteHandler(r.SuppressionDeleter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SuppressionsAdminAuthenticator, r.DatabaseAllocator)
+}
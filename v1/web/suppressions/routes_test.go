@@ -0,0 +1,98 @@
+package suppressions_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		collection := mocks.NewSuppressionsCollection()
+
+		muxer = web.NewMuxer()
+		suppressions.Routes{
+			RequestCounter:                 middleware.RequestCounter{},
+			RequestLogging:                 middleware.RequestLogging{},
+			DatabaseAllocator:              middleware.DatabaseAllocator{},
+			SuppressionsAdminAuthenticator: middleware.Authenticator{Scopes: []string{"suppressions.admin"}},
+
+			ErrorWriter:        mocks.NewErrorWriter(),
+			SuppressionCreator: collection,
+			SuppressionGetter:  collection,
+			SuppressionLister:  collection,
+			SuppressionUpdater: collection,
+			SuppressionDeleter: collection,
+		}.Register(muxer)
+	})
+
+	It("routes GET /suppressions", func() {
+		request, err := http.NewRequest("GET", "/suppressions", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(suppressions.ListHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"suppressions.admin"}))
+	})
+
+	It("routes POST /suppressions", func() {
+		request, err := http.NewRequest("POST", "/suppressions", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(suppressions.CreateHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"suppressions.admin"}))
+	})
+
+	It("routes GET /suppressions/{suppression_id}", func() {
+		request, err := http.NewRequest("GET", "/suppressions/some-suppression-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(suppressions.GetHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"suppressions.admin"}))
+	})
+
+	It("routes PUT /suppressions/{suppression_id}", func() {
+		request, err := http.NewRequest("PUT", "/suppressions/some-suppression-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(suppressions.UpdateHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"suppressions.admin"}))
+	})
+
+	It("routes DELETE /suppressions/{suppression_id}", func() {
+		request, err := http.NewRequest("DELETE", "/suppressions/some-suppression-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(suppressions.DeleteHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"suppressions.admin"}))
+	})
+})
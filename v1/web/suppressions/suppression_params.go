@@ -0,0 +1,88 @@
+package suppressions
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/cloudfoundry-incubator/notifications/valiant"
+)
+
+type SuppressionParams struct {
+	Type      string `json:"type" validate-required:"true"`
+	Value     string `json:"value" validate-required:"true"`
+	Reason    string `json:"reason"`
+	Hard      bool   `json:"hard"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type SuppressionOutput struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason"`
+	Hard      bool       `json:"hard"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewSuppressionParams(body io.ReadCloser) (SuppressionParams, error) {
+	defer body.Close()
+
+	var params SuppressionParams
+	validator := valiant.NewValidator(body)
+
+	err := validator.Validate(&params)
+	if err != nil {
+		switch err.(type) {
+		case valiant.RequiredFieldError:
+			return params, webutil.ValidationError{Err: err}
+		default:
+			return params, webutil.ParseError{}
+		}
+	}
+
+	if params.ExpiresAt != "" {
+		_, err = time.Parse(time.RFC3339, params.ExpiresAt)
+		if err != nil {
+			return params, webutil.ValidationError{Err: fmt.Errorf("\"expires_at\" must be an RFC 3339 timestamp, got %q", params.ExpiresAt)}
+		}
+	}
+
+	return params, nil
+}
+
+func (p SuppressionParams) ToSuppression(id string) collections.Suppression {
+	var expiresAt time.Time
+	if p.ExpiresAt != "" {
+		expiresAt, _ = time.Parse(time.RFC3339, p.ExpiresAt)
+	}
+
+	return collections.Suppression{
+		ID:        id,
+		Type:      p.Type,
+		Value:     p.Value,
+		Reason:    p.Reason,
+		Hard:      p.Hard,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func NewSuppressionOutput(suppression collections.Suppression) SuppressionOutput {
+	output := SuppressionOutput{
+		ID:        suppression.ID,
+		Type:      suppression.Type,
+		Value:     suppression.Value,
+		Reason:    suppression.Reason,
+		Hard:      suppression.Hard,
+		CreatedAt: suppression.CreatedAt,
+	}
+
+	if !suppression.ExpiresAt.IsZero() {
+		output.ExpiresAt = &suppression.ExpiresAt
+	}
+
+	return output
+}
@@ -0,0 +1,45 @@
+package suppressions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type suppressionUpdater interface {
+	Update(connection collections.ConnectionInterface, suppression collections.Suppression) (collections.Suppression, error)
+}
+
+type UpdateHandler struct {
+	updater     suppressionUpdater
+	errorWriter errorWriter
+}
+
+func NewUpdateHandler(updater suppressionUpdater, errWriter errorWriter) UpdateHandler {
+	return UpdateHandler{
+		updater:     updater,
+		errorWriter: errWriter,
+	}
+}
+
+func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	id := strings.Split(req.URL.Path, "/suppressions/")[1]
+
+	params, err := NewSuppressionParams(req.Body)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	suppression, err := h.updater.Update(connection, params.ToSuppression(id))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NewSuppressionOutput(suppression))
+}
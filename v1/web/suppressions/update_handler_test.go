@@ -0,0 +1,105 @@
+package suppressions_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/suppressions"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UpdateHandler", func() {
+	var (
+		handler     suppressions.UpdateHandler
+		updater     *mocks.SuppressionsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		updater = mocks.NewSuppressionsCollection()
+		updater.UpdateCall.Returns.Suppression = collections.Suppression{
+			ID:     "suppression-id",
+			Type:   "user",
+			Value:  "user-123",
+			Reason: "account closed",
+			Hard:   true,
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("PUT", "/suppressions/suppression-id", bytes.NewBufferString(`{
+			"type": "user",
+			"value": "user-123",
+			"reason": "account closed",
+			"hard": true
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = suppressions.NewUpdateHandler(updater, errorWriter)
+	})
+
+	It("updates the suppression and responds with its representation", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(updater.UpdateCall.Receives.Connection).To(Equal(connection))
+		Expect(updater.UpdateCall.Receives.Suppression).To(Equal(collections.Suppression{
+			ID:     "suppression-id",
+			Type:   "user",
+			Value:  "user-123",
+			Reason: "account closed",
+			Hard:   true,
+		}))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "suppression-id",
+			"type": "user",
+			"value": "user-123",
+			"reason": "account closed",
+			"hard": true,
+			"expires_at": null,
+			"created_at": "0001-01-01T00:00:00Z"
+		}`))
+	})
+
+	Context("failure cases", func() {
+		It("writes a validation error when a required field is missing", func() {
+			request, err := http.NewRequest("PUT", "/suppressions/suppression-id", bytes.NewBufferString(`{"value": "user-123"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+
+		It("writes errors from the collection", func() {
+			updater.UpdateCall.Returns.Error = errors.New("not found")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("not found")))
+		})
+	})
+})
@@ -44,6 +44,8 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 		HTML:     templateParams.HTML,
 		Subject:  templateParams.Subject,
 		Metadata: string(templateParams.Metadata),
+		Layout:   templateParams.Layout,
+		Markdown: templateParams.Markdown,
 	})
 	if err != nil {
 		h.errorWriter.Write(w, webutil.TemplateCreateError{})
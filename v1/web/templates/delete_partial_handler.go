@@ -0,0 +1,38 @@
+package templates
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type partialDeleter interface {
+	Delete(connection collections.ConnectionInterface, name string) error
+}
+
+type DeletePartialHandler struct {
+	deleter     partialDeleter
+	errorWriter errorWriter
+}
+
+func NewDeletePartialHandler(deleter partialDeleter, errWriter errorWriter) DeletePartialHandler {
+	return DeletePartialHandler{
+		deleter:     deleter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeletePartialHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	name := strings.Split(req.URL.Path, "/partials/")[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	err := h.deleter.Delete(connection, name)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeletePartialHandler", func() {
+	var (
+		handler     templates.DeletePartialHandler
+		deleter     *mocks.PartialsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		deleter = mocks.NewPartialsCollection()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("DELETE", "/partials/footer", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = templates.NewDeletePartialHandler(deleter, errorWriter)
+	})
+
+	It("deletes the partial", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(deleter.DeleteCall.Receives.Connection).To(Equal(connection))
+		Expect(deleter.DeleteCall.Receives.Name).To(Equal("footer"))
+	})
+
+	It("writes errors from the deleter", func() {
+		deleter.DeleteCall.Returns.Error = errors.New("BOOM!")
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("BOOM!")))
+	})
+})
@@ -0,0 +1,37 @@
+package templates
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type templateExporter interface {
+	Export(connection collections.ConnectionInterface) (collections.TemplateBundle, error)
+}
+
+type ExportHandler struct {
+	exporter    templateExporter
+	errorWriter errorWriter
+}
+
+func NewExportHandler(exporter templateExporter, errWriter errorWriter) ExportHandler {
+	return ExportHandler{
+		exporter:    exporter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ExportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	database := context.Get("database").(DatabaseInterface)
+
+	bundle, err := h.exporter.Export(database.Connection())
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="templates.json"`)
+	writeJSON(w, http.StatusOK, NewTemplateBundleDocument(bundle))
+}
@@ -0,0 +1,108 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExportHandler", func() {
+	var (
+		handler     templates.ExportHandler
+		exporter    *mocks.TemplateBundler
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		exporter = mocks.NewTemplateBundler()
+		exporter.ExportCall.Returns.Bundle = collections.TemplateBundle{
+			Version: collections.TemplateBundleVersion,
+			Templates: []collections.BundledTemplate{
+				{
+					Template: collections.Template{
+						Name:     "Some Template",
+						Subject:  "{{.Subject}}",
+						Text:     "some-text",
+						HTML:     "<p>some-html</p>",
+						Metadata: `{"some": "metadata"}`,
+						Layout:   "branded",
+					},
+					Associations: []collections.TemplateAssociation{
+						{ClientID: "some-client"},
+						{ClientID: "some-client", NotificationID: "some-notification"},
+					},
+				},
+			},
+			Partials: []collections.Partial{
+				{Name: "branded", Content: `<html>{{template "footer" .}}</html>`},
+				{Name: "footer", Content: "<footer>{{.Organization}}</footer>"},
+			},
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("GET", "/templates/export", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = templates.NewExportHandler(exporter, errorWriter)
+	})
+
+	It("responds with the template bundle", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(exporter.ExportCall.Receives.Connection).To(Equal(connection))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.HeaderMap.Get("Content-Disposition")).To(Equal(`attachment; filename="templates.json"`))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"version": 1,
+			"templates": [
+				{
+					"name": "Some Template",
+					"subject": "{{.Subject}}",
+					"text": "some-text",
+					"html": "<p>some-html</p>",
+					"metadata": {"some": "metadata"},
+					"layout": "branded",
+					"associations": [
+						{"client": "some-client"},
+						{"client": "some-client", "notification": "some-notification"}
+					]
+				}
+			],
+			"partials": [
+				{"name": "branded", "content": "<html>{{template \"footer\" .}}</html>"},
+				{"name": "footer", "content": "<footer>{{.Organization}}</footer>"}
+			]
+		}`))
+	})
+
+	It("writes errors from the exporter", func() {
+		exporter.ExportCall.Returns.Error = errors.New("BOOM!")
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("BOOM!")))
+	})
+})
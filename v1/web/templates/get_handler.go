@@ -14,6 +14,8 @@ type TemplateOutput struct {
 	HTML     string                 `json:"html"`
 	Text     string                 `json:"text"`
 	Metadata map[string]interface{} `json:"metadata"`
+	Layout   string                 `json:"layout,omitempty"`
+	Markdown string                 `json:"markdown,omitempty"`
 }
 
 type GetHandler struct {
@@ -50,6 +52,8 @@ func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		HTML:     template.HTML,
 		Text:     template.Text,
 		Metadata: metadata,
+		Layout:   template.Layout,
+		Markdown: template.Markdown,
 	}
 
 	writeJSON(w, http.StatusOK, templateOutput)
@@ -0,0 +1,41 @@
+package templates
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type partialGetter interface {
+	Get(connection collections.ConnectionInterface, name string) (collections.Partial, error)
+}
+
+type GetPartialHandler struct {
+	getter      partialGetter
+	errorWriter errorWriter
+}
+
+func NewGetPartialHandler(getter partialGetter, errWriter errorWriter) GetPartialHandler {
+	return GetPartialHandler{
+		getter:      getter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetPartialHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	name := strings.Split(req.URL.Path, "/partials/")[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	partial, err := h.getter.Get(connection, name)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PartialOutput{
+		Name:    partial.Name,
+		Content: partial.Content,
+	})
+}
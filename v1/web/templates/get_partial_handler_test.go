@@ -0,0 +1,73 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetPartialHandler", func() {
+	var (
+		handler     templates.GetPartialHandler
+		getter      *mocks.PartialsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		getter = mocks.NewPartialsCollection()
+		getter.GetCall.Returns.Partial = collections.Partial{
+			Name:    "footer",
+			Content: "the footer",
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("GET", "/partials/footer", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = templates.NewGetPartialHandler(getter, errorWriter)
+	})
+
+	It("responds with the partial", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(getter.GetCall.Receives.Connection).To(Equal(connection))
+		Expect(getter.GetCall.Receives.Name).To(Equal("footer"))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"name": "footer",
+			"content": "the footer"
+		}`))
+	})
+
+	It("writes errors from the getter", func() {
+		getter.GetCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+	})
+})
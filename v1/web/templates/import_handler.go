@@ -0,0 +1,113 @@
+package templates
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+type templateImporter interface {
+	Import(connection collections.ConnectionInterface, bundle collections.TemplateBundle, options collections.ImportOptions) (collections.ImportReport, error)
+}
+
+type ImportResultOutput struct {
+	Name         string                `json:"name"`
+	ImportedName string                `json:"imported_name"`
+	TemplateID   string                `json:"template_id"`
+	Action       string                `json:"action"`
+	Associations []TemplateAssociation `json:"associations"`
+}
+
+type PartialImportResultOutput struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+type ImportOutput struct {
+	DryRun    bool                        `json:"dry_run"`
+	Templates []ImportResultOutput        `json:"templates"`
+	Partials  []PartialImportResultOutput `json:"partials"`
+}
+
+type ImportHandler struct {
+	importer    templateImporter
+	errorWriter errorWriter
+}
+
+func NewImportHandler(importer templateImporter, errWriter errorWriter) ImportHandler {
+	return ImportHandler{
+		importer:    importer,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ImportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	var dryRun bool
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"dry_run" must be true or false`)})
+			return
+		}
+	}
+
+	document, err := ParseTemplateBundleDocument(req.Body)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	report, err := h.importer.Import(database.Connection(), document.ToBundle(), collections.ImportOptions{
+		DryRun:     dryRun,
+		OnConflict: query.Get("on_conflict"),
+		Token:      context.Get("token").(*jwt.Token),
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	output := ImportOutput{
+		DryRun:    dryRun,
+		Templates: []ImportResultOutput{},
+		Partials:  []PartialImportResultOutput{},
+	}
+
+	for _, result := range report.Templates {
+		resultOutput := ImportResultOutput{
+			Name:         result.Name,
+			ImportedName: result.ImportedName,
+			TemplateID:   result.TemplateID,
+			Action:       result.Action,
+			Associations: []TemplateAssociation{},
+		}
+
+		for _, association := range result.Associations {
+			resultOutput.Associations = append(resultOutput.Associations, TemplateAssociation{
+				Client:       association.ClientID,
+				Notification: association.NotificationID,
+			})
+		}
+
+		output.Templates = append(output.Templates, resultOutput)
+	}
+
+	for _, result := range report.Partials {
+		output.Partials = append(output.Partials, PartialImportResultOutput{
+			Name:   result.Name,
+			Action: result.Action,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
@@ -0,0 +1,164 @@
+package templates_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImportHandler", func() {
+	var (
+		handler     templates.ImportHandler
+		importer    *mocks.TemplateBundler
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		connection  *mocks.Connection
+		body        string
+		token       *jwt.Token
+	)
+
+	BeforeEach(func() {
+		importer = mocks.NewTemplateBundler()
+		importer.ImportCall.Returns.Report = collections.ImportReport{
+			Templates: []collections.ImportResult{
+				{
+					Name:         "Some Template",
+					ImportedName: "Some Template (2)",
+					TemplateID:   "some-template-id",
+					Action:       collections.ImportRenamed,
+					Associations: []collections.TemplateAssociation{
+						{ClientID: "some-client", NotificationID: "some-notification"},
+					},
+				},
+			},
+			Partials: []collections.PartialImportResult{
+				{Name: "footer", Action: collections.ImportCreated},
+			},
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		token = &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}}
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", token)
+
+		body = `{
+			"version": 1,
+			"templates": [
+				{
+					"name": "Some Template",
+					"html": "<p>some-html</p>",
+					"metadata": {"some": "metadata"},
+					"associations": [{"client": "some-client", "notification": "some-notification"}]
+				}
+			],
+			"partials": [
+				{"name": "footer", "content": "<footer>{{.Organization}}</footer>"}
+			]
+		}`
+
+		handler = templates.NewImportHandler(importer, errorWriter)
+	})
+
+	It("imports the bundle and responds with the results", func() {
+		request, err := http.NewRequest("POST", "/templates/import?on_conflict=rename", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(importer.ImportCall.Receives.Connection).To(Equal(connection))
+		Expect(importer.ImportCall.Receives.Options).To(Equal(collections.ImportOptions{
+			OnConflict: collections.ConflictRename,
+			Token:      token,
+		}))
+		Expect(importer.ImportCall.Receives.Bundle).To(Equal(collections.TemplateBundle{
+			Version: 1,
+			Templates: []collections.BundledTemplate{
+				{
+					Template: collections.Template{
+						Name:     "Some Template",
+						HTML:     "<p>some-html</p>",
+						Metadata: `{"some": "metadata"}`,
+					},
+					Associations: []collections.TemplateAssociation{
+						{ClientID: "some-client", NotificationID: "some-notification"},
+					},
+				},
+			},
+			Partials: []collections.Partial{
+				{Name: "footer", Content: "<footer>{{.Organization}}</footer>"},
+			},
+		}))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"dry_run": false,
+			"templates": [
+				{
+					"name": "Some Template",
+					"imported_name": "Some Template (2)",
+					"template_id": "some-template-id",
+					"action": "renamed",
+					"associations": [{"client": "some-client", "notification": "some-notification"}]
+				}
+			],
+			"partials": [
+				{"name": "footer", "action": "created"}
+			]
+		}`))
+	})
+
+	It("passes the dry run flag to the importer", func() {
+		request, err := http.NewRequest("POST", "/templates/import?dry_run=true", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(importer.ImportCall.Receives.Options.DryRun).To(BeTrue())
+		Expect(writer.Body.String()).To(ContainSubstring(`"dry_run":true`))
+	})
+
+	It("writes a validation error when the dry run flag is malformed", func() {
+		request, err := http.NewRequest("POST", "/templates/import?dry_run=maybe", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+	})
+
+	It("writes a parse error when the bundle is not valid JSON", func() {
+		request, err := http.NewRequest("POST", "/templates/import", bytes.NewBufferString(`{"version": `))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ParseError{}))
+	})
+
+	It("writes errors from the importer", func() {
+		importer.ImportCall.Returns.Error = collections.TemplateBundleError{}
+
+		request, err := http.NewRequest("POST", "/templates/import", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(collections.TemplateBundleError{}))
+	})
+})
@@ -0,0 +1,47 @@
+package templates
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type partialLister interface {
+	List(connection collections.ConnectionInterface) ([]collections.Partial, error)
+}
+
+type ListPartialsHandler struct {
+	lister      partialLister
+	errorWriter errorWriter
+}
+
+func NewListPartialsHandler(lister partialLister, errWriter errorWriter) ListPartialsHandler {
+	return ListPartialsHandler{
+		lister:      lister,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListPartialsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	partials, err := h.lister.List(connection)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	structure := map[string][]PartialOutput{
+		"partials": {},
+	}
+
+	for _, partial := range partials {
+		structure["partials"] = append(structure["partials"], PartialOutput{
+			Name:    partial.Name,
+			Content: partial.Content,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, structure)
+}
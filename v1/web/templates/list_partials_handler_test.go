@@ -0,0 +1,76 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListPartialsHandler", func() {
+	var (
+		handler     templates.ListPartialsHandler
+		lister      *mocks.PartialsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		lister = mocks.NewPartialsCollection()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = mocks.NewConnection()
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("GET", "/partials", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = templates.NewListPartialsHandler(lister, errorWriter)
+	})
+
+	It("responds with the list of partials", func() {
+		lister.ListCall.Returns.Partials = []collections.Partial{
+			{Name: "footer", Content: "the footer"},
+			{Name: "header", Content: "the header"},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"partials": [
+				{"name": "footer", "content": "the footer"},
+				{"name": "header", "content": "the header"}
+			]
+		}`))
+	})
+
+	It("responds with an empty list when there are no partials", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"partials": []}`))
+	})
+
+	It("writes errors from the lister", func() {
+		lister.ListCall.Returns.Error = errors.New("BOOM!")
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("BOOM!")))
+	})
+})
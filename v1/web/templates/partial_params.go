@@ -0,0 +1,36 @@
+package templates
+
+import (
+	"io"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/cloudfoundry-incubator/notifications/valiant"
+)
+
+type PartialParams struct {
+	Content string `json:"content" validate-required:"true"`
+}
+
+type PartialOutput struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func NewPartialParams(body io.ReadCloser) (PartialParams, error) {
+	defer body.Close()
+
+	var partial PartialParams
+	validator := valiant.NewValidator(body)
+
+	err := validator.Validate(&partial)
+	if err != nil {
+		switch err.(type) {
+		case valiant.RequiredFieldError:
+			return partial, webutil.ValidationError{Err: err}
+		default:
+			return partial, webutil.ParseError{}
+		}
+	}
+
+	return partial, nil
+}
@@ -15,21 +15,34 @@ type Routes struct {
 	NotificationsManageAuthenticator        stack.Middleware
 
 	ErrorWriter               errorWriter
+	AuditLog                  auditLog
 	TemplateFinder            templateFinder
 	TemplateLister            templateLister
 	TemplateUpdater           templateUpdater
 	TemplateCreator           templateCreator
 	TemplateDeleter           templateDeleter
 	TemplateAssociationLister templateAssociationLister
+	PartialSetter             partialSetter
+	PartialGetter             partialGetter
+	PartialLister             partialLister
+	PartialDeleter            partialDeleter
+	TemplateExporter          templateExporter
+	TemplateImporter          templateImporter
 }
 
 func (r Routes) Register(m muxer) {
 	m.Handle("GET", "/default_template", NewGetDefaultHandler(r.TemplateFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
-	m.Handle("PUT", "/default_template", NewUpdateDefaultHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/default_template", NewUpdateDefaultHandler(r.TemplateFinder, r.TemplateUpdater, r.AuditLog, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/templates", NewListHandler(r.TemplateLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
 	m.Handle("POST", "/templates", NewCreateHandler(r.TemplateCreator, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/templates/export", NewExportHandler(r.TemplateExporter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/templates/import", NewImportHandler(r.TemplateImporter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/templates/{template_id}", NewGetHandler(r.TemplateFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
 	m.Handle("PUT", "/templates/{template_id}", NewUpdateHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("DELETE", "/templates/{template_id}", NewDeleteHandler(r.TemplateDeleter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/templates/{template_id}/associations", NewListAssociationsHandler(r.TemplateAssociationLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/partials", NewListPartialsHandler(r.PartialLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/partials/{name}", NewGetPartialHandler(r.PartialGetter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/partials/{name}", NewSetPartialHandler(r.PartialSetter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/partials/{name}", NewDeletePartialHandler(r.PartialDeleter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
 }
@@ -21,12 +21,17 @@ var _ = Describe("Routes", func() {
 		muxer = web.NewMuxer()
 		templates.Routes{
 			ErrorWriter:               mocks.NewErrorWriter(),
+			AuditLog:                  mocks.NewAuditLog(),
 			TemplateFinder:            mocks.NewTemplateFinder(),
 			TemplateUpdater:           mocks.NewTemplateUpdater(),
 			TemplateCreator:           mocks.NewTemplateCreator(),
 			TemplateDeleter:           mocks.NewTemplateDeleter(),
 			TemplateLister:            mocks.NewTemplateLister(),
 			TemplateAssociationLister: mocks.NewTemplateAssociationLister(),
+			PartialSetter:             mocks.NewPartialsCollection(),
+			PartialGetter:             mocks.NewPartialsCollection(),
+			PartialLister:             mocks.NewPartialsCollection(),
+			PartialDeleter:            mocks.NewPartialsCollection(),
 
 			RequestCounter:                          middleware.RequestCounter{},
 			RequestLogging:                          middleware.RequestLogging{},
@@ -111,6 +116,30 @@ var _ = Describe("Routes", func() {
 			authenticator := s.Middleware[2].(middleware.Authenticator)
 			Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
 		})
+
+		It("routes GET /templates/export", func() {
+			request, err := http.NewRequest("GET", "/templates/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ExportHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+		})
+
+		It("routes POST /templates/import", func() {
+			request, err := http.NewRequest("POST", "/templates/import", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ImportHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+		})
 	})
 
 	Describe("/default_template", func() {
@@ -138,4 +167,54 @@ var _ = Describe("Routes", func() {
 			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
 		})
 	})
+
+	Describe("/partials", func() {
+		It("routes GET /partials", func() {
+			request, err := http.NewRequest("GET", "/partials", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ListPartialsHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes GET /partials/{name}", func() {
+			request, err := http.NewRequest("GET", "/partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.GetPartialHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes PUT /partials/{name}", func() {
+			request, err := http.NewRequest("PUT", "/partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.SetPartialHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+
+		It("routes DELETE /partials/{name}", func() {
+			request, err := http.NewRequest("DELETE", "/partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.DeletePartialHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+	})
 })
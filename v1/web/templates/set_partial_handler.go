@@ -0,0 +1,51 @@
+package templates
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type partialSetter interface {
+	Set(connection collections.ConnectionInterface, partial collections.Partial) (collections.Partial, error)
+}
+
+type SetPartialHandler struct {
+	setter      partialSetter
+	errorWriter errorWriter
+}
+
+func NewSetPartialHandler(setter partialSetter, errWriter errorWriter) SetPartialHandler {
+	return SetPartialHandler{
+		setter:      setter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h SetPartialHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	name := strings.Split(req.URL.Path, "/partials/")[1]
+
+	params, err := NewPartialParams(req.Body)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	partial, err := h.setter.Set(connection, collections.Partial{
+		Name:    name,
+		Content: params.Content,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PartialOutput{
+		Name:    partial.Name,
+		Content: partial.Content,
+	})
+}
@@ -0,0 +1,93 @@
+package templates_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetPartialHandler", func() {
+	var (
+		handler     templates.SetPartialHandler
+		setter      *mocks.PartialsCollection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+		connection  *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		setter = mocks.NewPartialsCollection()
+		setter.SetCall.Returns.Partial = collections.Partial{
+			Name:    "footer",
+			Content: "<footer>{{.Organization}}</footer>",
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		request, err = http.NewRequest("PUT", "/partials/footer", bytes.NewBufferString(`{"content": "<footer>{{.Organization}}</footer>"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = templates.NewSetPartialHandler(setter, errorWriter)
+	})
+
+	It("sets the partial and responds with its representation", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(setter.SetCall.Receives.Connection).To(Equal(connection))
+		Expect(setter.SetCall.Receives.Partial).To(Equal(collections.Partial{
+			Name:    "footer",
+			Content: "<footer>{{.Organization}}</footer>",
+		}))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"name": "footer",
+			"content": "<footer>{{.Organization}}</footer>"
+		}`))
+	})
+
+	It("writes a validation error when the content is missing", func() {
+		request, err := http.NewRequest("PUT", "/partials/footer", bytes.NewBufferString(`{}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+	})
+
+	It("writes a parse error for an invalid request", func() {
+		request, err := http.NewRequest("PUT", "/partials/footer", bytes.NewBufferString(`{"content": `))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ParseError{}))
+	})
+
+	It("writes errors from the setter", func() {
+		setter.SetCall.Returns.Error = collections.PartialValidationError{Err: errors.New("cycle")}
+
+		handler.ServeHTTP(writer, request, context)
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(collections.PartialValidationError{Err: errors.New("cycle")}))
+	})
+})
@@ -0,0 +1,131 @@
+package templates
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+)
+
+type TemplateBundleDocument struct {
+	Version   int                       `json:"version"`
+	Templates []BundledTemplateDocument `json:"templates"`
+	Partials  []BundledPartialDocument  `json:"partials"`
+}
+
+type BundledTemplateDocument struct {
+	Name         string                `json:"name"`
+	Subject      string                `json:"subject"`
+	Text         string                `json:"text"`
+	HTML         string                `json:"html"`
+	Metadata     json.RawMessage       `json:"metadata"`
+	Layout       string                `json:"layout,omitempty"`
+	Markdown     string                `json:"markdown,omitempty"`
+	Associations []TemplateAssociation `json:"associations"`
+}
+
+type BundledPartialDocument struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func NewTemplateBundleDocument(bundle collections.TemplateBundle) TemplateBundleDocument {
+	document := TemplateBundleDocument{
+		Version:   bundle.Version,
+		Templates: []BundledTemplateDocument{},
+		Partials:  []BundledPartialDocument{},
+	}
+
+	for _, template := range bundle.Templates {
+		metadata := json.RawMessage(template.Metadata)
+		if !json.Valid(metadata) {
+			metadata = json.RawMessage("{}")
+		}
+
+		bundled := BundledTemplateDocument{
+			Name:         template.Name,
+			Subject:      template.Subject,
+			Text:         template.Text,
+			HTML:         template.HTML,
+			Metadata:     metadata,
+			Layout:       template.Layout,
+			Markdown:     template.Markdown,
+			Associations: []TemplateAssociation{},
+		}
+
+		for _, association := range template.Associations {
+			bundled.Associations = append(bundled.Associations, TemplateAssociation{
+				Client:       association.ClientID,
+				Notification: association.NotificationID,
+			})
+		}
+
+		document.Templates = append(document.Templates, bundled)
+	}
+
+	for _, partial := range bundle.Partials {
+		document.Partials = append(document.Partials, BundledPartialDocument{
+			Name:    partial.Name,
+			Content: partial.Content,
+		})
+	}
+
+	return document
+}
+
+func ParseTemplateBundleDocument(body io.ReadCloser) (TemplateBundleDocument, error) {
+	defer body.Close()
+
+	var document TemplateBundleDocument
+	err := json.NewDecoder(body).Decode(&document)
+	if err != nil {
+		return document, webutil.ParseError{}
+	}
+
+	return document, nil
+}
+
+func (d TemplateBundleDocument) ToBundle() collections.TemplateBundle {
+	bundle := collections.TemplateBundle{
+		Version:   d.Version,
+		Templates: []collections.BundledTemplate{},
+	}
+
+	for _, template := range d.Templates {
+		metadata := string(template.Metadata)
+		if metadata == "" || metadata == "null" {
+			metadata = "{}"
+		}
+
+		bundled := collections.BundledTemplate{
+			Template: collections.Template{
+				Name:     template.Name,
+				Subject:  template.Subject,
+				Text:     template.Text,
+				HTML:     template.HTML,
+				Metadata: metadata,
+				Layout:   template.Layout,
+				Markdown: template.Markdown,
+			},
+		}
+
+		for _, association := range template.Associations {
+			bundled.Associations = append(bundled.Associations, collections.TemplateAssociation{
+				ClientID:       association.Client,
+				NotificationID: association.Notification,
+			})
+		}
+
+		bundle.Templates = append(bundle.Templates, bundled)
+	}
+
+	for _, partial := range d.Partials {
+		bundle.Partials = append(bundle.Partials, collections.Partial{
+			Name:    partial.Name,
+			Content: partial.Content,
+		})
+	}
+
+	return bundle
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"text/template"
 
+	"github.com/cloudfoundry-incubator/notifications/markdown"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/cloudfoundry-incubator/notifications/valiant"
@@ -14,9 +15,11 @@ import (
 type TemplateParams struct {
 	Name     string          `json:"name" validate-required:"true"`
 	Text     string          `json:"text"`
-	HTML     string          `json:"html" validate-required:"true"`
+	HTML     string          `json:"html"`
 	Subject  string          `json:"subject"`
 	Metadata json.RawMessage `json:"metadata"`
+	Layout   string          `json:"layout"`
+	Markdown string          `json:"markdown"`
 }
 
 func NewTemplateParams(body io.ReadCloser) (TemplateParams, error) {
@@ -35,6 +38,12 @@ func NewTemplateParams(body io.ReadCloser) (TemplateParams, error) {
 		}
 	}
 
+	if template.HTML == "" && template.Markdown == "" {
+		return template, webutil.ValidationError{Err: valiant.RequiredFieldError{ErrorMessage: "Missing required field 'html'"}}
+	}
+
+	template.renderMarkdown()
+
 	if template.Metadata == nil {
 		template.Metadata = json.RawMessage("{}")
 	}
@@ -57,7 +66,7 @@ func (t TemplateParams) validateSyntax() error {
 	}
 
 	for field, contents := range toValidate {
-		_, err := template.New("test").Parse(contents)
+		_, err := template.New("test").Funcs(markdown.TemplateFuncs).Parse(contents)
 		if err != nil {
 			return webutil.ValidationError{Err: fmt.Errorf("%s syntax is malformed please check your braces", field)}
 		}
@@ -73,6 +82,44 @@ func (t TemplateParams) ToModel() models.Template {
 		HTML:     t.HTML,
 		Subject:  t.Subject,
 		Metadata: string(t.Metadata),
+		Layout:   t.Layout,
+		Markdown: t.Markdown,
+	}
+}
+
+// templateParamsFromModel is the inverse of ToModel. It describes the state
+// of a template in audit events.
+func templateParamsFromModel(template models.Template) TemplateParams {
+	metadata := template.Metadata
+	if metadata == "" {
+		metadata = "{}"
+	}
+
+	return TemplateParams{
+		Name:     template.Name,
+		Text:     template.Text,
+		HTML:     template.HTML,
+		Subject:  template.Subject,
+		Metadata: json.RawMessage(metadata),
+		Layout:   template.Layout,
+		Markdown: template.Markdown,
+	}
+}
+
+// renderMarkdown derives the html and text templates from the markdown
+// template, leaving any explicitly given html or text untouched.
+func (t *TemplateParams) renderMarkdown() {
+	if t.Markdown == "" {
+		return
+	}
+
+	html, text := markdown.RenderTemplate(t.Markdown)
+	if t.HTML == "" {
+		t.HTML = html
+	}
+
+	if t.Text == "" {
+		t.Text = text
 	}
 }
 
@@ -62,6 +62,33 @@ var _ = Describe("TemplateParams", func() {
 				Expect(parameters.Metadata).To(Equal(json.RawMessage("{}")))
 			})
 
+			Context("when a markdown template is given", func() {
+				It("renders the html and text templates from it", func() {
+					body, err := json.Marshal(map[string]interface{}{
+						"name":     "Foo Bar Baz",
+						"markdown": "Hello **{{.Data.name}}**, see [the docs]({{.Data.link}})",
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					parameters, err := templates.NewTemplateParams(ioutil.NopCloser(bytes.NewBuffer(body)))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(parameters.Markdown).To(Equal("Hello **{{.Data.name}}**, see [the docs]({{.Data.link}})"))
+					Expect(parameters.HTML).To(Equal(`<p>Hello <strong>{{.Data.name}}</strong>, see <a href="{{markdownURL (.Data.link)}}">the docs</a></p>`))
+					Expect(parameters.Text).To(Equal("Hello {{.Data.name}}, see the docs ({{.Data.link}})"))
+				})
+
+				It("returns a validation error when the rendered template has invalid syntax", func() {
+					body, err := json.Marshal(map[string]interface{}{
+						"name":     "Foo Bar Baz",
+						"markdown": "Hello {{.bad}",
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = templates.NewTemplateParams(ioutil.NopCloser(bytes.NewBuffer(body)))
+					Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+				})
+			})
+
 			Context("when the template has invalid syntax", func() {
 				Context("when subject template has invalid syntax", func() {
 					It("returns a validation error", func() {
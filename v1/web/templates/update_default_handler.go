@@ -5,21 +5,30 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
 type templateUpdater interface {
-	Update(database services.DatabaseInterface, templateID string, template models.Template) error
+	Update(conn services.ConnectionInterface, templateID string, template models.Template) error
+}
+
+type auditLog interface {
+	Record(conn services.ConnectionInterface, entry services.AuditEntry) error
 }
 
 type UpdateDefaultHandler struct {
+	finder      templateFinder
 	updater     templateUpdater
+	auditLog    auditLog
 	errorWriter errorWriter
 }
 
-func NewUpdateDefaultHandler(updater templateUpdater, errWriter errorWriter) UpdateDefaultHandler {
+func NewUpdateDefaultHandler(finder templateFinder, updater templateUpdater, auditLog auditLog, errWriter errorWriter) UpdateDefaultHandler {
 	return UpdateDefaultHandler{
+		finder:      finder,
 		updater:     updater,
+		auditLog:    auditLog,
 		errorWriter: errWriter,
 	}
 }
@@ -31,9 +40,45 @@ func (h UpdateDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = h.updater.Update(context.Get("database").(DatabaseInterface), models.DefaultTemplateID, template.ToModel())
+	database := context.Get("database").(DatabaseInterface)
+
+	previous, err := h.finder.FindByID(database, models.DefaultTemplateID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	transaction := database.Connection().Transaction()
+	err = transaction.Begin()
 	if err != nil {
 		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.updater.Update(transaction, models.DefaultTemplateID, template.ToModel())
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.auditLog.Record(transaction, services.AuditEntry{
+		Token:  context.Get("token").(*jwt.Token),
+		Action: services.AuditActionUpdateDefaultTemplate,
+		Target: "default_template",
+		Before: templateParamsFromModel(previous),
+		After:  template,
+	})
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
@@ -1,6 +1,7 @@
 package templates_test
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/cloudfoundry-incubator/notifications/valiant"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -23,14 +25,29 @@ var _ = Describe("UpdateDefaultHandler", func() {
 		writer      *httptest.ResponseRecorder
 		request     *http.Request
 		context     stack.Context
+		finder      *mocks.TemplateFinder
 		updater     *mocks.TemplateUpdater
+		auditLog    *mocks.AuditLog
+		token       *jwt.Token
 		errorWriter *mocks.ErrorWriter
 		database    *mocks.Database
+		connection  *mocks.Connection
+		transaction *mocks.Transaction
 	)
 
 	BeforeEach(func() {
 		var err error
+		finder = mocks.NewTemplateFinder()
+		finder.FindByIDCall.Returns.Template = models.Template{
+			ID:       models.DefaultTemplateID,
+			Name:     "Default Template",
+			Subject:  "{{.Subject}}",
+			HTML:     "<p>{{.HTML}}</p>",
+			Text:     "{{.Text}}",
+			Metadata: "{}",
+		}
 		updater = mocks.NewTemplateUpdater()
+		auditLog = mocks.NewAuditLog()
 		errorWriter = mocks.NewErrorWriter()
 		writer = httptest.NewRecorder()
 		request, err = http.NewRequest("PUT", "/default_template", strings.NewReader(`{
@@ -42,18 +59,25 @@ var _ = Describe("UpdateDefaultHandler", func() {
 		}`))
 		Expect(err).NotTo(HaveOccurred())
 
+		connection = mocks.NewConnection()
+		transaction = mocks.NewTransaction()
+		transaction.Connection = connection
+		connection.TransactionCall.Returns.Transaction = transaction
 		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+		token = &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}}
 		context = stack.NewContext()
 		context.Set("database", database)
+		context.Set("token", token)
 
-		handler = templates.NewUpdateDefaultHandler(updater, errorWriter)
+		handler = templates.NewUpdateDefaultHandler(finder, updater, auditLog, errorWriter)
 	})
 
 	It("updates the default template", func() {
 		handler.ServeHTTP(writer, request, context)
 
 		Expect(writer.Code).To(Equal(http.StatusNoContent))
-		Expect(updater.UpdateCall.Receives.Database).To(Equal(database))
+		Expect(updater.UpdateCall.Receives.Connection).To(Equal(transaction))
 		Expect(updater.UpdateCall.Receives.TemplateID).To(Equal(models.DefaultTemplateID))
 		Expect(updater.UpdateCall.Receives.Template).To(Equal(models.Template{
 			Name:     "Defaultish Template",
@@ -64,6 +88,46 @@ var _ = Describe("UpdateDefaultHandler", func() {
 		}))
 	})
 
+	It("records the change in the audit log in the same transaction", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(finder.FindByIDCall.Receives.Database).To(Equal(database))
+		Expect(finder.FindByIDCall.Receives.TemplateID).To(Equal(models.DefaultTemplateID))
+
+		Expect(auditLog.RecordCall.CallCount).To(Equal(1))
+		Expect(auditLog.RecordCall.Receives.Connection).To(Equal(transaction))
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+
+		entry := auditLog.RecordCall.Receives.Entry
+		Expect(entry.Token).To(Equal(token))
+		Expect(entry.Action).To(Equal("default_template.update"))
+		Expect(entry.Target).To(Equal("default_template"))
+
+		before, err := json.Marshal(entry.Before)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(before).To(MatchJSON(`{
+			"name": "Default Template",
+			"subject": "{{.Subject}}",
+			"html": "<p>{{.HTML}}</p>",
+			"text": "{{.Text}}",
+			"metadata": {},
+			"layout": "",
+			"markdown": ""
+		}`))
+
+		after, err := json.Marshal(entry.After)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after).To(MatchJSON(`{
+			"name": "Defaultish Template",
+			"subject": "{{.Subject}}",
+			"html": "<p>something</p>",
+			"text": "something",
+			"metadata": {"hello": true},
+			"layout": "",
+			"markdown": ""
+		}`))
+	})
+
 	Context("when the request is not valid", func() {
 		It("indicates that fields are missing", func() {
 			body := `{
@@ -87,6 +151,41 @@ var _ = Describe("UpdateDefaultHandler", func() {
 			handler.ServeHTTP(writer, request, context)
 
 			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("updating default template error")))
+			Expect(auditLog.RecordCall.CallCount).To(Equal(0))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		})
+	})
+
+	Context("when the default template cannot be found", func() {
+		It("delegates the error handling to the error writer", func() {
+			finder.FindByIDCall.Returns.Error = errors.New("finding default template error")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("finding default template error")))
+			Expect(updater.UpdateCall.Receives.TemplateID).To(BeEmpty())
+		})
+	})
+
+	Context("when the audit log errors", func() {
+		It("rolls the change back and delegates the error handling to the error writer", func() {
+			auditLog.RecordCall.Returns.Error = errors.New("recording audit event error")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("recording audit event error")))
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		})
+	})
+
+	Context("when the transaction cannot be committed", func() {
+		It("delegates the error handling to the error writer", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(models.TransactionCommitError{Err: errors.New("commit failed")}))
 		})
 	})
 })
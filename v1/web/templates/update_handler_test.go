@@ -27,6 +27,7 @@ var _ = Describe("UpdateHandler", func() {
 		updater     *mocks.TemplateUpdater
 		errorWriter *mocks.ErrorWriter
 		database    *mocks.Database
+		connection  *mocks.Connection
 	)
 
 	Describe("ServeHTTP", func() {
@@ -38,7 +39,9 @@ var _ = Describe("UpdateHandler", func() {
 			request, err = http.NewRequest("PUT", "/templates/a-template-id", bytes.NewBuffer(body))
 			Expect(err).NotTo(HaveOccurred())
 
+			connection = mocks.NewConnection()
 			database = mocks.NewDatabase()
+			database.ConnectionCall.Returns.Connection = connection
 			context = stack.NewContext()
 			context.Set("database", database)
 
@@ -49,7 +52,7 @@ var _ = Describe("UpdateHandler", func() {
 			handler.ServeHTTP(writer, request, context)
 			Expect(writer.Code).To(Equal(http.StatusNoContent))
 
-			Expect(updater.UpdateCall.Receives.Database).To(Equal(database))
+			Expect(updater.UpdateCall.Receives.Connection).To(Equal(connection))
 			Expect(updater.UpdateCall.Receives.TemplateID).To(Equal("a-template-id"))
 			Expect(updater.UpdateCall.Receives.Template).To(Equal(models.Template{
 				Name:     "An Interesting Template",
@@ -3,6 +3,8 @@ package webutil
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
@@ -21,7 +23,7 @@ func (writer ErrorWriter) Write(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch err.(type) {
-	case UAAScopesError, CriticalNotificationError, collections.TemplateAssignmentError, MissingUserTokenError, ValidationError:
+	case UAAScopesError, CriticalNotificationError, collections.TemplateAssignmentError, collections.PartialValidationError, collections.SuppressionValidationError, collections.TemplateBundleError, MissingUserTokenError, ValidationError:
 		w.WriteHeader(422)
 	case services.CCDownError:
 		w.WriteHeader(http.StatusBadGateway)
@@ -29,10 +31,15 @@ func (writer ErrorWriter) Write(w http.ResponseWriter, err error) {
 		w.WriteHeader(http.StatusNotFound)
 	case ParseError, SchemaError:
 		w.WriteHeader(http.StatusBadRequest)
+	case RequestTooLargeError:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
 	case models.DuplicateError:
 		w.WriteHeader(http.StatusConflict)
 	case services.DefaultScopeError:
 		w.WriteHeader(http.StatusNotAcceptable)
+	case TooManyRequestsError:
+		w.Header().Set("Retry-After", retryAfterSeconds(err.(TooManyRequestsError).RetryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -41,3 +48,14 @@ func (writer ErrorWriter) Write(w http.ResponseWriter, err error) {
 		"errors": []string{err.Error()},
 	})
 }
+
+// retryAfterSeconds renders a wait as a whole number of seconds, rounding up
+// so that a client that waits as long as it was told is not turned away again.
+func retryAfterSeconds(wait time.Duration) string {
+	seconds := int64((wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return strconv.FormatInt(seconds, 10)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
@@ -130,6 +131,30 @@ var _ = Describe("ErrorWriter", func() {
 		}`))
 	})
 
+	It("returns a 422 when a template bundle cannot be imported", func() {
+		writer.Write(recorder, collections.TemplateBundleError{Err: errors.New("Unsupported bundle version 2")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": ["Unsupported bundle version 2"]
+		}`))
+	})
+
+	It("returns a 422 when a partial is invalid", func() {
+		writer.Write(recorder, collections.PartialValidationError{Err: errors.New("partials form a cycle")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": ["partials form a cycle"]
+		}`))
+	})
+
+	It("returns a 422 when a suppression is invalid", func() {
+		writer.Write(recorder, collections.SuppressionValidationError{Err: errors.New("Suppressed domain \"a@b\" is improperly formatted")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": ["Suppressed domain \"a@b\" is improperly formatted"]
+		}`))
+	})
+
 	It("returns a 422 when a user token was expected but is not present", func() {
 		writer.Write(recorder, webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")})
 		Expect(recorder.Code).To(Equal(422))
@@ -138,6 +163,26 @@ var _ = Describe("ErrorWriter", func() {
 		}`))
 	})
 
+	It("returns a 429 with a Retry-After header when a client has sent too many requests", func() {
+		writer.Write(recorder, webutil.TooManyRequestsError{
+			Err:        errors.New("Client \"some-client\" has exceeded its rate limit"),
+			RetryAfter: 1500 * time.Millisecond,
+		})
+		Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(recorder.HeaderMap.Get("Retry-After")).To(Equal("2"))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": ["Client \"some-client\" has exceeded its rate limit"]
+		}`))
+	})
+
+	It("returns a 413 when the request body is too large", func() {
+		writer.Write(recorder, webutil.RequestTooLargeError{Limit: 1024})
+		Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": ["Request body must not be larger than 1024 bytes"]
+		}`))
+	})
+
 	It("returns a 500 for unknown errors", func() {
 		writer.Write(recorder, errors.New("unknown error"))
 		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
@@ -1,6 +1,9 @@
 package webutil
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type ParseError struct{}
 
@@ -8,6 +11,16 @@ func (err ParseError) Error() string {
 	return "Request body could not be parsed"
 }
 
+// RequestTooLargeError is returned when a request body is larger than the
+// endpoint accepts.
+type RequestTooLargeError struct {
+	Limit int64
+}
+
+func (e RequestTooLargeError) Error() string {
+	return fmt.Sprintf("Request body must not be larger than %d bytes", e.Limit)
+}
+
 type SchemaError struct {
 	Err error
 }
@@ -57,3 +70,14 @@ func NewCriticalNotificationError(kindID string) CriticalNotificationError {
 func (e CriticalNotificationError) Error() string {
 	return e.Err.Error()
 }
+
+// TooManyRequestsError is returned when a client has exceeded its rate limit
+// or quota. RetryAfter is how long the client should wait before trying again.
+type TooManyRequestsError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e TooManyRequestsError) Error() string {
+	return e.Err.Error()
+}
@@ -18,6 +18,15 @@ func NewRouter(config Config) http.Handler {
 		CCHost:            config.CCHost,
 		CORSOrigin:        config.CORSOrigin,
 		SQLDB:             config.SQLDB,
+		KeyRing:           config.KeyRing,
+
+		MailClient:                config.MailClient,
+		UAAKeyRefreshInterval:     config.UAAKeyRefreshInterval,
+		HealthCacheDuration:       config.HealthCacheDuration,
+		HealthQueueDepthThreshold: config.HealthQueueDepthThreshold,
+		MaxRequestBodySize:        config.MaxRequestBodySize,
+
+		Tracer: config.Tracer,
 	})
 
 	return VersionRouter{
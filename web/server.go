@@ -7,6 +7,9 @@ import (
 	"fmt"
 
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/keyring"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/pivotal-golang/lager"
 )
@@ -19,6 +22,7 @@ type Config struct {
 	QueueWaitMaxDuration int
 	SQLDB                *sql.DB
 	Queue                gobble.QueueInterface
+	KeyRing              keyring.KeyRing
 	Logger               lager.Logger
 
 	UAATokenValidator *uaa.TokenValidator
@@ -27,6 +31,14 @@ type Config struct {
 	UAAClientSecret   string
 	DefaultUAAScopes  []string
 	CCHost            string
+
+	MailClient                func() *mail.Client
+	UAAKeyRefreshInterval     int
+	HealthCacheDuration       int
+	HealthQueueDepthThreshold int
+	MaxRequestBodySize        int
+
+	Tracer *tracing.Tracer
 }
 
 type Server struct{}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/prometheus"
+	"github.com/cloudfoundry-incubator/notifications/util"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/health"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+type pinger interface {
+	Ping() error
+}
+
+type queueLengther interface {
+	Len() (int, error)
+}
+
+// WorkerConfig configures the listener of an instance that only delivers
+// notifications. It serves the metrics and health of the workers, but not
+// the API.
+type WorkerConfig struct {
+	Port     int
+	Database pinger
+	Queue    queueLengther
+	Logger   lager.Logger
+
+	HealthCacheDuration       int
+	HealthQueueDepthThreshold int
+}
+
+// NewWorkerRouter serves /metrics like the API does, and /health along with
+// /health/live and /health/ready, which only check the database and the
+// queue that the workers depend on.
+func NewWorkerRouter(config WorkerConfig) http.Handler {
+	mx := NewMuxer()
+
+	checker := health.NewChecker(map[string]health.Check{
+		"database": health.NewDatabaseCheck(config.Database),
+		"queue":    health.NewQueueCheck(config.Queue, config.HealthQueueDepthThreshold),
+	}, time.Duration(config.HealthCacheDuration)*time.Millisecond, util.NewClock())
+
+	mx.GetRouter().Handle("/metrics", prometheus.NewHandler(metrics.DefaultRegistry)).Methods("GET")
+	mx.Handle("GET", "/health", health.NewReadyHandler(checker))
+	mx.Handle("GET", "/health/live", health.NewLiveHandler())
+	mx.Handle("GET", "/health/ready", health.NewReadyHandler(checker))
+
+	return mx
+}
+
+func (s Server) RunWorker(config WorkerConfig) {
+	config.Logger.Info("listen-and-serve", lager.Data{
+		"port": config.Port,
+	})
+
+	http.ListenAndServe(fmt.Sprintf(":%d", config.Port), NewWorkerRouter(config))
+}
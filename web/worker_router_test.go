@@ -0,0 +1,80 @@
+package web_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkerRouter", func() {
+	var (
+		router   http.Handler
+		database *mocks.Pinger
+		queue    *mocks.Queue
+	)
+
+	BeforeEach(func() {
+		database = mocks.NewPinger()
+		queue = mocks.NewQueue()
+
+		router = web.NewWorkerRouter(web.WorkerConfig{
+			Database: database,
+			Queue:    queue,
+			Logger:   lager.NewLogger("notifications"),
+
+			HealthQueueDepthThreshold: 10,
+		})
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest("GET", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		return writer
+	}
+
+	It("serves the metrics of the workers", func() {
+		metrics.GetOrRegisterCounter("notifications.worker.delivered", nil).Inc(1)
+
+		writer := serve("/metrics")
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(ContainSubstring("notifications_worker_delivered"))
+	})
+
+	It("reports the health of the database and the queue", func() {
+		writer := serve("/health")
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"status": "ok",
+			"checks": {
+				"database": {"status": "ok"},
+				"queue": {"status": "ok"}
+			}
+		}`))
+
+		Expect(serve("/health/ready").Code).To(Equal(http.StatusOK))
+		Expect(serve("/health/live").Code).To(Equal(http.StatusOK))
+	})
+
+	It("reports that it is unavailable when the database cannot be reached", func() {
+		database.PingCall.Returns.Error = errors.New("connection refused")
+
+		Expect(serve("/health").Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(serve("/health/live").Code).To(Equal(http.StatusOK))
+	})
+
+	It("does not serve the API", func() {
+		Expect(serve("/notifications").Code).To(Equal(http.StatusNotFound))
+	})
+})